@@ -0,0 +1,31 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// moduleAddress returns the full address of a module call, qualified by its config so
+// that it is unique across configs, such as network/module.vpc. Calls found with
+// -flatten-nested are addressed through their callers, such as
+// network/module.networking.module.subnet, as are the dotted keys of calls read from
+// the module manifest.
+func moduleAddress(mod ModuleInfo) string {
+	names := strings.Split(mod.Name, ".")
+	if mod.Path != "" {
+		names = strings.Split(mod.Path, " > ")[1:]
+	}
+
+	steps := make([]string, len(names))
+	for i, name := range names {
+		steps[i] = "module." + name
+	}
+	return filepath.ToSlash(mod.Config) + "/" + strings.Join(steps, ".")
+}
+
+// setAddresses records the full address of every module call, keeping the short name.
+func setAddresses(modules []ModuleInfo) {
+	for i := range modules {
+		modules[i].Address = moduleAddress(modules[i])
+	}
+}
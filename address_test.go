@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+// TestSetAddressesTopLevel tests addressing top-level module calls by config, and
+// dotted keys read from the module manifest through their callers.
+func TestSetAddressesTopLevel(t *testing.T) {
+	modules := []ModuleInfo{
+		{Config: "network", Name: "vpc"},
+		{Config: "app", Name: "vpc"},
+		{Config: "app", Name: "cluster.node_group"},
+	}
+	setAddresses(modules)
+
+	expected := []string{"network/module.vpc", "app/module.vpc", "app/module.cluster.module.node_group"}
+	for i, mod := range modules {
+		if mod.Address != expected[i] {
+			t.Errorf("Expected address %s, got %s", expected[i], mod.Address)
+		}
+	}
+	if modules[0].Name != "vpc" {
+		t.Errorf("Expected the short name to be kept, got %s", modules[0].Name)
+	}
+	if keyOf(modules[0]) == keyOf(modules[1]) {
+		t.Errorf("Expected modules of the same name in different configs to have different keys")
+	}
+}
+
+// TestSetAddressesNested tests addressing modules found with -flatten-nested through
+// their callers.
+func TestSetAddressesNested(t *testing.T) {
+	sbom, err := generateSBOM(context.Background(), "testdata/nested", scanOptions{FlattenNested: true})
+	if err != nil {
+		t.Fatalf("Failed to generate SBOM: %v", err)
+	}
+	setAddresses(sbom.Modules)
+
+	var addresses []string
+	for _, mod := range sbom.Modules {
+		addresses = append(addresses, mod.Address)
+	}
+	expected := []string{
+		"testdata/nested/module.networking",
+		"testdata/nested/module.networking.module.dns",
+		"testdata/nested/module.networking.module.subnet",
+		"testdata/nested/module.networking.module.subnet.module.label",
+		"testdata/nested/module.vpc",
+	}
+	if !reflect.DeepEqual(addresses, expected) {
+		t.Errorf("Addresses mismatch:\nexpected %v\ngot      %v", expected, addresses)
+	}
+}
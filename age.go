@@ -0,0 +1,203 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// defaultAgeTimeout bounds each lookup of the date a module version was published.
+const defaultAgeTimeout = 30 * time.Second
+
+// ageChecker looks up when the version each module is pinned to was published:
+// registry modules through the registry's module API, and git, GitHub, and
+// Bitbucket modules from the commit date of their ref. Unpinned modules and other
+// source types are not checked.
+type ageChecker struct {
+	client      *apiClient
+	timeout     time.Duration
+	credentials registryCredentials // Tokens sent to private registries
+	cache       *registryCache      // Reuses earlier registry lookups when set
+
+	// now returns the current time ages are computed against. It is replaced in tests.
+	now func() time.Time
+	// commitDate returns the commit date of a ref in a git repository. It is replaced in tests.
+	commitDate func(ctx context.Context, url, ref string) (time.Time, error)
+}
+
+// newAgeChecker creates a checker sending registry requests through client.
+func newAgeChecker(client *apiClient) *ageChecker {
+	return &ageChecker{
+		client:     client,
+		timeout:    defaultAgeTimeout,
+		now:        time.Now,
+		commitDate: gitCommitDate,
+	}
+}
+
+// checkAges records the age in days of the version each module is pinned to, and
+// returns a warning for each lookup that failed. Versions shared by several modules,
+// including ones spelling the source differently, are only looked up once.
+func (c *ageChecker) checkAges(ctx context.Context, sbom *SBOM) []string {
+	type result struct {
+		published time.Time
+		err       error
+	}
+	results := make(map[string]result)
+
+	var warnings []string
+	for i := range sbom.Modules {
+		mod := &sbom.Modules[i]
+
+		key := mod.SourceType + " " + normalizeSource(mod.Source) + " " + mod.Version
+		r, ok := results[key]
+		if !ok {
+			var checked bool
+			checked, r.published, r.err = c.published(ctx, *mod)
+			if !checked {
+				continue
+			}
+			results[key] = r
+		}
+
+		if r.err != nil {
+			warnings = append(warnings, fmt.Sprintf("%s: failed to look up the age of module %s: %v", mod.Config, mod.Name, r.err))
+			continue
+		}
+		days := int(c.now().Sub(r.published).Hours() / 24)
+		if days < 0 {
+			days = 0
+		}
+		mod.AgeDays = &days
+	}
+	return warnings
+}
+
+// published returns when a module's pinned version was published. It reports false
+// if the module is not pinned or its source type is not checked.
+func (c *ageChecker) published(ctx context.Context, mod ModuleInfo) (bool, time.Time, error) {
+	if isUnpinned(mod) {
+		return false, time.Time{}, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	switch mod.SourceType {
+	case sourceTypeRegistry:
+		version := exactVersion(mod.Version)
+		if version == "" {
+			return false, time.Time{}, nil
+		}
+		published, err := c.registryPublished(ctx, mod.Source, version)
+		return true, published, err
+	case sourceTypeGit, sourceTypeGitHub, sourceTypeBitbucket:
+		published, err := c.commitDate(ctx, gitRemoteURL(mod.Source), mod.Version)
+		return true, published, err
+	}
+
+	return false, time.Time{}, nil
+}
+
+// registryPublished returns when a version of a registry module was published,
+// from the published_at date the registry's module API reports for it, or from the
+// cache if it was looked up recently.
+func (c *ageChecker) registryPublished(ctx context.Context, source, version string) (time.Time, error) {
+	host, address := splitRegistryAddress(source)
+
+	key := host + "/" + address + "@" + version
+	if c.cache != nil {
+		if entry, ok := c.cache.get(key); ok {
+			return entry.published(), entry.err()
+		}
+	}
+
+	cacheable, published, err := c.fetchPublished(ctx, host, address, version)
+	if cacheable && c.cache != nil {
+		c.cache.putPublished(key, published, err)
+	}
+	return published, err
+}
+
+// fetchPublished requests a version of a registry module from the registry's module
+// API. It reports whether the result can be cached: only a version that was found,
+// or one the registry does not know, is. Network errors and other statuses, such as
+// 401 or 503, may not last and are looked up again next time.
+func (c *ageChecker) fetchPublished(ctx context.Context, host, address, version string) (bool, time.Time, error) {
+	url := fmt.Sprintf("https://%s/v1/modules/%s/%s", host, address, version)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return false, time.Time{}, fmt.Errorf("failed to create registry request: %v", err)
+	}
+	c.credentials.authorize(req)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return false, time.Time{}, fmt.Errorf("registry request failed: %v", err)
+	}
+	defer closeResponse(resp)
+
+	if resp.StatusCode != http.StatusOK {
+		return isCacheableStatus(resp.StatusCode), time.Time{}, fmt.Errorf("registry returned %s", resp.Status)
+	}
+
+	var body struct {
+		PublishedAt time.Time `json:"published_at"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return false, time.Time{}, fmt.Errorf("failed to decode registry response: %v", err)
+	}
+	if body.PublishedAt.IsZero() {
+		return true, time.Time{}, fmt.Errorf("registry did not report when %s was published", version)
+	}
+	return true, body.PublishedAt, nil
+}
+
+// gitCommitDate returns the committer date of the commit a ref points to, fetching
+// only that commit into a temporary repository. The ref and URL come from the scanned
+// configuration, so ones git would read as an option are refused.
+func gitCommitDate(ctx context.Context, url, ref string) (time.Time, error) {
+	if strings.HasPrefix(url, "-") {
+		return time.Time{}, fmt.Errorf("refusing to fetch %q: a git URL cannot start with -", url)
+	}
+	if strings.HasPrefix(ref, "-") {
+		return time.Time{}, fmt.Errorf("refusing to fetch ref %q: a git ref cannot start with -", ref)
+	}
+
+	dir, err := os.MkdirTemp("", "terraform-sbom-git-*")
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	if _, err := runGit(ctx, dir, "init", "--quiet"); err != nil {
+		return time.Time{}, err
+	}
+	if _, err := runGit(ctx, dir, "fetch", "--quiet", "--depth", "1", "--", url, ref); err != nil {
+		return time.Time{}, err
+	}
+	lines, err := runGit(ctx, dir, "log", "-1", "--format=%cI", "FETCH_HEAD")
+	if err != nil {
+		return time.Time{}, err
+	}
+	if len(lines) == 0 {
+		return time.Time{}, fmt.Errorf("no commit found for %s", ref)
+	}
+	return time.Parse(time.RFC3339, lines[0])
+}
+
+// staleWarnings returns a warning for each module whose pinned version is older
+// than the given number of days.
+func staleWarnings(modules []ModuleInfo, staleAfter int) []string {
+	var warnings []string
+	for _, mod := range modules {
+		if mod.AgeDays != nil && *mod.AgeDays > staleAfter {
+			warnings = append(warnings, fmt.Sprintf("%s: module %s is pinned to %s, which is %d days old", mod.Config, mod.Name, mod.Version, *mod.AgeDays))
+		}
+	}
+	return warnings
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestCheckAges tests recording the age of registry and git modules using stubbed externals.
+func TestCheckAges(t *testing.T) {
+	var requests []string
+	client := newAPIClient(roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		requests = append(requests, req.URL.String())
+		if req.URL.Path == "/v1/modules/terraform-aws-modules/vpc/aws/5.1.0" {
+			resp := stubResponse(http.StatusOK, nil)
+			resp.Body = io.NopCloser(strings.NewReader(`{"id": "terraform-aws-modules/vpc/aws/5.1.0", "published_at": "2024-01-01T12:00:00Z"}`))
+			return resp, nil
+		}
+		return &http.Response{Status: "404 Not Found", StatusCode: http.StatusNotFound, Header: make(http.Header), Body: http.NoBody}, nil
+	}), 0)
+
+	var fetches []string
+	checker := newAgeChecker(client)
+	checker.now = func() time.Time { return time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC) }
+	checker.commitDate = func(ctx context.Context, url, ref string) (time.Time, error) {
+		fetches = append(fetches, url+"@"+ref)
+		if ref == "missing" {
+			return time.Time{}, errors.New("couldn't find remote ref missing")
+		}
+		return time.Date(2023, 3, 1, 0, 0, 0, 0, time.UTC), nil
+	}
+
+	sbom := &SBOM{Modules: []ModuleInfo{
+		{Name: "vpc", Source: "terraform-aws-modules/vpc/aws", SourceType: sourceTypeRegistry, Version: "5.1.0", Config: "network"},
+		{Name: "vpc_dr", Source: "terraform-aws-modules/vpc/aws", SourceType: sourceTypeRegistry, Version: "5.1.0", Config: "network-dr"},
+		{Name: "eks", Source: "terraform-aws-modules/eks/aws", SourceType: sourceTypeRegistry, Version: "~> 20.0", Config: "network"},
+		{Name: "db", Source: "git::https://github.com/acme/terraform-db.git?ref=v1.4.2", SourceType: sourceTypeGit, Version: "v1.4.2", Config: "app"},
+		{Name: "dns", Source: "github.com/acme/dns?ref=missing", SourceType: sourceTypeGitHub, Version: "missing", Config: "app"},
+		{Name: "cache", Source: "github.com/acme/cache", SourceType: sourceTypeGitHub, Version: "N/A", Config: "app"},
+		{Name: "service", Source: "./modules/service", SourceType: sourceTypeLocal, Version: "local", Config: "app"},
+	}}
+
+	warnings := checker.checkAges(context.Background(), sbom)
+
+	ages := make(map[string]int)
+	for _, mod := range sbom.Modules {
+		if mod.AgeDays != nil {
+			ages[mod.Name] = *mod.AgeDays
+		}
+	}
+	expectedAges := map[string]int{"vpc": 59, "vpc_dr": 59, "db": 366}
+	if !reflect.DeepEqual(ages, expectedAges) {
+		t.Errorf("Expected ages %v, got %v", expectedAges, ages)
+	}
+
+	expectedWarnings := []string{"app: failed to look up the age of module dns: couldn't find remote ref missing"}
+	if !reflect.DeepEqual(warnings, expectedWarnings) {
+		t.Errorf("Expected warnings %v, got %v", expectedWarnings, warnings)
+	}
+
+	expectedRequests := []string{"https://registry.terraform.io/v1/modules/terraform-aws-modules/vpc/aws/5.1.0"}
+	if !reflect.DeepEqual(requests, expectedRequests) {
+		t.Errorf("Expected registry requests %v, got %v", expectedRequests, requests)
+	}
+	expectedFetches := []string{"https://github.com/acme/terraform-db.git@v1.4.2", "https://github.com/acme/dns.git@missing"}
+	if !reflect.DeepEqual(fetches, expectedFetches) {
+		t.Errorf("Expected git fetches %v, got %v", expectedFetches, fetches)
+	}
+}
+
+// TestStaleWarnings tests that only modules older than the threshold are reported.
+func TestStaleWarnings(t *testing.T) {
+	old, recent := 400, 30
+	modules := []ModuleInfo{
+		{Name: "db", Version: "v1.4.2", Config: "app", AgeDays: &old},
+		{Name: "vpc", Version: "5.1.0", Config: "network", AgeDays: &recent},
+		{Name: "service", Version: "local", Config: "app"},
+	}
+
+	expected := []string{"app: module db is pinned to v1.4.2, which is 400 days old"}
+	if warnings := staleWarnings(modules, 365); !reflect.DeepEqual(warnings, expected) {
+		t.Errorf("Expected warnings %v, got %v", expected, warnings)
+	}
+}
+
+// TestGitCommitDate tests reading the commit date of a tag from a local repository.
+func TestGitCommitDate(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not available")
+	}
+
+	repo := t.TempDir()
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-C", repo}, args...)...)
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com", "GIT_COMMITTER_DATE=2023-06-15T10:00:00Z")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v: %s", args, err, out)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(repo, "main.tf"), []byte("variable \"name\" {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	git("init", "-q")
+	git("add", "-A")
+	git("commit", "-q", "-m", "initial")
+	git("tag", "v1.0.0")
+
+	date, err := gitCommitDate(context.Background(), "file://"+filepath.ToSlash(repo), "v1.0.0")
+	if err != nil {
+		t.Fatalf("Failed to read commit date: %v", err)
+	}
+	if expected := time.Date(2023, 6, 15, 10, 0, 0, 0, time.UTC); !date.Equal(expected) {
+		t.Errorf("Expected commit date %v, got %v", expected, date)
+	}
+}
+
+// TestGitCommitDateOptionInjection tests that a ref or URL from the scanned configuration
+// that git would read as an option, such as --upload-pack, is refused instead of run.
+func TestGitCommitDateOptionInjection(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not available")
+	}
+
+	dir := t.TempDir()
+	marker := filepath.Join(dir, "pwned")
+	script := filepath.Join(dir, "evil.sh")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\ntouch "+marker+"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	repo := filepath.Join(dir, "repo")
+	if out, err := exec.Command("git", "init", "-q", repo).CombinedOutput(); err != nil {
+		t.Fatalf("git init failed: %v: %s", err, out)
+	}
+
+	tests := []struct {
+		url string
+		ref string
+	}{
+		{"file://" + filepath.ToSlash(repo), "--upload-pack=" + script},
+		{"--upload-pack=" + script, "v1.0.0"},
+	}
+	for _, tt := range tests {
+		if _, err := gitCommitDate(context.Background(), tt.url, tt.ref); err == nil {
+			t.Errorf("Expected %s@%s to be refused", tt.url, tt.ref)
+		}
+	}
+	if _, err := os.Stat(marker); err == nil {
+		t.Errorf("Expected the --upload-pack command not to run")
+	}
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// Default retry behaviour for registry and GitHub API calls.
+const (
+	defaultAPIRetries = 3
+	apiBaseDelay      = 500 * time.Millisecond
+	apiMaxDelay       = 30 * time.Second
+	apiMaxRetryAfter  = time.Minute
+	apiRequestTimeout = 30 * time.Second
+)
+
+// Connection pooling for API calls. A scan looks up every module from the same
+// few hosts, so keeping idle connections per host open lets each lookup skip the
+// TCP and TLS handshakes, where http.DefaultTransport keeps only two per host.
+const (
+	apiMaxIdleConns        = 64
+	apiMaxIdleConnsPerHost = 16
+	apiIdleConnTimeout     = 90 * time.Second
+	apiMaxDrain            = 64 << 10
+)
+
+// apiClient is the HTTP client shared by every feature that calls a remote API.
+// Requests failing with a network error, 429 Too Many Requests, or a 5xx status
+// are retried with exponential backoff, honoring any Retry-After header. With a
+// rate limit, every attempt first waits for a token from a bucket shared by all
+// callers, so concurrent checks together stay under the limit.
+type apiClient struct {
+	httpClient *http.Client
+	maxRetries int
+	baseDelay  time.Duration
+	maxDelay   time.Duration
+	limiter    *rate.Limiter // Spaces out requests when set
+
+	// sleep waits between attempts. It is replaced in tests to avoid real delays.
+	sleep func(ctx context.Context, d time.Duration) error
+	// now returns the current time for the rate limiter. It is replaced in tests.
+	now func() time.Time
+}
+
+// newAPIClient creates an API client sending requests through the given transport,
+// retrying failed requests up to maxRetries times. A nil transport uses a pooled
+// transport from newAPITransport.
+func newAPIClient(transport http.RoundTripper, maxRetries int) *apiClient {
+	if transport == nil {
+		transport = newAPITransport()
+	}
+	if maxRetries < 0 {
+		maxRetries = 0
+	}
+
+	return &apiClient{
+		httpClient: &http.Client{Transport: transport, Timeout: apiRequestTimeout},
+		maxRetries: maxRetries,
+		baseDelay:  apiBaseDelay,
+		maxDelay:   apiMaxDelay,
+		sleep:      sleepContext,
+		now:        time.Now,
+	}
+}
+
+// newAPITransport returns a copy of http.DefaultTransport, keeping its proxy and
+// timeout settings, that holds more idle connections open per host.
+func newAPITransport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConns = apiMaxIdleConns
+	transport.MaxIdleConnsPerHost = apiMaxIdleConnsPerHost
+	transport.IdleConnTimeout = apiIdleConnTimeout
+	return transport
+}
+
+// withRateLimit limits the client to rps requests per second, evenly spaced. A
+// limit of zero or less leaves the client unlimited.
+func (c *apiClient) withRateLimit(rps float64) *apiClient {
+	if rps > 0 {
+		c.limiter = rate.NewLimiter(rate.Limit(rps), 1)
+	}
+	return c
+}
+
+// wait blocks until the rate limiter allows another request.
+func (c *apiClient) wait(ctx context.Context) error {
+	if c.limiter == nil {
+		return nil
+	}
+
+	now := c.now()
+	reservation := c.limiter.ReserveN(now, 1)
+	delay := reservation.DelayFrom(now)
+	if delay <= 0 {
+		return nil
+	}
+	if err := c.sleep(ctx, delay); err != nil {
+		reservation.CancelAt(c.now())
+		return err
+	}
+	return nil
+}
+
+// Do sends the request, retrying transient failures. Requests with a body must
+// set GetBody so the body can be replayed. The response of the final attempt is
+// returned, so callers still see the status of a request that never succeeded.
+func (c *apiClient) Do(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+
+	for attempt := 0; ; attempt++ {
+		attemptReq := req.Clone(ctx)
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			attemptReq.Body = body
+		}
+
+		if err := c.wait(ctx); err != nil {
+			return nil, err
+		}
+
+		resp, err := c.httpClient.Do(attemptReq)
+		if attempt >= c.maxRetries || ctx.Err() != nil || !shouldRetry(resp, err) {
+			return resp, err
+		}
+
+		delay := c.backoff(attempt, resp)
+		if resp != nil {
+			closeResponse(resp)
+		}
+
+		if err := c.sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// closeResponse reads what is left of a response body before closing it, so the
+// connection goes back to the pool instead of being torn down. Bodies are only
+// drained up to a limit, since a large leftover is cheaper to drop with the
+// connection than to read.
+func closeResponse(resp *http.Response) {
+	io.Copy(io.Discard, io.LimitReader(resp.Body, apiMaxDrain))
+	resp.Body.Close()
+}
+
+// shouldRetry reports whether a request outcome is a transient failure.
+func shouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+}
+
+// backoff returns how long to wait before the next attempt. A Retry-After header
+// on the response takes precedence over the exponential schedule.
+func (c *apiClient) backoff(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if delay, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+			return min(delay, apiMaxRetryAfter)
+		}
+	}
+
+	delay := c.baseDelay << attempt
+	if delay <= 0 || delay > c.maxDelay {
+		delay = c.maxDelay
+	}
+	return delay
+}
+
+// parseRetryAfter parses a Retry-After header given either as a number of seconds
+// or as an HTTP date.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		return max(date.Sub(now), 0), true
+	}
+
+	return 0, false
+}
+
+// sleepContext waits for the given duration or until the context is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
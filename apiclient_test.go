@@ -0,0 +1,301 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// roundTripperFunc adapts a function to http.RoundTripper for stubbing API calls.
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// stubResponse creates a response with the given status code and headers.
+func stubResponse(status int, headers map[string]string) *http.Response {
+	resp := &http.Response{
+		StatusCode: status,
+		Header:     make(http.Header),
+		Body:       io.NopCloser(strings.NewReader("")),
+	}
+	for key, value := range headers {
+		resp.Header.Set(key, value)
+	}
+	return resp
+}
+
+// newStubAPIClient creates an API client that replays the given responses and records its delays.
+func newStubAPIClient(maxRetries int, responses []*http.Response, delays *[]time.Duration) (*apiClient, *int) {
+	attempts := 0
+	client := newAPIClient(roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		resp := responses[attempts]
+		attempts++
+		if resp == nil {
+			return nil, errors.New("connection reset")
+		}
+		return resp, nil
+	}), maxRetries)
+
+	client.sleep = func(ctx context.Context, d time.Duration) error {
+		*delays = append(*delays, d)
+		return ctx.Err()
+	}
+
+	return client, &attempts
+}
+
+// TestAPIClientRetriesTransientFailures tests retrying 5xx, 429, and network errors with backoff.
+func TestAPIClientRetriesTransientFailures(t *testing.T) {
+	var delays []time.Duration
+	client, attempts := newStubAPIClient(3, []*http.Response{
+		stubResponse(http.StatusServiceUnavailable, nil),
+		nil,
+		stubResponse(http.StatusTooManyRequests, map[string]string{"Retry-After": "7"}),
+		stubResponse(http.StatusOK, nil),
+	}, &delays)
+
+	req, _ := http.NewRequest(http.MethodGet, "https://registry.example.com/v1/modules", nil)
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Expected request to succeed, got %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", resp.StatusCode)
+	}
+	if *attempts != 4 {
+		t.Errorf("Expected 4 attempts, got %d", *attempts)
+	}
+
+	expected := []time.Duration{apiBaseDelay, 2 * apiBaseDelay, 7 * time.Second}
+	if !reflect.DeepEqual(delays, expected) {
+		t.Errorf("Backoff delays mismatch: expected %v, got %v", expected, delays)
+	}
+}
+
+// TestAPIClientGivesUp tests that the final response is returned once retries are exhausted.
+func TestAPIClientGivesUp(t *testing.T) {
+	var delays []time.Duration
+	client, attempts := newStubAPIClient(1, []*http.Response{
+		stubResponse(http.StatusBadGateway, nil),
+		stubResponse(http.StatusBadGateway, nil),
+	}, &delays)
+
+	req, _ := http.NewRequest(http.MethodGet, "https://registry.example.com/v1/modules", nil)
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Expected the final response, got error %v", err)
+	}
+	if resp.StatusCode != http.StatusBadGateway {
+		t.Errorf("Expected status 502, got %d", resp.StatusCode)
+	}
+	if *attempts != 2 {
+		t.Errorf("Expected 2 attempts, got %d", *attempts)
+	}
+}
+
+// TestAPIClientDoesNotRetryClientErrors tests that 4xx responses other than 429 are returned immediately.
+func TestAPIClientDoesNotRetryClientErrors(t *testing.T) {
+	var delays []time.Duration
+	client, attempts := newStubAPIClient(3, []*http.Response{
+		stubResponse(http.StatusNotFound, nil),
+	}, &delays)
+
+	req, _ := http.NewRequest(http.MethodGet, "https://registry.example.com/v1/modules", nil)
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if resp.StatusCode != http.StatusNotFound || *attempts != 1 {
+		t.Errorf("Expected a single 404 attempt, got status %d after %d attempts", resp.StatusCode, *attempts)
+	}
+}
+
+// TestAPIClientCancelled tests that cancelling the context stops further retries.
+func TestAPIClientCancelled(t *testing.T) {
+	var delays []time.Duration
+	client, _ := newStubAPIClient(3, []*http.Response{
+		stubResponse(http.StatusServiceUnavailable, nil),
+	}, &delays)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	client.sleep = func(context.Context, time.Duration) error {
+		cancel()
+		return ctx.Err()
+	}
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "https://registry.example.com/v1/modules", nil)
+	_, err := client.Do(req)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+// TestAPIClientRateLimit tests that rate-limited requests are spaced evenly.
+func TestAPIClientRateLimit(t *testing.T) {
+	var delays []time.Duration
+	client, attempts := newStubAPIClient(0, []*http.Response{
+		stubResponse(http.StatusOK, nil),
+		stubResponse(http.StatusOK, nil),
+		stubResponse(http.StatusOK, nil),
+	}, &delays)
+	client.withRateLimit(20)
+
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	client.now = func() time.Time { return now }
+	client.sleep = func(ctx context.Context, d time.Duration) error {
+		delays = append(delays, d)
+		now = now.Add(d)
+		return ctx.Err()
+	}
+
+	for i := 0; i < 3; i++ {
+		req, _ := http.NewRequest(http.MethodGet, "https://registry.example.com/v1/modules", nil)
+		if _, err := client.Do(req); err != nil {
+			t.Fatalf("Expected request to succeed, got %v", err)
+		}
+	}
+	if *attempts != 3 {
+		t.Errorf("Expected 3 attempts, got %d", *attempts)
+	}
+
+	expected := []time.Duration{50 * time.Millisecond, 50 * time.Millisecond}
+	if !reflect.DeepEqual(delays, expected) {
+		t.Errorf("Rate limit delays mismatch: expected %v, got %v", expected, delays)
+	}
+}
+
+// TestParseRetryAfter tests both forms of the Retry-After header.
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value    string
+		expected time.Duration
+		ok       bool
+	}{
+		{"", 0, false},
+		{"120", 2 * time.Minute, true},
+		{"-1", 0, false},
+		{"Mon, 01 Jan 2024 12:00:30 GMT", 30 * time.Second, true},
+		{"Mon, 01 Jan 2024 11:00:00 GMT", 0, true},
+		{"soon", 0, false},
+	}
+
+	for _, tt := range tests {
+		delay, ok := parseRetryAfter(tt.value, now)
+		if delay != tt.expected || ok != tt.ok {
+			t.Errorf("parseRetryAfter(%q) = (%v, %t), expected (%v, %t)", tt.value, delay, ok, tt.expected, tt.ok)
+		}
+	}
+}
+
+// newRegistryTestClient starts a stub registry over TLS and returns an API client
+// whose transport dials it for every host, with the number of connections the stub
+// has accepted. Lookups of a module version report a published_at date, and version
+// lists are empty.
+func newRegistryTestClient(tb testing.TB, transport *http.Transport) (*apiClient, *atomic.Int32) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/versions") {
+			json.NewEncoder(w).Encode(map[string]any{"modules": []any{}})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"id": strings.TrimPrefix(r.URL.Path, "/v1/modules/"), "published_at": "2024-01-01T12:00:00Z"})
+	}))
+	var connections atomic.Int32
+	server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			connections.Add(1)
+		}
+	}
+	server.StartTLS()
+	tb.Cleanup(server.Close)
+
+	addr := server.Listener.Addr().String()
+	dialer := &net.Dialer{}
+	transport.DialContext = func(ctx context.Context, network, _ string) (net.Conn, error) {
+		return dialer.DialContext(ctx, network, addr)
+	}
+	transport.TLSClientConfig = server.Client().Transport.(*http.Transport).TLSClientConfig.Clone()
+	transport.TLSClientConfig.ServerName = "example.com"
+	tb.Cleanup(transport.CloseIdleConnections)
+
+	return newAPIClient(transport, 0), &connections
+}
+
+// registryModules returns count registry modules, each pinned to a different version.
+func registryModules(count int) []ModuleInfo {
+	modules := make([]ModuleInfo, count)
+	for i := range modules {
+		modules[i] = ModuleInfo{
+			Name:       fmt.Sprintf("vpc_%d", i),
+			Source:     "terraform-aws-modules/vpc/aws",
+			SourceType: sourceTypeRegistry,
+			Version:    fmt.Sprintf("5.%d.0", i),
+			Config:     "network",
+		}
+	}
+	return modules
+}
+
+// TestAPIClientReusesConnections tests that age and reachability lookups sharing an
+// API client are sent over a single pooled connection.
+func TestAPIClientReusesConnections(t *testing.T) {
+	client, connections := newRegistryTestClient(t, newAPITransport())
+
+	sbom := &SBOM{Modules: registryModules(10)}
+	if warnings := newAgeChecker(client).checkAges(context.Background(), sbom); len(warnings) > 0 {
+		t.Fatalf("Expected no warnings, got %v", warnings)
+	}
+	sbom.Modules = append(sbom.Modules, ModuleInfo{Name: "eks", Source: "terraform-aws-modules/eks/aws", SourceType: sourceTypeRegistry, Config: "network"})
+	newReachabilityChecker(client, time.Second).checkReachability(context.Background(), sbom)
+
+	for _, mod := range sbom.Modules {
+		if mod.Reachable == nil || !*mod.Reachable {
+			t.Errorf("Expected module %s to be reachable, got %q", mod.Name, mod.ReachabilityError)
+		}
+	}
+	if got := connections.Load(); got != 1 {
+		t.Errorf("Expected 12 lookups to share 1 connection, got %d connections", got)
+	}
+}
+
+// BenchmarkRegistryLookups compares looking up the ages of 20 module versions with
+// the pooled API transport against opening a connection for every lookup.
+func BenchmarkRegistryLookups(b *testing.B) {
+	transports := map[string]func() *http.Transport{
+		"pooled": newAPITransport,
+		"unpooled": func() *http.Transport {
+			transport := newAPITransport()
+			transport.DisableKeepAlives = true
+			return transport
+		},
+	}
+
+	for _, name := range []string{"pooled", "unpooled"} {
+		b.Run(name, func(b *testing.B) {
+			client, connections := newRegistryTestClient(b, transports[name]())
+			checker := newAgeChecker(client)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				sbom := &SBOM{Modules: registryModules(20)}
+				if warnings := checker.checkAges(context.Background(), sbom); len(warnings) > 0 {
+					b.Fatalf("Expected no warnings, got %v", warnings)
+				}
+			}
+			b.ReportMetric(float64(connections.Load())/float64(b.N), "conns/op")
+		})
+	}
+}
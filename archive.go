@@ -0,0 +1,159 @@
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// isTarArchive reports whether a config path names a tar archive, optionally
+// gzip-compressed, rather than a directory.
+func isTarArchive(path string) bool {
+	name := strings.ToLower(path)
+	return strings.HasSuffix(name, ".tar") || strings.HasSuffix(name, ".tar.gz") || strings.HasSuffix(name, ".tgz")
+}
+
+// generateSBOMFromArchive generates an SBOM for the Terraform configuration bundled in a
+// tar or tar.gz archive. The archive is extracted to a temporary directory that is
+// scanned, recursively if requested, and then removed. Config paths and warnings in
+// the result refer to the archive, such as bundle.tar.gz/network.
+func generateSBOMFromArchive(ctx context.Context, archivePath string, recursive bool, opts scanOptions, progress *progressReporter) (*SBOM, error) {
+	dir, err := os.MkdirTemp("", "terraform-sbom-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	err = extractTarArchive(archivePath, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var sbom *SBOM
+	if recursive {
+		sbom, err = generateRecursiveSBOM(ctx, dir, opts, progress)
+	} else {
+		sbom, err = generateSBOM(ctx, dir, opts)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	relocateSBOM(sbom, dir, filepath.ToSlash(archivePath))
+	return sbom, nil
+}
+
+// relocateSBOM rewrites the config paths and warnings of an SBOM generated from dir
+// to refer to root instead.
+func relocateSBOM(sbom *SBOM, dir string, root string) {
+	relocate := func(p string) string {
+		if p == dir {
+			return root
+		}
+		if rel, ok := strings.CutPrefix(p, dir+string(filepath.Separator)); ok {
+			return path.Join(root, filepath.ToSlash(rel))
+		}
+		return p
+	}
+
+	for i := range sbom.Modules {
+		sbom.Modules[i].Config = relocate(sbom.Modules[i].Config)
+		sbom.Modules[i].File = relocate(sbom.Modules[i].File)
+	}
+	for i := range sbom.Providers {
+		sbom.Providers[i].Config = relocate(sbom.Providers[i].Config)
+		sbom.Providers[i].File = relocate(sbom.Providers[i].File)
+	}
+	for i := range sbom.Outputs {
+		sbom.Outputs[i].Config = relocate(sbom.Outputs[i].Config)
+	}
+	for i := range sbom.Configs {
+		sbom.Configs[i].Path = relocate(sbom.Configs[i].Path)
+	}
+	for i, warning := range sbom.Warnings {
+		sbom.Warnings[i] = strings.ReplaceAll(warning, dir, root)
+	}
+	for i := range sbom.LoadErrors {
+		sbom.LoadErrors[i].Path = relocate(sbom.LoadErrors[i].Path)
+		sbom.LoadErrors[i].Message = strings.ReplaceAll(sbom.LoadErrors[i].Message, dir, root)
+	}
+}
+
+// extractTarArchive extracts the directories and regular files of a tar archive to
+// dir, decompressing it first if it is gzip-compressed. Archives with an entry that
+// would be written outside dir, such as ../main.tf or an absolute path, are rejected
+// as a whole. Symbolic links, hard links, and other special entries are skipped.
+func extractTarArchive(archivePath string, dir string) error {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return fmt.Errorf("failed to open archive: %v", err)
+	}
+	defer file.Close()
+
+	var r io.Reader = file
+	name := strings.ToLower(archivePath)
+	if strings.HasSuffix(name, ".gz") || strings.HasSuffix(name, ".tgz") {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return fmt.Errorf("failed to decompress archive: %v", err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	reader := tar.NewReader(r)
+	for {
+		header, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read archive: %v", err)
+		}
+
+		entry := filepath.FromSlash(strings.TrimPrefix(header.Name, "./"))
+		if entry == "" || entry == "." {
+			continue
+		}
+		if !filepath.IsLocal(entry) {
+			return fmt.Errorf("archive entry %s is outside the archive root", header.Name)
+		}
+		target := filepath.Join(dir, entry)
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return fmt.Errorf("failed to extract %s: %v", header.Name, err)
+			}
+		case tar.TypeReg:
+			if err := extractTarFile(reader, target); err != nil {
+				return fmt.Errorf("failed to extract %s: %v", header.Name, err)
+			}
+		}
+	}
+}
+
+// extractTarFile writes the contents of the current archive entry to target,
+// creating its parent directories.
+func extractTarFile(r io.Reader, target string) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+
+	out, err := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, r); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
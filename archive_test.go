@@ -0,0 +1,130 @@
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeTestArchive writes a tar archive with the given entries, gzip-compressed when
+// the path ends in .gz or .tgz. Entries ending in / are written as directories.
+func writeTestArchive(t *testing.T, path string, entries [][2]string) {
+	t.Helper()
+
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create archive: %v", err)
+	}
+	defer file.Close()
+
+	var w io.Writer = file
+	if strings.HasSuffix(path, ".gz") || strings.HasSuffix(path, ".tgz") {
+		gz := gzip.NewWriter(file)
+		defer gz.Close()
+		w = gz
+	}
+
+	tw := tar.NewWriter(w)
+	for _, entry := range entries {
+		header := &tar.Header{Name: entry[0], Mode: 0644, Size: int64(len(entry[1])), Typeflag: tar.TypeReg}
+		if strings.HasSuffix(entry[0], "/") {
+			header = &tar.Header{Name: entry[0], Mode: 0755, Typeflag: tar.TypeDir}
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatalf("Failed to write archive header: %v", err)
+		}
+		if _, err := tw.Write([]byte(entry[1])); err != nil {
+			t.Fatalf("Failed to write archive entry: %v", err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("Failed to close archive: %v", err)
+	}
+}
+
+// TestIsTarArchive tests recognizing archive config paths.
+func TestIsTarArchive(t *testing.T) {
+	for path, want := range map[string]bool{
+		"bundle.tar":      true,
+		"bundle.tar.gz":   true,
+		"bundle.TGZ":      true,
+		"infra":           false,
+		"bundle.zip":      false,
+		"infra/main.tf":   false,
+		"dir.tar/network": false,
+	} {
+		if got := isTarArchive(path); got != want {
+			t.Errorf("isTarArchive(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+// TestGenerateSBOMFromArchive tests recursively scanning the configs inside a tar.gz archive.
+func TestGenerateSBOMFromArchive(t *testing.T) {
+	archivePath := filepath.Join(t.TempDir(), "bundle.tar.gz")
+	writeTestArchive(t, archivePath, [][2]string{
+		{"./infra/", ""},
+		{"./infra/network/main.tf", `module "vpc" {
+  source  = "terraform-aws-modules/vpc/aws"
+  version = "5.1.0"
+}
+`},
+		{"infra/app/main.tf", `module "app" { source = "./app" }`},
+		{"infra/app/app/main.tf", `variable "name" {}`},
+		{"infra/network/broken.tf", "resource {\n"},
+	})
+
+	sbom, err := generateSBOMFromArchive(context.Background(), archivePath, true, scanOptions{}, nil)
+	if err != nil {
+		t.Fatalf("Failed to generate SBOM: %v", err)
+	}
+
+	var modules []string
+	for _, mod := range sbom.Modules {
+		modules = append(modules, mod.Config+" "+mod.Name)
+	}
+	root := filepath.ToSlash(archivePath)
+	want := []string{root + "/infra/app app", root + "/infra/network vpc"}
+	if !reflect.DeepEqual(modules, want) {
+		t.Errorf("Expected modules %v, got %v", want, modules)
+	}
+	if len(sbom.Warnings) == 0 || !strings.HasPrefix(sbom.Warnings[0], root+"/infra/network/broken.tf") {
+		t.Errorf("Expected a warning for %s/infra/network/broken.tf, got %v", root, sbom.Warnings)
+	}
+}
+
+// TestExtractTarArchivePathTraversal tests that an archive with an entry escaping
+// the extraction directory is rejected without writing the entry.
+func TestExtractTarArchivePathTraversal(t *testing.T) {
+	base := t.TempDir()
+	archivePath := filepath.Join(base, "evil.tar")
+	writeTestArchive(t, archivePath, [][2]string{
+		{"main.tf", `module "vpc" { source = "terraform-aws-modules/vpc/aws" }`},
+		{"../evil.tf", "pwned"},
+	})
+
+	dir := filepath.Join(base, "extract")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+
+	err := extractTarArchive(archivePath, dir)
+	if err == nil || !strings.Contains(err.Error(), "outside the archive root") {
+		t.Fatalf("Expected a path traversal error, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(base, "evil.tf")); !os.IsNotExist(err) {
+		t.Errorf("Expected ../evil.tf not to be written, got %v", err)
+	}
+
+	absolute := filepath.Join(base, "absolute.tar")
+	writeTestArchive(t, absolute, [][2]string{{"/tmp/evil.tf", "pwned"}})
+	if err := extractTarArchive(absolute, dir); err == nil {
+		t.Errorf("Expected an absolute entry to be rejected")
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestWriteFileAtomicFailure tests that a write failing partway through leaves the original file intact.
+func TestWriteFileAtomicFailure(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "sbom.json")
+	original := "{\"modules\": []}\n"
+	err := os.WriteFile(path, []byte(original), 0644)
+	if err != nil {
+		t.Fatalf("Failed to write original file: %v", err)
+	}
+
+	err = writeFileAtomic(path, func(w io.Writer) error {
+		fmt.Fprint(w, "{\"modules\": [")
+		return errors.New("killed mid-write")
+	})
+	if err == nil {
+		t.Fatalf("Expected the write to fail")
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+	if string(content) != original {
+		t.Errorf("Expected original contents %q, got %q", original, content)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("Failed to read directory: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("Expected the temporary file to be removed, got %d files", len(entries))
+	}
+}
+
+// TestWriteFileAtomic tests that a successful write replaces the file with readable permissions.
+func TestWriteFileAtomic(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sbom.xml")
+	err := os.WriteFile(path, []byte("old"), 0600)
+	if err != nil {
+		t.Fatalf("Failed to write original file: %v", err)
+	}
+
+	err = writeFileAtomic(path, func(w io.Writer) error {
+		_, err := fmt.Fprint(w, "new")
+		return err
+	})
+	if err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+	if string(content) != "new" {
+		t.Errorf("Expected new contents, got %q", content)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Failed to stat file: %v", err)
+	}
+	if info.Mode().Perm() != 0644 {
+		t.Errorf("Expected permissions 0644, got %v", info.Mode().Perm())
+	}
+}
+
+// TestWriteJSONFailureKeepsOriginal tests that a JSON SBOM that fails to encode does not clobber an existing file.
+func TestWriteJSONFailureKeepsOriginal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sbom.json")
+	original := "{\"modules\": []}\n"
+	err := os.WriteFile(path, []byte(original), 0644)
+	if err != nil {
+		t.Fatalf("Failed to write original file: %v", err)
+	}
+
+	err = writeJSON(map[string]any{"modules": make(chan int)}, path)
+	if err == nil {
+		t.Fatalf("Expected the write to fail")
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+	if string(content) != original {
+		t.Errorf("Expected original contents %q, got %q", original, content)
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// auditEventName identifies scan events among the other messages of the system log.
+const auditEventName = "terraform_sbom.scan"
+
+// auditEvent is the summary of a scan sent to the system log with -syslog, for audit
+// trails kept apart from the SBOM file. Like telemetry, it only holds counts and the
+// outcome of the policy checks, never module sources or other SBOM content.
+type auditEvent struct {
+	Name           string `json:"name"`
+	Version        string `json:"version"`
+	ConfigRoot     string `json:"config_root"`
+	Output         string `json:"output"`
+	SerialNumber   string `json:"serial_number"`
+	ConfigsScanned int    `json:"configs_scanned"`
+	ModuleCount    int    `json:"module_count"`
+	ProviderCount  int    `json:"provider_count"`
+	Policy         string `json:"policy"` // "passed" or "failed"
+	Violations     int    `json:"violations"`
+	Accepted       int    `json:"accepted"`
+}
+
+// newAuditEvent builds the event for a scan of configRoot written to output. The
+// policy fails if any violation is not accepted by the baseline, which may be nil.
+func newAuditEvent(configRoot string, output string, sbom *SBOM, violations []policyViolation, accepted baseline) auditEvent {
+	remaining := accepted.filter(violations)
+
+	configs := make(map[string]bool)
+	for _, mod := range sbom.Modules {
+		configs[mod.Config] = true
+	}
+	for _, config := range sbom.Configs {
+		configs[config.Path] = true
+	}
+
+	event := auditEvent{
+		Name:           auditEventName,
+		Version:        version,
+		ConfigRoot:     configRoot,
+		Output:         output,
+		SerialNumber:   sbom.SerialNumber,
+		ConfigsScanned: len(configs),
+		ModuleCount:    len(sbom.Modules),
+		ProviderCount:  len(sbom.Providers),
+		Policy:         "passed",
+		Violations:     len(remaining),
+		Accepted:       len(violations) - len(remaining),
+	}
+	if len(remaining) > 0 {
+		event.Policy = "failed"
+	}
+	return event
+}
+
+// auditSink is a destination for audit events, such as the system log.
+type auditSink interface {
+	Info(message string) error
+	Warning(message string) error
+	Close() error
+}
+
+// openSyslog connects to the system log. It is nil on platforms without one, and set
+// from a file behind a build tag on the others.
+var openSyslog func() (auditSink, error)
+
+// sendAuditEvent sends the event to sink as a JSON message, at warning priority when
+// the policy failed and at info priority otherwise.
+func sendAuditEvent(sink auditSink, event auditEvent) error {
+	message, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit event: %v", err)
+	}
+
+	if event.Policy == "failed" {
+		err = sink.Warning(string(message))
+	} else {
+		err = sink.Info(string(message))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to send audit event to syslog: %v", err)
+	}
+	return nil
+}
+
+// recordAudit sends the event to the system log. Like telemetry, auditing is
+// best-effort: a failure, or a platform without a system log, is reported on stderr
+// but never fails the scan.
+func recordAudit(event auditEvent) {
+	if openSyslog == nil {
+		fmt.Fprintln(os.Stderr, "Warning: ignoring -syslog because this platform has no system log")
+		return
+	}
+
+	sink, err := openSyslog()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to connect to syslog: %v\n", err)
+		return
+	}
+	defer sink.Close()
+
+	if err := sendAuditEvent(sink, event); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+}
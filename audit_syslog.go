@@ -0,0 +1,14 @@
+//go:build !windows && !plan9
+
+package main
+
+import "log/syslog"
+
+// auditTag is the program name audit events are logged under.
+const auditTag = "terraform-sbom"
+
+func init() {
+	openSyslog = func() (auditSink, error) {
+		return syslog.New(syslog.LOG_INFO|syslog.LOG_AUTH, auditTag)
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// mockAuditSink records the messages sent to it by priority.
+type mockAuditSink struct {
+	infos    []string
+	warnings []string
+}
+
+func (s *mockAuditSink) Info(message string) error {
+	s.infos = append(s.infos, message)
+	return nil
+}
+
+func (s *mockAuditSink) Warning(message string) error {
+	s.warnings = append(s.warnings, message)
+	return nil
+}
+
+func (s *mockAuditSink) Close() error {
+	return nil
+}
+
+// TestNewAuditEvent tests counting configs, modules, and providers, and taking the
+// policy result from the violations not accepted by the baseline.
+func TestNewAuditEvent(t *testing.T) {
+	sbom := mockSBOM()
+	violations := insecureViolations([]ModuleInfo{
+		{Name: "legacy", Config: "app", Insecure: true},
+		{Name: "old", Config: "app", Insecure: true},
+	})
+
+	event := newAuditEvent("/path/to/config", "sbom.json", sbom, violations, baseline{violations[0].key(): true})
+	if event.Name != auditEventName || event.ConfigRoot != "/path/to/config" || event.Output != "sbom.json" {
+		t.Errorf("Unexpected event identity: %+v", event)
+	}
+	if event.ConfigsScanned != 1 || event.ModuleCount != len(sbom.Modules) || event.ProviderCount != len(sbom.Providers) {
+		t.Errorf("Unexpected counts: %+v", event)
+	}
+	if event.Policy != "failed" || event.Violations != 1 || event.Accepted != 1 {
+		t.Errorf("Expected one failing and one accepted violation, got %+v", event)
+	}
+
+	if event := newAuditEvent("/path/to/config", "sbom.json", sbom, nil, nil); event.Policy != "passed" || event.Violations != 0 {
+		t.Errorf("Expected the policy to pass without violations, got %+v", event)
+	}
+}
+
+// TestSendAuditEvent tests that events are sent as JSON, at warning priority when the
+// policy failed.
+func TestSendAuditEvent(t *testing.T) {
+	sink := &mockAuditSink{}
+	passed := auditEvent{Name: auditEventName, ModuleCount: 2, Policy: "passed"}
+	failed := auditEvent{Name: auditEventName, ModuleCount: 3, Policy: "failed", Violations: 1}
+	for _, event := range []auditEvent{passed, failed} {
+		if err := sendAuditEvent(sink, event); err != nil {
+			t.Fatalf("Failed to send audit event: %v", err)
+		}
+	}
+
+	if len(sink.infos) != 1 || len(sink.warnings) != 1 {
+		t.Fatalf("Expected one info and one warning message, got %v and %v", sink.infos, sink.warnings)
+	}
+
+	var decoded auditEvent
+	if err := json.Unmarshal([]byte(sink.warnings[0]), &decoded); err != nil {
+		t.Fatalf("Failed to decode audit event: %v", err)
+	}
+	if decoded != failed {
+		t.Errorf("Expected %+v, got %+v", failed, decoded)
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+)
+
+// redactedValue replaces backend attribute values that look like credentials.
+const redactedValue = "REDACTED"
+
+// terraformBlockSchema describes the top-level terraform settings block.
+var terraformBlockSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: "terraform"},
+	},
+}
+
+// backendBlockSchema describes the blocks inside terraform {} that configure
+// where state is stored. A cloud block is recorded as a backend of type "cloud".
+var backendBlockSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: "backend", LabelNames: []string{"type"}},
+		{Type: "cloud"},
+	},
+}
+
+// secretAttributeMarkers are substrings of backend attribute names whose values
+// are credentials and must not end up in an SBOM.
+var secretAttributeMarkers = []string{"secret", "password", "token", "access_key", "private_key", "credentials"}
+
+// parseBackend returns the backend configured by the terraform block in the given
+// files, or nil if the configuration uses the default local state without declaring it.
+// Attribute values that cannot be evaluated statically are recorded as their source text.
+func parseBackend(files []*hcl.File) *BackendInfo {
+	for _, file := range files {
+		content, _, _ := file.Body.PartialContent(terraformBlockSchema)
+		for _, block := range content.Blocks {
+			inner, _, _ := block.Body.PartialContent(backendBlockSchema)
+			for _, backend := range inner.Blocks {
+				info := &BackendInfo{Type: backend.Type}
+				if len(backend.Labels) > 0 {
+					info.Type = backend.Labels[0]
+				}
+
+				// Nested blocks such as cloud { workspaces {} } are reported as
+				// diagnostics by JustAttributes; the attributes are still returned.
+				attrs, _ := backend.Body.JustAttributes()
+				if len(attrs) > 0 {
+					info.Config = make(AttributeMap, len(attrs))
+				}
+				for name, attr := range attrs {
+					info.Config[name] = attributeValue(file.Bytes, attr.Expr)
+					if isSecretAttribute(name) {
+						info.Config[name] = redactedValue
+					}
+				}
+
+				return info
+			}
+		}
+	}
+
+	return nil
+}
+
+// attributeValue renders a primitive attribute value as a string, falling back to
+// the expression's source text for anything else, such as variable references.
+func attributeValue(src []byte, expr hcl.Expression) string {
+	value, diags := expr.Value(nil)
+	if !diags.HasErrors() && value.IsWhollyKnown() && !value.IsNull() && value.Type().IsPrimitiveType() {
+		if str, err := convert.Convert(value, cty.String); err == nil {
+			return str.AsString()
+		}
+	}
+
+	return strings.Trim(string(expr.Range().SliceBytes(src)), `"`)
+}
+
+// isSecretAttribute reports whether a backend attribute name suggests its value is a credential.
+func isSecretAttribute(name string) bool {
+	name = strings.ToLower(name)
+	for _, marker := range secretAttributeMarkers {
+		if strings.Contains(name, marker) {
+			return true
+		}
+	}
+	return false
+}
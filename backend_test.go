@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"encoding/xml"
+	"reflect"
+	"testing"
+)
+
+// TestGenerateSBOMBackend tests that s3 and local backends are recorded with credentials redacted.
+func TestGenerateSBOMBackend(t *testing.T) {
+	tests := []struct {
+		configPath string
+		expected   *BackendInfo
+	}{
+		{
+			configPath: "testdata/backend-s3",
+			expected: &BackendInfo{
+				Type: "s3",
+				Config: AttributeMap{
+					"bucket":     "acme-terraform-state",
+					"key":        "network/terraform.tfstate",
+					"region":     "us-east-1",
+					"encrypt":    "true",
+					"access_key": redactedValue,
+					"secret_key": redactedValue,
+				},
+			},
+		},
+		{
+			configPath: "testdata/backend-local",
+			expected: &BackendInfo{
+				Type:   "local",
+				Config: AttributeMap{"path": "state/terraform.tfstate"},
+			},
+		},
+		{
+			configPath: "testdata/outputs",
+			expected:   nil,
+		},
+	}
+
+	for _, tt := range tests {
+		sbom, err := generateSBOM(context.Background(), tt.configPath, scanOptions{IncludeBackend: true})
+		if err != nil {
+			t.Fatalf("Failed to generate SBOM for %s: %v", tt.configPath, err)
+		}
+		if len(sbom.Configs) != 1 {
+			t.Fatalf("Expected 1 config for %s, got %d", tt.configPath, len(sbom.Configs))
+		}
+		if !reflect.DeepEqual(sbom.Configs[0].Backend, tt.expected) {
+			t.Errorf("Backend mismatch for %s: expected %v, got %v", tt.configPath, tt.expected, sbom.Configs[0].Backend)
+		}
+	}
+
+	sbom, err := generateSBOM(context.Background(), "testdata/backend-s3", scanOptions{})
+	if err != nil {
+		t.Fatalf("Failed to generate SBOM: %v", err)
+	}
+	if len(sbom.Configs) != 0 {
+		t.Errorf("Expected no configs without IncludeBackend, got %v", sbom.Configs)
+	}
+}
+
+// TestBackendInfoXML tests that backend attributes survive an XML round trip.
+func TestBackendInfoXML(t *testing.T) {
+	config := ConfigInfo{
+		Path:    "/path/to/config",
+		Backend: &BackendInfo{Type: "s3", Config: AttributeMap{"bucket": "state", "region": "us-east-1"}},
+	}
+
+	content, err := xml.Marshal(config)
+	if err != nil {
+		t.Fatalf("Failed to marshal XML: %v", err)
+	}
+
+	var result ConfigInfo
+	if err := xml.Unmarshal(content, &result); err != nil {
+		t.Fatalf("Failed to unmarshal XML: %v", err)
+	}
+	if !reflect.DeepEqual(result, config) {
+		t.Errorf("XML round trip mismatch: expected %v, got %v", config, result)
+	}
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+// Rules reported by the policy checks. A baseline identifies accepted findings by rule.
+const (
+	ruleUnapprovedSource    = "unapproved-source"
+	ruleInconsistentPin     = "inconsistent-pin"
+	ruleNonSemverPin        = "non-semver-pin"
+	ruleInsecureSource      = "insecure-source"
+	ruleEmbeddedCredentials = "embedded-credentials"
+	ruleConflictingProvider = "conflicting-provider-source"
+	ruleTooManyModules      = "too-many-modules"
+	ruleDeprecatedModule    = "deprecated-module"
+	ruleUnpinnedModule      = "unpinned-module"
+)
+
+// policyViolation is a single finding of a policy check. It is identified by the
+// rule it breaks together with the config and module it was found in, and the call
+// path of the module when -flatten-nested lists it, since nested calls of the same
+// name can be reached through different callers. Findings that span configs, such
+// as inconsistent pins, have no config and record the module source or provider
+// local name in place of the module name.
+type policyViolation struct {
+	Rule    string `json:"rule"`
+	Config  string `json:"config,omitempty"`
+	Module  string `json:"module"`
+	Path    string `json:"path,omitempty"`
+	Message string `json:"-"`
+}
+
+// String returns the human-readable description of the finding.
+func (v policyViolation) String() string {
+	return v.Message
+}
+
+// baselineKey identifies a finding independently of its message.
+type baselineKey struct {
+	Rule   string
+	Config string
+	Module string
+	Path   string
+}
+
+// key returns the identity under which the finding is matched against a baseline.
+func (v policyViolation) key() baselineKey {
+	return baselineKey{Rule: v.Rule, Config: v.Config, Module: v.Module, Path: v.Path}
+}
+
+// baselineFile is the on-disk form of a baseline of accepted findings.
+type baselineFile struct {
+	Findings []policyViolation `json:"findings"`
+}
+
+// baseline is a set of accepted findings that policy checks do not fail on.
+type baseline map[baselineKey]bool
+
+// loadBaseline reads a baseline file written by -write-baseline.
+func loadBaseline(path string) (baseline, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read baseline: %v", err)
+	}
+
+	var file baselineFile
+	if err := json.Unmarshal(content, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse baseline: %v", err)
+	}
+
+	accepted := make(baseline)
+	for _, finding := range file.Findings {
+		accepted[finding.key()] = true
+	}
+	return accepted, nil
+}
+
+// filter returns the violations that are not accepted by the baseline.
+func (b baseline) filter(violations []policyViolation) []policyViolation {
+	var remaining []policyViolation
+	for _, violation := range violations {
+		if !b[violation.key()] {
+			remaining = append(remaining, violation)
+		}
+	}
+	return remaining
+}
+
+// writeBaseline records every finding as accepted, sorted and without duplicates
+// so that the file diffs cleanly when it is regenerated.
+func writeBaseline(path string, violations []policyViolation) error {
+	seen := make(map[baselineKey]bool)
+	file := baselineFile{Findings: []policyViolation{}}
+	for _, violation := range violations {
+		if seen[violation.key()] {
+			continue
+		}
+		seen[violation.key()] = true
+		file.Findings = append(file.Findings, policyViolation{Rule: violation.Rule, Config: violation.Config, Module: violation.Module, Path: violation.Path})
+	}
+
+	sort.Slice(file.Findings, func(i, j int) bool {
+		a, b := file.Findings[i], file.Findings[j]
+		if a.Rule != b.Rule {
+			return a.Rule < b.Rule
+		}
+		if a.Config != b.Config {
+			return a.Config < b.Config
+		}
+		if a.Module != b.Module {
+			return a.Module < b.Module
+		}
+		return a.Path < b.Path
+	})
+
+	err := writeFileAtomic(path, func(w io.Writer) error {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(file)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write baseline: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// violationMessages returns the message of each violation.
+func violationMessages(violations []policyViolation) []string {
+	var messages []string
+	for _, violation := range violations {
+		messages = append(messages, violation.String())
+	}
+	return messages
+}
+
+// TestBaselineFiltersAcceptedFindings tests that only findings missing from a baseline remain.
+func TestBaselineFiltersAcceptedFindings(t *testing.T) {
+	legacy := []ModuleInfo{
+		{Name: "legacy", Source: "git::https://github.com/acme/legacy.git?ref=main", SourceType: sourceTypeGit, Version: "main", Config: "app"},
+		{Name: "dns", Source: "github.com/acme/dns?ref=2.0", SourceType: sourceTypeGitHub, Version: "2.0", Config: "network"},
+	}
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	if err := writeBaseline(path, strictSemverViolations(legacy)); err != nil {
+		t.Fatalf("Failed to write baseline: %v", err)
+	}
+
+	accepted, err := loadBaseline(path)
+	if err != nil {
+		t.Fatalf("Failed to load baseline: %v", err)
+	}
+
+	modules := append(legacy,
+		ModuleInfo{Name: "db", Source: "git::https://github.com/acme/db.git?ref=release-2.0.0", SourceType: sourceTypeGit, Version: "release-2.0.0", Config: "app"},
+		ModuleInfo{Name: "legacy", Source: "git::https://github.com/acme/legacy.git?ref=main", SourceType: sourceTypeGit, Version: "main", Config: "network"},
+	)
+	violations := strictSemverViolations(modules)
+	if len(violations) != 4 {
+		t.Fatalf("Expected 4 violations before filtering, got %v", violations)
+	}
+
+	expected := []string{
+		`app: module db is pinned to "release-2.0.0", which is not a vMAJOR.MINOR.PATCH version`,
+		`network: module legacy is pinned to "main", which is not a vMAJOR.MINOR.PATCH version`,
+	}
+	if remaining := violationMessages(accepted.filter(violations)); !reflect.DeepEqual(remaining, expected) {
+		t.Errorf("New violations mismatch:\nexpected %v\ngot      %v", expected, remaining)
+	}
+}
+
+// TestBaselineFiltersAcceptedUnpinnedModules tests that an accepted unpinned module
+// passes while a newly unpinned one still fails.
+func TestBaselineFiltersAcceptedUnpinnedModules(t *testing.T) {
+	legacy := []ModuleInfo{
+		{Name: "legacy", Source: "git::https://github.com/acme/legacy.git", SourceType: sourceTypeGit, Version: unknownVersion, Config: "app"},
+		{Name: "vpc", Source: "terraform-aws-modules/vpc/aws", SourceType: sourceTypeRegistry, Version: "5.1.0", Config: "app"},
+	}
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	if err := writeBaseline(path, unpinnedViolations(legacy)); err != nil {
+		t.Fatalf("Failed to write baseline: %v", err)
+	}
+
+	accepted, err := loadBaseline(path)
+	if err != nil {
+		t.Fatalf("Failed to load baseline: %v", err)
+	}
+
+	if remaining := accepted.filter(unpinnedViolations(legacy)); len(remaining) != 0 {
+		t.Errorf("Expected the accepted unpinned module to pass, got %v", violationMessages(remaining))
+	}
+
+	modules := append(legacy,
+		ModuleInfo{Name: "dns", Source: "terraform-aws-modules/route53/aws", SourceType: sourceTypeRegistry, Config: "app"},
+		ModuleInfo{Name: "local", Source: "./modules/local", SourceType: sourceTypeLocal, Config: "app"},
+	)
+	expected := []string{"app: module dns is not pinned to a version of terraform-aws-modules/route53/aws"}
+	if remaining := violationMessages(accepted.filter(unpinnedViolations(modules))); !reflect.DeepEqual(remaining, expected) {
+		t.Errorf("New violations mismatch:\nexpected %v\ngot      %v", expected, remaining)
+	}
+}
+
+// TestBaselineMatchesRule tests that a finding accepted for one rule does not suppress another rule.
+func TestBaselineMatchesRule(t *testing.T) {
+	accepted := baseline{
+		{Rule: ruleNonSemverPin, Config: "app", Module: "legacy"}: true,
+	}
+	violations := []policyViolation{
+		{Rule: ruleNonSemverPin, Config: "app", Module: "legacy", Message: "non-semver"},
+		{Rule: ruleUnapprovedSource, Config: "app", Module: "legacy", Message: "unapproved"},
+	}
+
+	if remaining := violationMessages(accepted.filter(violations)); !reflect.DeepEqual(remaining, []string{"unapproved"}) {
+		t.Errorf("Expected only the unapproved source violation, got %v", remaining)
+	}
+}
+
+// TestWriteBaseline tests that baselines are written sorted and without duplicates.
+func TestWriteBaseline(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	violations := []policyViolation{
+		{Rule: ruleUnapprovedSource, Config: "network", Module: "legacy"},
+		{Rule: ruleInconsistentPin, Module: "terraform-aws-modules/vpc/aws"},
+		{Rule: ruleUnapprovedSource, Config: "app", Module: "legacy"},
+		{Rule: ruleUnapprovedSource, Config: "network", Module: "legacy"},
+	}
+	if err := writeBaseline(path, violations); err != nil {
+		t.Fatalf("Failed to write baseline: %v", err)
+	}
+
+	accepted, err := loadBaseline(path)
+	if err != nil {
+		t.Fatalf("Failed to load baseline: %v", err)
+	}
+	if len(accepted) != 3 {
+		t.Errorf("Expected 3 accepted findings, got %v", accepted)
+	}
+	if !accepted[baselineKey{Rule: ruleInconsistentPin, Module: "terraform-aws-modules/vpc/aws"}] {
+		t.Errorf("Expected the inconsistent pin to be accepted, got %v", accepted)
+	}
+}
+
+// TestLoadBaselineInvalid tests that a malformed baseline is reported.
+func TestLoadBaselineInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	if err := os.WriteFile(path, []byte("not json"), 0644); err != nil {
+		t.Fatalf("Failed to write baseline: %v", err)
+	}
+
+	if _, err := loadBaseline(path); err == nil {
+		t.Errorf("Expected an error for a malformed baseline")
+	}
+	if _, err := loadBaseline(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Errorf("Expected an error for a missing baseline")
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+// How a module in a delta SBOM written with -only-changed-versions differs from the
+// previous SBOM.
+const (
+	changeAdded   = "added"
+	changeChanged = "changed"
+	changeRemoved = "removed"
+)
+
+// onlyChangedVersions reduces a fresh SBOM to the delta from a previous one, for
+// focused upgrade reports. Only the module calls whose version changed are kept,
+// together with the calls that were added when includeAdded is set, and the calls of
+// the previous SBOM that were removed when includeRemoved is set. Module calls are
+// matched by config path and name, as by the diff command. Each module records how
+// it changed, and changed and removed modules the version of the previous SBOM. Kept
+// modules are in scan order, followed by the removed ones. Providers, outputs, and
+// per-config details are left out, while the document metadata and warnings are kept.
+func onlyChangedVersions(sbom *SBOM, previous *SBOM, includeAdded bool, includeRemoved bool) {
+	diff := diffSBOMs(previous, sbom)
+
+	changes := make(map[moduleKey]ModuleInfo)
+	for _, change := range diff.Changed {
+		if change.Old.Version != change.New.Version {
+			changes[keyOf(change.New)] = change.Old
+		}
+	}
+	added := make(map[moduleKey]bool)
+	if includeAdded {
+		for _, mod := range diff.Added {
+			added[keyOf(mod)] = true
+		}
+	}
+
+	var modules []ModuleInfo
+	for _, mod := range sbom.Modules {
+		if old, ok := changes[keyOf(mod)]; ok {
+			mod.Change = changeChanged
+			mod.PreviousVersion = old.Version
+			modules = append(modules, mod)
+		} else if added[keyOf(mod)] {
+			mod.Change = changeAdded
+			modules = append(modules, mod)
+		}
+	}
+	if includeRemoved {
+		for _, mod := range diff.Removed {
+			mod.Change = changeRemoved
+			mod.PreviousVersion = mod.Version
+			modules = append(modules, mod)
+		}
+	}
+
+	sbom.Modules = modules
+	sbom.Providers = nil
+	sbom.Outputs = nil
+	sbom.Configs = nil
+}
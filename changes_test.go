@@ -0,0 +1,70 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+// changeSBOMs returns a previous and a fresh SBOM where one module's version changed,
+// one only moved to another source, one was added, one was removed, and one is unchanged.
+func changeSBOMs() (*SBOM, *SBOM) {
+	previous := &SBOM{
+		Modules: []ModuleInfo{
+			{Config: "app", Name: "vpc", Source: "terraform-aws-modules/vpc/aws", Version: "5.0.0"},
+			{Config: "app", Name: "labels", Source: "git::https://github.com/acme/labels.git", Version: "v1.0.0"},
+			{Config: "app", Name: "dns", Source: "terraform-aws-modules/route53/aws", Version: "2.0.0"},
+			{Config: "app", Name: "legacy", Source: "git::https://github.com/acme/legacy.git", Version: "v0.9.0"},
+		},
+	}
+	fresh := &SBOM{
+		SerialNumber: "urn:uuid:3e671687-395b-41f5-a30f-a58921a69b79",
+		Modules: []ModuleInfo{
+			{Config: "app", Name: "bucket", Source: "terraform-aws-modules/s3-bucket/aws", Version: "4.1.0"},
+			{Config: "app", Name: "vpc", Source: "terraform-aws-modules/vpc/aws", Version: "5.1.0"},
+			{Config: "app", Name: "labels", Source: "git::https://github.com/acme-forks/labels.git", Version: "v1.0.0"},
+			{Config: "app", Name: "dns", Source: "terraform-aws-modules/route53/aws", Version: "2.0.0"},
+		},
+		Providers: []ProviderInfo{{Config: "app", Name: "aws", Source: "hashicorp/aws"}},
+		Warnings:  []string{"app: something to note"},
+	}
+	return previous, fresh
+}
+
+// TestOnlyChangedVersions tests that only modules whose version changed are kept by
+// default, with their previous version, and that the document metadata is kept.
+func TestOnlyChangedVersions(t *testing.T) {
+	previous, sbom := changeSBOMs()
+	onlyChangedVersions(sbom, previous, false, false)
+
+	expected := []ModuleInfo{
+		{Config: "app", Name: "vpc", Source: "terraform-aws-modules/vpc/aws", Version: "5.1.0", Change: changeChanged, PreviousVersion: "5.0.0"},
+	}
+	if !reflect.DeepEqual(sbom.Modules, expected) {
+		t.Errorf("Modules mismatch:\nexpected %+v\ngot      %+v", expected, sbom.Modules)
+	}
+	if sbom.Providers != nil || sbom.SerialNumber == "" || len(sbom.Warnings) != 1 {
+		t.Errorf("Expected providers to be left out and metadata and warnings to be kept, got %+v", sbom)
+	}
+}
+
+// TestOnlyChangedVersionsAddedRemoved tests including added modules in scan order and
+// removed modules after them.
+func TestOnlyChangedVersionsAddedRemoved(t *testing.T) {
+	previous, sbom := changeSBOMs()
+	onlyChangedVersions(sbom, previous, true, true)
+
+	expected := []ModuleInfo{
+		{Config: "app", Name: "bucket", Source: "terraform-aws-modules/s3-bucket/aws", Version: "4.1.0", Change: changeAdded},
+		{Config: "app", Name: "vpc", Source: "terraform-aws-modules/vpc/aws", Version: "5.1.0", Change: changeChanged, PreviousVersion: "5.0.0"},
+		{Config: "app", Name: "legacy", Source: "git::https://github.com/acme/legacy.git", Version: "v0.9.0", Change: changeRemoved, PreviousVersion: "v0.9.0"},
+	}
+	if !reflect.DeepEqual(sbom.Modules, expected) {
+		t.Errorf("Modules mismatch:\nexpected %+v\ngot      %+v", expected, sbom.Modules)
+	}
+
+	previous, sbom = changeSBOMs()
+	onlyChangedVersions(sbom, previous, false, true)
+	if len(sbom.Modules) != 2 || sbom.Modules[1].Change != changeRemoved {
+		t.Errorf("Expected the changed and removed modules only, got %+v", sbom.Modules)
+	}
+}
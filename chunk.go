@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// sbomChunk is one of the output files a large SBOM is split across.
+type sbomChunk struct {
+	SBOM *SBOM
+	Path string
+}
+
+// splitSBOM splits an SBOM across numbered output files holding at most maxModules
+// modules each: out.json becomes out.1.json, out.2.json, and so on. Every file
+// repeats the document metadata, such as the serial number and name, so each can be
+// imported on its own. Providers, outputs, per-config details, and warnings are
+// written to the first file only. An SBOM without modules still produces out.1.json.
+func splitSBOM(sbom *SBOM, outputPath string, maxModules int) []sbomChunk {
+	var chunks []sbomChunk
+	for start := 0; start == 0 || start < len(sbom.Modules); start += maxModules {
+		end := min(start+maxModules, len(sbom.Modules))
+
+		chunk := SBOM{
+			SerialNumber: sbom.SerialNumber,
+			Version:      sbom.Version,
+			Name:         sbom.Name,
+			Namespace:    sbom.Namespace,
+			Supplier:     sbom.Supplier,
+			Timestamp:    sbom.Timestamp,
+			Modules:      sbom.Modules[start:end:end],
+		}
+		if start == 0 {
+			chunk.Providers = sbom.Providers
+			chunk.Outputs = sbom.Outputs
+			chunk.Configs = sbom.Configs
+			chunk.Warnings = sbom.Warnings
+		}
+
+		chunks = append(chunks, sbomChunk{SBOM: &chunk, Path: chunkPath(outputPath, len(chunks)+1)})
+	}
+	return chunks
+}
+
+// chunkPath numbers an output path by inserting the number before its extension.
+func chunkPath(outputPath string, number int) string {
+	ext := filepath.Ext(outputPath)
+	return fmt.Sprintf("%s.%d%s", strings.TrimSuffix(outputPath, ext), number, ext)
+}
+
+// writeChunks writes every chunk concurrently, with at most one writer per CPU at a
+// time. Each writer has its own output file and only reads its chunk, so a failed
+// write leaves the other files intact: the remaining writers still complete, and the
+// first error is returned once all of them are done.
+func writeChunks(chunks []sbomChunk, write func(chunk sbomChunk) error) error {
+	var g errgroup.Group
+	g.SetLimit(runtime.GOMAXPROCS(0))
+	for _, chunk := range chunks {
+		g.Go(func() error {
+			return write(chunk)
+		})
+	}
+	return g.Wait()
+}
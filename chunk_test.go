@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// chunkTestSBOM returns an SBOM with the given number of modules and one provider.
+func chunkTestSBOM(modules int) *SBOM {
+	sbom := &SBOM{
+		SerialNumber: "urn:uuid:3e671687-395b-41f5-a30f-a58921a69b79",
+		Version:      1,
+		Name:         "estate",
+		Providers:    []ProviderInfo{{Name: "aws", Source: "hashicorp/aws", Config: "app"}},
+	}
+	for i := 0; i < modules; i++ {
+		sbom.Modules = append(sbom.Modules, ModuleInfo{Name: fmt.Sprintf("module_%d", i), Source: "./modules/shared", SourceType: sourceTypeLocal, Version: "local", Config: "app"})
+	}
+	return sbom
+}
+
+// TestSplitSBOM tests splitting modules across numbered files with repeated metadata.
+func TestSplitSBOM(t *testing.T) {
+	dir := t.TempDir()
+	sbom := chunkTestSBOM(5)
+
+	chunks := splitSBOM(sbom, filepath.Join(dir, "out.json"), 2)
+	if len(chunks) != 3 {
+		t.Fatalf("Expected 3 chunks, got %d", len(chunks))
+	}
+
+	var modules []ModuleInfo
+	for i, chunk := range chunks {
+		if err := writeSBOM(chunk.SBOM, "json", chunk.Path); err != nil {
+			t.Fatalf("Failed to write chunk: %v", err)
+		}
+		written, err := readSBOM(filepath.Join(dir, fmt.Sprintf("out.%d.json", i+1)))
+		if err != nil {
+			t.Fatalf("Failed to read chunk %d: %v", i+1, err)
+		}
+
+		if len(written.Modules) > 2 {
+			t.Errorf("Chunk %d has %d modules, expected at most 2", i+1, len(written.Modules))
+		}
+		if written.SerialNumber != sbom.SerialNumber || written.Name != sbom.Name || written.Version != sbom.Version {
+			t.Errorf("Chunk %d metadata mismatch: %+v", i+1, written)
+		}
+		if providers := len(written.Providers); i == 0 && providers != 1 || i > 0 && providers != 0 {
+			t.Errorf("Chunk %d has %d providers", i+1, providers)
+		}
+		modules = append(modules, written.Modules...)
+	}
+
+	if !reflect.DeepEqual(modules, sbom.Modules) {
+		t.Errorf("Modules across chunks mismatch:\nexpected %v\ngot      %v", sbom.Modules, modules)
+	}
+}
+
+// TestSplitSBOMEmpty tests that an SBOM without modules still produces a first file.
+func TestSplitSBOMEmpty(t *testing.T) {
+	chunks := splitSBOM(chunkTestSBOM(0), "out.csv", 2)
+	if len(chunks) != 1 || chunks[0].Path != "out.1.csv" || len(chunks[0].SBOM.Providers) != 1 {
+		t.Errorf("Expected a single out.1.csv chunk with the providers, got %+v", chunks)
+	}
+}
+
+// TestChunkPath tests numbering output paths.
+func TestChunkPath(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"out.json", "out.3.json"},
+		{"reports/sbom.intoto.json", "reports/sbom.intoto.3.json"},
+		{"sbom", "sbom.3"},
+	}
+
+	for _, tt := range tests {
+		if got := chunkPath(tt.path, 3); got != tt.expected {
+			t.Errorf("chunkPath(%q, 3) = %q, expected %q", tt.path, got, tt.expected)
+		}
+	}
+}
+
+// TestWriteChunks tests that every chunk is written concurrently and that a failed
+// write is reported while the other files are still written intact.
+func TestWriteChunks(t *testing.T) {
+	dir := t.TempDir()
+	chunks := splitSBOM(chunkTestSBOM(8), filepath.Join(dir, "out.json"), 2)
+	chunks[2].Path = filepath.Join(dir, "missing", "out.3.json")
+
+	err := writeChunks(chunks, func(chunk sbomChunk) error {
+		return writeSBOM(chunk.SBOM, "json", chunk.Path)
+	})
+	if err == nil {
+		t.Fatalf("Expected an error writing to a missing directory")
+	}
+
+	for i, chunk := range chunks {
+		if i == 2 {
+			continue
+		}
+		written, err := readSBOM(chunk.Path)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", chunk.Path, err)
+		}
+		if !reflect.DeepEqual(written.Modules, chunk.SBOM.Modules) {
+			t.Errorf("Expected %s to hold %v, got %v", chunk.Path, chunk.SBOM.Modules, written.Modules)
+		}
+	}
+}
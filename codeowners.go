@@ -0,0 +1,156 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// codeownersRule is a line of a CODEOWNERS file: a path pattern and the owners of
+// the files it matches. A rule without owners leaves matching files unowned.
+type codeownersRule struct {
+	pattern *regexp.Regexp
+	owners  []string
+}
+
+// codeowners holds the rules of a GitHub CODEOWNERS file.
+type codeowners struct {
+	root  string // Repository root the patterns are relative to
+	rules []codeownersRule
+}
+
+// loadCodeowners reads a CODEOWNERS file. Its patterns are relative to the repository
+// root, which is the directory holding the file, or its parent when the file is in a
+// .github or docs directory.
+func loadCodeowners(path string) (*codeowners, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open CODEOWNERS file: %v", err)
+	}
+	defer file.Close()
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve CODEOWNERS path: %v", err)
+	}
+	c := &codeowners{root: filepath.Dir(abs)}
+	if base := filepath.Base(c.root); base == ".github" || base == "docs" {
+		c.root = filepath.Dir(c.root)
+	}
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		rule := codeownersRule{pattern: codeownersRegexp(fields[0])}
+		for _, owner := range fields[1:] {
+			if strings.HasPrefix(owner, "#") {
+				break
+			}
+			rule.owners = append(rule.owners, owner)
+		}
+		c.rules = append(c.rules, rule)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read CODEOWNERS file: %v", err)
+	}
+
+	return c, nil
+}
+
+// codeownersRegexp converts a CODEOWNERS pattern, which follows gitignore rules, into
+// a regular expression matching slash-separated paths relative to the repository root.
+// A pattern with a leading or inner slash is anchored to the root, and a pattern
+// matching a directory also matches everything below it.
+func codeownersRegexp(pattern string) *regexp.Regexp {
+	anchored := strings.Contains(strings.TrimSuffix(pattern, "/"), "/")
+	pattern = strings.Trim(pattern, "/")
+
+	var expr strings.Builder
+	expr.WriteString("^")
+	if !anchored {
+		expr.WriteString("(?:.*/)?")
+	}
+
+	for i := 0; i < len(pattern); i++ {
+		switch {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			expr.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**"):
+			expr.WriteString(".*")
+			i++
+		case pattern[i] == '*':
+			expr.WriteString("[^/]*")
+		case pattern[i] == '?':
+			expr.WriteString("[^/]")
+		default:
+			expr.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+
+	expr.WriteString("(?:/.*)?$")
+	return regexp.MustCompile(expr.String())
+}
+
+// owners returns the owners of a path relative to the repository root. As on GitHub,
+// the last matching rule wins.
+func (c *codeowners) owners(rel string) []string {
+	for i := len(c.rules) - 1; i >= 0; i-- {
+		if c.rules[i].pattern.MatchString(rel) {
+			return c.rules[i].owners
+		}
+	}
+	return nil
+}
+
+// configOwners returns the owners of a configuration: the owners of its Terraform
+// files, in the order they are first found. Configurations outside the repository
+// root have no owners.
+func (c *codeowners) configOwners(configPath string) []string {
+	files := configFiles(configPath)
+	if len(files) == 0 {
+		files = []string{configPath}
+	}
+
+	var owners []string
+	seen := make(map[string]bool)
+	for _, file := range files {
+		abs, err := filepath.Abs(file)
+		if err != nil {
+			continue
+		}
+		rel, err := filepath.Rel(c.root, abs)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+
+		for _, owner := range c.owners(filepath.ToSlash(rel)) {
+			if !seen[owner] {
+				seen[owner] = true
+				owners = append(owners, owner)
+			}
+		}
+	}
+
+	return owners
+}
+
+// setOwners records the owners of each module's configuration.
+func setOwners(modules []ModuleInfo, c *codeowners) {
+	cache := make(map[string][]string)
+	for i := range modules {
+		owners, ok := cache[modules[i].Config]
+		if !ok {
+			owners = c.configOwners(modules[i].Config)
+			cache[modules[i].Config] = owners
+		}
+		modules[i].Owners = owners
+	}
+}
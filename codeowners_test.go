@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+// TestCodeownersRegexp tests matching CODEOWNERS patterns against repository paths.
+func TestCodeownersRegexp(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		path     string
+		expected bool
+	}{
+		{"*", "infra/network/main.tf", true},
+		{"*.tf", "infra/network/main.tf", true},
+		{"*.tf", "README.md", false},
+		{"/infra/", "infra/network/main.tf", true},
+		{"/infra/", "modules/infra/main.tf", false},
+		{"infra/", "modules/infra/main.tf", true},
+		{"infra/network", "infra/network/main.tf", true},
+		{"infra/network", "modules/infra/network/main.tf", false},
+		{"infra/*", "infra/main.tf", true},
+		{"infra/*/main.tf", "infra/app/main.tf", true},
+		{"infra/*/main.tf", "infra/app/scratch/main.tf", false},
+		{"infra/**/legacy", "infra/data/legacy/main.tf", true},
+		{"infra/**/legacy", "infra/legacy/main.tf", true},
+		{"**/legacy", "infra/data/legacy/main.tf", true},
+		{"/infra/**", "infra/app/scratch/main.tf", true},
+		{"ma?n.tf", "infra/main.tf", true},
+	}
+
+	for _, test := range tests {
+		if got := codeownersRegexp(test.pattern).MatchString(test.path); got != test.expected {
+			t.Errorf("Pattern %q matching %q = %v, expected %v", test.pattern, test.path, got, test.expected)
+		}
+	}
+}
+
+// TestSetOwners tests assigning owners to modules by the last matching CODEOWNERS pattern.
+func TestSetOwners(t *testing.T) {
+	owners, err := loadCodeowners("testdata/codeowners/.github/CODEOWNERS")
+	if err != nil {
+		t.Fatalf("Failed to load CODEOWNERS: %v", err)
+	}
+
+	sbom, err := generateRecursiveSBOM(context.Background(), "testdata/codeowners", scanOptions{}, nil)
+	if err != nil {
+		t.Fatalf("Failed to generate SBOM: %v", err)
+	}
+	setOwners(sbom.Modules, owners)
+
+	expected := map[string][]string{
+		"testdata/codeowners/infra/app":         {"@acme/app"},
+		"testdata/codeowners/infra/app/scratch": nil,
+		"testdata/codeowners/infra/data":        {"@acme/terraform"},
+		"testdata/codeowners/infra/data/legacy": {"@acme/legacy"},
+		"testdata/codeowners/infra/network":     {"@acme/network", "@alice"},
+	}
+	if len(sbom.Modules) != len(expected) {
+		t.Fatalf("Expected %d modules, got %d", len(expected), len(sbom.Modules))
+	}
+	for _, mod := range sbom.Modules {
+		if !reflect.DeepEqual(mod.Owners, expected[mod.Config]) {
+			t.Errorf("Expected owners %v for %s, got %v", expected[mod.Config], mod.Config, mod.Owners)
+		}
+	}
+}
+
+// TestConfigOwnersOutsideRoot tests that configs outside the repository root have no owners.
+func TestConfigOwnersOutsideRoot(t *testing.T) {
+	owners, err := loadCodeowners("testdata/codeowners/.github/CODEOWNERS")
+	if err != nil {
+		t.Fatalf("Failed to load CODEOWNERS: %v", err)
+	}
+
+	if got := owners.configOwners("testdata/outputs"); got != nil {
+		t.Errorf("Expected no owners outside the repository root, got %v", got)
+	}
+}
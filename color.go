@@ -0,0 +1,37 @@
+package main
+
+import (
+	"os"
+)
+
+// ANSI escape sequences used to color terminal output.
+const (
+	ansiReset = "\x1b[0m"
+	ansiDim   = "\x1b[2m"
+	ansiRed   = "\x1b[31m"
+	ansiCyan  = "\x1b[36m"
+)
+
+// colorizer wraps text in ANSI escape sequences. A disabled colorizer returns text
+// unchanged, so printing code can use it unconditionally.
+type colorizer struct {
+	enabled bool
+}
+
+// paint wraps s in the given escape sequence if coloring is enabled.
+func (c colorizer) paint(code, s string) string {
+	if !c.enabled || s == "" {
+		return s
+	}
+	return code + s + ansiReset
+}
+
+// useColor reports whether output written to f should be colored. Coloring is
+// disabled by the -no-color flag, by a non-empty NO_COLOR environment variable
+// (see https://no-color.org), and when f is not a terminal.
+func useColor(f *os.File, noColor bool) bool {
+	if noColor || os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	return isTerminal(f)
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+// TestPrintSBOMColor tests that source types, unpinned versions, and local modules are colored.
+func TestPrintSBOMColor(t *testing.T) {
+	sbom := mockSBOM()
+	sbom.Modules = append(sbom.Modules, ModuleInfo{
+		Name:       "network",
+		Source:     "./modules/network",
+		SourceType: sourceTypeLocal,
+		Version:    "local",
+		Config:     "/path/to/config",
+	})
+
+	var buf bytes.Buffer
+	printSBOM(&buf, sbom, true)
+	output := buf.String()
+
+	expected := []string{
+		"Source Type: " + ansiCyan + "git" + ansiReset,
+		"Version: v2.0.0\n",
+		"Version: " + ansiRed + "N/A" + ansiReset,
+		ansiDim + "Module Name: network" + ansiReset,
+		ansiDim + "Version: local" + ansiReset,
+	}
+	for _, want := range expected {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected colored output to contain %q, got:\n%s", want, output)
+		}
+	}
+}
+
+// TestPrintSBOMNoColor tests that no escape sequences are written when color is disabled.
+func TestPrintSBOMNoColor(t *testing.T) {
+	var buf bytes.Buffer
+	printSBOM(&buf, mockSBOM(), false)
+
+	if strings.Contains(buf.String(), "\x1b[") {
+		t.Errorf("Expected no ANSI escape sequences, got:\n%s", buf.String())
+	}
+	if !strings.Contains(buf.String(), "Version: N/A\n") {
+		t.Errorf("Expected plain version line, got:\n%s", buf.String())
+	}
+}
+
+// TestUseColor tests that the flag, NO_COLOR, and non-terminal output all disable color.
+func TestUseColor(t *testing.T) {
+	file, err := os.CreateTemp(t.TempDir(), "stdout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	t.Setenv("NO_COLOR", "")
+	if useColor(file, false) {
+		t.Errorf("Expected color to be disabled for a regular file")
+	}
+
+	tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+	if err != nil {
+		t.Skip("No terminal available")
+	}
+	defer tty.Close()
+
+	if !useColor(tty, false) {
+		t.Errorf("Expected color to be enabled for a terminal")
+	}
+	if useColor(tty, true) {
+		t.Errorf("Expected -no-color to disable color")
+	}
+	t.Setenv("NO_COLOR", "1")
+	if useColor(tty, false) {
+		t.Errorf("Expected NO_COLOR to disable color")
+	}
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// version is the release version of the tool, set at build time with
+// -ldflags "-X main.version=v1.2.3".
+var version = "dev"
+
+// command is a subcommand of the CLI. Each command parses its own flags from args.
+type command struct {
+	summary string
+	run     func(args []string)
+}
+
+// commands lists the available subcommands by name.
+var commands map[string]command
+
+func init() {
+	commands = map[string]command{
+		"scan":     {"Generate an SBOM for a Terraform configuration (default)", runScan},
+		"merge":    {"Merge several SBOM files into one", runMerge},
+		"diff":     {"Show the module changes between two SBOM files", runDiff},
+		"validate": {"Check that SBOM files are well-formed", runValidate},
+		"serve":    {"Serve the SBOM of a Terraform configuration over HTTP", runServe},
+		"version":  {"Print the version of this tool", runVersion},
+		"help":     {"Show this help", func([]string) { usage() }},
+	}
+}
+
+// resolveCommand returns the command named by the first argument along with its
+// remaining arguments. Invocations that do not start with a command name are treated
+// as an implicit scan, preserving the original `terraform-sbom <config> <output>` form.
+func resolveCommand(args []string) (string, []string) {
+	if len(args) > 0 {
+		if _, ok := commands[args[0]]; ok {
+			return args[0], args[1:]
+		}
+	}
+	return "scan", args
+}
+
+// runCommand dispatches the command line to the matching subcommand.
+func runCommand(args []string) {
+	name, args := resolveCommand(args)
+	commands[name].run(args)
+}
+
+// usage prints the list of available commands.
+func usage() {
+	program := filepath.Base(os.Args[0])
+	fmt.Fprintf(os.Stderr, "Usage: %s <command> [flags] [arguments]\n\nCommands:\n", program)
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", name, commands[name].summary)
+	}
+	fmt.Fprintf(os.Stderr, "\nRun '%s <command> -h' for the flags of a command.\n", program)
+}
+
+// newFlagSet creates the flag set of a subcommand with a usage line describing its arguments.
+func newFlagSet(name string, arguments string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s %s [flags] %s\n", filepath.Base(os.Args[0]), name, arguments)
+		flags.PrintDefaults()
+	}
+	return flags
+}
+
+// stringsFlag is a flag that can be given several times, collecting every value.
+type stringsFlag []string
+
+// String returns the collected values separated by commas.
+func (f *stringsFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+// Set appends a value.
+func (f *stringsFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+// runMerge implements the merge command, which combines several SBOM files into one.
+func runMerge(args []string) {
+	flags := newFlagSet("merge", "<output-file> <input-file>...")
+	outputFormat := flags.String("output", "json", "Specify output format: "+strings.Join(outputFormatNames(), ", "))
+	flags.Parse(args)
+
+	if flags.NArg() < 2 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	var sboms []*SBOM
+	for _, inputPath := range flags.Args()[1:] {
+		sbom, err := readSBOM(inputPath)
+		if err != nil {
+			log.Fatalf("Error reading SBOM: %v", err)
+		}
+		sboms = append(sboms, sbom)
+	}
+
+	merged := mergeSBOMs(sboms...)
+	merged.SerialNumber = newSerialNumber()
+	merged.Version = 1
+
+	err := writeSBOM(merged, *outputFormat, flags.Arg(0))
+	if err != nil {
+		log.Fatalf("Error writing SBOM: %v", err)
+	}
+}
+
+// runDiff implements the diff command, which reports modules added, removed, or
+// changed between two SBOM files.
+func runDiff(args []string) {
+	flags := newFlagSet("diff", "<old-sbom> <new-sbom>")
+	exitCode := flags.Bool("exit-code", false, "Exit with status 1 if the SBOMs differ")
+	flags.Parse(args)
+
+	if flags.NArg() != 2 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	oldSBOM, err := readSBOM(flags.Arg(0))
+	if err != nil {
+		log.Fatalf("Error reading SBOM: %v", err)
+	}
+	newSBOM, err := readSBOM(flags.Arg(1))
+	if err != nil {
+		log.Fatalf("Error reading SBOM: %v", err)
+	}
+
+	diff := diffSBOMs(oldSBOM, newSBOM)
+	printDiff(os.Stdout, diff)
+
+	if *exitCode && !diff.Empty() {
+		os.Exit(1)
+	}
+}
+
+// runValidate implements the validate command, which checks SBOM files for
+// missing required fields.
+func runValidate(args []string) {
+	flags := newFlagSet("validate", "<sbom-file>...")
+	flags.Parse(args)
+
+	if flags.NArg() < 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	failed := false
+	for _, path := range flags.Args() {
+		sbom, err := readSBOM(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+			failed = true
+			continue
+		}
+
+		problems := validateSBOM(sbom)
+		for _, problem := range problems {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", path, problem)
+		}
+		if len(problems) > 0 {
+			failed = true
+			continue
+		}
+		fmt.Printf("%s: valid (%d modules)\n", path, len(sbom.Modules))
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// runVersion implements the version command.
+func runVersion(args []string) {
+	flags := newFlagSet("version", "")
+	flags.Parse(args)
+
+	fmt.Printf("%s %s\n", filepath.Base(os.Args[0]), strings.TrimSpace(version))
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestResolveCommand tests subcommand dispatch and the implicit scan command.
+func TestResolveCommand(t *testing.T) {
+	tests := []struct {
+		args     []string
+		name     string
+		expected []string
+	}{
+		{[]string{"scan", "-output", "json", "config", "out.json"}, "scan", []string{"-output", "json", "config", "out.json"}},
+		{[]string{"merge", "out.json", "a.json", "b.json"}, "merge", []string{"out.json", "a.json", "b.json"}},
+		{[]string{"diff", "old.json", "new.json"}, "diff", []string{"old.json", "new.json"}},
+		{[]string{"validate", "sbom.json"}, "validate", []string{"sbom.json"}},
+		{[]string{"version"}, "version", []string{}},
+		{[]string{"config", "out.csv"}, "scan", []string{"config", "out.csv"}},
+		{[]string{"-output", "xml", "config", "out.xml"}, "scan", []string{"-output", "xml", "config", "out.xml"}},
+		{nil, "scan", nil},
+	}
+
+	for _, tt := range tests {
+		name, args := resolveCommand(tt.args)
+		if name != tt.name || !reflect.DeepEqual(args, tt.expected) {
+			t.Errorf("resolveCommand(%v) = (%q, %v), expected (%q, %v)", tt.args, name, args, tt.name, tt.expected)
+		}
+	}
+}
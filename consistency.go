@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// pinningWarnings reports module sources that are pinned differently across the
+// scanned configs, such as a git source pinned to v2.0.0 in one config and to main
+// in another. Sources are compared in their normalized form, so spellings that only
+// differ in case or trailing slashes are the same source. Each warning lists every
+// config calling the source with its pin. Local modules are part of the repository
+// and are not checked.
+func pinningWarnings(modules []ModuleInfo) []policyViolation {
+	uses := make(map[string][]ModuleInfo)
+	for _, mod := range modules {
+		if mod.SourceType == sourceTypeLocal {
+			continue
+		}
+		address := normalizeSource(mod.Source)
+		if query := strings.Index(address, "?"); query > -1 {
+			address = address[:query]
+		}
+		uses[address] = append(uses[address], mod)
+	}
+
+	var warnings []policyViolation
+	for address, mods := range uses {
+		pins := make(map[string]bool)
+		for _, mod := range mods {
+			pins[mod.Version] = true
+		}
+		if len(pins) < 2 {
+			continue
+		}
+
+		sort.Slice(mods, func(i, j int) bool {
+			if mods[i].Config != mods[j].Config {
+				return mods[i].Config < mods[j].Config
+			}
+			return mods[i].Name < mods[j].Name
+		})
+		details := make([]string, len(mods))
+		for i, mod := range mods {
+			details[i] = fmt.Sprintf("%s (module %s) pins %s", mod.Config, mod.Name, mod.Version)
+		}
+		warnings = append(warnings, policyViolation{
+			Rule:    ruleInconsistentPin,
+			Module:  address,
+			Message: fmt.Sprintf("module source %s is pinned inconsistently: %s", address, strings.Join(details, ", ")),
+		})
+	}
+
+	sort.Slice(warnings, func(i, j int) bool {
+		return warnings[i].Module < warnings[j].Module
+	})
+	return warnings
+}
+
+// providerConflicts reports provider local names that refer to different provider
+// sources across the scanned configs, such as aws meaning hashicorp/aws in one
+// config and a fork in another, which can pull in a provider nobody meant to trust.
+// Sources are compared by their fully-qualified address, so hashicorp/aws and
+// registry.terraform.io/hashicorp/aws are the same. Each warning lists every config
+// requiring the local name with its source.
+func providerConflicts(providers []ProviderInfo) []policyViolation {
+	uses := make(map[string][]ProviderInfo)
+	for _, provider := range providers {
+		uses[provider.Name] = append(uses[provider.Name], provider)
+	}
+
+	var warnings []policyViolation
+	for name, requirements := range uses {
+		addresses := make(map[string]bool)
+		for _, provider := range requirements {
+			addresses[providerAddress(provider.Name, provider.Source)] = true
+		}
+		if len(addresses) < 2 {
+			continue
+		}
+
+		sort.Slice(requirements, func(i, j int) bool {
+			return requirements[i].Config < requirements[j].Config
+		})
+		details := make([]string, len(requirements))
+		for i, provider := range requirements {
+			details[i] = fmt.Sprintf("%s requires %s", provider.Config, providerAddress(provider.Name, provider.Source))
+		}
+		warnings = append(warnings, policyViolation{
+			Rule:    ruleConflictingProvider,
+			Module:  name,
+			Message: fmt.Sprintf("provider %s refers to different sources: %s", name, strings.Join(details, ", ")),
+		})
+	}
+
+	sort.Slice(warnings, func(i, j int) bool {
+		return warnings[i].Module < warnings[j].Module
+	})
+	return warnings
+}
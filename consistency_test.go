@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+// TestPinningWarnings tests that sources pinned differently across configs are reported with each config's pin.
+func TestPinningWarnings(t *testing.T) {
+	sbom, err := generateRecursiveSBOM(context.Background(), "testdata/pinning", scanOptions{}, nil)
+	if err != nil {
+		t.Fatalf("Failed to generate SBOM: %v", err)
+	}
+
+	expected := []string{
+		"module source git::https://github.com/acme/terraform-labels.git is pinned inconsistently: testdata/pinning/app (module labels) pins main, testdata/pinning/network (module labels) pins v2.0.0",
+		"module source terraform-aws-modules/vpc/aws is pinned inconsistently: testdata/pinning/app (module vpc) pins ~> 5.0, testdata/pinning/network (module vpc) pins 5.1.0",
+	}
+	warnings := violationMessages(pinningWarnings(sbom.Modules))
+	if !reflect.DeepEqual(warnings, expected) {
+		t.Errorf("Pinning warnings mismatch:\nexpected %v\ngot      %v", expected, warnings)
+	}
+}
+
+// TestPinningWarningsConsistent tests that consistently pinned and local modules are not reported.
+func TestPinningWarningsConsistent(t *testing.T) {
+	modules := []ModuleInfo{
+		{Name: "dns", Source: "acme/dns/aws", SourceType: sourceTypeRegistry, Version: "1.2.0", Config: "network"},
+		{Name: "dns", Source: "acme/dns/aws", SourceType: sourceTypeRegistry, Version: "1.2.0", Config: "app"},
+		{Name: "service", Source: "../modules/service", SourceType: sourceTypeLocal, Version: "local", Config: "network"},
+		{Name: "service", Source: "../modules/service", SourceType: sourceTypeLocal, Version: "local", Config: "app"},
+	}
+
+	if warnings := pinningWarnings(modules); len(warnings) != 0 {
+		t.Errorf("Expected no pinning warnings, got %v", warnings)
+	}
+}
+
+// TestPinningWarningsNormalized tests that spellings of a source differing in case or
+// trailing slashes are compared as one source, while git sources differing in case are not.
+func TestPinningWarningsNormalized(t *testing.T) {
+	modules := []ModuleInfo{
+		{Name: "vpc", Source: "terraform-aws-modules/vpc/aws", SourceType: sourceTypeRegistry, Version: "5.1.0", Config: "network"},
+		{Name: "vpc", Source: "Terraform-AWS-Modules/VPC/aws/", SourceType: sourceTypeRegistry, Version: "5.2.0", Config: "app"},
+		{Name: "labels", Source: "git::https://github.com/acme/labels.git?ref=v1.0.0", SourceType: sourceTypeGit, Version: "v1.0.0", Config: "network"},
+		{Name: "labels", Source: "git::https://github.com/Acme/Labels.git?ref=v2.0.0", SourceType: sourceTypeGit, Version: "v2.0.0", Config: "app"},
+	}
+
+	expected := []string{
+		"module source terraform-aws-modules/vpc/aws is pinned inconsistently: app (module vpc) pins 5.2.0, network (module vpc) pins 5.1.0",
+	}
+	warnings := violationMessages(pinningWarnings(modules))
+	if !reflect.DeepEqual(warnings, expected) {
+		t.Errorf("Pinning warnings mismatch:\nexpected %v\ngot      %v", expected, warnings)
+	}
+}
+
+// TestProviderConflicts tests that a provider local name required from different sources
+// across configs is reported with each config's source, while the same source written
+// with and without the registry host is not.
+func TestProviderConflicts(t *testing.T) {
+	sbom, err := generateRecursiveSBOM(context.Background(), "testdata/provider-conflict", scanOptions{}, nil)
+	if err != nil {
+		t.Fatalf("Failed to generate SBOM: %v", err)
+	}
+
+	expected := []string{
+		"provider aws refers to different sources: testdata/provider-conflict/app requires registry.terraform.io/hashicorp/aws, testdata/provider-conflict/network requires registry.terraform.io/acme-forks/aws",
+	}
+	warnings := violationMessages(providerConflicts(sbom.Providers))
+	if !reflect.DeepEqual(warnings, expected) {
+		t.Errorf("Provider conflicts mismatch:\nexpected %v\ngot      %v", expected, warnings)
+	}
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	goversion "github.com/hashicorp/go-version"
+)
+
+// constraintPattern splits a single version constraint into its operator and version.
+var constraintPattern = regexp.MustCompile(`^\s*(=|!=|>=|<=|>|<|~>)?\s*(\S+)\s*$`)
+
+// wildcardPattern matches a version with a trailing wildcard, such as 2.x or 1.4.*,
+// or a bare wildcard that allows any version.
+var wildcardPattern = regexp.MustCompile(`^v?((?:\d+\.)*)[xX*]$`)
+
+// strictSemverPattern matches a tag in the vMAJOR.MINOR.PATCH form required by -strict-semver.
+var strictSemverPattern = regexp.MustCompile(`^v(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)$`)
+
+// operatorOrder ranks the constraint operators so that constraints on the same
+// version are written in a fixed order.
+var operatorOrder = map[string]int{">": 0, ">=": 1, "~>": 2, "=": 3, "!=": 4, "<=": 5, "<": 6}
+
+// normalizedConstraint is a single constraint in canonical form.
+type normalizedConstraint struct {
+	op      string
+	version *goversion.Version
+	text    string // Canonical version text, keeping the precision of ~> constraints
+}
+
+// normalizeConstraint rewrites a version constraint such as ">= 2.0, < 3.0", "~> 2.0",
+// or "2.x" in a canonical form: each constraint has an explicit operator and a full
+// version, wildcards become the equivalent ~> constraint, and the constraints are
+// sorted by version and then operator. The precision of ~> constraints is kept, since
+// it changes which versions they allow.
+func normalizeConstraint(s string) (string, error) {
+	var constraints []normalizedConstraint
+	seen := make(map[string]bool)
+
+	for _, part := range strings.Split(s, ",") {
+		c, err := parseConstraint(part)
+		if err != nil {
+			return "", err
+		}
+		if seen[c.String()] {
+			continue
+		}
+		seen[c.String()] = true
+		constraints = append(constraints, c)
+	}
+
+	sort.SliceStable(constraints, func(i, j int) bool {
+		a, b := constraints[i], constraints[j]
+		if cmp := a.version.Compare(b.version); cmp != 0 {
+			return cmp < 0
+		}
+		return operatorOrder[a.op] < operatorOrder[b.op]
+	})
+
+	parts := make([]string, len(constraints))
+	for i, c := range constraints {
+		parts[i] = c.String()
+	}
+	return strings.Join(parts, ", "), nil
+}
+
+// parseConstraint parses a single constraint, validating it with go-version.
+func parseConstraint(s string) (normalizedConstraint, error) {
+	matches := constraintPattern.FindStringSubmatch(s)
+	if matches == nil {
+		return normalizedConstraint{}, fmt.Errorf("malformed constraint %q", strings.TrimSpace(s))
+	}
+	op, raw := matches[1], matches[2]
+
+	if wildcard := wildcardPattern.FindStringSubmatch(raw); wildcard != nil {
+		if op != "" && op != "=" {
+			return normalizedConstraint{}, fmt.Errorf("malformed constraint %q: wildcards cannot be combined with %s", strings.TrimSpace(s), op)
+		}
+		prefix := strings.TrimSuffix(wildcard[1], ".")
+		if prefix == "" {
+			op, raw = ">=", "0.0.0"
+		} else {
+			op, raw = "~>", prefix+".0"
+		}
+	}
+	if op == "" {
+		op = "="
+	}
+
+	if _, err := goversion.NewConstraint(op + " " + raw); err != nil {
+		return normalizedConstraint{}, fmt.Errorf("malformed constraint %q: %v", strings.TrimSpace(s), err)
+	}
+	v, err := goversion.NewVersion(raw)
+	if err != nil {
+		return normalizedConstraint{}, fmt.Errorf("malformed constraint %q: %v", strings.TrimSpace(s), err)
+	}
+
+	c := normalizedConstraint{op: op, version: v, text: v.String()}
+	if op == "~>" {
+		c.text = pessimisticVersion(v, raw)
+	}
+	return c, nil
+}
+
+// pessimisticVersion formats a version with as many segments as were written, since
+// "~> 2.0" allows any 2.x release while "~> 2.0.0" only allows 2.0.x releases.
+func pessimisticVersion(v *goversion.Version, raw string) string {
+	core := strings.TrimPrefix(raw, "v")
+	if i := strings.IndexAny(core, "-+"); i > -1 {
+		core = core[:i]
+	}
+	precision := strings.Count(core, ".") + 1
+
+	segments := v.Segments()
+	if precision > len(segments) {
+		precision = len(segments)
+	}
+	parts := make([]string, precision)
+	for i := range parts {
+		parts[i] = strconv.Itoa(segments[i])
+	}
+
+	text := strings.Join(parts, ".")
+	if pre := v.Prerelease(); pre != "" {
+		text += "-" + pre
+	}
+	return text
+}
+
+// String returns the constraint with its operator and canonical version.
+func (c normalizedConstraint) String() string {
+	return c.op + " " + c.text
+}
+
+// setNormalizedVersions records the canonical form of the version constraint of each
+// registry module. Modules whose version is not a valid constraint are left without one.
+func setNormalizedVersions(modules []ModuleInfo) {
+	for i := range modules {
+		if modules[i].SourceType != sourceTypeRegistry {
+			continue
+		}
+		normalized, err := normalizeConstraint(modules[i].Version)
+		if err == nil {
+			modules[i].NormalizedVersion = normalized
+		}
+	}
+}
+
+// isStrictSemver reports whether a pinned version is exactly vMAJOR.MINOR.PATCH.
+func isStrictSemver(pin string) bool {
+	if !strictSemverPattern.MatchString(pin) {
+		return false
+	}
+	_, err := goversion.NewSemver(pin)
+	return err == nil
+}
+
+// strictSemverViolations lists the modules pinned to a version that is not exactly
+// vMAJOR.MINOR.PATCH, such as 2.0 or release-2.0.0. Local modules, unpinned modules,
+// and registry modules constrained to a range of versions are exempt.
+func strictSemverViolations(modules []ModuleInfo) []policyViolation {
+	var violations []policyViolation
+	for _, mod := range modules {
+		if mod.SourceType == sourceTypeLocal || mod.Version == "" || mod.Version == unknownVersion {
+			continue
+		}
+
+		pin := mod.Version
+		if mod.SourceType == sourceTypeRegistry {
+			if !isExactConstraint([]string{pin}) {
+				continue
+			}
+			pin = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(pin), "="))
+		}
+
+		if !isStrictSemver(pin) {
+			violations = append(violations, policyViolation{
+				Rule:    ruleNonSemverPin,
+				Config:  mod.Config,
+				Module:  mod.Name,
+				Path:    mod.Path,
+				Message: fmt.Sprintf("%s: module %s is pinned to %q, which is not a vMAJOR.MINOR.PATCH version", mod.Config, mod.Name, mod.Version),
+			})
+		}
+	}
+	return violations
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestNormalizeConstraint tests that version constraints in different syntaxes are normalized to a canonical form.
+func TestNormalizeConstraint(t *testing.T) {
+	tests := []struct {
+		constraint string
+		expected   string
+	}{
+		{"5.1.0", "= 5.1.0"},
+		{"v5.1", "= 5.1.0"},
+		{"= 5.1.0", "= 5.1.0"},
+		{">= 2.0, < 3.0", ">= 2.0.0, < 3.0.0"},
+		{"<3.0,>=2.0", ">= 2.0.0, < 3.0.0"},
+		{"~> 2.0", "~> 2.0"},
+		{"~>2.0.1", "~> 2.0.1"},
+		{"~> 2", "~> 2"},
+		{"2.x", "~> 2.0"},
+		{"2.1.*", "~> 2.1.0"},
+		{"*", ">= 0.0.0"},
+		{">= 1.0.0-beta1", ">= 1.0.0-beta1"},
+		{"!= 2.5.0, >= 2.0, < 3.0, >= 2.0", ">= 2.0.0, != 2.5.0, < 3.0.0"},
+		{"> 1.0, >= 1.0", "> 1.0.0, >= 1.0.0"},
+	}
+
+	for _, test := range tests {
+		got, err := normalizeConstraint(test.constraint)
+		if err != nil {
+			t.Errorf("normalizeConstraint(%q) returned error: %v", test.constraint, err)
+			continue
+		}
+		if got != test.expected {
+			t.Errorf("normalizeConstraint(%q) = %q, expected %q", test.constraint, got, test.expected)
+		}
+	}
+}
+
+// TestNormalizeConstraintInvalid tests that malformed constraints are rejected.
+func TestNormalizeConstraintInvalid(t *testing.T) {
+	tests := []string{"", "N/A", "local", "latest", ">= abc", "2.0,", ">= 2.x", "=> 1.0", "main"}
+
+	for _, constraint := range tests {
+		if got, err := normalizeConstraint(constraint); err == nil {
+			t.Errorf("normalizeConstraint(%q) = %q, expected an error", constraint, got)
+		}
+	}
+}
+
+// TestSetNormalizedVersions tests that only registry modules get a normalized version.
+func TestSetNormalizedVersions(t *testing.T) {
+	modules := []ModuleInfo{
+		{Name: "vpc", SourceType: sourceTypeRegistry, Version: "~> 5.0"},
+		{Name: "pinned", SourceType: sourceTypeRegistry, Version: "N/A"},
+		{Name: "db", SourceType: sourceTypeGit, Version: "v1.4.2"},
+	}
+
+	setNormalizedVersions(modules)
+
+	expected := []string{"~> 5.0", "", ""}
+	for i, mod := range modules {
+		if mod.NormalizedVersion != expected[i] {
+			t.Errorf("Expected normalized version %q for %s, got %q", expected[i], mod.Name, mod.NormalizedVersion)
+		}
+	}
+}
+
+// TestIsStrictSemver tests the vMAJOR.MINOR.PATCH format check.
+func TestIsStrictSemver(t *testing.T) {
+	tests := map[string]bool{
+		"v2.0.0":         true,
+		"v10.21.3":       true,
+		"2.0.0":          false,
+		"v2.0":           false,
+		"2.0":            false,
+		"release-2.0.0":  false,
+		"v2.0.0-beta.1":  false,
+		"v2.0.0+build.5": false,
+		"v02.0.0":        false,
+		"main":           false,
+	}
+
+	for pin, expected := range tests {
+		if got := isStrictSemver(pin); got != expected {
+			t.Errorf("isStrictSemver(%q) = %v, expected %v", pin, got, expected)
+		}
+	}
+}
+
+// TestStrictSemverViolations tests that only pinned, non-conforming modules are reported.
+func TestStrictSemverViolations(t *testing.T) {
+	modules := []ModuleInfo{
+		{Name: "labels", Source: "git::https://github.com/acme/labels.git?ref=v2.0.0", SourceType: sourceTypeGit, Version: "v2.0.0", Config: "app"},
+		{Name: "db", Source: "git::https://github.com/acme/db.git?ref=release-2.0.0", SourceType: sourceTypeGit, Version: "release-2.0.0", Config: "app"},
+		{Name: "dns", Source: "github.com/acme/dns?ref=2.0", SourceType: sourceTypeGitHub, Version: "2.0", Config: "app"},
+		{Name: "vpc", Source: "terraform-aws-modules/vpc/aws", SourceType: sourceTypeRegistry, Version: "~> 5.0", Config: "app"},
+		{Name: "subnets", Source: "acme/subnets/aws", SourceType: sourceTypeRegistry, Version: "= v1.2.0", Config: "app"},
+		{Name: "sg", Source: "acme/sg/aws", SourceType: sourceTypeRegistry, Version: "1.2.0", Config: "app"},
+		{Name: "cache", Source: "acme/cache/aws", SourceType: sourceTypeRegistry, Version: "v1.2.0+build.5", Config: "app"},
+		{Name: "labels", Source: "git::https://github.com/acme/labels.git?ref=v0.0.0-20210101000000-abcdef123456", SourceType: sourceTypeGit, Version: "v0.0.0-20210101000000-abcdef123456", Config: "app"},
+		{Name: "service", Source: "./modules/service", SourceType: sourceTypeLocal, Version: "local", Config: "app"},
+		{Name: "archive", Source: "https://example.com/module.zip", SourceType: sourceTypeHTTP, Version: "N/A", Config: "app"},
+	}
+
+	expected := []string{
+		`app: module db is pinned to "release-2.0.0", which is not a vMAJOR.MINOR.PATCH version`,
+		`app: module dns is pinned to "2.0", which is not a vMAJOR.MINOR.PATCH version`,
+		`app: module sg is pinned to "1.2.0", which is not a vMAJOR.MINOR.PATCH version`,
+		`app: module cache is pinned to "v1.2.0+build.5", which is not a vMAJOR.MINOR.PATCH version`,
+		`app: module labels is pinned to "v0.0.0-20210101000000-abcdef123456", which is not a vMAJOR.MINOR.PATCH version`,
+	}
+	if violations := violationMessages(strictSemverViolations(modules)); !reflect.DeepEqual(violations, expected) {
+		t.Errorf("Violations mismatch:\nexpected %v\ngot      %v", expected, violations)
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+)
+
+// csvMetadataPrefix starts the comment row that -csv-metadata writes before the CSV header.
+const csvMetadataPrefix = "# "
+
+// csvMetadata describes how a CSV SBOM was generated: the version of this tool, the
+// generation time, and the config root that was scanned. It is written as a comment
+// row before the header, which CSV readers that skip lines starting with # ignore.
+type csvMetadata struct {
+	ToolVersion string
+	Timestamp   string
+	ConfigRoot  string
+}
+
+// String renders the comment row as semicolon-separated name=value pairs, such as
+// "# tool=terraform-sbom; version=1.4.0; timestamp=2024-01-01T12:00:00Z; config_root=live".
+// The timestamp is left out of canonical output, which has none.
+func (m csvMetadata) String() string {
+	pairs := []string{"tool=terraform-sbom", "version=" + m.ToolVersion}
+	if m.Timestamp != "" {
+		pairs = append(pairs, "timestamp="+m.Timestamp)
+	}
+	pairs = append(pairs, "config_root="+m.ConfigRoot)
+	return csvMetadataPrefix + strings.Join(pairs, "; ")
+}
+
+// splitCSVMetadata separates the leading comment rows of a CSV file from its records,
+// returning the metadata written by -csv-metadata, if any, and the rest of the file.
+func splitCSVMetadata(content []byte) (*csvMetadata, []byte) {
+	var metadata *csvMetadata
+	for bytes.HasPrefix(content, []byte("#")) {
+		line, rest, _ := bytes.Cut(content, []byte("\n"))
+		content = rest
+		if m, ok := parseCSVMetadata(strings.TrimRight(string(line), "\r")); ok {
+			metadata = &m
+		}
+	}
+	return metadata, content
+}
+
+// parseCSVMetadata parses a comment row written by csvMetadata.String.
+func parseCSVMetadata(line string) (csvMetadata, bool) {
+	pairs := make(map[string]string)
+	for _, pair := range strings.Split(strings.TrimPrefix(line, "#"), ";") {
+		name, value, _ := strings.Cut(strings.TrimSpace(pair), "=")
+		pairs[name] = value
+	}
+	if pairs["tool"] != "terraform-sbom" {
+		return csvMetadata{}, false
+	}
+	return csvMetadata{ToolVersion: pairs["version"], Timestamp: pairs["timestamp"], ConfigRoot: pairs["config_root"]}, true
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestWriteCSVMetadata tests that the metadata comment row comes before the header and
+// that reading the file back parses it while leaving the records unchanged.
+func TestWriteCSVMetadata(t *testing.T) {
+	outputPath := filepath.Join(t.TempDir(), "sbom.csv")
+	metadata := &csvMetadata{ToolVersion: "1.2.3", Timestamp: "2024-01-01T12:00:00Z", ConfigRoot: "live/network"}
+	if err := writeCSV(mockSBOM(), outputPath, moduleFields, metadata); err != nil {
+		t.Fatalf("Failed to write SBOM to CSV: %v", err)
+	}
+
+	content, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("Failed to read CSV file: %v", err)
+	}
+	lines := strings.Split(string(content), "\n")
+	expected := "# tool=terraform-sbom; version=1.2.3; timestamp=2024-01-01T12:00:00Z; config_root=live/network"
+	if lines[0] != expected {
+		t.Errorf("Expected metadata row %q, got %q", expected, lines[0])
+	}
+	if !strings.HasPrefix(lines[1], strings.Join(fieldHeaders(moduleFields), ",")) {
+		t.Errorf("Expected the header row after the metadata row, got %q", lines[1])
+	}
+
+	withMetadata, err := readSBOM(outputPath)
+	if err != nil {
+		t.Fatalf("Failed to read SBOM: %v", err)
+	}
+	if withMetadata.Timestamp != metadata.Timestamp {
+		t.Errorf("Expected timestamp %q from the metadata row, got %q", metadata.Timestamp, withMetadata.Timestamp)
+	}
+
+	plainPath := filepath.Join(t.TempDir(), "sbom.csv")
+	if err := writeCSV(mockSBOM(), plainPath, moduleFields, nil); err != nil {
+		t.Fatalf("Failed to write SBOM to CSV: %v", err)
+	}
+	plain, err := readSBOM(plainPath)
+	if err != nil {
+		t.Fatalf("Failed to read SBOM: %v", err)
+	}
+	if !reflect.DeepEqual(withMetadata.Modules, plain.Modules) || !reflect.DeepEqual(withMetadata.Providers, plain.Providers) {
+		t.Errorf("Expected the same records with and without the metadata row")
+	}
+
+	// Appending to an existing file does not repeat the metadata row.
+	if err := writeCSV(mockSBOM(), outputPath, moduleFields, metadata); err != nil {
+		t.Fatalf("Failed to append SBOM to CSV: %v", err)
+	}
+	content, err = os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("Failed to read CSV file: %v", err)
+	}
+	if count := strings.Count(string(content), "# tool="); count != 1 {
+		t.Errorf("Expected one metadata row after appending, got %d", count)
+	}
+}
+
+// TestParseCSVMetadata tests parsing metadata rows, with and without a timestamp, and
+// ignoring comments written by other tools.
+func TestParseCSVMetadata(t *testing.T) {
+	canonical := csvMetadata{ToolVersion: "dev", ConfigRoot: "/work/live"}
+	parsed, ok := parseCSVMetadata(canonical.String())
+	if !ok || parsed != canonical {
+		t.Errorf("Expected %+v, got %+v (ok %v)", canonical, parsed, ok)
+	}
+	if strings.Contains(canonical.String(), "timestamp=") {
+		t.Errorf("Expected no timestamp in canonical metadata, got %q", canonical.String())
+	}
+
+	if _, ok := parseCSVMetadata("# exported by another tool"); ok {
+		t.Errorf("Expected a comment from another tool not to be parsed as metadata")
+	}
+}
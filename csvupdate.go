@@ -0,0 +1,268 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// updateCSV rewrites an existing CSV SBOM with the results of a new scan of root.
+// Entries for configs under root are replaced by the new results, so modules that
+// changed are updated and modules that were removed are dropped, while entries for
+// other configs are kept. The file is replaced atomically, starting with the new
+// metadata comment row, if any.
+func updateCSV(sbom *SBOM, outputPath string, root string, metadata *csvMetadata) error {
+	updated := sbom
+	if fileExists(outputPath) {
+		existing, err := readSBOM(outputPath)
+		if err != nil {
+			return err
+		}
+		updated = mergeSBOMs(withoutConfigsUnder(existing, root), sbom)
+	}
+
+	err := writeFileAtomic(outputPath, func(w io.Writer) error {
+		return writeCSVRecords(w, updated, moduleFields, true, metadata)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update CSV file: %v", err)
+	}
+
+	fmt.Printf("SBOM successfully written to %s\n", outputPath)
+	return nil
+}
+
+// withoutConfigsUnder returns a copy of the SBOM without the entries of root and
+// the configs below it.
+func withoutConfigsUnder(sbom *SBOM, root string) *SBOM {
+	var filtered SBOM
+	for _, mod := range sbom.Modules {
+		if !isUnder(mod.Config, root) {
+			filtered.Modules = append(filtered.Modules, mod)
+		}
+	}
+	for _, provider := range sbom.Providers {
+		if !isUnder(provider.Config, root) {
+			filtered.Providers = append(filtered.Providers, provider)
+		}
+	}
+	for _, output := range sbom.Outputs {
+		if !isUnder(output.Config, root) {
+			filtered.Outputs = append(filtered.Outputs, output)
+		}
+	}
+	for _, config := range sbom.Configs {
+		if !isUnder(config.Path, root) {
+			filtered.Configs = append(filtered.Configs, config)
+		}
+	}
+	return &filtered
+}
+
+// isUnder reports whether path is root or a directory below it.
+func isUnder(path string, root string) bool {
+	rel, err := filepath.Rel(filepath.Clean(root), filepath.Clean(path))
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// parseCSV reads an SBOM from the CSV written by writeSBOMToCSV. Module columns are
+// matched by their header, so files written with -fields or by older versions with
+// fewer columns can still be read. The timestamp is taken from the metadata comment
+// row written by -csv-metadata, if there is one.
+func parseCSV(content []byte) (*SBOM, error) {
+	metadata, content := splitCSVMetadata(content)
+	reader := csv.NewReader(bytes.NewReader(content))
+	reader.FieldsPerRecord = -1
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	var sbom SBOM
+	if metadata != nil {
+		sbom.Timestamp = metadata.Timestamp
+	}
+	var header []string
+	section := "modules"
+	for i, record := range records {
+		switch {
+		case i == 0:
+			header = record
+			continue
+		case isCSVHeader(record, csvProviderHeader), isCSVHeader(record, csvProviderHeader[:len(csvProviderHeader)-1]):
+			// Files written before the PURL column was added lack it.
+			section = "providers"
+			continue
+		case isCSVHeader(record, csvOutputHeader):
+			section = "outputs"
+			continue
+		case isCSVHeader(record, csvConfigHeader), isCSVHeader(record, csvConfigHeader[:len(csvConfigHeader)-1]), isCSVHeader(record, csvConfigHeader[:len(csvConfigHeader)-2]), isCSVHeader(record, csvConfigHeader[:len(csvConfigHeader)-3]), isCSVHeader(record, csvConfigHeader[:len(csvConfigHeader)-5]):
+			// Files written before the Required Version, Resources, and CLI Version
+			// columns, or before the Experiments and Provider Functions columns, were
+			// added lack them.
+			section = "configs"
+			continue
+		}
+
+		switch section {
+		case "modules":
+			sbom.Modules = append(sbom.Modules, parseCSVModule(header, record))
+		case "providers":
+			record = padCSVRecord(record, len(csvProviderHeader))
+			sbom.Providers = append(sbom.Providers, ProviderInfo{Config: record[0], Name: record[1], Source: record[2], VersionConstraint: record[3], LockedVersion: record[4], PURL: record[5]})
+		case "outputs":
+			record = padCSVRecord(record, len(csvOutputHeader))
+			sensitive, _ := strconv.ParseBool(record[3])
+			sbom.Outputs = append(sbom.Outputs, OutputInfo{Config: record[0], Name: record[1], Description: record[2], Sensitive: sensitive})
+		case "configs":
+			record = padCSVRecord(record, len(csvConfigHeader))
+			lineCount, _ := strconv.Atoi(record[1])
+			config := ConfigInfo{Path: record[0], LineCount: lineCount, Moves: parseMoves(record[4]), Imports: parseImports(record[5]), ProviderConfigs: parseProviderConfigs(record[6]), ResourceCounts: parseResourceCounts(record[7]), Experiments: splitList(record[8]), ProviderFunctions: splitList(record[9]), CLIVersion: record[10], Resources: parseResources(record[11]), RequiredVersion: record[12]}
+			if record[2] != "" {
+				config.Backend = &BackendInfo{Type: record[2], Config: AttributeMap(parsePairs(record[3]))}
+			}
+			sbom.Configs = append(sbom.Configs, config)
+		}
+	}
+
+	return &sbom, nil
+}
+
+// isCSVHeader reports whether a record is the given section header, ignoring padding.
+func isCSVHeader(record []string, header []string) bool {
+	if len(record) < len(header) {
+		return false
+	}
+	for i := len(header); i < len(record); i++ {
+		if record[i] != "" {
+			return false
+		}
+	}
+	return reflect.DeepEqual(record[:len(header)], header)
+}
+
+// parseCSVModule builds a module from a CSV record using the column headers.
+func parseCSVModule(header []string, record []string) ModuleInfo {
+	var mod ModuleInfo
+	for i, column := range header {
+		if i >= len(record) {
+			break
+		}
+		value := record[i]
+
+		switch column {
+		case "Config Path":
+			mod.Config = value
+		case "Module Name":
+			mod.Name = value
+		case "Source":
+			mod.Source = value
+		case "Call Path":
+			mod.Path = value
+		case "Subdir":
+			mod.Subdir = value
+		case "Source Type":
+			mod.SourceType = value
+		case "Version":
+			mod.Version = value
+		case "Version Source":
+			mod.VersionSource = value
+		case "Original Version":
+			mod.OriginalVersion = value
+		case "Normalized Version":
+			mod.NormalizedVersion = value
+		case "PURL":
+			mod.PURL = value
+		case "Registry":
+			mod.Registry = value
+		case "Private":
+			mod.Private = value == "true"
+		case "Insecure":
+			mod.Insecure = value == "true"
+		case "Organization":
+			mod.Organization = value
+		case "Providers":
+			if value != "" {
+				mod.ProviderMappings = ProviderMap(parsePairs(value))
+			}
+		case "Owners":
+			mod.Owners = splitList(value)
+		case "Description":
+			mod.Description = value
+		case "Has README":
+			mod.HasReadme = parseOptionalBool(value)
+		case "Approved":
+			mod.Approved = parseOptionalBool(value)
+		case "Reachable":
+			mod.Reachable = parseOptionalBool(value)
+		case "Reachability Error":
+			mod.ReachabilityError = value
+		case "Age Days":
+			mod.AgeDays = parseOptionalInt(value)
+		case "Local Version":
+			mod.LocalVersion = value
+		case "Required Version":
+			mod.RequiredVersion = value
+		case "Change":
+			mod.Change = value
+		case "Previous Version":
+			mod.PreviousVersion = value
+		case "Depends On":
+			mod.DependsOn = splitList(value)
+		case "Address":
+			mod.Address = value
+		case "Deprecated":
+			mod.Deprecated = value == "true"
+		case "Replacement":
+			mod.Replacement = value
+		case "Normalized Source":
+			mod.NormalizedSource = value
+		}
+	}
+	return mod
+}
+
+// parsePairs parses the semicolon-separated name=value pairs written by ProviderMap.String.
+func parsePairs(s string) map[string]string {
+	if s == "" {
+		return nil
+	}
+	pairs := make(map[string]string)
+	for _, pair := range strings.Split(s, ";") {
+		name, value, _ := strings.Cut(pair, "=")
+		pairs[name] = value
+	}
+	return pairs
+}
+
+// splitList parses a semicolon-separated list, returning nil for an empty string.
+func splitList(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ";")
+}
+
+// parseOptionalBool parses a bool written by optionalBool.
+func parseOptionalBool(s string) *bool {
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		return nil
+	}
+	return &b
+}
+
+// parseOptionalInt parses an int written by optionalInt.
+func parseOptionalInt(s string) *int {
+	i, err := strconv.Atoi(s)
+	if err != nil {
+		return nil
+	}
+	return &i
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestUpdateCSV tests that an update adds new modules, modifies changed ones, and
+// deletes removed ones for the scanned configs while keeping other configs.
+func TestUpdateCSV(t *testing.T) {
+	outputPath := filepath.Join(t.TempDir(), "sbom.csv")
+
+	initial := &SBOM{Modules: []ModuleInfo{
+		{Name: "vpc", Source: "terraform-aws-modules/vpc/aws", SourceType: sourceTypeRegistry, Version: "4.0.0", Config: "live/network"},
+		{Name: "legacy", Source: "./modules/legacy", SourceType: sourceTypeLocal, Version: "local", Config: "live/network/edge"},
+		{Name: "app", Source: "./modules/app", SourceType: sourceTypeLocal, Version: "local", Config: "live/app"},
+	}}
+	if err := writeSBOMToCSV(initial, outputPath); err != nil {
+		t.Fatalf("Failed to write SBOM to CSV: %v", err)
+	}
+
+	rescan := &SBOM{Modules: []ModuleInfo{
+		{Name: "vpc", Source: "terraform-aws-modules/vpc/aws", SourceType: sourceTypeRegistry, Version: "5.1.0", Config: "live/network"},
+		{Name: "dns", Source: "./modules/dns", SourceType: sourceTypeLocal, Version: "local", Config: "live/network"},
+	}}
+	for i := 0; i < 2; i++ {
+		if err := updateCSV(rescan, outputPath, "live/network", nil); err != nil {
+			t.Fatalf("Failed to update CSV: %v", err)
+		}
+	}
+
+	result, err := readSBOM(outputPath)
+	if err != nil {
+		t.Fatalf("Failed to read CSV: %v", err)
+	}
+
+	var got []string
+	for _, mod := range result.Modules {
+		got = append(got, mod.Config+":"+mod.Name+"@"+mod.Version)
+	}
+	expected := []string{
+		"live/app:app@local",
+		"live/network:dns@local",
+		"live/network:vpc@5.1.0",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Updated modules mismatch: expected %v, got %v", expected, got)
+	}
+
+	content, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("Failed to read CSV: %v", err)
+	}
+	if strings.Count(string(content), "Config Path") != 1 {
+		t.Errorf("Expected a single header row, got:\n%s", content)
+	}
+}
+
+// TestUpdateCSVNewFile tests that updating a missing file creates it.
+func TestUpdateCSVNewFile(t *testing.T) {
+	outputPath := filepath.Join(t.TempDir(), "sbom.csv")
+	if err := updateCSV(mockSBOM(), outputPath, "/path/to/config", nil); err != nil {
+		t.Fatalf("Failed to update CSV: %v", err)
+	}
+
+	result, err := readSBOM(outputPath)
+	if err != nil {
+		t.Fatalf("Failed to read CSV: %v", err)
+	}
+	if !reflect.DeepEqual(result.Modules, mockSBOM().Modules) {
+		t.Errorf("Modules mismatch: expected %v, got %v", mockSBOM().Modules, result.Modules)
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(outputPath))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("Expected only the CSV file to remain, got %v", entries)
+	}
+}
+
+// TestParseCSVSections tests reading outputs and configs back from their CSV sections.
+func TestParseCSVSections(t *testing.T) {
+	sbom := mockSBOM()
+	sbom.Providers = []ProviderInfo{{Name: "aws", Source: "hashicorp/aws", VersionConstraint: "~> 5.0", LockedVersion: "5.31.0", PURL: "pkg:terraform/hashicorp/aws@5.31.0", Config: "/path/to/config"}}
+	sbom.Outputs = []OutputInfo{{Name: "vpc_id", Description: "ID of the VPC", Sensitive: true, Config: "/path/to/config"}}
+	sbom.Configs = []ConfigInfo{{Path: "/path/to/config", LineCount: 12, Backend: &BackendInfo{Type: "s3", Config: AttributeMap{"bucket": "state", "key": "a/b"}}, ProviderConfigs: []ProviderConfigInfo{{Name: "aws"}, {Name: "aws", Alias: "west"}}, ResourceCounts: ResourceCounts{"aws": 12, "datadog": 3}, Experiments: []string{"module_variable_optional_attrs"}, ProviderFunctions: []string{"provider::aws::arn_parse", "provider::time::rfc3339_parse"}, CLIVersion: "1.7.5", Resources: []ResourceInfo{{Type: "aws_vpc", Name: "main", Provider: "aws"}, {Type: "aws_subnet", Name: "private", Provider: "aws", Module: "module.network.module.subnet"}}}}
+
+	outputPath := filepath.Join(t.TempDir(), "sbom.csv")
+	if err := writeSBOMToCSV(sbom, outputPath); err != nil {
+		t.Fatalf("Failed to write SBOM to CSV: %v", err)
+	}
+
+	result, err := readSBOM(outputPath)
+	if err != nil {
+		t.Fatalf("Failed to read CSV: %v", err)
+	}
+	if !reflect.DeepEqual(result.Providers, sbom.Providers) {
+		t.Errorf("Providers mismatch: expected %v, got %v", sbom.Providers, result.Providers)
+	}
+	if !reflect.DeepEqual(result.Outputs, sbom.Outputs) {
+		t.Errorf("Outputs mismatch: expected %v, got %v", sbom.Outputs, result.Outputs)
+	}
+	if !reflect.DeepEqual(result.Configs, sbom.Configs) {
+		t.Errorf("Configs mismatch: expected %v, got %v", sbom.Configs, result.Configs)
+	}
+}
+
+// TestParseCSVLegacyProviderHeader tests reading the provider section of a CSV file
+// written before the PURL column was added.
+func TestParseCSVLegacyProviderHeader(t *testing.T) {
+	content := strings.Join([]string{
+		strings.Join(csvHeader, ","),
+		"Config Path,Provider Name,Source,Version Constraint,Locked Version" + strings.Repeat(",", len(csvHeader)-5),
+		"app,aws,hashicorp/aws,~> 5.0,5.31.0" + strings.Repeat(",", len(csvHeader)-5),
+	}, "\n") + "\n"
+	path := filepath.Join(t.TempDir(), "sbom.csv")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write CSV: %v", err)
+	}
+
+	sbom, err := readSBOM(path)
+	if err != nil {
+		t.Fatalf("Failed to read CSV: %v", err)
+	}
+	if len(sbom.Modules) != 0 {
+		t.Errorf("Expected no modules, got %v", sbom.Modules)
+	}
+	expected := []ProviderInfo{{Name: "aws", Source: "hashicorp/aws", VersionConstraint: "~> 5.0", LockedVersion: "5.31.0", Config: "app"}}
+	if !reflect.DeepEqual(sbom.Providers, expected) {
+		t.Errorf("Providers mismatch: expected %v, got %v", expected, sbom.Providers)
+	}
+}
+
+// TestIsUnder tests matching config paths against a scan root.
+func TestIsUnder(t *testing.T) {
+	tests := []struct {
+		path     string
+		root     string
+		expected bool
+	}{
+		{"live/network", "live/network", true},
+		{"live/network/edge", "live/network", true},
+		{"live/network-dr", "live/network", false},
+		{"live/app", "live/network", false},
+		{"..foo", ".", true},
+		{"live", ".", true},
+	}
+	for _, tt := range tests {
+		if got := isUnder(tt.path, tt.root); got != tt.expected {
+			t.Errorf("isUnder(%q, %q) = %t, expected %t", tt.path, tt.root, got, tt.expected)
+		}
+	}
+}
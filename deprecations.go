@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// deprecation marks the module sources matching a glob pattern as deprecated, with the
+// source to migrate to and a message explaining why.
+type deprecation struct {
+	Pattern     *regexp.Regexp
+	Replacement string
+	Message     string
+}
+
+// loadDeprecations reads a file of deprecated module sources, one per line as a source
+// pattern, the source replacing it, and a message, separated by whitespace, such as
+// git::https://github.com/acme/network* acme/vpc/aws Use the registry module. The
+// pattern is a glob as in an allowlist, a replacement of - means there is none, and
+// the message may be left out. Blank lines and lines starting with # are ignored.
+func loadDeprecations(path string) ([]deprecation, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open deprecations: %v", err)
+	}
+	defer file.Close()
+
+	var deprecations []deprecation
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		fields := strings.Fields(text)
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("%s:%d: expected a source pattern and a replacement", path, line)
+		}
+		replacement := fields[1]
+		if replacement == "-" {
+			replacement = ""
+		}
+		deprecations = append(deprecations, deprecation{
+			Pattern:     globRegexp(fields[0]),
+			Replacement: replacement,
+			Message:     strings.Join(fields[2:], " "),
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read deprecations: %v", err)
+	}
+
+	return deprecations, nil
+}
+
+// applyDeprecations marks every module whose source matches a deprecation as
+// deprecated, recording its replacement, and returns a violation for each. When
+// several deprecations match a source, the first one in the file applies.
+func applyDeprecations(modules []ModuleInfo, deprecations []deprecation) []policyViolation {
+	var violations []policyViolation
+	for i := range modules {
+		mod := &modules[i]
+		for _, d := range deprecations {
+			if !d.Pattern.MatchString(mod.Source) {
+				continue
+			}
+			mod.Deprecated = true
+			mod.Replacement = d.Replacement
+			violations = append(violations, deprecatedViolation(*mod, d.Message))
+			break
+		}
+	}
+	return violations
+}
+
+// deprecatedViolation returns the violation for a module with a deprecated source,
+// explained by message when it is not empty.
+func deprecatedViolation(mod ModuleInfo, message string) policyViolation {
+	text := fmt.Sprintf("%s: module %s uses deprecated source %s", mod.Config, mod.Name, mod.Source)
+	if message != "" {
+		text += ": " + message
+	}
+	if mod.Replacement != "" {
+		text += "; migrate to " + mod.Replacement
+	}
+	return policyViolation{
+		Rule:    ruleDeprecatedModule,
+		Config:  mod.Config,
+		Module:  mod.Name,
+		Path:    mod.Path,
+		Message: text,
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestLoadDeprecations tests reading patterns, replacements, and messages, ignoring comments.
+func TestLoadDeprecations(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "deprecations.txt")
+	content := "# Archived repos\ngit::https://github.com/acme/network.git*  acme/vpc/aws  The network repo is archived.\n\nterraform-aws-modules/vpc/aws -\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write deprecations: %v", err)
+	}
+
+	deprecations, err := loadDeprecations(path)
+	if err != nil {
+		t.Fatalf("Failed to load deprecations: %v", err)
+	}
+	if len(deprecations) != 2 {
+		t.Fatalf("Expected 2 deprecations, got %d", len(deprecations))
+	}
+	if deprecations[0].Replacement != "acme/vpc/aws" || deprecations[0].Message != "The network repo is archived." {
+		t.Errorf("Unexpected first deprecation: %+v", deprecations[0])
+	}
+	if deprecations[1].Replacement != "" || deprecations[1].Message != "" {
+		t.Errorf("Expected no replacement or message for -, got %+v", deprecations[1])
+	}
+}
+
+// TestLoadDeprecationsInvalid tests that a line without a replacement is reported with its line number.
+func TestLoadDeprecationsInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "deprecations.txt")
+	if err := os.WriteFile(path, []byte("# Comment\nacme/network/aws\n"), 0644); err != nil {
+		t.Fatalf("Failed to write deprecations: %v", err)
+	}
+
+	_, err := loadDeprecations(path)
+	if err == nil || !strings.Contains(err.Error(), ":2:") {
+		t.Errorf("Expected an error for line 2, got %v", err)
+	}
+}
+
+// TestApplyDeprecations tests that deprecated sources are tagged and current ones are left alone.
+func TestApplyDeprecations(t *testing.T) {
+	modules := []ModuleInfo{
+		{Config: "/infra", Name: "network", Source: "git::https://github.com/acme/network.git?ref=v1.0.0", SourceType: sourceTypeGit},
+		{Config: "/infra", Name: "vpc", Source: "acme/vpc/aws", SourceType: sourceTypeRegistry},
+		{Config: "/infra", Name: "legacy", Source: "terraform-aws-modules/vpc/aws", SourceType: sourceTypeRegistry},
+	}
+	deprecations := []deprecation{
+		{Pattern: globRegexp("git::https://github.com/acme/network.git*"), Replacement: "acme/vpc/aws", Message: "The network repo is archived."},
+		{Pattern: globRegexp("git::*"), Replacement: "acme/other/aws"},
+		{Pattern: globRegexp("terraform-aws-modules/vpc/aws")},
+	}
+
+	violations := applyDeprecations(modules, deprecations)
+
+	if !modules[0].Deprecated || modules[0].Replacement != "acme/vpc/aws" {
+		t.Errorf("Expected network to be deprecated in favor of acme/vpc/aws, got %+v", modules[0])
+	}
+	if modules[1].Deprecated || modules[1].Replacement != "" {
+		t.Errorf("Expected vpc to be current, got %+v", modules[1])
+	}
+	if !modules[2].Deprecated || modules[2].Replacement != "" {
+		t.Errorf("Expected legacy to be deprecated without a replacement, got %+v", modules[2])
+	}
+
+	if len(violations) != 2 {
+		t.Fatalf("Expected 2 violations, got %d: %v", len(violations), violations)
+	}
+	expected := "/infra: module network uses deprecated source git::https://github.com/acme/network.git?ref=v1.0.0: The network repo is archived.; migrate to acme/vpc/aws"
+	if violations[0].Rule != ruleDeprecatedModule || violations[0].String() != expected {
+		t.Errorf("Unexpected violation: %s", violations[0])
+	}
+	if violations[1].String() != "/infra: module legacy uses deprecated source terraform-aws-modules/vpc/aws" {
+		t.Errorf("Unexpected violation: %s", violations[1])
+	}
+}
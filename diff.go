@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"io"
+)
+
+// moduleChange records a module call present in both SBOMs whose source or version changed.
+type moduleChange struct {
+	Old ModuleInfo
+	New ModuleInfo
+}
+
+// sbomDiff holds the differences in module calls between two SBOMs.
+type sbomDiff struct {
+	Added   []ModuleInfo
+	Removed []ModuleInfo
+	Changed []moduleChange
+}
+
+// Empty reports whether the two SBOMs declare the same modules.
+func (d sbomDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// diffSBOMs compares the module calls of two SBOMs, matching them by config path and name.
+func diffSBOMs(oldSBOM, newSBOM *SBOM) sbomDiff {
+	var diff sbomDiff
+
+	oldModules := make(map[moduleKey]ModuleInfo)
+	for _, mod := range oldSBOM.Modules {
+		oldModules[keyOf(mod)] = mod
+	}
+
+	newModules := make(map[moduleKey]bool)
+	for _, mod := range newSBOM.Modules {
+		newModules[keyOf(mod)] = true
+
+		old, ok := oldModules[keyOf(mod)]
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, mod)
+		case old.Source != mod.Source || old.Subdir != mod.Subdir || old.Version != mod.Version:
+			diff.Changed = append(diff.Changed, moduleChange{Old: old, New: mod})
+		}
+	}
+
+	for _, mod := range oldSBOM.Modules {
+		if !newModules[keyOf(mod)] {
+			diff.Removed = append(diff.Removed, mod)
+		}
+	}
+
+	return diff
+}
+
+// printDiff writes a human-readable summary of the differences, one module per line.
+func printDiff(w io.Writer, diff sbomDiff) {
+	for _, mod := range diff.Added {
+		fmt.Fprintf(w, "+ %s: %s %s (%s)\n", mod.Config, mod.Name, mod.Source, mod.Version)
+	}
+	for _, mod := range diff.Removed {
+		fmt.Fprintf(w, "- %s: %s %s (%s)\n", mod.Config, mod.Name, mod.Source, mod.Version)
+	}
+	for _, change := range diff.Changed {
+		fmt.Fprintf(w, "~ %s: %s", change.New.Config, change.New.Name)
+		if change.Old.Source != change.New.Source || change.Old.Subdir != change.New.Subdir {
+			fmt.Fprintf(w, " source %s -> %s", sourceWithSubdir(change.Old), sourceWithSubdir(change.New))
+		}
+		if change.Old.Version != change.New.Version {
+			fmt.Fprintf(w, " version %s -> %s", change.Old.Version, change.New.Version)
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+// sourceWithSubdir renders a module source with its subdirectory, if any.
+func sourceWithSubdir(mod ModuleInfo) string {
+	if mod.Subdir == "" {
+		return mod.Source
+	}
+	return mod.Source + "//" + mod.Subdir
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+// TestDiffSBOMs tests detection of added, removed, and changed modules.
+func TestDiffSBOMs(t *testing.T) {
+	oldSBOM := &SBOM{Modules: []ModuleInfo{
+		{Name: "vpc", Source: "terraform-aws-modules/vpc/aws", Version: "5.0.0", Config: "network"},
+		{Name: "dns", Source: "./modules/dns", Version: "local", Config: "network"},
+		{Name: "app", Source: "./modules/app", Version: "local", Config: "app"},
+	}}
+	newSBOM := &SBOM{Modules: []ModuleInfo{
+		{Name: "vpc", Source: "terraform-aws-modules/vpc/aws", Version: "5.1.0", Config: "network"},
+		{Name: "app", Source: "./modules/app", Version: "local", Config: "app"},
+		{Name: "cdn", Source: "git::https://example.com/cdn.git?ref=v1", Version: "v1", Config: "app"},
+	}}
+
+	diff := diffSBOMs(oldSBOM, newSBOM)
+	if diff.Empty() {
+		t.Fatal("Expected differences")
+	}
+
+	var buf bytes.Buffer
+	printDiff(&buf, diff)
+
+	expected := "+ app: cdn git::https://example.com/cdn.git?ref=v1 (v1)\n" +
+		"- network: dns ./modules/dns (local)\n" +
+		"~ network: vpc version 5.0.0 -> 5.1.0\n"
+	if buf.String() != expected {
+		t.Errorf("Diff output mismatch:\nexpected %q\ngot      %q", expected, buf.String())
+	}
+
+	if !diffSBOMs(newSBOM, newSBOM).Empty() {
+		t.Error("Expected no differences between identical SBOMs")
+	}
+}
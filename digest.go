@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// digestExt is appended to the output path to name the file -write-digest writes.
+const digestExt = ".sha256"
+
+// canonicalSBOM is the content of an SBOM that its digest covers: everything but the
+// generation timestamp, the serial number, which -canonical derives from the digest,
+// and the revision. Each component is kept as its JSON encoding, sorted, so the digest
+// does not depend on the order the components were found in.
+type canonicalSBOM struct {
+	Name      string            `json:"name"`
+	Namespace string            `json:"namespace"`
+	Supplier  string            `json:"supplier"`
+	Modules   []json.RawMessage `json:"modules"`
+	Providers []json.RawMessage `json:"providers"`
+	Outputs   []json.RawMessage `json:"outputs"`
+	Configs   []json.RawMessage `json:"configs"`
+	Warnings  []string          `json:"warnings"`
+}
+
+// sbomDigest returns the hex-encoded SHA-256 digest of the content of an SBOM. Two
+// scans of unchanged configs have the same digest, whatever their timestamps and
+// serial numbers, so it can tell whether anything changed without comparing files.
+func sbomDigest(sbom *SBOM) (string, error) {
+	canonical := canonicalSBOM{
+		Name:      sbom.Name,
+		Namespace: sbom.Namespace,
+		Supplier:  sbom.Supplier,
+		Warnings:  append([]string{}, sbom.Warnings...),
+	}
+	sort.Strings(canonical.Warnings)
+
+	var err error
+	if canonical.Modules, err = sortedJSON(sbom.Modules); err != nil {
+		return "", err
+	}
+	if canonical.Providers, err = sortedJSON(sbom.Providers); err != nil {
+		return "", err
+	}
+	if canonical.Outputs, err = sortedJSON(sbom.Outputs); err != nil {
+		return "", err
+	}
+	if canonical.Configs, err = sortedJSON(sbom.Configs); err != nil {
+		return "", err
+	}
+
+	data, err := json.Marshal(canonical)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode SBOM for its digest: %v", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// sortedJSON returns the JSON encoding of each element of a slice, sorted bytewise.
+func sortedJSON(elements any) ([]json.RawMessage, error) {
+	data, err := json.Marshal(elements)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode SBOM for its digest: %v", err)
+	}
+	encoded := []json.RawMessage{}
+	if err := json.Unmarshal(data, &encoded); err != nil {
+		return nil, fmt.Errorf("failed to encode SBOM for its digest: %v", err)
+	}
+	if encoded == nil {
+		// A nil slice encodes as null, and should have the digest of an empty one.
+		encoded = []json.RawMessage{}
+	}
+	sort.Slice(encoded, func(i, j int) bool {
+		return bytes.Compare(encoded[i], encoded[j]) < 0
+	})
+	return encoded, nil
+}
+
+// writeDigestFile writes the digest to path on a line of its own.
+func writeDigestFile(path string, digest string) error {
+	err := writeFileAtomic(path, func(w io.Writer) error {
+		_, err := fmt.Fprintln(w, digest)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write digest: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestSBOMDigestDeterministic tests that two scans of an unchanged config have the same
+// digest, even with different timestamps and serial numbers.
+func TestSBOMDigestDeterministic(t *testing.T) {
+	var digests []string
+	for i, timestamp := range []string{"2024-01-01T00:00:00Z", "2024-06-30T12:00:00Z"} {
+		sbom, err := generateSBOM(context.Background(), "testdata/nested", scanOptions{FlattenNested: true})
+		if err != nil {
+			t.Fatalf("Failed to generate SBOM: %v", err)
+		}
+		sbom.SerialNumber = newSerialNumber()
+		sbom.Version = i + 1
+		sbom.Timestamp = timestamp
+
+		digest, err := sbomDigest(sbom)
+		if err != nil {
+			t.Fatalf("Failed to compute digest: %v", err)
+		}
+		if len(digest) != 64 {
+			t.Errorf("Expected a hex-encoded SHA-256 digest, got %q", digest)
+		}
+		digests = append(digests, digest)
+	}
+
+	if digests[0] != digests[1] {
+		t.Errorf("Expected identical digests for unchanged configs, got %s and %s", digests[0], digests[1])
+	}
+}
+
+// TestSBOMDigestOrder tests that the digest does not depend on the order of components,
+// or on whether a list without any is empty or missing, but does change with their content.
+func TestSBOMDigestOrder(t *testing.T) {
+	sbom := mockSBOM()
+	digest, err := sbomDigest(sbom)
+	if err != nil {
+		t.Fatalf("Failed to compute digest: %v", err)
+	}
+
+	reordered := mockSBOM()
+	reordered.Modules[0], reordered.Modules[1] = reordered.Modules[1], reordered.Modules[0]
+	reordered.Providers = []ProviderInfo{}
+	if other, _ := sbomDigest(reordered); other != digest {
+		t.Errorf("Expected the digest to ignore component order, got %s and %s", digest, other)
+	}
+
+	changed := mockSBOM()
+	changed.Modules[0].Version = "v2.1.0"
+	if other, _ := sbomDigest(changed); other == digest {
+		t.Errorf("Expected the digest to change with a module version")
+	}
+}
+
+// TestWriteDigestFile tests that the digest is written on a line of its own.
+func TestWriteDigestFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sbom.json"+digestExt)
+	if err := writeDigestFile(path, "abc123"); err != nil {
+		t.Fatalf("Failed to write digest: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read digest: %v", err)
+	}
+	if string(data) != "abc123\n" {
+		t.Errorf("Expected the digest on its own line, got %q", data)
+	}
+}
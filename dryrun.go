@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+	"io"
+)
+
+// writePlan describes the file a scan would write, for reporting in dry-run mode.
+type writePlan struct {
+	Path   string // Output file
+	Format string // Output format, or the template file for template output
+	Mode   string // How the file would be written: create, overwrite, append, or update
+}
+
+// newWritePlan describes how an SBOM in the given format would be written to outputPath.
+func newWritePlan(format string, outputPath string, update bool) writePlan {
+	plan := writePlan{Path: outputPath, Format: format, Mode: "create"}
+	switch {
+	case update:
+		plan.Mode = "update"
+	case !fileExists(outputPath):
+	case format == "csv":
+		plan.Mode = "append"
+	default:
+		plan.Mode = "overwrite"
+	}
+	return plan
+}
+
+// printDryRun reports the write a scan would perform and the number of records it
+// would contain, without touching the output file.
+func printDryRun(w io.Writer, sbom *SBOM, plan writePlan) {
+	fmt.Fprintln(w, "Dry run: no files were written")
+	fmt.Fprintf(w, "Would %s %s (%s)\n", plan.Mode, plan.Path, plan.Format)
+	fmt.Fprintf(w, "  Modules: %d\n", len(sbom.Modules))
+	fmt.Fprintf(w, "  Outputs: %d\n", len(sbom.Outputs))
+	fmt.Fprintf(w, "  Configs: %d\n", len(sbom.Configs))
+	if len(sbom.Warnings) > 0 {
+		fmt.Fprintf(w, "  Warnings: %d\n", len(sbom.Warnings))
+	}
+}
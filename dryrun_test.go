@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestNewWritePlan tests describing how the output file would be written.
+func TestNewWritePlan(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "existing.csv")
+	if err := os.WriteFile(existing, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	missing := filepath.Join(dir, "missing.csv")
+
+	tests := []struct {
+		format   string
+		path     string
+		update   bool
+		expected string
+	}{
+		{"csv", missing, false, "create"},
+		{"csv", existing, false, "append"},
+		{"json", existing, false, "overwrite"},
+		{"csv", existing, true, "update"},
+	}
+	for _, tt := range tests {
+		if got := newWritePlan(tt.format, tt.path, tt.update).Mode; got != tt.expected {
+			t.Errorf("newWritePlan(%s, %s, %t) mode = %s, expected %s", tt.format, tt.path, tt.update, got, tt.expected)
+		}
+	}
+}
+
+// TestPrintDryRun tests the dry-run summary and that it leaves the output path untouched.
+func TestPrintDryRun(t *testing.T) {
+	outputPath := filepath.Join(t.TempDir(), "sbom.json")
+	sbom := mockSBOM()
+	sbom.Outputs = []OutputInfo{{Name: "vpc_id", Config: "/path/to/config"}}
+
+	var buf bytes.Buffer
+	printDryRun(&buf, sbom, newWritePlan("json", outputPath, false))
+
+	expected := "Dry run: no files were written\n" +
+		"Would create " + outputPath + " (json)\n" +
+		"  Modules: 2\n" +
+		"  Outputs: 1\n" +
+		"  Configs: 0\n"
+	if buf.String() != expected {
+		t.Errorf("Dry-run summary mismatch:\nexpected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+	if fileExists(outputPath) {
+		t.Errorf("Expected %s not to be created", outputPath)
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// experimentsSchema describes the experiments argument of the terraform block.
+var experimentsSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{Name: "experiments"},
+	},
+}
+
+// parseExperiments returns the language experiments opted into by the terraform
+// blocks in the given files, such as module_variable_optional_attrs, sorted and
+// without duplicates.
+func parseExperiments(files []*hcl.File) []string {
+	experiments := make(map[string]bool)
+	for _, file := range files {
+		content, _, _ := file.Body.PartialContent(terraformBlockSchema)
+		for _, block := range content.Blocks {
+			inner, _, _ := block.Body.PartialContent(experimentsSchema)
+			attr, ok := inner.Attributes["experiments"]
+			if !ok {
+				continue
+			}
+
+			exprs, diags := hcl.ExprList(attr.Expr)
+			if diags.HasErrors() {
+				continue
+			}
+			for _, expr := range exprs {
+				if name := hcl.ExprAsKeyword(expr); name != "" {
+					experiments[name] = true
+				}
+			}
+		}
+	}
+	return sortedSet(experiments)
+}
+
+// providerFunctionCalls returns the provider-defined functions called anywhere in
+// the given files, such as provider::aws::arn_parse, sorted and without duplicates.
+// Only native syntax files are inspected, since function calls in .tf.json files are
+// embedded in template strings.
+func providerFunctionCalls(files []*hcl.File) []string {
+	functions := make(map[string]bool)
+	for _, file := range files {
+		body, ok := file.Body.(*hclsyntax.Body)
+		if !ok {
+			continue
+		}
+		hclsyntax.VisitAll(body, func(node hclsyntax.Node) hcl.Diagnostics {
+			if call, ok := node.(*hclsyntax.FunctionCallExpr); ok && strings.HasPrefix(call.Name, "provider::") {
+				functions[call.Name] = true
+			}
+			return nil
+		})
+	}
+	return sortedSet(functions)
+}
+
+// sortedSet returns the members of a set in order, or nil if it is empty.
+func sortedSet(set map[string]bool) []string {
+	if len(set) == 0 {
+		return nil
+	}
+	members := make([]string, 0, len(set))
+	for member := range set {
+		members = append(members, member)
+	}
+	sort.Strings(members)
+	return members
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+// TestGenerateSBOMExperiments tests recording the experiments and provider-defined
+// functions of a config from HCL and JSON files.
+func TestGenerateSBOMExperiments(t *testing.T) {
+	sbom, err := generateSBOM(context.Background(), "testdata/experiments", scanOptions{IncludeLifecycle: true})
+	if err != nil {
+		t.Fatalf("Failed to generate SBOM: %v", err)
+	}
+	if len(sbom.Configs) != 1 {
+		t.Fatalf("Expected 1 config, got %d", len(sbom.Configs))
+	}
+
+	expectedExperiments := []string{"module_variable_optional_attrs", "unknown_instances"}
+	if !reflect.DeepEqual(sbom.Configs[0].Experiments, expectedExperiments) {
+		t.Errorf("Experiments mismatch: expected %v, got %v", expectedExperiments, sbom.Configs[0].Experiments)
+	}
+
+	expectedFunctions := []string{"provider::aws::arn_parse", "provider::time::rfc3339_parse"}
+	if !reflect.DeepEqual(sbom.Configs[0].ProviderFunctions, expectedFunctions) {
+		t.Errorf("Provider functions mismatch: expected %v, got %v", expectedFunctions, sbom.Configs[0].ProviderFunctions)
+	}
+
+	sbom, err = generateSBOM(context.Background(), "testdata/experiments", scanOptions{})
+	if err != nil {
+		t.Fatalf("Failed to generate SBOM: %v", err)
+	}
+	if len(sbom.Configs) != 0 {
+		t.Errorf("Expected no configs without IncludeLifecycle, got %v", sbom.Configs)
+	}
+}
+
+// TestGenerateSBOMWithoutExperiments tests that configs without experiments record none.
+func TestGenerateSBOMWithoutExperiments(t *testing.T) {
+	sbom, err := generateSBOM(context.Background(), "testdata/lifecycle", scanOptions{IncludeLifecycle: true})
+	if err != nil {
+		t.Fatalf("Failed to generate SBOM: %v", err)
+	}
+	if sbom.Configs[0].Experiments != nil || sbom.Configs[0].ProviderFunctions != nil {
+		t.Errorf("Expected no experiments or provider functions, got %v and %v", sbom.Configs[0].Experiments, sbom.Configs[0].ProviderFunctions)
+	}
+}
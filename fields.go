@@ -0,0 +1,172 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// moduleField describes one module column that can be selected with -fields.
+type moduleField struct {
+	name   string                  // Name used by -fields and as the JSON key
+	header string                  // CSV column header
+	csv    func(ModuleInfo) string // Value written to CSV
+	json   func(ModuleInfo) any    // Value written to JSON
+}
+
+// moduleFields lists every module field in the default CSV column order. The first
+// four columns are the ones every version has written, so that positional consumers
+// keep working; columns added since follow them.
+var moduleFields = []moduleField{
+	{"config", "Config Path", func(m ModuleInfo) string { return m.Config }, func(m ModuleInfo) any { return m.Config }},
+	{"name", "Module Name", func(m ModuleInfo) string { return m.Name }, func(m ModuleInfo) any { return m.Name }},
+	{"source", "Source", func(m ModuleInfo) string { return m.Source }, func(m ModuleInfo) any { return m.Source }},
+	{"version", "Version", func(m ModuleInfo) string { return m.Version }, func(m ModuleInfo) any { return m.Version }},
+	{"path", "Call Path", func(m ModuleInfo) string { return m.Path }, func(m ModuleInfo) any { return m.Path }},
+	{"subdir", "Subdir", func(m ModuleInfo) string { return m.Subdir }, func(m ModuleInfo) any { return m.Subdir }},
+	{"source_type", "Source Type", func(m ModuleInfo) string { return m.SourceType }, func(m ModuleInfo) any { return m.SourceType }},
+	{"version_source", "Version Source", func(m ModuleInfo) string { return m.VersionSource }, func(m ModuleInfo) any { return m.VersionSource }},
+	{"original_version", "Original Version", func(m ModuleInfo) string { return m.OriginalVersion }, func(m ModuleInfo) any { return m.OriginalVersion }},
+	{"normalized_version", "Normalized Version", func(m ModuleInfo) string { return m.NormalizedVersion }, func(m ModuleInfo) any { return m.NormalizedVersion }},
+	{"purl", "PURL", func(m ModuleInfo) string { return m.PURL }, func(m ModuleInfo) any { return m.PURL }},
+	{"registry", "Registry", func(m ModuleInfo) string { return m.Registry }, func(m ModuleInfo) any { return m.Registry }},
+	{"private", "Private", csvPrivate, func(m ModuleInfo) any { return m.Private }},
+	{"insecure", "Insecure", func(m ModuleInfo) string { return strconv.FormatBool(m.Insecure) }, func(m ModuleInfo) any { return m.Insecure }},
+	{"organization", "Organization", func(m ModuleInfo) string { return m.Organization }, func(m ModuleInfo) any { return m.Organization }},
+	{"provider_mappings", "Providers", func(m ModuleInfo) string { return m.ProviderMappings.String() }, func(m ModuleInfo) any { return m.ProviderMappings }},
+	{"owners", "Owners", func(m ModuleInfo) string { return strings.Join(m.Owners, ";") }, func(m ModuleInfo) any { return m.Owners }},
+	{"description", "Description", func(m ModuleInfo) string { return m.Description }, func(m ModuleInfo) any { return m.Description }},
+	{"has_readme", "Has README", func(m ModuleInfo) string { return optionalBool(m.HasReadme) }, func(m ModuleInfo) any { return m.HasReadme }},
+	{"approved", "Approved", func(m ModuleInfo) string { return optionalBool(m.Approved) }, func(m ModuleInfo) any { return m.Approved }},
+	{"reachable", "Reachable", func(m ModuleInfo) string { return optionalBool(m.Reachable) }, func(m ModuleInfo) any { return m.Reachable }},
+	{"reachability_error", "Reachability Error", func(m ModuleInfo) string { return m.ReachabilityError }, func(m ModuleInfo) any { return m.ReachabilityError }},
+	{"age_days", "Age Days", func(m ModuleInfo) string { return optionalInt(m.AgeDays) }, func(m ModuleInfo) any { return m.AgeDays }},
+	{"local_version", "Local Version", func(m ModuleInfo) string { return m.LocalVersion }, func(m ModuleInfo) any { return m.LocalVersion }},
+	{"required_version", "Required Version", func(m ModuleInfo) string { return m.RequiredVersion }, func(m ModuleInfo) any { return m.RequiredVersion }},
+	{"change", "Change", func(m ModuleInfo) string { return m.Change }, func(m ModuleInfo) any { return m.Change }},
+	{"previous_version", "Previous Version", func(m ModuleInfo) string { return m.PreviousVersion }, func(m ModuleInfo) any { return m.PreviousVersion }},
+	{"depends_on", "Depends On", func(m ModuleInfo) string { return strings.Join(m.DependsOn, ";") }, func(m ModuleInfo) any { return m.DependsOn }},
+	{"address", "Address", func(m ModuleInfo) string { return m.Address }, func(m ModuleInfo) any { return m.Address }},
+	{"deprecated", "Deprecated", func(m ModuleInfo) string { return strconv.FormatBool(m.Deprecated) }, func(m ModuleInfo) any { return m.Deprecated }},
+	{"replacement", "Replacement", func(m ModuleInfo) string { return m.Replacement }, func(m ModuleInfo) any { return m.Replacement }},
+	{"normalized_source", "Normalized Source", func(m ModuleInfo) string { return m.NormalizedSource }, func(m ModuleInfo) any { return m.NormalizedSource }},
+}
+
+// csvPrivate formats whether a module comes from a private registry, leaving the
+// column empty for modules that do not come from a registry.
+func csvPrivate(mod ModuleInfo) string {
+	if mod.Registry == "" {
+		return ""
+	}
+	return strconv.FormatBool(mod.Private)
+}
+
+// fieldNames returns the names of every selectable module field.
+func fieldNames() []string {
+	names := make([]string, len(moduleFields))
+	for i, field := range moduleFields {
+		names[i] = field.name
+	}
+	return names
+}
+
+// parseFields resolves a comma-separated list of field names, keeping the given order.
+func parseFields(spec string) ([]moduleField, error) {
+	byName := make(map[string]moduleField, len(moduleFields))
+	for _, field := range moduleFields {
+		byName[field.name] = field
+	}
+
+	var fields []moduleField
+	for _, name := range strings.Split(spec, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		field, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown field %q. Valid fields are: %s", name, strings.Join(fieldNames(), ", "))
+		}
+		fields = append(fields, field)
+	}
+
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("no fields given. Valid fields are: %s", strings.Join(fieldNames(), ", "))
+	}
+	return fields, nil
+}
+
+// fieldHeaders returns the CSV header for the given fields.
+func fieldHeaders(fields []moduleField) []string {
+	headers := make([]string, len(fields))
+	for i, field := range fields {
+		headers[i] = field.header
+	}
+	return headers
+}
+
+// fieldRecord returns the CSV record of a module restricted to the given fields.
+func fieldRecord(fields []moduleField, mod ModuleInfo) []string {
+	record := make([]string, len(fields))
+	for i, field := range fields {
+		record[i] = field.csv(mod)
+	}
+	return record
+}
+
+// projectedModule encodes a module as a JSON object holding only the selected fields, in order.
+type projectedModule struct {
+	mod    ModuleInfo
+	fields []moduleField
+}
+
+// MarshalJSON writes the selected fields of the module.
+func (p projectedModule) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, field := range p.fields {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(field.name)
+		value, err := json.Marshal(field.json(p.mod))
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// projectedSBOM is an SBOM whose modules are restricted to the selected fields.
+// The Modules field shadows the one of the embedded SBOM when encoding.
+type projectedSBOM struct {
+	*SBOM
+	Modules []projectedModule `json:"modules"`
+}
+
+// projectSBOM restricts the modules of an SBOM to the given fields for JSON output.
+func projectSBOM(sbom *SBOM, fields []moduleField) *projectedSBOM {
+	projected := &projectedSBOM{SBOM: sbom, Modules: []projectedModule{}}
+	for _, mod := range sbom.Modules {
+		projected.Modules = append(projected.Modules, projectedModule{mod: mod, fields: fields})
+	}
+	return projected
+}
+
+// writeSBOMWithFields writes the SBOM in the given output format with only the
+// selected module fields. Only CSV and JSON output support field selection.
+func writeSBOMWithFields(sbom *SBOM, format string, outputPath string, fields []moduleField) error {
+	switch strings.ToLower(format) {
+	case "csv":
+		return writeCSV(sbom, outputPath, fields, nil)
+	case "json":
+		return writeJSON(projectSBOM(sbom, fields), outputPath)
+	}
+	return fmt.Errorf("field selection is not supported for %s output", format)
+}
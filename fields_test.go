@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestParseFields tests resolving field names in order and rejecting unknown names.
+func TestParseFields(t *testing.T) {
+	fields, err := parseFields("name, Source,version")
+	if err != nil {
+		t.Fatalf("Failed to parse fields: %v", err)
+	}
+	if got := fieldHeaders(fields); !reflect.DeepEqual(got, []string{"Module Name", "Source", "Version"}) {
+		t.Errorf("Unexpected headers: %v", got)
+	}
+
+	_, err = parseFields("name,pinning")
+	if err == nil || !strings.Contains(err.Error(), `unknown field "pinning"`) || !strings.Contains(err.Error(), "source_type") {
+		t.Errorf("Expected an unknown field error listing the valid fields, got %v", err)
+	}
+
+	if _, err := parseFields(" , "); err == nil {
+		t.Errorf("Expected an error for an empty field list")
+	}
+}
+
+// TestWriteSBOMWithFieldsCSV tests that CSV output contains only the selected columns in order.
+func TestWriteSBOMWithFieldsCSV(t *testing.T) {
+	fields, err := parseFields("version,name")
+	if err != nil {
+		t.Fatalf("Failed to parse fields: %v", err)
+	}
+
+	outputPath := filepath.Join(t.TempDir(), "sbom.csv")
+	if err := writeSBOMWithFields(mockSBOM(), "csv", outputPath, fields); err != nil {
+		t.Fatalf("Failed to write SBOM to CSV: %v", err)
+	}
+
+	file, err := os.Open(outputPath)
+	if err != nil {
+		t.Fatalf("Failed to open CSV file: %v", err)
+	}
+	defer file.Close()
+
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to read CSV records: %v", err)
+	}
+
+	expected := [][]string{
+		{"Version", "Module Name"},
+		{"v2.0.0", "aws_vpc"},
+		{"N/A", "s3_bucket"},
+	}
+	if !reflect.DeepEqual(records, expected) {
+		t.Errorf("CSV mismatch: expected %v, got %v", expected, records)
+	}
+}
+
+// TestWriteSBOMWithFieldsJSON tests that JSON modules contain only the selected keys in order.
+func TestWriteSBOMWithFieldsJSON(t *testing.T) {
+	fields, err := parseFields("name,provider_mappings")
+	if err != nil {
+		t.Fatalf("Failed to parse fields: %v", err)
+	}
+
+	sbom := mockSBOM()
+	sbom.Modules = sbom.Modules[:1]
+
+	outputPath := filepath.Join(t.TempDir(), "sbom.json")
+	if err := writeSBOMWithFields(sbom, "json", outputPath, fields); err != nil {
+		t.Fatalf("Failed to write SBOM to JSON: %v", err)
+	}
+
+	content, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("Failed to read JSON file: %v", err)
+	}
+
+	expected := `{
+  "modules": [
+    {
+      "name": "aws_vpc",
+      "provider_mappings": {
+        "aws": "aws.useast1"
+      }
+    }
+  ]
+}
+`
+	if string(content) != expected {
+		t.Errorf("JSON mismatch:\nexpected:\n%s\ngot:\n%s", expected, content)
+	}
+
+	if err := writeSBOMWithFields(sbom, "xml", outputPath, fields); err == nil {
+		t.Errorf("Expected an error for XML output")
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-config-inspect/tfconfig"
+)
+
+// byteSizeFlag is a flag holding a number of bytes, given either as a plain number
+// or with a KB, MB, or GB suffix in powers of 1024, such as 50MB.
+type byteSizeFlag int64
+
+// byteSizeUnits lists the accepted suffixes from the longest to the shortest, so
+// that KB is not read as a number ending in B.
+var byteSizeUnits = []struct {
+	suffix string
+	size   int64
+}{
+	{"KB", 1 << 10},
+	{"MB", 1 << 20},
+	{"GB", 1 << 30},
+	{"B", 1},
+}
+
+func (f *byteSizeFlag) String() string {
+	return strconv.FormatInt(int64(*f), 10)
+}
+
+func (f *byteSizeFlag) Set(value string) error {
+	size, err := parseByteSize(value)
+	if err != nil {
+		return err
+	}
+	*f = byteSizeFlag(size)
+	return nil
+}
+
+// parseByteSize parses a number of bytes with an optional KB, MB, or GB suffix.
+func parseByteSize(value string) (int64, error) {
+	number, unit := strings.ToUpper(strings.TrimSpace(value)), int64(1)
+	for _, u := range byteSizeUnits {
+		if trimmed, ok := strings.CutSuffix(number, u.suffix); ok {
+			number, unit = strings.TrimSpace(trimmed), u.size
+			break
+		}
+	}
+
+	size, err := strconv.ParseInt(number, 10, 64)
+	if err != nil || size < 0 {
+		return 0, fmt.Errorf("invalid size %q: expected a number of bytes, optionally followed by KB, MB, or GB", value)
+	}
+	return size * unit, nil
+}
+
+// isOversized reports whether a Terraform or OpenTofu configuration file is larger
+// than maxFileSize. A maxFileSize of zero means there is no limit.
+func isOversized(info os.FileInfo, maxFileSize int64) bool {
+	if maxFileSize <= 0 || info.IsDir() {
+		return false
+	}
+	return isConfigFile(info.Name()) && info.Size() > maxFileSize
+}
+
+// oversizedFiles returns a warning for each Terraform configuration file in a
+// directory that is larger than maxFileSize, and so is not loaded.
+func oversizedFiles(dir string, maxFileSize int64) []string {
+	if maxFileSize <= 0 {
+		return nil
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	var warnings []string
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || !isOversized(info, maxFileSize) {
+			continue
+		}
+		warnings = append(warnings, fmt.Sprintf("%s: skipped because it is %d bytes, larger than the maximum file size of %d bytes",
+			filepath.Join(dir, entry.Name()), info.Size(), maxFileSize))
+	}
+	return warnings
+}
+
+// sizeLimitFS is the local filesystem as seen by tfconfig, without the Terraform
+// configuration files that are larger than maxFileSize. Hiding them from directory
+// listings keeps tfconfig from ever reading them into memory.
+type sizeLimitFS struct {
+	tfconfig.FS
+	maxFileSize int64
+}
+
+func (fs sizeLimitFS) ReadDir(dirname string) ([]os.FileInfo, error) {
+	infos, err := fs.FS.ReadDir(dirname)
+	if err != nil {
+		return nil, err
+	}
+
+	kept := infos[:0]
+	for _, info := range infos {
+		if !isOversized(info, fs.maxFileSize) {
+			kept = append(kept, info)
+		}
+	}
+	return kept, nil
+}
+
+// loadModule loads the Terraform module in a directory like tfconfig.LoadModule,
+// including its OpenTofu configuration files, and skipping the configuration files
+// larger than maxFileSize. A maxFileSize of zero loads every file.
+func loadModule(dir string, maxFileSize int64) (*tfconfig.Module, tfconfig.Diagnostics) {
+	fs := tfconfig.NewOsFs()
+	if maxFileSize > 0 {
+		fs = sizeLimitFS{FS: fs, maxFileSize: maxFileSize}
+	}
+
+	module, diags := tfconfig.LoadModuleFromFilesystem(tofuFS{fs}, dir)
+	if module != nil {
+		restoreTofuFilenames(module)
+	}
+	return module, diags
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// TestGenerateSBOMMaxFileSize tests that a configuration file over the size limit is
+// skipped with a warning while its smaller sibling is still scanned.
+func TestGenerateSBOMMaxFileSize(t *testing.T) {
+	sbom, err := generateSBOM(context.Background(), "testdata/large-files", scanOptions{MaxFileSize: 1024})
+	if err != nil {
+		t.Fatalf("Failed to generate SBOM: %v", err)
+	}
+
+	if len(sbom.Modules) != 1 || sbom.Modules[0].Name != "vpc" {
+		t.Errorf("Expected only the vpc module from main.tf, got %+v", sbom.Modules)
+	}
+	if len(sbom.Warnings) != 1 || !strings.HasPrefix(sbom.Warnings[0], "testdata/large-files/generated.tf: skipped because it is ") {
+		t.Errorf("Expected a warning for generated.tf, got %v", sbom.Warnings)
+	}
+
+	sbom, err = generateSBOM(context.Background(), "testdata/large-files", scanOptions{})
+	if err != nil {
+		t.Fatalf("Failed to generate SBOM: %v", err)
+	}
+	if len(sbom.Modules) != 2 || len(sbom.Warnings) != 0 {
+		t.Errorf("Expected both modules and no warnings without a limit, got %+v and %v", sbom.Modules, sbom.Warnings)
+	}
+}
+
+// TestParseByteSize tests parsing sizes with and without a unit suffix.
+func TestParseByteSize(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected int64
+	}{
+		{"0", 0},
+		{"512", 512},
+		{"512B", 512},
+		{"4KB", 4 << 10},
+		{"50MB", 50 << 20},
+		{"1gb", 1 << 30},
+		{" 2 MB ", 2 << 20},
+	}
+	for _, tt := range tests {
+		size, err := parseByteSize(tt.value)
+		if err != nil {
+			t.Errorf("parseByteSize(%q) failed: %v", tt.value, err)
+			continue
+		}
+		if size != tt.expected {
+			t.Errorf("parseByteSize(%q) = %d, expected %d", tt.value, size, tt.expected)
+		}
+	}
+
+	for _, value := range []string{"", "MB", "-1", "1.5MB", "10TB"} {
+		if _, err := parseByteSize(value); err == nil {
+			t.Errorf("Expected an error parsing %q", value)
+		}
+	}
+}
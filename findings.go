@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Rules reported only as findings, without a flag that fails the scan on them.
+const ruleUnofficialProvider = "unofficial-provider"
+
+// severity is how serious a finding is, using the levels of SARIF results.
+type severity string
+
+const (
+	severityError   severity = "error"
+	severityWarning severity = "warning"
+	severityNote    severity = "note"
+)
+
+// findingRule describes a rule that findings are reported against.
+type findingRule struct {
+	ID          string
+	Name        string
+	Description string
+	Severity    severity
+}
+
+// findingRules lists every rule in the order they are reported.
+var findingRules = []findingRule{
+	{ruleEmbeddedCredentials, "EmbeddedCredentials", "A module or provider source has credentials embedded in it.", severityError},
+	{ruleInsecureSource, "InsecureSource", "A module is fetched over an unencrypted transport.", severityError},
+	{ruleUnapprovedSource, "UnapprovedSource", "A module source is not approved by the source policy.", severityError},
+	{ruleConflictingProvider, "ConflictingProviderSource", "A provider local name refers to different provider sources across configs.", severityError},
+	{ruleUnpinnedModule, "UnpinnedModule", "A module is not pinned to a version, so each init may fetch different code.", severityWarning},
+	{ruleDeprecatedModule, "DeprecatedModule", "A module source is deprecated and should be migrated to its replacement.", severityWarning},
+	{ruleInconsistentPin, "InconsistentPin", "A module source is pinned to different versions across configs.", severityWarning},
+	{ruleNonSemverPin, "NonSemverPin", "A module is pinned to a version that is not vMAJOR.MINOR.PATCH.", severityNote},
+	{ruleUnofficialProvider, "UnofficialProvider", "A provider is not published by HashiCorp in the public registry.", severityNote},
+}
+
+// Severity returns the severity of the rule the finding breaks.
+func (v policyViolation) Severity() severity {
+	for _, rule := range findingRules {
+		if rule.ID == v.Rule {
+			return rule.Severity
+		}
+	}
+	return severityWarning
+}
+
+// unpinnedViolations returns a finding for each remote module that is not pinned to a version.
+func unpinnedViolations(modules []ModuleInfo) []policyViolation {
+	var violations []policyViolation
+	for _, mod := range modules {
+		if mod.SourceType == sourceTypeLocal || !isUnpinned(mod) {
+			continue
+		}
+		violations = append(violations, policyViolation{
+			Rule:    ruleUnpinnedModule,
+			Config:  mod.Config,
+			Module:  mod.Name,
+			Path:    mod.Path,
+			Message: fmt.Sprintf("%s: module %s is not pinned to a version of %s", mod.Config, mod.Name, mod.Source),
+		})
+	}
+	return violations
+}
+
+// unofficialProviderViolations returns a finding for each provider that does not come
+// from the hashicorp namespace of the public registry. Built-in providers are exempt.
+func unofficialProviderViolations(providers []ProviderInfo) []policyViolation {
+	official := defaultRegistryHost + "/" + defaultProviderNamespace + "/"
+	var violations []policyViolation
+	for _, provider := range providers {
+		address := providerAddress(provider.Name, provider.Source)
+		if strings.HasPrefix(address, official) || strings.HasPrefix(address, "terraform.io/builtin/") {
+			continue
+		}
+		violations = append(violations, policyViolation{
+			Rule:    ruleUnofficialProvider,
+			Config:  provider.Config,
+			Module:  provider.Name,
+			Message: fmt.Sprintf("%s: provider %s comes from %s rather than the official hashicorp namespace", provider.Config, provider.Name, address),
+		})
+	}
+	return violations
+}
+
+// sbomFindings runs every check that can be made from the SBOM alone, regardless of
+// the flags that fail a scan. Unapproved sources are only found once a source policy
+// has set the Approved flag of the modules, and deprecated ones once deprecations
+// have been applied. Findings that an sbom:ignore directive
+// exempts a module from are left out.
+func sbomFindings(sbom *SBOM) []policyViolation {
+	var findings []policyViolation
+	findings = append(findings, secretViolations(sbom)...)
+	findings = append(findings, insecureViolations(sbom.Modules)...)
+	for _, mod := range sbom.Modules {
+		if mod.Approved != nil && !*mod.Approved {
+			findings = append(findings, unapprovedViolation(mod))
+		}
+	}
+	for _, mod := range sbom.Modules {
+		if mod.Deprecated {
+			findings = append(findings, deprecatedViolation(mod, ""))
+		}
+	}
+	findings = append(findings, unpinnedViolations(sbom.Modules)...)
+	findings = append(findings, pinningWarnings(sbom.Modules)...)
+	findings = append(findings, providerConflicts(sbom.Providers)...)
+	findings = append(findings, strictSemverViolations(sbom.Modules)...)
+	findings = append(findings, unofficialProviderViolations(sbom.Providers)...)
+	return filterIgnored(findings, sbom.Modules)
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestUnpinnedViolations tests that only remote modules without a version are reported.
+func TestUnpinnedViolations(t *testing.T) {
+	modules := []ModuleInfo{
+		{Name: "network", Source: "github.com/acme/network", SourceType: sourceTypeGitHub, Version: "N/A", Config: "app"},
+		{Name: "vpc", Source: "terraform-aws-modules/vpc/aws", SourceType: sourceTypeRegistry, Version: "5.1.0", Config: "app"},
+		{Name: "app", Source: "./modules/app", SourceType: sourceTypeLocal, Version: "local", Config: "app"},
+	}
+
+	violations := unpinnedViolations(modules)
+	if len(violations) != 1 || violations[0].Module != "network" || violations[0].Rule != ruleUnpinnedModule {
+		t.Fatalf("Expected an unpinned-module finding for network, got %+v", violations)
+	}
+}
+
+// TestUnofficialProviderViolations tests that providers outside the hashicorp
+// namespace of the public registry are reported.
+func TestUnofficialProviderViolations(t *testing.T) {
+	providers := []ProviderInfo{
+		{Name: "aws", Source: "hashicorp/aws", Config: "app"},
+		{Name: "null", Config: "app"},
+		{Name: "terraform", Source: "terraform.io/builtin/terraform", Config: "app"},
+		{Name: "datadog", Source: "DataDog/datadog", Config: "app"},
+		{Name: "internal", Source: "tf.example.com/hashicorp/internal", Config: "app"},
+	}
+
+	var names []string
+	for _, violation := range unofficialProviderViolations(providers) {
+		names = append(names, violation.Module)
+	}
+	if expected := []string{"datadog", "internal"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("Expected findings for %v, got %v", expected, names)
+	}
+}
+
+// TestSBOMFindingsSeverity tests that every check runs on the SBOM and each finding
+// takes the severity of its rule.
+func TestSBOMFindingsSeverity(t *testing.T) {
+	denied := false
+	sbom := &SBOM{Modules: []ModuleInfo{
+		{Name: "secret", Source: "git::https://***@github.com/acme/secret.git?ref=v1.0.0", SourceType: sourceTypeGit, Version: "v1.0.0", Config: "app"},
+		{Name: "denied", Source: "github.com/evil/denied?ref=v1.0.0", SourceType: sourceTypeGitHub, Version: "v1.0.0", Config: "app", Approved: &denied},
+		{Name: "dns", Source: "github.com/acme/dns?ref=2.0", SourceType: sourceTypeGitHub, Version: "2.0", Config: "app"},
+	}}
+
+	severities := make(map[string]severity)
+	for _, finding := range sbomFindings(sbom) {
+		severities[finding.Rule] = finding.Severity()
+	}
+	expected := map[string]severity{
+		ruleEmbeddedCredentials: severityError,
+		ruleUnapprovedSource:    severityError,
+		ruleNonSemverPin:        severityNote,
+	}
+	if !reflect.DeepEqual(severities, expected) {
+		t.Errorf("Expected severities %v, got %v", expected, severities)
+	}
+}
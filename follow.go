@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// localModuleMetadata is what a local module declares about itself.
+type localModuleMetadata struct {
+	version         string
+	requiredVersion string
+	err             error
+}
+
+// followLocalSources records the metadata each local module declares about itself:
+// the version in its VERSION file, and the Terraform versions its required_version
+// settings accept. The module directory is resolved against the file declaring the
+// call, so modules found with -flatten-nested are followed from their caller. Each
+// directory is loaded once, and a module whose source is the directory of its own
+// caller, which Terraform would expand forever, is reported as a warning instead of
+// being followed. Files larger than maxFileSize are not loaded.
+func followLocalSources(modules []ModuleInfo, maxFileSize int64) []string {
+	loaded := make(map[string]localModuleMetadata)
+
+	var warnings []string
+	for i := range modules {
+		mod := &modules[i]
+		if mod.SourceType != sourceTypeLocal {
+			continue
+		}
+
+		callerDir := mod.Config
+		if mod.File != "" {
+			callerDir = filepath.Dir(mod.File)
+		}
+		dir := filepath.Clean(filepath.Join(callerDir, mod.Source, mod.Subdir))
+		if dir == filepath.Clean(callerDir) {
+			warnings = append(warnings, fmt.Sprintf("%s: module %s sources its own directory %s; not following it", mod.Config, mod.Name, mod.Source))
+			continue
+		}
+
+		metadata, ok := loaded[dir]
+		if !ok {
+			metadata = readLocalModuleMetadata(dir, maxFileSize)
+			loaded[dir] = metadata
+		}
+		if metadata.err != nil {
+			warnings = append(warnings, fmt.Sprintf("%s: failed to follow module %s: %v", mod.Config, mod.Name, metadata.err))
+			continue
+		}
+		mod.LocalVersion = metadata.version
+		mod.RequiredVersion = metadata.requiredVersion
+	}
+	return warnings
+}
+
+// readLocalModuleMetadata loads the local module in dir.
+func readLocalModuleMetadata(dir string, maxFileSize int64) localModuleMetadata {
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return localModuleMetadata{err: fmt.Errorf("%s is not a directory", dir)}
+	}
+
+	module, diag := loadModule(dir, maxFileSize)
+	if module == nil {
+		return localModuleMetadata{err: fmt.Errorf("failed to load %s: %v", dir, diag.Err())}
+	}
+
+	version, err := readVersionFile(filepath.Join(dir, "VERSION"))
+	if err != nil {
+		return localModuleMetadata{err: err}
+	}
+	return localModuleMetadata{
+		version:         version,
+		requiredVersion: strings.Join(module.RequiredCore, ", "),
+	}
+}
+
+// readVersionFile returns the first non-empty line of a version file, such as VERSION
+// or .terraform-version, or an empty string if there is no such file.
+func readVersionFile(path string) (string, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %v", path, err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			return line, nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("failed to read %s: %v", path, err)
+	}
+	return "", nil
+}
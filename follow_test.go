@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+// TestFollowLocalSources tests recording the VERSION file and required_version of
+// local modules, including nested ones, while a module sourcing its own directory is
+// reported instead of being followed.
+func TestFollowLocalSources(t *testing.T) {
+	sbom, err := generateSBOM(context.Background(), "testdata/follow-local", scanOptions{FollowLocalSources: true, FlattenNested: true})
+	if err != nil {
+		t.Fatalf("Failed to generate SBOM: %v", err)
+	}
+
+	type metadata struct{ LocalVersion, RequiredVersion string }
+	result := make(map[string]metadata)
+	for _, mod := range sbom.Modules {
+		result[mod.Path] = metadata{mod.LocalVersion, mod.RequiredVersion}
+	}
+	expected := map[string]metadata{
+		"root > network":          {"1.4.0", ">= 1.5"},
+		"root > network > subnet": {"", ">= 1.3, < 2.0"},
+		"root > self":             {},
+		"root > vpc":              {},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Local module metadata mismatch:\nexpected %v\ngot      %v", expected, result)
+	}
+
+	expectedWarning := "testdata/follow-local: module self sources its own directory ./; not following it"
+	found := false
+	for _, warning := range sbom.Warnings {
+		found = found || warning == expectedWarning
+	}
+	if !found {
+		t.Errorf("Expected warning %q, got %v", expectedWarning, sbom.Warnings)
+	}
+}
+
+// TestFollowLocalSourcesDisabled tests that local modules are not loaded without the option.
+func TestFollowLocalSourcesDisabled(t *testing.T) {
+	sbom, err := generateSBOM(context.Background(), "testdata/follow-local", scanOptions{})
+	if err != nil {
+		t.Fatalf("Failed to generate SBOM: %v", err)
+	}
+	for _, mod := range sbom.Modules {
+		if mod.LocalVersion != "" || mod.RequiredVersion != "" {
+			t.Errorf("Expected no local module metadata for %s, got %q and %q", mod.Name, mod.LocalVersion, mod.RequiredVersion)
+		}
+	}
+}
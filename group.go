@@ -0,0 +1,65 @@
+package main
+
+// configGroup holds the components of a single config in grouped JSON output.
+type configGroup struct {
+	Modules   []ModuleInfo   `json:"modules"`
+	Providers []ProviderInfo `json:"providers,omitempty"`
+	Outputs   []OutputInfo   `json:"outputs,omitempty"`
+	Details   *ConfigInfo    `json:"details,omitempty"` // Per-config details such as the line count or backend, when collected
+}
+
+// groupedSBOM is an SBOM whose components are nested under their config path instead
+// of listed flat, for per-stack views. The document metadata is kept as is.
+type groupedSBOM struct {
+	SerialNumber string                  `json:"serial_number,omitempty"`
+	Version      int                     `json:"version,omitempty"`
+	Name         string                  `json:"name,omitempty"`
+	Namespace    string                  `json:"namespace,omitempty"`
+	Supplier     string                  `json:"supplier,omitempty"`
+	Timestamp    string                  `json:"timestamp,omitempty"`
+	Configs      map[string]*configGroup `json:"configs"`
+	Warnings     []string                `json:"warnings,omitempty"`
+}
+
+// groupSBOMByConfig nests the modules, providers, outputs, and per-config details of
+// an SBOM under the path of the config they belong to. Every config has a module
+// list, even if it only declares providers.
+func groupSBOMByConfig(sbom *SBOM) *groupedSBOM {
+	grouped := &groupedSBOM{
+		SerialNumber: sbom.SerialNumber,
+		Version:      sbom.Version,
+		Name:         sbom.Name,
+		Namespace:    sbom.Namespace,
+		Supplier:     sbom.Supplier,
+		Timestamp:    sbom.Timestamp,
+		Configs:      make(map[string]*configGroup),
+		Warnings:     sbom.Warnings,
+	}
+
+	group := func(config string) *configGroup {
+		g, ok := grouped.Configs[config]
+		if !ok {
+			g = &configGroup{Modules: []ModuleInfo{}}
+			grouped.Configs[config] = g
+		}
+		return g
+	}
+
+	for _, mod := range sbom.Modules {
+		g := group(mod.Config)
+		g.Modules = append(g.Modules, mod)
+	}
+	for _, provider := range sbom.Providers {
+		g := group(provider.Config)
+		g.Providers = append(g.Providers, provider)
+	}
+	for _, output := range sbom.Outputs {
+		g := group(output.Config)
+		g.Outputs = append(g.Outputs, output)
+	}
+	for i := range sbom.Configs {
+		group(sbom.Configs[i].Path).Details = &sbom.Configs[i]
+	}
+
+	return grouped
+}
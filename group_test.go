@@ -0,0 +1,80 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestGroupSBOMByConfig tests nesting the components of a multi-config SBOM under their config paths.
+func TestGroupSBOMByConfig(t *testing.T) {
+	sbom := &SBOM{
+		Name: "estate",
+		Modules: []ModuleInfo{
+			{Name: "labels", Source: "./modules/labels", SourceType: sourceTypeLocal, Version: "local", Config: "app"},
+			{Name: "vpc", Source: "terraform-aws-modules/vpc/aws", SourceType: sourceTypeRegistry, Version: "5.1.0", Config: "network"},
+			{Name: "dns", Source: "acme/dns/aws", SourceType: sourceTypeRegistry, Version: "1.2.0", Config: "network"},
+		},
+		Providers: []ProviderInfo{
+			{Name: "aws", Source: "hashicorp/aws", Config: "network"},
+			{Name: "random", Source: "hashicorp/random", Config: "shared"},
+		},
+		Configs: []ConfigInfo{{Path: "app", LineCount: 12}},
+	}
+
+	path := filepath.Join(t.TempDir(), "grouped.json")
+	if err := writeJSON(groupSBOMByConfig(sbom), path); err != nil {
+		t.Fatalf("Failed to write grouped SBOM: %v", err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read grouped SBOM: %v", err)
+	}
+
+	var grouped struct {
+		Name    string `json:"name"`
+		Configs map[string]struct {
+			Modules []struct {
+				Name string `json:"name"`
+			} `json:"modules"`
+			Providers []struct {
+				Name string `json:"name"`
+			} `json:"providers"`
+			Details *struct {
+				LineCount int `json:"line_count"`
+			} `json:"details"`
+		} `json:"configs"`
+	}
+	if err := json.Unmarshal(content, &grouped); err != nil {
+		t.Fatalf("Failed to parse grouped SBOM: %v", err)
+	}
+
+	if grouped.Name != "estate" {
+		t.Errorf("Expected the SBOM name to be kept, got %q", grouped.Name)
+	}
+
+	names := func(config string) []string {
+		var modules []string
+		for _, mod := range grouped.Configs[config].Modules {
+			modules = append(modules, mod.Name)
+		}
+		return modules
+	}
+	if !reflect.DeepEqual(names("network"), []string{"vpc", "dns"}) || !reflect.DeepEqual(names("app"), []string{"labels"}) {
+		t.Errorf("Unexpected modules per config: app %v, network %v", names("app"), names("network"))
+	}
+	if len(grouped.Configs) != 3 {
+		t.Errorf("Expected 3 configs, got %d", len(grouped.Configs))
+	}
+	if shared := grouped.Configs["shared"]; shared.Modules == nil || len(shared.Modules) != 0 || len(shared.Providers) != 1 {
+		t.Errorf("Expected the shared config to have an empty module list and one provider, got %+v", shared)
+	}
+	if len(grouped.Configs["network"].Providers) != 1 || grouped.Configs["network"].Providers[0].Name != "aws" {
+		t.Errorf("Expected the aws provider under network, got %+v", grouped.Configs["network"].Providers)
+	}
+	if details := grouped.Configs["app"].Details; details == nil || details.LineCount != 12 {
+		t.Errorf("Expected the app details to be nested, got %+v", details)
+	}
+}
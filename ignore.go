@@ -0,0 +1,151 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// ignoreDirective starts a comment that exempts the module block below it from the
+// named policy rules, such as # sbom:ignore=unpinned-module. Several rules can be
+// given separated by commas, and anything after the rules is taken as the reason.
+const ignoreDirective = "sbom:ignore="
+
+// ruleAliases maps the short names an ignore directive accepts to the rules they stand for.
+var ruleAliases = map[string]string{
+	"unpinned": ruleUnpinnedModule,
+}
+
+// moduleIgnoreDirectives returns the rules each module block is exempted from by
+// ignore directives in the comments directly above it, keyed by module name. Only
+// native syntax files are inspected, since JSON has no comments.
+func moduleIgnoreDirectives(files []*hcl.File) map[string][]string {
+	ignored := make(map[string][]string)
+
+	for _, file := range files {
+		content, _, _ := file.Body.PartialContent(moduleBlockSchema)
+		if len(content.Blocks) == 0 || isJSONConfigFile(content.Blocks[0].DefRange.Filename) {
+			continue
+		}
+
+		comments := lineComments(file)
+		for _, block := range content.Blocks {
+			var rules []string
+			for line := block.DefRange.Start.Line - 1; ; line-- {
+				comment, ok := comments[line]
+				if !ok {
+					break
+				}
+				rules = append(rules, parseIgnoreDirective(comment)...)
+			}
+			if len(rules) > 0 {
+				slices.Sort(rules)
+				ignored[block.Labels[0]] = slices.Compact(rules)
+			}
+		}
+	}
+
+	return ignored
+}
+
+// lineComments returns the text of the single-line # and // comments in a native
+// syntax file, keyed by line number.
+func lineComments(file *hcl.File) map[int]string {
+	tokens, _ := hclsyntax.LexConfig(file.Bytes, "", hcl.InitialPos)
+
+	comments := make(map[int]string)
+	for _, token := range tokens {
+		if token.Type != hclsyntax.TokenComment {
+			continue
+		}
+		text := strings.TrimSpace(string(token.Bytes))
+		if strings.HasPrefix(text, "#") || strings.HasPrefix(text, "//") {
+			comments[token.Range.Start.Line] = text
+		}
+	}
+	return comments
+}
+
+// parseIgnoreDirective returns the rules named by an ignore directive in a comment,
+// or nothing if the comment holds no directive. Aliases are resolved to the rules
+// they stand for.
+func parseIgnoreDirective(comment string) []string {
+	text := strings.TrimSpace(strings.TrimLeft(comment, "#/"))
+	value, ok := strings.CutPrefix(text, ignoreDirective)
+	if !ok {
+		return nil
+	}
+
+	fields := strings.Fields(value)
+	if len(fields) == 0 {
+		return nil
+	}
+
+	var rules []string
+	for _, rule := range strings.Split(fields[0], ",") {
+		rule = strings.TrimSpace(rule)
+		if alias, ok := ruleAliases[rule]; ok {
+			rule = alias
+		}
+		if rule != "" {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// setIgnoredRules records the rules each module is exempted from by an ignore
+// directive, and returns a warning for each rule that does not exist.
+func setIgnoredRules(modules []ModuleInfo, ignored map[string][]string) []string {
+	var warnings []string
+	for i := range modules {
+		mod := &modules[i]
+		rules, ok := ignored[mod.Name]
+		if !ok {
+			continue
+		}
+		mod.IgnoredRules = rules
+		for _, rule := range rules {
+			if !isFindingRule(rule) {
+				warnings = append(warnings, fmt.Sprintf("%s: module %s ignores unknown rule %q", mod.Config, mod.Name, rule))
+			}
+		}
+	}
+	return warnings
+}
+
+// isFindingRule reports whether a rule ID names one of the findingRules.
+func isFindingRule(id string) bool {
+	for _, rule := range findingRules {
+		if rule.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// filterIgnored returns the violations that no ignore directive exempts the module
+// they were found in from. Findings that span configs, such as inconsistent pins,
+// are not tied to a single module block and are always kept.
+func filterIgnored(violations []policyViolation, modules []ModuleInfo) []policyViolation {
+	ignored := make(map[baselineKey]bool)
+	for _, mod := range modules {
+		for _, rule := range mod.IgnoredRules {
+			ignored[baselineKey{Rule: rule, Config: mod.Config, Module: mod.Name, Path: mod.Path}] = true
+		}
+	}
+	if len(ignored) == 0 {
+		return violations
+	}
+
+	var remaining []policyViolation
+	for _, violation := range violations {
+		if !ignored[violation.key()] {
+			remaining = append(remaining, violation)
+		}
+	}
+	return remaining
+}
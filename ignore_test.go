@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+// TestGenerateSBOMIgnoreDirectives tests that sbom:ignore comments directly above a
+// module block exempt it from the named rules, and that the failing policy checks and
+// SARIF findings leave the exempted modules out.
+func TestGenerateSBOMIgnoreDirectives(t *testing.T) {
+	sbom, err := generateSBOM(context.Background(), "testdata/ignore", scanOptions{})
+	if err != nil {
+		t.Fatalf("Failed to generate SBOM: %v", err)
+	}
+
+	ignored := make(map[string][]string)
+	for _, mod := range sbom.Modules {
+		if mod.IgnoredRules != nil {
+			ignored[mod.Name] = mod.IgnoredRules
+		}
+	}
+	expected := map[string][]string{
+		"platform": {"unpinned-module"},
+		"db":       {"non-semver-pin", "unpinned-module"},
+		"queue":    {"unpinned-module"},
+		"search":   {"pinned"},
+	}
+	if !reflect.DeepEqual(ignored, expected) {
+		t.Errorf("Expected ignored rules %v, got %v", expected, ignored)
+	}
+
+	expectedWarnings := []string{`testdata/ignore: module search ignores unknown rule "pinned"`}
+	if !reflect.DeepEqual(sbom.Warnings, expectedWarnings) {
+		t.Errorf("Expected warnings %v, got %v", expectedWarnings, sbom.Warnings)
+	}
+
+	if violations := filterIgnored(strictSemverViolations(sbom.Modules), sbom.Modules); len(violations) > 0 {
+		t.Errorf("Expected the ignored non-semver pin not to fail -strict-semver, got %v", violations)
+	}
+
+	var failing []string
+	for _, violation := range filterIgnored(unpinnedViolations(sbom.Modules), sbom.Modules) {
+		failing = append(failing, violation.Module)
+	}
+	expectedUnpinned := []string{"cache", "dns"}
+	if !reflect.DeepEqual(failing, expectedUnpinned) {
+		t.Errorf("Expected -fail-on-unpinned to fail on %v, got %v", expectedUnpinned, failing)
+	}
+
+	var unpinned []string
+	for _, finding := range sbomFindings(sbom) {
+		if finding.Rule == ruleUnpinnedModule {
+			unpinned = append(unpinned, finding.Module)
+		}
+	}
+	if !reflect.DeepEqual(unpinned, expectedUnpinned) {
+		t.Errorf("Expected unpinned-module findings for %v, got %v", expectedUnpinned, unpinned)
+	}
+}
+
+// TestParseIgnoreDirective tests reading the rules of an ignore directive.
+func TestParseIgnoreDirective(t *testing.T) {
+	tests := []struct {
+		comment  string
+		expected []string
+	}{
+		{"# sbom:ignore=unpinned-module", []string{"unpinned-module"}},
+		{"// sbom:ignore=non-semver-pin,insecure-source because of the vendor", []string{"non-semver-pin", "insecure-source"}},
+		{"#sbom:ignore=unpinned-module", []string{"unpinned-module"}},
+		{"# sbom:ignore=unpinned,insecure-source", []string{"unpinned-module", "insecure-source"}},
+		{"# sbom:ignore=", nil},
+		{"# see sbom:ignore=unpinned-module", nil},
+		{"# nothing to see here", nil},
+	}
+
+	for _, tt := range tests {
+		if result := parseIgnoreDirective(tt.comment); !reflect.DeepEqual(result, tt.expected) {
+			t.Errorf("parseIgnoreDirective(%q) = %v, expected %v", tt.comment, result, tt.expected)
+		}
+	}
+}
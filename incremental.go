@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// gitChangedFiles lists the files under root that differ from the given git ref,
+// including untracked files, as paths relative to root.
+func gitChangedFiles(ctx context.Context, root string, ref string) ([]string, error) {
+	changed, err := runGit(ctx, root, "diff", "--name-only", "--relative", ref, "--")
+	if err != nil {
+		return nil, err
+	}
+	untracked, err := runGit(ctx, root, "ls-files", "--others", "--exclude-standard")
+	if err != nil {
+		return nil, err
+	}
+	return append(changed, untracked...), nil
+}
+
+// runGit runs a git command in dir and returns the non-empty lines of its output.
+func runGit(ctx context.Context, dir string, args ...string) ([]string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", dir}, args...)...)
+	// Never block waiting for credentials; a command that needs them fails instead.
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("git %s failed: %v: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+
+	var lines []string
+	for _, line := range strings.Split(stdout.String(), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, nil
+}
+
+// changedConfigDirs maps changed files, relative to root, to the configuration
+// directories containing them. Only Terraform, OpenTofu, and Terragrunt files are
+// considered.
+func changedConfigDirs(root string, files []string) []string {
+	dirs := make(map[string]bool)
+	for _, file := range files {
+		name := filepath.Base(file)
+		if !isConfigFile(name) && name != terragruntFileName {
+			continue
+		}
+		dirs[filepath.Join(root, filepath.Dir(filepath.FromSlash(file)))] = true
+	}
+
+	sorted := make([]string, 0, len(dirs))
+	for dir := range dirs {
+		sorted = append(sorted, dir)
+	}
+	sort.Strings(sorted)
+	return sorted
+}
+
+// generateIncrementalSBOM rescans only the configurations under root touched since
+// the given git ref. When a base SBOM is given, the entries of the touched configs
+// are replaced in it, and configs that no longer exist are dropped, producing an
+// updated SBOM for the whole tree; otherwise only the touched configs are reported.
+func generateIncrementalSBOM(ctx context.Context, root string, ref string, base *SBOM, opts scanOptions) (*SBOM, error) {
+	files, err := gitChangedFiles(ctx, root, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	return updateSBOM(ctx, base, changedConfigDirs(root, files), opts)
+}
+
+// updateSBOM replaces the entries of the given configuration directories in the base
+// SBOM with a fresh scan of each. Directories that no longer hold a configuration
+// only have their entries removed.
+func updateSBOM(ctx context.Context, base *SBOM, dirs []string, opts scanOptions) (*SBOM, error) {
+	touched := make(map[string]bool)
+	for _, dir := range dirs {
+		touched[filepath.Clean(dir)] = true
+	}
+
+	var updated SBOM
+	if base != nil {
+		updated.SerialNumber = base.SerialNumber
+		updated.Name = base.Name
+		updated.Namespace = base.Namespace
+		updated.Supplier = base.Supplier
+		updated.Version = base.Version + 1
+		for _, mod := range base.Modules {
+			if !touched[filepath.Clean(mod.Config)] {
+				updated.Modules = append(updated.Modules, mod)
+			}
+		}
+		for _, provider := range base.Providers {
+			if !touched[filepath.Clean(provider.Config)] {
+				updated.Providers = append(updated.Providers, provider)
+			}
+		}
+		for _, output := range base.Outputs {
+			if !touched[filepath.Clean(output.Config)] {
+				updated.Outputs = append(updated.Outputs, output)
+			}
+		}
+		for _, config := range base.Configs {
+			if !touched[filepath.Clean(config.Path)] {
+				updated.Configs = append(updated.Configs, config)
+			}
+		}
+		for _, warning := range base.Warnings {
+			if !warningInDirs(warning, dirs) {
+				updated.Warnings = append(updated.Warnings, warning)
+			}
+		}
+		for _, loadErr := range base.LoadErrors {
+			if !touched[loadErrorDir(loadErr)] {
+				updated.LoadErrors = append(updated.LoadErrors, loadErr)
+			}
+		}
+	}
+
+	for _, dir := range dirs {
+		if !isConfigDir(dir) && !(opts.Terragrunt && hasTerragruntConfig(dir)) {
+			continue
+		}
+
+		configSBOM, err := generateSBOM(ctx, dir, opts)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", dir, err)
+		}
+		updated.Modules = append(updated.Modules, configSBOM.Modules...)
+		updated.Providers = append(updated.Providers, configSBOM.Providers...)
+		updated.Outputs = append(updated.Outputs, configSBOM.Outputs...)
+		updated.Configs = append(updated.Configs, configSBOM.Configs...)
+		updated.Warnings = append(updated.Warnings, configSBOM.Warnings...)
+		updated.LoadErrors = append(updated.LoadErrors, configSBOM.LoadErrors...)
+	}
+
+	sortSBOM(&updated)
+
+	return &updated, nil
+}
+
+// warningInDirs reports whether a warning refers to one of the given config
+// directories, either directly or through a file inside it.
+func warningInDirs(warning string, dirs []string) bool {
+	for _, dir := range dirs {
+		dir = filepath.Clean(dir)
+		if strings.HasPrefix(warning, dir+":") || strings.HasPrefix(warning, dir+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// loadErrorDir returns the config directory a load error belongs to: its path, or the
+// directory of the file it was found in.
+func loadErrorDir(loadErr loadError) string {
+	path := filepath.Clean(loadErr.Path)
+	if loadErr.Line > 0 || isConfigFile(path) {
+		return filepath.Dir(path)
+	}
+	return path
+}
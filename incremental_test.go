@@ -0,0 +1,169 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestChangedConfigDirs tests mapping changed files to their configuration directories.
+func TestChangedConfigDirs(t *testing.T) {
+	files := []string{
+		"network/main.tf",
+		"network/variables.tf",
+		"app/terragrunt.hcl",
+		"storage/main.tf.json",
+		"README.md",
+		"network/README.md",
+	}
+
+	expected := []string{
+		filepath.Join("repo", "app"),
+		filepath.Join("repo", "network"),
+		filepath.Join("repo", "storage"),
+	}
+	if dirs := changedConfigDirs("repo", files); !reflect.DeepEqual(dirs, expected) {
+		t.Errorf("Changed dirs mismatch: expected %v, got %v", expected, dirs)
+	}
+}
+
+// TestUpdateSBOM tests replacing changed configs and dropping deleted ones in a base SBOM.
+func TestUpdateSBOM(t *testing.T) {
+	network := filepath.Join("testdata", "recursive", "network")
+	deleted := filepath.Join("testdata", "recursive", "deleted")
+	untouched := filepath.Join("testdata", "recursive", "app")
+
+	base := &SBOM{
+		SerialNumber: "urn:uuid:3e671687-395b-41f5-a30f-a58921a69b79",
+		Version:      2,
+		Modules: []ModuleInfo{
+			{Name: "service", Source: "./modules/service", Version: "local", Config: untouched},
+			{Name: "old", Source: "./old", Version: "local", Config: deleted},
+			{Name: "vpc", Source: "terraform-aws-modules/vpc/aws", Version: "4.0.0", Config: network},
+		},
+	}
+
+	updated, err := updateSBOM(context.Background(), base, []string{deleted, network}, scanOptions{})
+	if err != nil {
+		t.Fatalf("Failed to update SBOM: %v", err)
+	}
+
+	var versions []string
+	for _, mod := range updated.Modules {
+		versions = append(versions, mod.Config+":"+mod.Name+"@"+mod.Version)
+	}
+	expected := []string{
+		untouched + ":service@local",
+		network + ":vpc@5.1.0",
+	}
+	if !reflect.DeepEqual(versions, expected) {
+		t.Errorf("Updated modules mismatch: expected %v, got %v", expected, versions)
+	}
+
+	if updated.SerialNumber != base.SerialNumber || updated.Version != 3 {
+		t.Errorf("Expected revision 3 of %s, got revision %d of %s", base.SerialNumber, updated.Version, updated.SerialNumber)
+	}
+}
+
+// TestUpdateSBOMLoadErrors tests that the load errors of untouched configs are kept
+// and those of rescanned configs are replaced by the fresh ones.
+func TestUpdateSBOMLoadErrors(t *testing.T) {
+	broken := filepath.Join("testdata", "load-errors", "broken")
+	valid := filepath.Join("testdata", "load-errors", "valid")
+	untouched := filepath.Join("testdata", "recursive", "app")
+
+	base := &SBOM{
+		LoadErrors: []loadError{
+			{Path: filepath.Join(untouched, "main.tf"), Line: 3, Message: "Unsupported argument", Severity: loadSeverityError},
+			{Path: filepath.Join(broken, "broken.tf"), Line: 1, Message: "stale error", Severity: loadSeverityError},
+			{Path: valid, Message: "stale error", Severity: loadSeverityError},
+		},
+	}
+
+	updated, err := updateSBOM(context.Background(), base, []string{broken, valid}, scanOptions{})
+	if err != nil {
+		t.Fatalf("Failed to update SBOM: %v", err)
+	}
+
+	if len(updated.LoadErrors) < 2 {
+		t.Fatalf("Expected the untouched and fresh load errors, got %+v", updated.LoadErrors)
+	}
+	if updated.LoadErrors[0] != base.LoadErrors[0] {
+		t.Errorf("Expected the load error of the untouched config to be kept, got %+v", updated.LoadErrors[0])
+	}
+	for _, loadErr := range updated.LoadErrors[1:] {
+		if loadErr.Message == "stale error" || loadErrorDir(loadErr) != broken {
+			t.Errorf("Expected only fresh load errors of %s, got %+v", broken, loadErr)
+		}
+	}
+}
+
+// TestGenerateIncrementalSBOM tests that only configs changed since a git ref are rescanned.
+func TestGenerateIncrementalSBOM(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not available")
+	}
+
+	root := t.TempDir()
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-C", root}, args...)...)
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v: %s", args, err, out)
+		}
+	}
+	write := func(path, content string) {
+		path = filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("network/main.tf", "module \"vpc\" {\n  source = \"./vpc\"\n}\n")
+	write("app/main.tf", "module \"app\" {\n  source = \"./app\"\n}\n")
+	git("init", "-q")
+	git("add", "-A")
+	git("commit", "-q", "-m", "initial")
+
+	write("app/main.tf", "module \"web\" {\n  source = \"./web\"\n}\n")
+	write("storage/main.tf", "module \"bucket\" {\n  source = \"./bucket\"\n}\n")
+
+	sbom, err := generateIncrementalSBOM(context.Background(), root, "HEAD", nil, scanOptions{})
+	if err != nil {
+		t.Fatalf("Failed to generate incremental SBOM: %v", err)
+	}
+
+	var names []string
+	for _, mod := range sbom.Modules {
+		names = append(names, mod.Name)
+	}
+	expected := []string{"web", "bucket"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("Expected only changed configs to be scanned: expected %v, got %v", expected, names)
+	}
+}
+
+// TestWarningInDirs tests matching warnings to the config directories they refer to.
+func TestWarningInDirs(t *testing.T) {
+	network := filepath.Join("live", "network")
+	dirs := []string{network}
+
+	tests := map[string]bool{
+		filepath.Join(network, "main.tf") + ":3: Unclosed configuration block": true,
+		network + ": Failed to read module directory":                          true,
+		filepath.Join("live", "network-dr", "main.tf") + ":1: Invalid block":   false,
+		filepath.Join("live", "app", "main.tf") + ":1: Invalid block":          false,
+	}
+	for warning, expected := range tests {
+		if got := warningInDirs(warning, dirs); got != expected {
+			t.Errorf("warningInDirs(%q) = %t, expected %t", warning, got, expected)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// In-toto statement identifiers used by the intoto output format.
+const (
+	inTotoStatementType = "https://in-toto.io/Statement/v1"
+	inTotoPredicateType = "https://github.com/rodmhgl/terraform-sbom/predicate/v1"
+)
+
+// inTotoStatement is an in-toto attestation statement whose predicate is the SBOM.
+type inTotoStatement struct {
+	Type          string          `json:"_type"`
+	Subject       []inTotoSubject `json:"subject"`
+	PredicateType string          `json:"predicateType"`
+	Predicate     *SBOM           `json:"predicate"`
+}
+
+// inTotoSubject identifies a scanned configuration directory by the digest of its contents.
+type inTotoSubject struct {
+	Name   string            `json:"name"`
+	Digest map[string]string `json:"digest"`
+}
+
+// newInTotoStatement wraps the SBOM in an in-toto statement with one subject per
+// scanned configuration directory.
+func newInTotoStatement(sbom *SBOM) (*inTotoStatement, error) {
+	paths := make(map[string]bool)
+	for _, mod := range sbom.Modules {
+		paths[mod.Config] = true
+	}
+	for _, config := range sbom.Configs {
+		paths[config.Path] = true
+	}
+
+	sorted := make([]string, 0, len(paths))
+	for path := range paths {
+		sorted = append(sorted, path)
+	}
+	sort.Strings(sorted)
+
+	statement := &inTotoStatement{
+		Type:          inTotoStatementType,
+		Subject:       []inTotoSubject{},
+		PredicateType: inTotoPredicateType,
+		Predicate:     withModuleList(sbom),
+	}
+
+	for _, path := range sorted {
+		digest, err := configDigest(path)
+		if err != nil {
+			return nil, err
+		}
+		statement.Subject = append(statement.Subject, inTotoSubject{
+			Name:   filepath.ToSlash(path),
+			Digest: map[string]string{"sha256": digest},
+		})
+	}
+
+	return statement, nil
+}
+
+// configDigest returns the hex-encoded SHA-256 digest of the Terraform files in a
+// configuration directory. Each file contributes its name and the digest of its
+// contents, in name order, so the result only changes when the configuration does.
+func configDigest(configPath string) (string, error) {
+	hash := sha256.New()
+
+	for _, path := range configFiles(configPath) {
+		file, err := os.Open(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to open %s: %v", path, err)
+		}
+
+		fileHash := sha256.New()
+		_, err = io.Copy(fileHash, file)
+		file.Close()
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s: %v", path, err)
+		}
+
+		fmt.Fprintf(hash, "%s\x00%x\n", filepath.Base(path), fileHash.Sum(nil))
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// writeSBOMToInToto writes the SBOM as the predicate of an in-toto statement.
+func writeSBOMToInToto(sbom *SBOM, outputPath string) error {
+	statement, err := newInTotoStatement(sbom)
+	if err != nil {
+		return fmt.Errorf("failed to create in-toto statement: %v", err)
+	}
+
+	err = writeFileAtomic(outputPath, func(w io.Writer) error {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(statement)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write in-toto file: %v", err)
+	}
+
+	fmt.Printf("SBOM successfully written to %s\n", outputPath)
+	return nil
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+)
+
+// TestWriteSBOMToInToto tests the structure of the in-toto statement.
+func TestWriteSBOMToInToto(t *testing.T) {
+	sbom, err := generateSBOM(context.Background(), "testdata/aliased-providers", scanOptions{})
+	if err != nil {
+		t.Fatalf("Failed to generate SBOM: %v", err)
+	}
+
+	outputPath := filepath.Join(t.TempDir(), "sbom.intoto.json")
+	err = writeSBOMToInToto(sbom, outputPath)
+	if err != nil {
+		t.Fatalf("Failed to write in-toto statement: %v", err)
+	}
+
+	content, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("Failed to read in-toto file: %v", err)
+	}
+
+	var statement struct {
+		Type    string `json:"_type"`
+		Subject []struct {
+			Name   string            `json:"name"`
+			Digest map[string]string `json:"digest"`
+		} `json:"subject"`
+		PredicateType string `json:"predicateType"`
+		Predicate     SBOM   `json:"predicate"`
+	}
+	err = json.Unmarshal(content, &statement)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal in-toto statement: %v", err)
+	}
+
+	if statement.Type != inTotoStatementType {
+		t.Errorf("Expected _type %q, got %q", inTotoStatementType, statement.Type)
+	}
+	if statement.PredicateType != inTotoPredicateType {
+		t.Errorf("Expected predicateType %q, got %q", inTotoPredicateType, statement.PredicateType)
+	}
+	if len(statement.Subject) != 1 {
+		t.Fatalf("Expected 1 subject, got %d", len(statement.Subject))
+	}
+	if statement.Subject[0].Name != "testdata/aliased-providers" {
+		t.Errorf("Unexpected subject name %q", statement.Subject[0].Name)
+	}
+	if !regexp.MustCompile(`^[0-9a-f]{64}$`).MatchString(statement.Subject[0].Digest["sha256"]) {
+		t.Errorf("Subject digest is not a SHA-256 hex string: %q", statement.Subject[0].Digest["sha256"])
+	}
+	if len(statement.Predicate.Modules) != len(sbom.Modules) {
+		t.Errorf("Expected %d modules in the predicate, got %d", len(sbom.Modules), len(statement.Predicate.Modules))
+	}
+}
+
+// TestConfigDigest tests that the digest is stable and changes with the file contents.
+func TestConfigDigest(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "main.tf")
+	if err := os.WriteFile(path, []byte("module \"a\" {\n  source = \"./a\"\n}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := configDigest(dir)
+	if err != nil {
+		t.Fatalf("Failed to compute digest: %v", err)
+	}
+	second, err := configDigest(dir)
+	if err != nil {
+		t.Fatalf("Failed to compute digest: %v", err)
+	}
+	if first != second {
+		t.Errorf("Digest is not stable: %s != %s", first, second)
+	}
+
+	if err := os.WriteFile(path, []byte("module \"b\" {\n  source = \"./b\"\n}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	changed, err := configDigest(dir)
+	if err != nil {
+		t.Fatalf("Failed to compute digest: %v", err)
+	}
+	if changed == first {
+		t.Error("Expected the digest to change with the file contents")
+	}
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// jsonStreamEncoder writes an SBOM as indented JSON one module at a time, so that
+// neither the module list nor the encoded document has to be held in memory as a
+// whole. Producers such as a recursive scan can encode each module as soon as it is
+// found. The output is identical to encoding the complete SBOM with json.Encoder.
+type jsonStreamEncoder struct {
+	w       *bufio.Writer
+	buf     bytes.Buffer  // Encoded form of the current module, reused between modules
+	enc     *json.Encoder // Encodes modules into buf
+	modules int           // Modules written so far
+}
+
+// moduleListKey is the module list as a top-level field of an indented SBOM. Encoded
+// values never contain a raw newline, so only the top-level field can match it.
+var moduleListKey = []byte("\n  \"modules\": [")
+
+// jsonEnvelope encodes sbom without its modules and splits the document where the
+// module list goes: head ends with the opening bracket of the list and tail starts
+// with the closing one. Deriving both from the SBOM itself keeps every top-level
+// field, including ones added later, in the streamed document.
+func jsonEnvelope(sbom *SBOM) (head, tail []byte, err error) {
+	envelope := *sbom
+	envelope.Modules = []ModuleInfo{}
+	content, err := json.MarshalIndent(&envelope, "", "  ")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode SBOM: %v", err)
+	}
+
+	i := bytes.Index(content, moduleListKey)
+	if i < 0 {
+		return nil, nil, fmt.Errorf("failed to encode SBOM: no module list in %s", content)
+	}
+	i += len(moduleListKey)
+	return content[:i], content[i:], nil
+}
+
+// newJSONStreamEncoder writes the opening of the document, including the fields of
+// sbom that precede the module list. The modules of sbom are ignored; they are
+// written with Encode.
+func newJSONStreamEncoder(w io.Writer, sbom *SBOM) (*jsonStreamEncoder, error) {
+	head, _, err := jsonEnvelope(sbom)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &jsonStreamEncoder{w: bufio.NewWriter(w)}
+	e.enc = json.NewEncoder(&e.buf)
+	e.enc.SetIndent("    ", "  ")
+	if _, err := e.w.Write(head); err != nil {
+		return nil, fmt.Errorf("failed to write JSON: %v", err)
+	}
+	return e, nil
+}
+
+// Encode appends a module to the module list.
+func (e *jsonStreamEncoder) Encode(mod ModuleInfo) error {
+	e.buf.Reset()
+	if err := e.enc.Encode(mod); err != nil {
+		return fmt.Errorf("failed to encode module %s: %v", mod.Name, err)
+	}
+	content := bytes.TrimSuffix(e.buf.Bytes(), []byte("\n"))
+
+	if e.modules > 0 {
+		e.w.WriteString(",")
+	}
+	e.w.WriteString("\n    ")
+	if _, err := e.w.Write(content); err != nil {
+		return fmt.Errorf("failed to write JSON: %v", err)
+	}
+	e.modules++
+	return nil
+}
+
+// Close ends the module list, writes the fields of sbom that follow it, and flushes
+// the document. The modules of sbom and the fields that precede them are ignored, so
+// sbom may hold only what became known while the modules were written.
+func (e *jsonStreamEncoder) Close(sbom *SBOM) error {
+	_, tail, err := jsonEnvelope(sbom)
+	if err != nil {
+		return err
+	}
+
+	if e.modules > 0 {
+		e.w.WriteString("\n  ")
+	}
+	e.w.Write(tail)
+	e.w.WriteString("\n")
+	if err := e.w.Flush(); err != nil {
+		return fmt.Errorf("failed to write JSON: %v", err)
+	}
+	return nil
+}
+
+// encodeSBOMJSON streams a complete SBOM through a jsonStreamEncoder.
+func encodeSBOMJSON(w io.Writer, sbom *SBOM) error {
+	encoder, err := newJSONStreamEncoder(w, sbom)
+	if err != nil {
+		return err
+	}
+	for _, mod := range sbom.Modules {
+		if err := encoder.Encode(mod); err != nil {
+			return err
+		}
+	}
+	return encoder.Close(sbom)
+}
+
+// streamFlags are the flags -stream can be combined with. They only affect how each
+// configuration is scanned, or where and how the scan runs, so the modules can be
+// written as they are found. Every other option needs the complete module list.
+var streamFlags = map[string]bool{
+	"stream":                   true,
+	"recursive":                true,
+	"output":                   true,
+	"force":                    true,
+	"progress":                 true,
+	"timeout":                  true,
+	"cpuprofile":               true,
+	"memprofile":               true,
+	"serial":                   true,
+	"name":                     true,
+	"namespace":                true,
+	"supplier":                 true,
+	"terragrunt":               true,
+	"from-manifest":            true,
+	"follow-local-sources":     true,
+	"flatten-nested":           true,
+	"no-version-heuristics":    true,
+	"explain":                  true,
+	"metrics":                  true,
+	"include-outputs":          true,
+	"include-backend":          true,
+	"include-lifecycle":        true,
+	"include-provider-configs": true,
+	"include-resources":        true,
+	"private-registry-host":    true,
+	"var":                      true,
+	"var-file":                 true,
+	"max-file-size":            true,
+	"api-retries":              true,
+	"rate-limit":               true,
+}
+
+// writeRecursiveJSON scans every Terraform configuration found under root, as
+// generateRecursiveSBOM does, and encodes the modules of each as soon as it is
+// scanned, so that the modules of the whole tree are never held in memory. The
+// document opens with the metadata of sbom. The returned SBOM holds everything the
+// scan found except the modules.
+func writeRecursiveJSON(ctx context.Context, w io.Writer, root string, opts scanOptions, progress *progressReporter, sbom *SBOM) (*SBOM, error) {
+	encoder, err := newJSONStreamEncoder(w, sbom)
+	if err != nil {
+		return nil, err
+	}
+
+	scanned, err := scanRecursive(ctx, root, opts, progress, func(modules []ModuleInfo) error {
+		for _, mod := range modules {
+			if err := encoder.Encode(mod); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := encoder.Close(scanned); err != nil {
+		return nil, err
+	}
+	return scanned, nil
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// encodeSBOMBuffered encodes the SBOM as a whole, as the JSON writer did before streaming.
+func encodeSBOMBuffered(w io.Writer, sbom *SBOM) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(withModuleList(sbom))
+}
+
+// streamTestSBOM returns an SBOM using every top-level field.
+func streamTestSBOM() *SBOM {
+	approved := true
+	return &SBOM{
+		SerialNumber: "urn:uuid:3e671687-395b-41f5-a30f-a58921a69b79",
+		Version:      2,
+		Name:         "estate",
+		Namespace:    "https://example.com/<sboms>",
+		Supplier:     "Example & Co",
+		Timestamp:    "2024-01-01T00:00:00Z",
+		Modules: []ModuleInfo{
+			{Name: "vpc", Source: "terraform-aws-modules/vpc/aws", SourceType: sourceTypeRegistry, Version: "~> 5.0", Config: "app", ProviderMappings: ProviderMap{"aws": "aws.useast1"}, Approved: &approved},
+			{Name: "labels", Source: "git::https://github.com/acme/labels.git?ref=v1.0.0", SourceType: sourceTypeGit, Version: "v1.0.0", Config: "app", Owners: []string{"@acme/platform"}},
+		},
+		Providers: []ProviderInfo{{Name: "aws", Source: "hashicorp/aws", VersionConstraint: "~> 5.0", Config: "app"}},
+		Outputs:   []OutputInfo{{Name: "vpc_id", Description: "ID of the VPC", Config: "app"}},
+		Configs:   []ConfigInfo{{Path: "app", LineCount: 42, ResourceCounts: ResourceCounts{"aws": 3}}},
+		Warnings:  []string{"app/broken.tf:1: Invalid block definition"},
+	}
+}
+
+// TestEncodeSBOMJSONMatchesEncoder tests that streaming produces the same document as encoding the whole SBOM.
+func TestEncodeSBOMJSONMatchesEncoder(t *testing.T) {
+	tests := map[string]*SBOM{
+		"full":    streamTestSBOM(),
+		"minimal": {Modules: []ModuleInfo{{Name: "vpc", Source: "./modules/vpc", SourceType: sourceTypeLocal, Version: "local", Config: "."}}},
+		"empty":   {},
+		"trailer": {Warnings: []string{"no modules"}},
+	}
+
+	for name, sbom := range tests {
+		var expected, streamed bytes.Buffer
+		if err := encodeSBOMBuffered(&expected, sbom); err != nil {
+			t.Fatalf("Failed to encode %s SBOM: %v", name, err)
+		}
+		if err := encodeSBOMJSON(&streamed, sbom); err != nil {
+			t.Fatalf("Failed to stream %s SBOM: %v", name, err)
+		}
+		if streamed.String() != expected.String() {
+			t.Errorf("Streamed %s SBOM mismatch:\nexpected %s\ngot      %s", name, expected.String(), streamed.String())
+		}
+	}
+}
+
+// TestJSONStreamEncoderIncremental tests encoding modules as a producer emits them.
+func TestJSONStreamEncoderIncremental(t *testing.T) {
+	sbom := streamTestSBOM()
+
+	produced := make(chan ModuleInfo)
+	go func() {
+		defer close(produced)
+		for _, mod := range sbom.Modules {
+			produced <- mod
+		}
+	}()
+
+	var streamed bytes.Buffer
+	encoder, err := newJSONStreamEncoder(&streamed, sbom)
+	if err != nil {
+		t.Fatalf("Failed to start JSON stream: %v", err)
+	}
+	for mod := range produced {
+		if err := encoder.Encode(mod); err != nil {
+			t.Fatalf("Failed to encode module: %v", err)
+		}
+	}
+	if err := encoder.Close(sbom); err != nil {
+		t.Fatalf("Failed to close JSON stream: %v", err)
+	}
+
+	var decoded SBOM
+	if err := json.Unmarshal(streamed.Bytes(), &decoded); err != nil {
+		t.Fatalf("Failed to parse streamed JSON: %v", err)
+	}
+	if len(decoded.Modules) != 2 || decoded.Modules[1].Name != "labels" || len(decoded.Configs) != 1 {
+		t.Errorf("Unexpected decoded SBOM: %+v", decoded)
+	}
+}
+
+// TestWriteRecursiveJSONMatchesScan tests that streaming a recursive scan produces the
+// same document as encoding the SBOM of generateRecursiveSBOM.
+func TestWriteRecursiveJSONMatchesScan(t *testing.T) {
+	metadata := &SBOM{SerialNumber: "urn:uuid:3e671687-395b-41f5-a30f-a58921a69b79", Version: 1, Name: "recursive"}
+
+	sbom, err := generateRecursiveSBOM(context.Background(), "testdata/recursive", scanOptions{}, nil)
+	if err != nil {
+		t.Fatalf("Failed to generate SBOM: %v", err)
+	}
+	sbom.SerialNumber, sbom.Version, sbom.Name = metadata.SerialNumber, metadata.Version, metadata.Name
+	var expected bytes.Buffer
+	if err := encodeSBOMBuffered(&expected, sbom); err != nil {
+		t.Fatalf("Failed to encode SBOM: %v", err)
+	}
+
+	var streamed bytes.Buffer
+	scanned, err := writeRecursiveJSON(context.Background(), &streamed, "testdata/recursive", scanOptions{}, nil, metadata)
+	if err != nil {
+		t.Fatalf("Failed to stream SBOM: %v", err)
+	}
+	if streamed.String() != expected.String() {
+		t.Errorf("Streamed SBOM mismatch:\nexpected %s\ngot      %s", expected.String(), streamed.String())
+	}
+	if scanned.Modules != nil || !reflect.DeepEqual(scanned.Configs, sbom.Configs) {
+		t.Errorf("Expected the scan to return everything but the modules, got %+v", scanned)
+	}
+}
+
+// TestScanRecursiveEmitsEachConfig tests that a recursive scan hands over the modules
+// of each configuration before scanning the next, and stops when they cannot be written.
+func TestScanRecursiveEmitsEachConfig(t *testing.T) {
+	var configs []string
+	_, err := scanRecursive(context.Background(), "testdata/recursive", scanOptions{}, nil, func(modules []ModuleInfo) error {
+		configs = append(configs, modules[0].Config)
+		return errors.New("disk full")
+	})
+	if err == nil || err.Error() != "disk full" {
+		t.Errorf("Expected the scan to stop with the emit error, got %v", err)
+	}
+	if len(configs) != 1 {
+		t.Errorf("Expected the scan to stop after the first configuration, got %v", configs)
+	}
+}
+
+// largeSBOM returns an SBOM with the given number of modules.
+func largeSBOM(count int) *SBOM {
+	sbom := streamTestSBOM()
+	sbom.Modules = make([]ModuleInfo, count)
+	for i := range sbom.Modules {
+		sbom.Modules[i] = ModuleInfo{
+			Name:       fmt.Sprintf("module_%d", i),
+			Source:     "terraform-aws-modules/vpc/aws",
+			SourceType: sourceTypeRegistry,
+			Version:    "5.1.0",
+			Config:     fmt.Sprintf("configs/%d", i/10),
+			Registry:   defaultRegistryHost,
+		}
+	}
+	return sbom
+}
+
+// reportPeakHeap runs encode b.N times while sampling the heap, and reports the
+// largest growth of the live heap over its starting size as peak-heap-B.
+func reportPeakHeap(b *testing.B, encode func() error) {
+	var stats runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&stats)
+	baseline := stats.HeapAlloc
+
+	var peak uint64
+	done := make(chan struct{})
+	sampled := make(chan struct{})
+	go func() {
+		defer close(sampled)
+		var sample runtime.MemStats
+		for {
+			runtime.ReadMemStats(&sample)
+			if sample.HeapAlloc > baseline && sample.HeapAlloc-baseline > peak {
+				peak = sample.HeapAlloc - baseline
+			}
+			select {
+			case <-done:
+				return
+			case <-time.After(100 * time.Microsecond):
+			}
+		}
+	}()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := encode(); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.StopTimer()
+
+	close(done)
+	<-sampled
+	b.ReportMetric(float64(peak), "peak-heap-B")
+}
+
+// BenchmarkEncodeSBOMJSONBuffered measures encoding a large SBOM as a whole, which
+// holds the complete encoded document in memory before writing it. Compare its
+// peak-heap-B with BenchmarkEncodeSBOMJSONStreaming.
+func BenchmarkEncodeSBOMJSONBuffered(b *testing.B) {
+	sbom := largeSBOM(20000)
+	reportPeakHeap(b, func() error {
+		return encodeSBOMBuffered(io.Discard, sbom)
+	})
+}
+
+// BenchmarkEncodeSBOMJSONStreaming measures streaming a large SBOM one module at a time.
+func BenchmarkEncodeSBOMJSONStreaming(b *testing.B) {
+	sbom := largeSBOM(20000)
+	reportPeakHeap(b, func() error {
+		return encodeSBOMJSON(io.Discard, sbom)
+	})
+}
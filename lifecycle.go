@@ -0,0 +1,111 @@
+package main
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+)
+
+// lifecycleBlockSchema describes the top-level moved and import blocks.
+var lifecycleBlockSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: "moved"},
+		{Type: "import"},
+	},
+}
+
+// parseLifecycleBlocks returns the moved and import blocks declared in the given files.
+// Addresses are recorded as written; import IDs have any URL password redacted, since
+// IDs such as database connection strings may embed credentials.
+func parseLifecycleBlocks(files []*hcl.File) ([]MoveInfo, []ImportInfo) {
+	var moves []MoveInfo
+	var imports []ImportInfo
+
+	for _, file := range files {
+		content, _, _ := file.Body.PartialContent(lifecycleBlockSchema)
+		for _, block := range content.Blocks {
+			attrs, _ := block.Body.JustAttributes()
+			value := func(name string) string {
+				if attr, ok := attrs[name]; ok {
+					return attributeValue(file.Bytes, attr.Expr)
+				}
+				return ""
+			}
+
+			switch block.Type {
+			case "moved":
+				moves = append(moves, MoveInfo{From: value("from"), To: value("to")})
+			case "import":
+				imports = append(imports, ImportInfo{
+					To:       value("to"),
+					ID:       redactURLPassword(value("id")),
+					Provider: value("provider"),
+				})
+			}
+		}
+	}
+
+	return moves, imports
+}
+
+// redactURLPassword replaces the password of a URL such as
+// postgres://admin:hunter2@db:5432 with redactedValue. Other strings are returned unchanged.
+func redactURLPassword(s string) string {
+	if !strings.Contains(s, "://") {
+		return s
+	}
+	u, err := url.Parse(s)
+	if err != nil || u.User == nil {
+		return s
+	}
+	if _, ok := u.User.Password(); !ok {
+		return s
+	}
+	u.User = url.UserPassword(u.User.Username(), redactedValue)
+	return u.String()
+}
+
+// movesString renders moved blocks for CSV output as semicolon-separated from->to pairs.
+func movesString(moves []MoveInfo) string {
+	pairs := make([]string, len(moves))
+	for i, move := range moves {
+		pairs[i] = move.From + "->" + move.To
+	}
+	return strings.Join(pairs, ";")
+}
+
+// importsString renders import blocks for CSV output as semicolon-separated to=id pairs.
+func importsString(imports []ImportInfo) string {
+	pairs := make([]string, len(imports))
+	for i, imp := range imports {
+		pairs[i] = imp.To + "=" + imp.ID
+	}
+	return strings.Join(pairs, ";")
+}
+
+// parseMoves parses the moved blocks written by movesString.
+func parseMoves(s string) []MoveInfo {
+	if s == "" {
+		return nil
+	}
+	var moves []MoveInfo
+	for _, pair := range strings.Split(s, ";") {
+		from, to, _ := strings.Cut(pair, "->")
+		moves = append(moves, MoveInfo{From: from, To: to})
+	}
+	return moves
+}
+
+// parseImports parses the import blocks written by importsString.
+func parseImports(s string) []ImportInfo {
+	if s == "" {
+		return nil
+	}
+	var imports []ImportInfo
+	for _, pair := range strings.Split(s, ";") {
+		to, id, _ := strings.Cut(pair, "=")
+		imports = append(imports, ImportInfo{To: to, ID: id})
+	}
+	return imports
+}
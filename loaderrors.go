@@ -0,0 +1,58 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/hashicorp/terraform-config-inspect/tfconfig"
+)
+
+// Severities of load errors.
+const (
+	loadSeverityError   = "error"
+	loadSeverityWarning = "warning"
+)
+
+// loadError is a problem loading a config, written to the report of -errors-to. Path
+// is the file the problem is in, with its line, or the config directory when the
+// problem is not tied to a file, such as a config that could not be loaded at all.
+type loadError struct {
+	Path     string `json:"path"`
+	Line     int    `json:"line,omitempty"`
+	Message  string `json:"message"`
+	Severity string `json:"severity"`
+}
+
+// newLoadError converts a diagnostic reported while loading a config.
+func newLoadError(configPath string, d tfconfig.Diagnostic) loadError {
+	loadErr := loadError{Path: configPath, Message: d.Summary, Severity: loadSeverityWarning}
+	if d.Detail != "" {
+		loadErr.Message += "; " + d.Detail
+	}
+	if d.Pos != nil {
+		loadErr.Path, loadErr.Line = d.Pos.Filename, d.Pos.Line
+	}
+	if d.Severity == tfconfig.DiagError {
+		loadErr.Severity = loadSeverityError
+	}
+	return loadErr
+}
+
+// writeLoadErrors writes the load errors of a scan as a JSON list, which is empty
+// when every config loaded cleanly.
+func writeLoadErrors(loadErrors []loadError, outputPath string) error {
+	if loadErrors == nil {
+		loadErrors = []loadError{}
+	}
+
+	err := writeFileAtomic(outputPath, func(w io.Writer) error {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(loadErrors)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write load errors: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestLoadErrorsReport tests that a recursive scan over broken and valid configs records
+// the modules of the valid ones while every load error ends up in the JSON report.
+func TestLoadErrorsReport(t *testing.T) {
+	tests := []struct {
+		name            string
+		strict          bool
+		expectedModules []string
+		expected        []loadError
+	}{
+		{
+			name:            "partial",
+			expectedModules: []string{"dns", "labels", "vpc"},
+			expected: []loadError{{
+				Path:     "testdata/load-errors/broken/broken.tf",
+				Line:     1,
+				Message:  "Unclosed configuration block; There is no closing brace for this block before the end of the file. This may be caused by incorrect brace nesting elsewhere in this file.",
+				Severity: loadSeverityError,
+			}},
+		},
+		{
+			name:            "strict",
+			strict:          true,
+			expectedModules: []string{"vpc"},
+			expected: []loadError{{
+				Path:     "testdata/load-errors/broken",
+				Message:  "failed to load Terraform module: Unclosed configuration block: There is no closing brace for this block before the end of the file. This may be caused by incorrect brace nesting elsewhere in this file.",
+				Severity: loadSeverityError,
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		sbom, err := generateRecursiveSBOM(context.Background(), "testdata/load-errors", scanOptions{Strict: tt.strict, SkipFailedConfigs: true}, nil)
+		if err != nil {
+			t.Fatalf("%s: Failed to generate SBOM: %v", tt.name, err)
+		}
+
+		var modules []string
+		for _, mod := range sbom.Modules {
+			modules = append(modules, mod.Name)
+		}
+		if !reflect.DeepEqual(modules, tt.expectedModules) {
+			t.Errorf("%s: Expected modules %v, got %v", tt.name, tt.expectedModules, modules)
+		}
+
+		path := filepath.Join(t.TempDir(), "errors.json")
+		if err := writeLoadErrors(sbom.LoadErrors, path); err != nil {
+			t.Fatalf("%s: Failed to write load errors: %v", tt.name, err)
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var report []loadError
+		if err := json.Unmarshal(content, &report); err != nil {
+			t.Fatalf("%s: Failed to parse load errors: %v", tt.name, err)
+		}
+		if !reflect.DeepEqual(report, tt.expected) {
+			t.Errorf("%s: Load errors mismatch:\nexpected %+v\ngot      %+v", tt.name, tt.expected, report)
+		}
+	}
+}
+
+// TestLoadErrorsStopScan tests that a config failing to load still stops a recursive
+// scan unless failed configs are skipped, and that a clean scan writes an empty list.
+func TestLoadErrorsStopScan(t *testing.T) {
+	if _, err := generateRecursiveSBOM(context.Background(), "testdata/load-errors", scanOptions{Strict: true}, nil); err == nil {
+		t.Errorf("Expected the broken config to stop the scan")
+	}
+
+	path := filepath.Join(t.TempDir(), "errors.json")
+	if err := writeLoadErrors(nil, path); err != nil {
+		t.Fatalf("Failed to write load errors: %v", err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "[]\n" {
+		t.Errorf("Expected an empty list, got %s", content)
+	}
+}
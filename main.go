@@ -1,207 +1,2214 @@
 package main
 
 import (
+	"bufio"
+	"context"
 	"encoding/csv"
 	"encoding/json"
 	"encoding/xml"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"syscall"
+	"text/template"
+	"time"
 
+	"github.com/BurntSushi/toml"
+	goversion "github.com/hashicorp/go-version"
 	"github.com/hashicorp/terraform-config-inspect/tfconfig"
+	"github.com/zclconf/go-cty/cty"
+	"gopkg.in/yaml.v3"
 )
 
 // ModuleInfo represents the information about a Terraform module.
 // It includes the module's name, source, version, and configuration.
 type ModuleInfo struct {
-	Name    string `json:"name" xml:"Name"`
-	Source  string `json:"source" xml:"Source"`
-	Version string `json:"version" xml:"Version"`
-	Config  string `json:"config" xml:"ConfigPath"`
+	Name              string      `json:"name" xml:"Name" toml:"name" yaml:"name"`
+	Source            string      `json:"source" xml:"Source" toml:"source" yaml:"source"`
+	NormalizedSource  string      `json:"normalized_source,omitempty" xml:"NormalizedSource,omitempty" toml:"normalized_source,omitempty" yaml:"normalized_source,omitempty"` // Source with case and slash variants normalized, set only when it differs
+	Subdir            string      `json:"subdir,omitempty" xml:"Subdir,omitempty" toml:"subdir,omitempty" yaml:"subdir,omitempty"`
+	SourceType        string      `json:"source_type" xml:"SourceType" toml:"source_type" yaml:"source_type"`
+	Version           string      `json:"version" xml:"Version" toml:"version" yaml:"version"`
+	VersionSource     string      `json:"version_source,omitempty" xml:"VersionSource,omitempty" toml:"version_source,omitempty" yaml:"version_source,omitempty"`                 // How the version was derived, set with -explain
+	OriginalVersion   string      `json:"original_version,omitempty" xml:"OriginalVersion,omitempty" toml:"original_version,omitempty" yaml:"original_version,omitempty"`         // Version declared by the configuration, kept when -version-overrides replaces it
+	NormalizedVersion string      `json:"normalized_version,omitempty" xml:"NormalizedVersion,omitempty" toml:"normalized_version,omitempty" yaml:"normalized_version,omitempty"` // Canonical form of a registry module's version constraint
+	PURL              string      `json:"purl,omitempty" xml:"PURL,omitempty" toml:"purl,omitempty" yaml:"purl,omitempty"`                                                        // Package URL, such as pkg:terraform/terraform-aws-modules/vpc/aws@5.1.0
+	Config            string      `json:"config" xml:"ConfigPath" toml:"config" yaml:"config"`
+	Path              string      `json:"path,omitempty" xml:"Path,omitempty" toml:"path,omitempty" yaml:"path,omitempty"`             // Call path from the root module, e.g. root > networking > subnet, set with -flatten-nested
+	Address           string      `json:"address,omitempty" xml:"Address,omitempty" toml:"address,omitempty" yaml:"address,omitempty"` // Full address including the config, e.g. network/module.vpc, set with -full-address
+	ProviderMappings  ProviderMap `json:"provider_mappings,omitempty" xml:"ProviderMappings,omitempty" toml:"provider_mappings,omitempty" yaml:"provider_mappings,omitempty"`
+	DependsOn         []string    `json:"depends_on,omitempty" xml:"DependsOn>Address,omitempty" toml:"depends_on,omitempty" yaml:"depends_on,omitempty"`  // Addresses in the depends_on meta-argument of the module block
+	Registry          string      `json:"registry,omitempty" xml:"Registry,omitempty" toml:"registry,omitempty" yaml:"registry,omitempty"`                 // Hostname of the registry serving a registry module
+	Private           bool        `json:"private,omitempty" xml:"Private,omitempty" toml:"private,omitempty" yaml:"private,omitempty"`                     // Set when the registry is one of the -private-registry-host hosts
+	Insecure          bool        `json:"insecure,omitempty" xml:"Insecure,omitempty" toml:"insecure,omitempty" yaml:"insecure,omitempty"`                 // Set when the source is fetched over plain http:// or git://
+	Organization      string      `json:"organization,omitempty" xml:"Organization,omitempty" toml:"organization,omitempty" yaml:"organization,omitempty"` // Registry namespace or GitHub/git organization of the source
+	Owners            []string    `json:"owners,omitempty" xml:"Owners>Owner,omitempty" toml:"owners,omitempty" yaml:"owners,omitempty"`                   // Teams or users owning the config, from -codeowners
+	Description       string      `json:"description,omitempty" xml:"Description,omitempty" toml:"description,omitempty" yaml:"description,omitempty"`     // Summary from a local module's README, collected with -metrics
+	HasReadme         *bool       `json:"has_readme,omitempty" xml:"HasReadme,omitempty" toml:"has_readme,omitempty" yaml:"has_readme,omitempty"`          // Set for local modules when -metrics is given
+	Approved          *bool       `json:"approved,omitempty" xml:"Approved,omitempty" toml:"approved,omitempty" yaml:"approved,omitempty"`                 // Set only when an allowlist or denylist is given
+	Deprecated        bool        `json:"deprecated,omitempty" xml:"Deprecated,omitempty" toml:"deprecated,omitempty" yaml:"deprecated,omitempty"`         // Set when the source matches a -deprecations pattern
+	Replacement       string      `json:"replacement,omitempty" xml:"Replacement,omitempty" toml:"replacement,omitempty" yaml:"replacement,omitempty"`     // Source to migrate a deprecated module to
+	Reachable         *bool       `json:"reachable,omitempty" xml:"Reachable,omitempty" toml:"reachable,omitempty" yaml:"reachable,omitempty"`             // Set only when -check-reachability is given
+	ReachabilityError string      `json:"reachability_error,omitempty" xml:"ReachabilityError,omitempty" toml:"reachability_error,omitempty" yaml:"reachability_error,omitempty"`
+	AgeDays           *int        `json:"age_days,omitempty" xml:"AgeDays,omitempty" toml:"age_days,omitempty" yaml:"age_days,omitempty"`                                 // Set only when -check-updates is given
+	LocalVersion      string      `json:"local_version,omitempty" xml:"LocalVersion,omitempty" toml:"local_version,omitempty" yaml:"local_version,omitempty"`             // From the VERSION file of a local module, set with -follow-local-sources
+	RequiredVersion   string      `json:"required_version,omitempty" xml:"RequiredVersion,omitempty" toml:"required_version,omitempty" yaml:"required_version,omitempty"` // Terraform versions a local module requires, set with -follow-local-sources
+	Change            string      `json:"change,omitempty" xml:"Change,omitempty" toml:"change,omitempty" yaml:"change,omitempty"`                                        // added, changed, or removed, set with -only-changed-versions
+	PreviousVersion   string      `json:"previous_version,omitempty" xml:"PreviousVersion,omitempty" toml:"previous_version,omitempty" yaml:"previous_version,omitempty"` // Version in the previous SBOM, set with -only-changed-versions
+
+	// File and Line locate the module block for findings reported in SARIF output.
+	// They are not part of the SBOM itself.
+	File string `json:"-" xml:"-" toml:"-" yaml:"-"`
+	Line int    `json:"-" xml:"-" toml:"-" yaml:"-"`
+
+	// IgnoredRules are the policy rules the module block is exempted from by an
+	// sbom:ignore directive in the comments above it. They are not part of the SBOM
+	// itself.
+	IgnoredRules []string `json:"-" xml:"-" toml:"-" yaml:"-"`
+}
+
+// ProviderMap maps the provider names expected by a module to the provider
+// configurations passed to it through the providers meta-argument,
+// e.g. aws = aws.useast1.
+type ProviderMap map[string]string
+
+// String renders the mappings as a semicolon-separated list of name=config pairs.
+func (p ProviderMap) String() string {
+	var pairs []string
+	for _, name := range sortedKeys(p) {
+		pairs = append(pairs, name+"="+p[name])
+	}
+	return strings.Join(pairs, ";")
+}
+
+// MarshalXML encodes the mappings as Provider elements sorted by name,
+// since encoding/xml cannot marshal maps directly.
+func (p ProviderMap) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return marshalStringMap(e, start, "Provider", p)
+}
+
+// UnmarshalXML decodes Provider elements back into the mappings.
+func (p *ProviderMap) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	m, err := unmarshalStringMap(d, start)
+	*p = m
+	return err
+}
+
+// AttributeMap holds the literal attributes of a configuration block, keyed by attribute name.
+type AttributeMap map[string]string
+
+// String renders the attributes as a semicolon-separated list of name=value pairs in name order.
+func (a AttributeMap) String() string {
+	var pairs []string
+	for _, name := range sortedKeys(a) {
+		pairs = append(pairs, name+"="+a[name])
+	}
+	return strings.Join(pairs, ";")
+}
+
+// MarshalXML encodes the attributes as Attribute elements sorted by name.
+func (a AttributeMap) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return marshalStringMap(e, start, "Attribute", a)
+}
+
+// UnmarshalXML decodes Attribute elements back into the attributes.
+func (a *AttributeMap) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	m, err := unmarshalStringMap(d, start)
+	*a = m
+	return err
+}
+
+// ResourceCounts holds the number of managed resources a configuration declares for
+// each provider, keyed by provider name.
+type ResourceCounts map[string]int
+
+// String renders the counts as a semicolon-separated list of provider=count pairs in provider order.
+func (r ResourceCounts) String() string {
+	var pairs []string
+	for _, name := range sortedKeys(r.stringValues()) {
+		pairs = append(pairs, name+"="+strconv.Itoa(r[name]))
+	}
+	return strings.Join(pairs, ";")
+}
+
+// stringValues returns the counts formatted as decimal strings.
+func (r ResourceCounts) stringValues() map[string]string {
+	m := make(map[string]string, len(r))
+	for name, count := range r {
+		m[name] = strconv.Itoa(count)
+	}
+	return m
+}
+
+// MarshalXML encodes the counts as Provider elements sorted by provider name.
+func (r ResourceCounts) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return marshalStringMap(e, start, "Provider", r.stringValues())
+}
+
+// UnmarshalXML decodes Provider elements back into the counts.
+func (r *ResourceCounts) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	m, err := unmarshalStringMap(d, start)
+	if err != nil {
+		return err
+	}
+	counts := make(ResourceCounts, len(m))
+	for name, value := range m {
+		count, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid resource count for %s: %v", name, err)
+		}
+		counts[name] = count
+	}
+	*r = counts
+	return nil
+}
+
+// stringMapXML is the XML representation of a string map: one element per entry,
+// carrying the key in a name attribute and the value as character data.
+type stringMapXML struct {
+	Entries []struct {
+		Name  string `xml:"name,attr"`
+		Value string `xml:",chardata"`
+	} `xml:",any"`
+}
+
+// marshalStringMap encodes a string map as elements named elemName, sorted by key.
+func marshalStringMap(e *xml.Encoder, start xml.StartElement, elemName string, m map[string]string) error {
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	for _, name := range sortedKeys(m) {
+		elem := xml.StartElement{
+			Name: xml.Name{Local: elemName},
+			Attr: []xml.Attr{{Name: xml.Name{Local: "name"}, Value: name}},
+		}
+		if err := e.EncodeElement(m[name], elem); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(start.End())
+}
+
+// unmarshalStringMap decodes the elements written by marshalStringMap.
+func unmarshalStringMap(d *xml.Decoder, start xml.StartElement) (map[string]string, error) {
+	var decoded stringMapXML
+	if err := d.DecodeElement(&decoded, &start); err != nil {
+		return nil, err
+	}
+	m := make(map[string]string, len(decoded.Entries))
+	for _, entry := range decoded.Entries {
+		m[entry.Name] = entry.Value
+	}
+	return m, nil
 }
 
 // SBOM represents a Software Bill of Materials (SBOM) which contains a list of modules.
 // It is used to track the components and dependencies of the Terraform config.
 type SBOM struct {
-	XMLName xml.Name     `json:"-" xml:"SBOM"` // Root element in the XML
-	Modules []ModuleInfo `json:"modules" xml:"Modules>Module"`
+	XMLName      xml.Name       `json:"-" xml:"SBOM" toml:"-" yaml:"-"`                                                                                     // Root element in the XML
+	SerialNumber string         `json:"serial_number,omitempty" xml:"SerialNumber,omitempty" toml:"serial_number,omitempty" yaml:"serial_number,omitempty"` // urn:uuid identifying this SBOM across revisions
+	Version      int            `json:"version,omitempty" xml:"Version,omitempty" toml:"version,omitempty" yaml:"version,omitempty"`                        // Revision of the SBOM, starting at 1
+	Name         string         `json:"name,omitempty" xml:"Name,omitempty" toml:"name,omitempty" yaml:"name,omitempty"`                                    // Name of the system the SBOM describes
+	Namespace    string         `json:"namespace,omitempty" xml:"Namespace,omitempty" toml:"namespace,omitempty" yaml:"namespace,omitempty"`                // URI that qualifies the name, e.g. the organization's domain
+	Supplier     string         `json:"supplier,omitempty" xml:"Supplier,omitempty" toml:"supplier,omitempty" yaml:"supplier,omitempty"`                    // Organization that supplies the system
+	Timestamp    string         `json:"timestamp,omitempty" xml:"Timestamp,omitempty" toml:"timestamp,omitempty" yaml:"timestamp,omitempty"`                // Generation time, omitted in canonical output
+	Modules      []ModuleInfo   `json:"modules" xml:"Modules>Module" toml:"modules" yaml:"modules"`
+	Providers    []ProviderInfo `json:"providers,omitempty" xml:"Providers>Provider" toml:"providers,omitempty" yaml:"providers,omitempty"`
+	Outputs      []OutputInfo   `json:"outputs,omitempty" xml:"Outputs>Output" toml:"outputs,omitempty" yaml:"outputs,omitempty"`
+	Configs      []ConfigInfo   `json:"configs,omitempty" xml:"Configs>Config" toml:"configs,omitempty" yaml:"configs,omitempty"`
+	Warnings     []string       `json:"warnings,omitempty" xml:"Warnings>Warning" toml:"warnings,omitempty" yaml:"warnings,omitempty"` // Problems that did not stop the scan
+
+	// LoadErrors are the problems loading each config, for the report written with
+	// -errors-to. They are not part of the SBOM itself.
+	LoadErrors []loadError `json:"-" xml:"-" toml:"-" yaml:"-"`
+}
+
+// ConfigInfo holds details about a scanned Terraform configuration as a whole,
+// as opposed to the individual components it declares.
+type ConfigInfo struct {
+	Path              string               `json:"path" xml:"Path" toml:"path" yaml:"path"`
+	LineCount         int                  `json:"line_count,omitempty" xml:"LineCount,omitempty" toml:"line_count,omitempty" yaml:"line_count,omitempty"` // Non-empty lines across .tf, .tf.json, .tofu, and .tofu.json files
+	Backend           *BackendInfo         `json:"backend,omitempty" xml:"Backend,omitempty" toml:"backend,omitempty" yaml:"backend,omitempty"`
+	Moves             []MoveInfo           `json:"moves,omitempty" xml:"Moves>Move" toml:"moves,omitempty" yaml:"moves,omitempty"`
+	Imports           []ImportInfo         `json:"imports,omitempty" xml:"Imports>Import" toml:"imports,omitempty" yaml:"imports,omitempty"`
+	ResourceCounts    ResourceCounts       `json:"resource_counts,omitempty" xml:"ResourceCounts,omitempty" toml:"resource_counts,omitempty" yaml:"resource_counts,omitempty"` // Managed resources per provider, collected with -include-resources
+	Resources         []ResourceInfo       `json:"resources,omitempty" xml:"Resources>Resource" toml:"resources,omitempty" yaml:"resources,omitempty"`                         // Managed resources of the config and its local modules, collected with -include-resources
+	ProviderConfigs   []ProviderConfigInfo `json:"provider_configs,omitempty" xml:"ProviderConfigs>ProviderConfig" toml:"provider_configs,omitempty" yaml:"provider_configs,omitempty"`
+	Experiments       []string             `json:"experiments,omitempty" xml:"Experiments>Experiment" toml:"experiments,omitempty" yaml:"experiments,omitempty"`                                  // Language experiments enabled in the terraform block, collected with -include-lifecycle
+	ProviderFunctions []string             `json:"provider_functions,omitempty" xml:"ProviderFunctions>ProviderFunction" toml:"provider_functions,omitempty" yaml:"provider_functions,omitempty"` // Provider-defined functions called, such as provider::aws::arn_parse, collected with -include-lifecycle
+	CLIVersion        string               `json:"cli_version,omitempty" xml:"CLIVersion,omitempty" toml:"cli_version,omitempty" yaml:"cli_version,omitempty"`                                    // Terraform CLI version pinned in a .terraform-version file, collected with -metrics
+	RequiredVersion   string               `json:"required_version,omitempty" xml:"RequiredVersion,omitempty" toml:"required_version,omitempty" yaml:"required_version,omitempty"`                // Terraform versions the config's required_version settings accept, collected with -tf-compat
+}
+
+// ResourceInfo identifies a managed resource block declared by a configuration or by
+// one of the local modules it calls.
+type ResourceInfo struct {
+	Type     string `json:"type" xml:"Type" toml:"type" yaml:"type"`
+	Name     string `json:"name" xml:"Name" toml:"name" yaml:"name"`
+	Provider string `json:"provider" xml:"Provider" toml:"provider" yaml:"provider"`                                 // Local name of the provider managing the resource
+	Module   string `json:"module,omitempty" xml:"Module,omitempty" toml:"module,omitempty" yaml:"module,omitempty"` // Address of the declaring module, such as module.network; empty for the config itself
+}
+
+// Address returns the address of the resource within its config, such as
+// module.network.aws_subnet.private.
+func (r ResourceInfo) Address() string {
+	if r.Module == "" {
+		return r.Type + "." + r.Name
+	}
+	return r.Module + "." + r.Type + "." + r.Name
+}
+
+// ProviderConfigInfo identifies a provider block, which configures an instance of a
+// provider. Only the name and alias are recorded, since the remaining attributes
+// often hold credentials.
+type ProviderConfigInfo struct {
+	Name  string `json:"name" xml:"Name" toml:"name" yaml:"name"`
+	Alias string `json:"alias,omitempty" xml:"Alias,omitempty" toml:"alias,omitempty" yaml:"alias,omitempty"` // Empty for the default configuration
+}
+
+// MoveInfo records a moved block, which tells Terraform that an object was renamed
+// or moved to a different address.
+type MoveInfo struct {
+	From string `json:"from" xml:"From" toml:"from" yaml:"from"`
+	To   string `json:"to" xml:"To" toml:"to" yaml:"to"`
+}
+
+// ImportInfo records an import block, which adopts an existing infrastructure object
+// into Terraform state at the given address.
+type ImportInfo struct {
+	To       string `json:"to" xml:"To" toml:"to" yaml:"to"`
+	ID       string `json:"id" xml:"ID" toml:"id" yaml:"id"`
+	Provider string `json:"provider,omitempty" xml:"Provider,omitempty" toml:"provider,omitempty" yaml:"provider,omitempty"`
+}
+
+// BackendInfo describes where a configuration stores its state, as declared by the
+// backend or cloud block inside its terraform block. Credentials are redacted.
+type BackendInfo struct {
+	Type   string       `json:"type" xml:"Type" toml:"type" yaml:"type"`
+	Config AttributeMap `json:"config,omitempty" xml:"Config,omitempty" toml:"config,omitempty" yaml:"config,omitempty"`
+}
+
+// ProviderInfo represents a provider required by a Terraform configuration.
+type ProviderInfo struct {
+	Name              string `json:"name" xml:"Name" toml:"name" yaml:"name"`                                                                                                // Local name used in the configuration
+	Source            string `json:"source,omitempty" xml:"Source,omitempty" toml:"source,omitempty" yaml:"source,omitempty"`                                                // e.g. hashicorp/aws
+	VersionConstraint string `json:"version_constraint,omitempty" xml:"VersionConstraint,omitempty" toml:"version_constraint,omitempty" yaml:"version_constraint,omitempty"` // Declared in required_providers
+	LockedVersion     string `json:"locked_version,omitempty" xml:"LockedVersion,omitempty" toml:"locked_version,omitempty" yaml:"locked_version,omitempty"`                 // From .terraform.lock.hcl when the constraint is a range
+	PURL              string `json:"purl,omitempty" xml:"PURL,omitempty" toml:"purl,omitempty" yaml:"purl,omitempty"`                                                        // Package URL, such as pkg:terraform/hashicorp/aws@5.31.0
+	Config            string `json:"config" xml:"ConfigPath" toml:"config" yaml:"config"`
+
+	// File and Line locate the required_providers entry for findings reported in
+	// SARIF output. They are not part of the SBOM itself.
+	File string `json:"-" xml:"-" toml:"-" yaml:"-"`
+	Line int    `json:"-" xml:"-" toml:"-" yaml:"-"`
+}
+
+// OutputInfo represents an output value declared by a Terraform configuration.
+type OutputInfo struct {
+	Name        string `json:"name" xml:"Name" toml:"name" yaml:"name"`
+	Description string `json:"description,omitempty" xml:"Description,omitempty" toml:"description,omitempty" yaml:"description,omitempty"`
+	Sensitive   bool   `json:"sensitive" xml:"Sensitive" toml:"sensitive" yaml:"sensitive"`
+	Config      string `json:"config" xml:"ConfigPath" toml:"config" yaml:"config"`
+}
+
+// scanOptions controls which optional details are collected while generating an SBOM.
+type scanOptions struct {
+	IncludeOutputs         bool  // Catalog the output values declared by the configuration
+	Terragrunt             bool  // Also record the sources declared in terragrunt.hcl files
+	Metrics                bool  // Collect per-config metrics such as line counts
+	IncludeBackend         bool  // Record the backend each configuration stores its state in
+	IncludeLifecycle       bool  // Record moved and import blocks
+	IncludeProviderConfigs bool  // Record the name and alias of each provider block
+	IncludeResources       bool  // Count the managed resources of each provider
+	RequiredVersions       bool  // Record the Terraform versions each configuration accepts
+	FromManifest           bool  // Read modules from the .terraform/modules/modules.json manifest instead of the module calls
+	FlattenNested          bool  // Also record the module calls of local modules, with their call path
+	FollowLocalSources     bool  // Record the version metadata local modules declare about themselves
+	Explain                bool  // Record how the version of each module was derived
+	NoVersionHeuristics    bool  // Only record versions given by the version argument of module calls
+	Strict                 bool  // Fail on configuration errors instead of recording what could be parsed
+	MaxFileSize            int64 // Skip configuration files larger than this many bytes; zero loads every file
+	SkipFailedConfigs      bool  // In a recursive scan, record configs that fail to load as load errors and go on with the rest
+
+	PrivateRegistryHosts []string             // Registry hostnames, including their subdomains, that are internal
+	Variables            map[string]cty.Value // Input variable values for module sources that reference variables; nil leaves them unresolved
+
+	APIClient *apiClient // Shared client for registry and GitHub API calls
 }
 
 // generateSBOM generates a Software Bill of Materials (SBOM) for a given Terraform configuration.
 // It loads the Terraform module from the specified configuration path, extracts module information,
 // and constructs an SBOM containing details about each module call.
-func generateSBOM(configPath string) (*SBOM, error) {
-	module, diag := tfconfig.LoadModule(configPath)
-	if diag.HasErrors() {
+// The scan stops with an error as soon as ctx is cancelled or its deadline expires.
+func generateSBOM(ctx context.Context, configPath string, opts scanOptions) (*SBOM, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("scan aborted: %w", err)
+	}
+
+	module, diag := loadModule(configPath, opts.MaxFileSize)
+	if diag.HasErrors() && (opts.Strict || module == nil) {
 		return nil, fmt.Errorf("failed to load Terraform module: %v", diag.Err())
 	}
 
 	var sbom SBOM
+	sbom.Warnings = oversizedFiles(configPath, opts.MaxFileSize)
+
+	rawFiles := parseRawFiles(configPath, opts.MaxFileSize)
+
+	var resolved map[tfconfig.SourcePos]bool
+	if opts.Variables != nil {
+		resolved = resolveModuleCalls(module, rawFiles, opts.Variables)
+	}
+
+	// Files that fail to parse are skipped by LoadModule, so the rest of the
+	// configuration is still recorded and the problems are kept as warnings.
+	for _, d := range diag {
+		if d.Pos != nil && resolved[*d.Pos] {
+			continue
+		}
+		sbom.Warnings = append(sbom.Warnings, diagnosticString(configPath, d))
+		sbom.LoadErrors = append(sbom.LoadErrors, newLoadError(configPath, d))
+	}
+
+	providerMappings := moduleProviderMappings(rawFiles)
+	dependsOn := moduleDependsOn(rawFiles)
+	ignored := moduleIgnoreDirectives(rawFiles)
+
+	if opts.FromManifest {
+		modules, err := readModuleManifest(configPath, providerMappings, dependsOn)
+		if err != nil {
+			return nil, err
+		}
+		sbom.Warnings = append(sbom.Warnings, setIgnoredRules(modules, ignored)...)
+		sbom.Modules = append(sbom.Modules, modules...)
+	} else {
+		for _, modCall := range module.ModuleCalls {
+			if err := ctx.Err(); err != nil {
+				return nil, fmt.Errorf("scan aborted: %w", err)
+			}
+
+			sbom.Modules = append(sbom.Modules, newModuleInfo(modCall, configPath, providerMappings, dependsOn))
+		}
+		sbom.Warnings = append(sbom.Warnings, setIgnoredRules(sbom.Modules, ignored)...)
+
+		if opts.FlattenNested {
+			modules, warnings, err := flattenNestedModules(ctx, configPath, sbom.Modules, opts.MaxFileSize)
+			if err != nil {
+				return nil, err
+			}
+			sbom.Modules = modules
+			sbom.Warnings = append(sbom.Warnings, warnings...)
+		}
+	}
+
+	if opts.FollowLocalSources {
+		sbom.Warnings = append(sbom.Warnings, followLocalSources(sbom.Modules, opts.MaxFileSize)...)
+	}
+
+	setRegistries(sbom.Modules, opts.PrivateRegistryHosts)
+
+	sbom.Providers = extractProviders(module, configPath, rawFiles)
+
+	if opts.Metrics {
+		setModuleDocs(sbom.Modules)
+	}
+
+	if opts.IncludeOutputs {
+		sbom.Outputs = extractOutputs(module, configPath)
+	}
 
-	for _, modCall := range module.ModuleCalls {
-		modInfo := ModuleInfo{
-			Name:   modCall.Name,
-			Source: modCall.Source,
-			Config: configPath,
+	if opts.Terragrunt {
+		modules, err := parseTerragrunt(configPath)
+		if err != nil {
+			return nil, err
 		}
+		sbom.Modules = append(sbom.Modules, modules...)
+	}
+
+	if opts.NoVersionHeuristics {
+		dropVersionHeuristics(sbom.Modules)
+	}
+	if opts.Explain {
+		setVersionSources(sbom.Modules)
+	}
+
+	setNormalizedVersions(sbom.Modules)
+	setOrganizations(sbom.Modules)
+	setInsecure(sbom.Modules)
+
+	sbom.Warnings = append(sbom.Warnings, credentialWarnings(module, configPath)...)
+	redactSources(&sbom)
+	setNormalizedSources(sbom.Modules)
+	setPURLs(&sbom)
 
-		modInfo.Version = extractVersion(modCall)
+	if opts.Metrics || opts.IncludeBackend || opts.IncludeLifecycle || opts.IncludeProviderConfigs || opts.IncludeResources || opts.RequiredVersions {
+		config := ConfigInfo{Path: configPath}
+		if opts.Metrics {
+			lineCount, err := countLines(configFiles(configPath))
+			if err != nil {
+				return nil, err
+			}
+			config.LineCount = lineCount
 
-		sbom.Modules = append(sbom.Modules, modInfo)
+			config.CLIVersion, err = readVersionFile(filepath.Join(configPath, terraformVersionFile))
+			if err != nil {
+				return nil, err
+			}
+		}
+		if opts.IncludeBackend {
+			config.Backend = parseBackend(rawFiles)
+		}
+		if opts.IncludeLifecycle {
+			config.Moves, config.Imports = parseLifecycleBlocks(rawFiles)
+			config.Experiments = parseExperiments(rawFiles)
+			config.ProviderFunctions = providerFunctionCalls(rawFiles)
+		}
+		if opts.IncludeProviderConfigs {
+			config.ProviderConfigs = parseProviderBlocks(rawFiles)
+		}
+		if opts.IncludeResources {
+			config.ResourceCounts = countResources(module)
+			config.Resources = collectResources(module, configPath, opts.MaxFileSize)
+		}
+		if opts.RequiredVersions {
+			config.RequiredVersion = strings.Join(module.RequiredCore, ", ")
+		}
+		sbom.Configs = append(sbom.Configs, config)
 	}
 
+	sortSBOM(&sbom)
+
 	return &sbom, nil
 }
 
-// extractVersion extracts the version of a Terraform module from a given ModuleCall.
-func extractVersion(modCall *tfconfig.ModuleCall) string {
-	if modCall.Version != "" {
-		return modCall.Version
+// newModuleInfo builds the entry for a module call declared in the given config.
+func newModuleInfo(modCall *tfconfig.ModuleCall, configPath string, providerMappings map[string]map[string]string, dependsOn map[string][]string) ModuleInfo {
+	source, subdir := splitSubdir(modCall.Source)
+
+	return ModuleInfo{
+		Name:             modCall.Name,
+		Source:           source,
+		Subdir:           subdir,
+		SourceType:       sourceType(modCall.Source),
+		Version:          extractVersion(modCall),
+		Config:           configPath,
+		ProviderMappings: providerMappings[modCall.Name],
+		DependsOn:        dependsOn[modCall.Name],
+		File:             modCall.Pos.Filename,
+		Line:             modCall.Pos.Line,
 	}
+}
+
+// sortSBOM orders the SBOM entries by config path and name so that repeated runs
+// over the same configuration produce identical output. Flattened nested modules
+// are ordered by their call path instead, keeping each module next to its callers.
+func sortSBOM(sbom *SBOM) {
+	sort.SliceStable(sbom.Modules, func(i, j int) bool {
+		a, b := sbom.Modules[i], sbom.Modules[j]
+		if a.Config != b.Config {
+			return a.Config < b.Config
+		}
+		if a.Path != "" && b.Path != "" {
+			return a.Path < b.Path
+		}
+		return a.Name < b.Name
+	})
 
-	source := modCall.Source
-	if strings.Contains(source, "?ref=") {
-		parts := strings.Split(source, "?ref=")
-		if len(parts) > 1 {
-			return parts[1]
+	sort.SliceStable(sbom.Providers, func(i, j int) bool {
+		a, b := sbom.Providers[i], sbom.Providers[j]
+		if a.Config != b.Config {
+			return a.Config < b.Config
 		}
+		return a.Name < b.Name
+	})
+
+	sort.SliceStable(sbom.Outputs, func(i, j int) bool {
+		a, b := sbom.Outputs[i], sbom.Outputs[j]
+		if a.Config != b.Config {
+			return a.Config < b.Config
+		}
+		return a.Name < b.Name
+	})
+
+	sort.SliceStable(sbom.Configs, func(i, j int) bool {
+		return sbom.Configs[i].Path < sbom.Configs[j].Path
+	})
+
+	sort.Strings(sbom.Warnings)
+}
+
+// extractOutputs collects the output values declared by a Terraform module.
+func extractOutputs(module *tfconfig.Module, configPath string) []OutputInfo {
+	var outputs []OutputInfo
+	for _, output := range module.Outputs {
+		outputs = append(outputs, OutputInfo{
+			Name:        output.Name,
+			Description: output.Description,
+			Sensitive:   output.Sensitive,
+			Config:      configPath,
+		})
 	}
+	return outputs
+}
 
-	if strings.HasPrefix(source, "./") || strings.HasPrefix(source, "../") {
-		return "local"
+// diagnosticString formats a diagnostic reported while loading a configuration,
+// prefixed with the file and line it refers to when known.
+func diagnosticString(configPath string, d tfconfig.Diagnostic) string {
+	location := configPath
+	if d.Pos != nil {
+		location = fmt.Sprintf("%s:%d", d.Pos.Filename, d.Pos.Line)
 	}
 
-	return "N/A"
+	message := fmt.Sprintf("%s: %s", location, d.Summary)
+	if d.Detail != "" {
+		message += "; " + d.Detail
+	}
+	return message
 }
 
-// printSBOM prints the Software Bill of Materials (SBOM) for a given Terraform configuration.
-// It outputs the configuration path, module name, source, and version for each module in the SBOM.
-func printSBOM(sbom *SBOM) {
-	fmt.Println("Software Bill of Materials (SBOM) for Terraform configuration")
-	fmt.Println("-----------------------------------------------------------")
-	for _, mod := range sbom.Modules {
-		fmt.Printf("Config Path: %s\n", mod.Config)
-		fmt.Printf("Module Name: %s\n", mod.Name)
-		fmt.Printf("Source: %s\n", mod.Source)
-		fmt.Printf("Version: %s\n\n", mod.Version)
+// extractVersion extracts the version of a Terraform module from a given ModuleCall.
+// The ref query parameter of a source, such as ?ref=v1.2.0, takes precedence over
+// the version argument, since it selects the code that is fetched while a version
+// argument only applies to registry sources. The ref is returned in full, so
+// pre-release tags such as v1.0.0-rc.1, build metadata such as v1.0.0+build.5, and
+// pseudo-versions such as v0.0.0-20210101000000-abcdef123456 are kept intact.
+func extractVersion(modCall *tfconfig.ModuleCall) string {
+	if ref := sourceQueryParam(modCall.Source, "ref"); ref != "" {
+		return ref
 	}
-}
 
-// writeSBOMToCSV writes the Software Bill of Materials (SBOM) to a CSV file.
-// If the file does not exist, it creates a new one and writes the header.
-// If the file exists, it appends the SBOM data to the file.
-func writeSBOMToCSV(sbom *SBOM, outputPath string) error {
-	fileExists := fileExists(outputPath)
+	if modCall.Version != "" {
+		return modCall.Version
+	}
 
-	file, err := os.OpenFile(outputPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	if err != nil {
-		return fmt.Errorf("failed to open CSV file: %v", err)
+	if isLocalSource(modCall.Source) {
+		return localVersion
 	}
-	defer file.Close()
 
-	writer := csv.NewWriter(file)
-	defer writer.Flush()
+	return unknownVersion
+}
 
-	if !fileExists {
-		err = writer.Write([]string{"Config Path", "Module Name", "Source", "Version"})
-		if err != nil {
-			return fmt.Errorf("failed to write CSV header: %v", err)
+// dropVersionHeuristics keeps only the versions given by the version argument of each
+// module call, for -no-version-heuristics. Versions extractVersion inferred, from the
+// ref of a source or the local placeholder, are cleared, as are the N/A placeholders
+// of unpinned modules and the versions of Terragrunt sources, which only come from
+// their source address. Terraform rejects a version argument on sources other than
+// registry modules, which cannot have a ref, so the versions a version argument gave
+// are exactly those versionSource attributes to it.
+func dropVersionHeuristics(modules []ModuleInfo) {
+	for i := range modules {
+		mod := &modules[i]
+		if mod.SourceType == sourceTypeTerragrunt || versionSource(*mod) != versionSourceAttribute {
+			mod.Version = ""
 		}
 	}
+}
 
-	for _, mod := range sbom.Modules {
-		err = writer.Write([]string{mod.Config, mod.Name, mod.Source, mod.Version})
-		if err != nil {
-			return fmt.Errorf("failed to write CSV record: %v", err)
+// Versions extractVersion records for modules that are not pinned. Checks rely on
+// them, so -unknown-version-label and -local-version-label only replace them in the
+// written SBOM, through relabelVersions.
+const (
+	unknownVersion = "N/A"
+	localVersion   = "local"
+)
+
+// relabelVersions replaces the unknownVersion and localVersion placeholders of every
+// module with the given labels, for consumers that expect other placeholders.
+func relabelVersions(modules []ModuleInfo, unknownLabel, localLabel string) {
+	relabel := func(mod ModuleInfo, version string) string {
+		switch {
+		case version == unknownVersion:
+			return unknownLabel
+		case version == localVersion && mod.SourceType == sourceTypeLocal:
+			return localLabel
 		}
+		return version
 	}
 
-	fmt.Printf("SBOM successfully written to %s\n", outputPath)
-	return nil
+	for i := range modules {
+		modules[i].Version = relabel(modules[i], modules[i].Version)
+		modules[i].OriginalVersion = relabel(modules[i], modules[i].OriginalVersion)
+	}
 }
 
-// writeSBOMToJSON writes the SBOM to a JSON file
-func writeSBOMToJSON(sbom *SBOM, outputPath string) error {
-	file, err := os.Create(outputPath)
-	if err != nil {
-		return fmt.Errorf("failed to create JSON file: %v", err)
+// Provenance notes recorded in ModuleInfo.VersionSource by -explain, one for each way
+// extractVersion derives a version and one for versions replaced by -version-overrides.
+const (
+	versionSourceAttribute = "version-attribute"    // The version argument of the module call
+	versionSourceRefQuery  = "ref-query"            // The ref query parameter of the source
+	versionSourceLocal     = "local-path-heuristic" // A ./ or ../ source, which has no version
+	versionSourceUnknown   = "unknown"              // Nothing pins the module, so it is N/A
+	versionSourceOverride  = "version-override"     // Replaced by -version-overrides
+)
+
+// versionSource explains which branch of extractVersion produced the version of a
+// module, following the same precedence. A module without a version, such as one whose
+// ref was ignored by -no-version-heuristics, is unknown.
+func versionSource(mod ModuleInfo) string {
+	switch {
+	case mod.Version == "" || mod.Version == unknownVersion:
+		return versionSourceUnknown
+	case sourceQueryParam(mod.Source, "ref") != "":
+		return versionSourceRefQuery
+	case isLocalSource(mod.Source) && mod.Version == localVersion:
+		return versionSourceLocal
 	}
-	defer file.Close()
+	return versionSourceAttribute
+}
 
-	encoder := json.NewEncoder(file)
-	encoder.SetIndent("", "  ")
+// setVersionSources records how the version of each module was derived.
+func setVersionSources(modules []ModuleInfo) {
+	for i := range modules {
+		modules[i].VersionSource = versionSource(modules[i])
+	}
+}
 
-	err = encoder.Encode(sbom)
-	if err != nil {
-		return fmt.Errorf("failed to write JSON file: %v", err)
+// sourceQueryParam returns the value of a query parameter of a source address, or an
+// empty string if it has none. Values are not unescaped, so a + in a version such
+// as v1.0.0+build.5 is kept rather than decoded as a space.
+func sourceQueryParam(source string, key string) string {
+	_, query, ok := strings.Cut(source, "?")
+	if !ok {
+		return ""
 	}
 
-	fmt.Printf("SBOM successfully written to %s\n", outputPath)
-	return nil
+	for _, param := range strings.Split(query, "&") {
+		if name, value, ok := strings.Cut(param, "="); ok && name == key {
+			return value
+		}
+	}
+	return ""
 }
 
-// writeSBOMToXML writes the SBOM to an XML file
-func writeSBOMToXML(sbom *SBOM, outputPath string) error {
-	file, err := os.Create(outputPath)
-	if err != nil {
-		return fmt.Errorf("failed to create XML file: %v", err)
+// printSBOM prints the Software Bill of Materials (SBOM) for a given Terraform configuration to w.
+// It outputs the configuration path, module name, source, and version for each module in the SBOM.
+// With color enabled, source types are highlighted, unpinned versions are shown in red,
+// and local modules are dimmed.
+func printSBOM(w io.Writer, sbom *SBOM, color bool) {
+	c := colorizer{enabled: color}
+
+	fmt.Fprintln(w, "Software Bill of Materials (SBOM) for Terraform configuration")
+	fmt.Fprintln(w, "-----------------------------------------------------------")
+	for _, mod := range sbom.Modules {
+		// Local modules are part of the repository rather than dependencies, so
+		// they are dimmed as a whole.
+		field := func(name, value string) {
+			line := name + ": " + value
+			if mod.SourceType == sourceTypeLocal {
+				line = c.paint(ansiDim, line)
+			}
+			fmt.Fprintln(w, line)
+		}
+
+		sourceType, version := mod.SourceType, mod.Version
+		if mod.SourceType != sourceTypeLocal {
+			sourceType = c.paint(ansiCyan, sourceType)
+			if isUnpinned(mod) {
+				version = c.paint(ansiRed, version)
+			}
+		}
+
+		field("Config Path", mod.Config)
+		field("Module Name", mod.Name)
+		if mod.Path != "" {
+			field("Call Path", mod.Path)
+		}
+		if mod.Address != "" {
+			field("Address", mod.Address)
+		}
+		field("Source", mod.Source)
+		if mod.NormalizedSource != "" {
+			field("Normalized Source", mod.NormalizedSource)
+		}
+		if mod.Subdir != "" {
+			field("Subdir", mod.Subdir)
+		}
+		field("Source Type", sourceType)
+		field("Version", version)
+		if mod.VersionSource != "" {
+			field("Version Source", mod.VersionSource)
+		}
+		if mod.OriginalVersion != "" {
+			field("Original Version", mod.OriginalVersion)
+		}
+		if mod.PURL != "" {
+			field("PURL", mod.PURL)
+		}
+		if mod.Registry != "" {
+			registry := mod.Registry
+			if mod.Private {
+				registry += " (private)"
+			}
+			field("Registry", registry)
+		}
+		if mod.Insecure {
+			field("Insecure", c.paint(ansiRed, "true"))
+		}
+		if mod.Organization != "" {
+			field("Organization", mod.Organization)
+		}
+		if len(mod.ProviderMappings) > 0 {
+			field("Providers", mod.ProviderMappings.String())
+		}
+		if len(mod.DependsOn) > 0 {
+			field("Depends On", strings.Join(mod.DependsOn, ", "))
+		}
+		if mod.Description != "" {
+			field("Description", mod.Description)
+		}
+		if mod.HasReadme != nil {
+			field("Has README", strconv.FormatBool(*mod.HasReadme))
+		}
+		if mod.Approved != nil {
+			field("Approved", strconv.FormatBool(*mod.Approved))
+		}
+		if mod.Deprecated {
+			deprecated := c.paint(ansiRed, "true")
+			if mod.Replacement != "" {
+				deprecated += " (replace with " + mod.Replacement + ")"
+			}
+			field("Deprecated", deprecated)
+		}
+		if mod.Reachable != nil {
+			reachable := strconv.FormatBool(*mod.Reachable)
+			if mod.ReachabilityError != "" {
+				reachable += " (" + mod.ReachabilityError + ")"
+			}
+			field("Reachable", reachable)
+		}
+		if mod.AgeDays != nil {
+			field("Age (days)", strconv.Itoa(*mod.AgeDays))
+		}
+		if mod.LocalVersion != "" {
+			field("Local Version", mod.LocalVersion)
+		}
+		if mod.RequiredVersion != "" {
+			field("Required Version", mod.RequiredVersion)
+		}
+		if mod.Change != "" {
+			field("Change", mod.Change)
+		}
+		if mod.PreviousVersion != "" {
+			field("Previous Version", mod.PreviousVersion)
+		}
+		fmt.Fprintln(w)
 	}
-	defer file.Close()
 
-	encoder := xml.NewEncoder(file)
-	encoder.Indent("", "  ")
+	for _, provider := range sbom.Providers {
+		fmt.Fprintf(w, "Config Path: %s\n", provider.Config)
+		fmt.Fprintf(w, "Provider Name: %s\n", provider.Name)
+		if provider.Source != "" {
+			fmt.Fprintf(w, "Source: %s\n", provider.Source)
+		}
+		if provider.VersionConstraint != "" {
+			fmt.Fprintf(w, "Version Constraint: %s\n", provider.VersionConstraint)
+		}
+		if provider.LockedVersion != "" {
+			fmt.Fprintf(w, "Locked Version: %s\n", provider.LockedVersion)
+		}
+		if provider.PURL != "" {
+			fmt.Fprintf(w, "PURL: %s\n", provider.PURL)
+		}
+		fmt.Fprintln(w)
+	}
 
-	err = encoder.Encode(sbom)
-	if err != nil {
-		return fmt.Errorf("failed to write XML file: %v", err)
+	for _, output := range sbom.Outputs {
+		fmt.Fprintf(w, "Config Path: %s\n", output.Config)
+		fmt.Fprintf(w, "Output Name: %s\n", output.Name)
+		if output.Description != "" {
+			fmt.Fprintf(w, "Description: %s\n", output.Description)
+		}
+		fmt.Fprintf(w, "Sensitive: %t\n\n", output.Sensitive)
 	}
 
-	fmt.Printf("SBOM successfully written to %s\n", outputPath)
-	return nil
+	for _, config := range sbom.Configs {
+		fmt.Fprintf(w, "Config Path: %s\n", config.Path)
+		if config.LineCount > 0 {
+			fmt.Fprintf(w, "Line Count: %d\n", config.LineCount)
+		}
+		if config.CLIVersion != "" {
+			fmt.Fprintf(w, "CLI Version: %s\n", config.CLIVersion)
+		}
+		if config.RequiredVersion != "" {
+			fmt.Fprintf(w, "Required Version: %s\n", config.RequiredVersion)
+		}
+		if config.Backend != nil {
+			fmt.Fprintf(w, "Backend: %s\n", config.Backend.Type)
+			if len(config.Backend.Config) > 0 {
+				fmt.Fprintf(w, "Backend Config: %s\n", config.Backend.Config)
+			}
+		}
+		for _, move := range config.Moves {
+			fmt.Fprintf(w, "Moved: %s -> %s\n", move.From, move.To)
+		}
+		for _, imp := range config.Imports {
+			fmt.Fprintf(w, "Import: %s = %s\n", imp.To, imp.ID)
+		}
+		for _, provider := range config.ProviderConfigs {
+			fmt.Fprintf(w, "Provider Config: %s\n", provider)
+		}
+		if len(config.Experiments) > 0 {
+			fmt.Fprintf(w, "Experiments: %s\n", strings.Join(config.Experiments, ", "))
+		}
+		if len(config.ProviderFunctions) > 0 {
+			fmt.Fprintf(w, "Provider Functions: %s\n", strings.Join(config.ProviderFunctions, ", "))
+		}
+		if len(config.ResourceCounts) > 0 {
+			fmt.Fprintf(w, "Resources: %s\n", config.ResourceCounts)
+		}
+		for _, resource := range config.Resources {
+			fmt.Fprintf(w, "Resource: %s (%s)\n", resource.Address(), resource.Provider)
+		}
+		fmt.Fprintln(w)
+	}
 }
 
-// fileExists checks if a file exists at the given file path.
-func fileExists(filePath string) bool {
-	_, err := os.Stat(filePath)
-	return err == nil
+// isUnpinned reports whether a module is not pinned to a specific version.
+func isUnpinned(mod ModuleInfo) bool {
+	return mod.Version == "" || mod.Version == unknownVersion
 }
 
-func main() {
-	verbose := flag.Bool("v", false, "Enable verbose output")
-	outputFormat := flag.String("output", "csv", "Specify output format: csv, json, or xml. Defaults to csv")
-	flag.Parse()
-
-	if flag.NArg() < 2 {
-		log.Fatalf("Usage: %s <path-to-terraform-config> <output-file>", filepath.Base(os.Args[0]))
-	}
+// csvHeader lists the CSV columns used for module records.
+var csvHeader = fieldHeaders(moduleFields)
 
-	configPath := flag.Arg(0)
-	outputPath := flag.Arg(1)
+// csvRecord returns the CSV record for a module, with all columns of csvHeader.
+func csvRecord(mod ModuleInfo) []string {
+	return fieldRecord(moduleFields, mod)
+}
 
-	sbom, err := generateSBOM(configPath)
-	if err != nil {
-		log.Fatalf("Error generating SBOM: %v", err)
+// optionalBool formats a bool that is only set by some options, using an empty string when it is unset.
+func optionalBool(b *bool) string {
+	if b == nil {
+		return ""
 	}
+	return strconv.FormatBool(*b)
+}
 
-	if *verbose {
-		printSBOM(sbom)
+// optionalInt formats an int that is only set by some options, using an empty string when it is unset.
+func optionalInt(i *int) string {
+	if i == nil {
+		return ""
 	}
+	return strconv.Itoa(*i)
+}
 
-	switch strings.ToLower(*outputFormat) {
-	case "csv":
-		err = writeSBOMToCSV(sbom, outputPath)
-	case "json":
-		err = writeSBOMToJSON(sbom, outputPath)
-	case "xml":
-		err = writeSBOMToXML(sbom, outputPath)
-	default:
-		log.Fatalf("Unsupported output format: %s. Supported formats are: csv, json, xml", *outputFormat)
+// csvProviderHeader lists the CSV columns used for provider records, which follow the module records.
+var csvProviderHeader = []string{"Config Path", "Provider Name", "Source", "Version Constraint", "Locked Version", "PURL"}
+
+// csvOutputHeader lists the CSV columns used for output records, which follow the module records.
+var csvOutputHeader = []string{"Config Path", "Output Name", "Description", "Sensitive"}
+
+// csvConfigHeader lists the CSV columns used for per-config records, which follow the output records.
+var csvConfigHeader = []string{"Config Path", "Line Count", "Backend", "Backend Config", "Moves", "Imports", "Provider Configs", "Resource Counts", "Experiments", "Provider Functions", "CLI Version", "Resources", "Required Version"}
+
+// writeSBOMToCSV writes the Software Bill of Materials (SBOM) to a CSV file.
+// If the file does not exist, it creates a new one and writes the header.
+// If the file exists, it appends the SBOM data to the file.
+// Outputs and per-config details, when present, are written as separate sections with
+// their own header rows, padded to the width of the module records so the file stays rectangular.
+func writeSBOMToCSV(sbom *SBOM, outputPath string) error {
+	return writeCSV(sbom, outputPath, moduleFields, nil)
+}
+
+// writeCSV writes the SBOM to a CSV file with the given module columns. A new file
+// starts with the metadata comment row, if any. An existing file is only appended to
+// if its header has the same columns, so rows never end up under the wrong column.
+func writeCSV(sbom *SBOM, outputPath string, fields []moduleField, metadata *csvMetadata) error {
+	fileExists := fileExists(outputPath)
+	if fileExists {
+		header, err := readCSVHeader(outputPath)
+		if err != nil {
+			return err
+		}
+		if header != nil && !slices.Equal(header, fieldHeaders(fields)) {
+			return fmt.Errorf("cannot append to %s: its columns differ from the ones being written, as it was written by another version or with other -fields; write to a new file or pass -update", outputPath)
+		}
 	}
 
+	file, err := os.OpenFile(outputPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
 	if err != nil {
-		log.Fatalf("Error writing SBOM: %v", err)
+		return fmt.Errorf("failed to open CSV file: %v", err)
 	}
+	defer file.Close()
+
+	err = writeCSVRecords(file, sbom, fields, !fileExists, metadata)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("SBOM successfully written to %s\n", outputPath)
+	return nil
+}
+
+// replaceCSV writes the SBOM to a CSV file with the given module columns, replacing
+// any existing file atomically instead of appending to it.
+func replaceCSV(sbom *SBOM, outputPath string, fields []moduleField, metadata *csvMetadata) error {
+	err := writeFileAtomic(outputPath, func(w io.Writer) error {
+		return writeCSVRecords(w, sbom, fields, true, metadata)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write CSV file: %v", err)
+	}
+
+	fmt.Printf("SBOM successfully written to %s\n", outputPath)
+	return nil
+}
+
+// readCSVHeader returns the module header row of an existing CSV file, skipping the
+// metadata comment row, or nil if the file is empty.
+func readCSVHeader(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open CSV file: %v", err)
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return nil, fmt.Errorf("failed to read CSV file: %v", err)
+		}
+		if strings.HasPrefix(line, "#") && err == nil {
+			continue
+		}
+		if strings.HasPrefix(line, "#") || strings.TrimSpace(line) == "" {
+			return nil, nil
+		}
+		header, parseErr := csv.NewReader(strings.NewReader(line)).Read()
+		if parseErr != nil {
+			return nil, fmt.Errorf("failed to read CSV header of %s: %v", path, parseErr)
+		}
+		return header, nil
+	}
+}
+
+// writeCSVRecords writes the CSV records of the SBOM to w, starting with the header
+// row of the module records if header is set. The metadata comment row, if any, is
+// written before the header.
+func writeCSVRecords(w io.Writer, sbom *SBOM, fields []moduleField, header bool, metadata *csvMetadata) error {
+	writer := csv.NewWriter(w)
+	var err error
+
+	if header && metadata != nil {
+		_, err = fmt.Fprintln(w, metadata)
+		if err != nil {
+			return fmt.Errorf("failed to write CSV metadata: %v", err)
+		}
+	}
+
+	if header {
+		err = writer.Write(fieldHeaders(fields))
+		if err != nil {
+			return fmt.Errorf("failed to write CSV header: %v", err)
+		}
+	}
+
+	for _, mod := range sbom.Modules {
+		err = writer.Write(fieldRecord(fields, mod))
+		if err != nil {
+			return fmt.Errorf("failed to write CSV record: %v", err)
+		}
+	}
+
+	if len(sbom.Providers) > 0 {
+		err = writer.Write(padCSVRecord(csvProviderHeader, len(fields)))
+		if err != nil {
+			return fmt.Errorf("failed to write CSV header: %v", err)
+		}
+	}
+
+	for _, provider := range sbom.Providers {
+		record := []string{provider.Config, provider.Name, provider.Source, provider.VersionConstraint, provider.LockedVersion, provider.PURL}
+		err = writer.Write(padCSVRecord(record, len(fields)))
+		if err != nil {
+			return fmt.Errorf("failed to write CSV record: %v", err)
+		}
+	}
+
+	if len(sbom.Outputs) > 0 {
+		err = writer.Write(padCSVRecord(csvOutputHeader, len(fields)))
+		if err != nil {
+			return fmt.Errorf("failed to write CSV header: %v", err)
+		}
+	}
+
+	for _, output := range sbom.Outputs {
+		record := []string{output.Config, output.Name, output.Description, strconv.FormatBool(output.Sensitive)}
+		err = writer.Write(padCSVRecord(record, len(fields)))
+		if err != nil {
+			return fmt.Errorf("failed to write CSV record: %v", err)
+		}
+	}
+
+	if len(sbom.Configs) > 0 {
+		err = writer.Write(padCSVRecord(csvConfigHeader, len(fields)))
+		if err != nil {
+			return fmt.Errorf("failed to write CSV header: %v", err)
+		}
+	}
+
+	for _, config := range sbom.Configs {
+		record := []string{config.Path, strconv.Itoa(config.LineCount), "", "", movesString(config.Moves), importsString(config.Imports), providerConfigsString(config.ProviderConfigs), config.ResourceCounts.String(), strings.Join(config.Experiments, ";"), strings.Join(config.ProviderFunctions, ";"), config.CLIVersion, resourcesString(config.Resources), config.RequiredVersion}
+		if config.Backend != nil {
+			record[2] = config.Backend.Type
+			record[3] = config.Backend.Config.String()
+		}
+		err = writer.Write(padCSVRecord(record, len(fields)))
+		if err != nil {
+			return fmt.Errorf("failed to write CSV record: %v", err)
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("failed to write CSV file: %v", err)
+	}
+	return nil
+}
+
+// padCSVRecord pads a record with empty fields to the width of the module records.
+func padCSVRecord(record []string, width int) []string {
+	for len(record) < width {
+		record = append(record, "")
+	}
+	return record
+}
+
+// writeSBOMToJSON writes the SBOM to a JSON file. Modules are encoded one at a time,
+// so large SBOMs are not duplicated in memory as encoded JSON.
+func writeSBOMToJSON(sbom *SBOM, outputPath string) error {
+	err := writeFileAtomic(outputPath, func(w io.Writer) error {
+		return encodeSBOMJSON(w, sbom)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write JSON file: %v", err)
+	}
+
+	fmt.Printf("SBOM successfully written to %s\n", outputPath)
+	return nil
+}
+
+// limitComponents drops the providers from an SBOM when only modules are wanted, or
+// the modules when only providers are wanted. Outputs and per-config details are kept.
+func limitComponents(sbom *SBOM, moduleOnly, providerOnly bool) {
+	if moduleOnly {
+		sbom.Providers = nil
+	}
+	if providerOnly {
+		sbom.Modules = nil
+	}
+}
+
+// omitPositions clears the file and line of every module and provider, so that
+// output reporting them, such as the locations of SARIF results, does not change when
+// terraform fmt moves blocks to other lines. Findings are then located at their config.
+func omitPositions(sbom *SBOM) {
+	for i := range sbom.Modules {
+		sbom.Modules[i].File, sbom.Modules[i].Line = "", 0
+	}
+	for i := range sbom.Providers {
+		sbom.Providers[i].File, sbom.Providers[i].Line = "", 0
+	}
+}
+
+// withModuleList returns the SBOM with an empty rather than nil module list, so that
+// an SBOM without modules is encoded as "modules": [] instead of null. The JSON
+// writer always writes a module list; this is needed where the SBOM is embedded in
+// another document.
+func withModuleList(sbom *SBOM) *SBOM {
+	if sbom.Modules != nil {
+		return sbom
+	}
+	empty := *sbom
+	empty.Modules = []ModuleInfo{}
+	return &empty
+}
+
+// writeJSON writes any JSON encodable form of the SBOM to a file.
+func writeJSON(sbom any, outputPath string) error {
+	err := writeFileAtomic(outputPath, func(w io.Writer) error {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(sbom)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write JSON file: %v", err)
+	}
+
+	fmt.Printf("SBOM successfully written to %s\n", outputPath)
+	return nil
+}
+
+// encodeSBOMXML writes the SBOM to w as indented XML.
+func encodeSBOMXML(w io.Writer, sbom *SBOM) error {
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	return encoder.Encode(sbom)
+}
+
+// encodeSBOMTOML writes the SBOM to w as TOML, with modules, outputs, and configs as
+// arrays of tables.
+func encodeSBOMTOML(w io.Writer, sbom *SBOM) error {
+	return toml.NewEncoder(w).Encode(sbom)
+}
+
+// encodeSBOMYAML writes the SBOM to w as YAML, using the same lowercase keys as the
+// JSON output.
+func encodeSBOMYAML(w io.Writer, sbom *SBOM) error {
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(sbom); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// writeSBOMToXML writes the SBOM to an XML file
+func writeSBOMToXML(sbom *SBOM, outputPath string) error {
+	err := writeFileAtomic(outputPath, func(w io.Writer) error {
+		return encodeSBOMXML(w, sbom)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write XML file: %v", err)
+	}
+
+	fmt.Printf("SBOM successfully written to %s\n", outputPath)
+	return nil
+}
+
+// writeSBOMToTOML writes the SBOM to a TOML file, with modules, outputs, and configs
+// as arrays of tables.
+func writeSBOMToTOML(sbom *SBOM, outputPath string) error {
+	err := writeFileAtomic(outputPath, func(w io.Writer) error {
+		return encodeSBOMTOML(w, sbom)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write TOML file: %v", err)
+	}
+
+	fmt.Printf("SBOM successfully written to %s\n", outputPath)
+	return nil
+}
+
+// writeSBOMToYAML writes the SBOM to a YAML file, using the same lowercase keys as
+// the JSON output.
+func writeSBOMToYAML(sbom *SBOM, outputPath string) error {
+	err := writeFileAtomic(outputPath, func(w io.Writer) error {
+		return encodeSBOMYAML(w, sbom)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write YAML file: %v", err)
+	}
+
+	fmt.Printf("SBOM successfully written to %s\n", outputPath)
+	return nil
+}
+
+// outputWriters maps each supported output format to the function writing it.
+var outputWriters = map[string]func(*SBOM, string) error{
+	"csv":       writeSBOMToCSV,
+	"json":      writeSBOMToJSON,
+	"xml":       writeSBOMToXML,
+	"intoto":    writeSBOMToInToto,
+	"mermaid":   writeSBOMToMermaid,
+	"toml":      writeSBOMToTOML,
+	"protobuf":  writeSBOMToProtobuf,
+	"protojson": writeSBOMToProtoJSON,
+	"sarif":     writeSBOMToSARIF,
+	"yaml":      writeSBOMToYAML,
+}
+
+// outputExtensions maps each output format to the extension of its default output file.
+var outputExtensions = map[string]string{
+	"csv":       ".csv",
+	"json":      ".json",
+	"xml":       ".xml",
+	"intoto":    ".intoto.json",
+	"mermaid":   ".mmd",
+	"toml":      ".toml",
+	"protobuf":  ".pb",
+	"protojson": ".pb.json",
+	"sarif":     ".sarif",
+	"yaml":      ".yaml",
+}
+
+// defaultOutputName is the base name of the file written when no output path is given.
+const defaultOutputName = "sbom"
+
+// defaultOutputPath returns the file written in the current directory when no output
+// path is given, such as sbom.json for json output. Output rendered through a
+// template takes the extension the template file carries before .tmpl, such as
+// sbom.md for report.md.tmpl, and otherwise .txt.
+func defaultOutputPath(format string, templatePath string) string {
+	if templatePath != "" {
+		ext := filepath.Ext(strings.TrimSuffix(filepath.Base(templatePath), ".tmpl"))
+		if ext == "" {
+			ext = ".txt"
+		}
+		return defaultOutputName + ext
+	}
+
+	ext, ok := outputExtensions[format]
+	if !ok {
+		ext = "." + format
+	}
+	return defaultOutputName + ext
+}
+
+// outputFormatNames returns the supported output formats in sorted order.
+func outputFormatNames() []string {
+	names := make([]string, 0, len(outputWriters))
+	for name := range outputWriters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// writeSBOM writes the SBOM to a file in the given output format.
+func writeSBOM(sbom *SBOM, format string, outputPath string) error {
+	writer, ok := outputWriters[strings.ToLower(format)]
+	if !ok {
+		return fmt.Errorf("unsupported output format: %s", format)
+	}
+	return writer(sbom, outputPath)
+}
+
+// readSBOM reads an SBOM previously written in CSV, JSON, XML, TOML, or YAML format, chosen by file extension.
+func readSBOM(path string) (*SBOM, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read SBOM file: %v", err)
+	}
+
+	var sbom SBOM
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		var parsed *SBOM
+		parsed, err = parseCSV(content)
+		if parsed != nil {
+			sbom = *parsed
+		}
+	case ".json":
+		err = json.Unmarshal(content, &sbom)
+	case ".xml":
+		err = xml.Unmarshal(content, &sbom)
+	case ".toml":
+		err = toml.Unmarshal(content, &sbom)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &sbom)
+	default:
+		return nil, fmt.Errorf("unsupported SBOM file %s: expected a .csv, .json, .xml, .toml, or .yaml file", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse SBOM file %s: %v", path, err)
+	}
+
+	return &sbom, nil
+}
+
+// defaultSBOMName returns the name of the directory a config path refers to, used
+// as the SBOM name when none is given.
+func defaultSBOMName(configPath string) string {
+	if abs, err := filepath.Abs(configPath); err == nil {
+		configPath = abs
+	}
+	return filepath.Base(configPath)
+}
+
+// fileExists checks if a file exists at the given file path.
+func fileExists(filePath string) bool {
+	_, err := os.Stat(filePath)
+	return err == nil
+}
+
+// expandPath expands environment variables such as $WORKSPACE or ${WORKSPACE} in a
+// path, along with a leading ~ for the current user's home directory. Unset variables
+// expand to an empty string, as they do in the shell.
+func expandPath(path string) string {
+	path = os.ExpandEnv(path)
+
+	if path == "~" || strings.HasPrefix(path, "~/") || strings.HasPrefix(path, "~"+string(filepath.Separator)) {
+		home, err := os.UserHomeDir()
+		if err == nil {
+			path = filepath.Join(home, path[1:])
+		}
+	}
+
+	return path
+}
+
+// runScan implements the scan command, which generates an SBOM for a Terraform configuration.
+// It is also run when no command is given, so `terraform-sbom <config> <output>` keeps working.
+func runScan(args []string) {
+	flags := newFlagSet("scan", "<path-to-terraform-config> [output-file]")
+
+	verbose := flags.Bool("v", false, "Enable verbose output")
+	tui := flags.Bool("tui", false, "Browse the modules in an interactive, searchable table after writing the SBOM. Ignored when not run in a terminal or when CI is set")
+	watch := flags.Bool("watch", false, "Keep running and scan again, rewriting the output, whenever a .tf, .tf.json, .tofu, or .tofu.json file in the config directory changes, or below it with -recursive. Stop with Ctrl+C")
+	noColor := flags.Bool("no-color", false, "Disable colored verbose output. Color is also disabled when NO_COLOR is set or stdout is not a terminal")
+	outputFormat := flags.String("output", "csv", "Specify output format: "+strings.Join(outputFormatNames(), ", ")+". Defaults to csv")
+	recursive := flags.Bool("recursive", false, "Scan every Terraform configuration found under the config path")
+	stream := flags.Bool("stream", false, "With -recursive and json output, write the modules of each configuration to the output file as soon as it is scanned, instead of holding every module in memory. Only options that affect how each configuration is scanned can be combined with it")
+	progress := flags.Bool("progress", false, "Report scan progress on stderr in recursive mode. Ignored when stderr is not a terminal")
+	metrics := flags.Bool("metrics", false, "Collect per-config metrics such as the number of lines of Terraform")
+	unknownVersionLabel := flags.String("unknown-version-label", unknownVersion, "Version written for modules that are not pinned to a version, such as an empty string or unknown")
+	localVersionLabel := flags.String("local-version-label", localVersion, "Version written for local modules, which have no version of their own")
+	noVersionHeuristics := flags.Bool("no-version-heuristics", false, "Only record versions given by the version argument of module calls. Versions are not read from the ref of a source, and local and unpinned modules are left without one")
+	explain := flags.Bool("explain", false, "Record how the version of each module was derived: version-attribute, ref-query, local-path-heuristic, unknown, or version-override")
+	followLocal := flags.Bool("follow-local-sources", false, "Load each local module and record the version in its VERSION file and the Terraform versions its required_version settings accept")
+	fullAddress := flags.Bool("full-address", false, "Record the full address of each module call, including its config and callers, such as network/module.vpc, to key modules uniquely in merges and diffs")
+	flattenNested := flags.Bool("flatten-nested", false, "Also record the module calls made by local modules, recursively, with each module's call path such as root > networking > subnet. Ignored with -from-manifest")
+	fromPlan := flags.Bool("from-plan", false, "Read modules, providers, and resource counts from a plan printed by terraform show -json, given in place of the config path")
+	fromManifest := flags.Bool("from-manifest", false, "Read modules from .terraform/modules/modules.json, including nested modules. Requires terraform init to have been run")
+	includeLifecycle := flags.Bool("include-lifecycle", false, "Record the moved and import blocks declared by each configuration")
+	includeProviderConfigs := flags.Bool("include-provider-configs", false, "Record the name and alias of each provider block declared by each configuration, without its other attributes")
+	includeResources := flags.Bool("include-resources", false, "Count the managed resources each configuration declares for each provider")
+	includeBackend := flags.Bool("include-backend", false, "Record the state backend declared by each configuration, with credentials redacted")
+	terragrunt := flags.Bool("terragrunt", false, "Also record module sources, includes, and dependencies declared in terragrunt.hcl files")
+	since := flags.String("since", "", "Only scan configurations changed since this git ref")
+	baseSBOMPath := flags.String("base-sbom", "", "JSON, XML, TOML, or YAML SBOM to update with the configurations rescanned by -since")
+	tfCompat := flags.String("tf-compat", "", "Only write the configs whose required_version does not permit this Terraform version, such as 1.9.0, with their constraints")
+	onlyChangedPath := flags.String("only-changed-versions", "", "Previous SBOM to compare the scan with. Only the modules whose version changed since then are written, with their previous version")
+	includeAdded := flags.Bool("include-added", false, "With -only-changed-versions, also write the modules added since the previous SBOM")
+	includeRemoved := flags.Bool("include-removed", false, "With -only-changed-versions, also write the modules removed since the previous SBOM")
+	includeOutputs := flags.Bool("include-outputs", false, "Catalog the output values declared by the configuration")
+	matrix := flags.Bool("matrix", false, "Write a usage matrix instead of the SBOM, with a row per config, a column per module source, and the number of calls in each cell. Supports csv and json output")
+	groupByConfig := flags.Bool("group-by-config", false, "Nest modules, providers, and outputs under their config path in JSON output instead of listing them flat")
+	fieldsSpec := flags.String("fields", "", "Comma-separated, ordered list of module fields to include in CSV or JSON output, e.g. name,source,version. Valid fields: "+strings.Join(fieldNames(), ", "))
+	templatePath := flags.String("template", "", "Render the SBOM through a Go text/template file instead of a built-in output format")
+	dryRun := flags.Bool("dry-run", false, "Generate the SBOM and report what would be written on stderr without writing any files")
+	errorsTo := flags.String("errors-to", "", "Write every problem loading a config, with its path, message, and severity, to this file as a JSON list. With -recursive, configs that fail to load are skipped instead of stopping the scan")
+	csvMetadataFlag := flags.Bool("csv-metadata", false, "Start CSV output with a comment row, beginning with #, that records the tool version, generation time, and config root")
+	update := flags.Bool("update", false, "Update an existing CSV file in place, replacing the entries of the scanned configs instead of appending")
+	noPositions := flags.Bool("omit-positions", false, "Leave the file and line of each module and provider out of the output, so that reformatting the configuration does not change it. Findings in SARIF output are then located at their config")
+	canonical := flags.Bool("canonical", false, "Omit the generation timestamp and derive the serial number from the content instead of a random one, so the output only changes when the configuration does")
+	serial := flags.String("serial", "", "Use this UUID as the SBOM serial number instead of a random one, for reproducible builds")
+	name := flags.String("name", "", "Name of the system the SBOM describes. Defaults to the base name of the config path")
+	namespace := flags.String("namespace", "", "Namespace that qualifies the SBOM name, such as a URI of the owning organization")
+	supplier := flags.String("supplier", "", "Organization that supplies the system the SBOM describes")
+	versionOverridesPath := flags.String("version-overrides", "", "File of module source and version pairs, one per line. Matching modules are recorded at that version, keeping the declared one as original_version")
+	codeownersPath := flags.String("codeowners", "", "GitHub CODEOWNERS file used to record the owners of each module's config")
+	allowlist := flags.String("allowlist", "", "File of approved module source patterns, one per line. Modules matching none of them are not approved")
+	denylist := flags.String("denylist", "", "File of denied module source patterns, one per line")
+	failOnSecrets := flags.Bool("fail-on-secrets", false, "Exit with a non-zero status if any module or provider source has credentials embedded in it. The credentials are always redacted from the SBOM")
+	failOnInsecure := flags.Bool("fail-on-insecure", false, "Exit with a non-zero status if any module source is fetched over an unencrypted transport such as http:// or git://")
+	failOnUnpinned := flags.Bool("fail-on-unpinned", false, "Exit with a non-zero status if any remote module is not pinned to a version")
+	failOnDenied := flags.Bool("fail-on-denied", false, "Exit with a non-zero status if any module is not approved by the allowlist or denylist")
+	deprecationsPath := flags.String("deprecations", "", "File of deprecated module source patterns, one per line with the source replacing them and a message. Matching modules are marked deprecated and warned about")
+	failOnDeprecated := flags.Bool("fail-on-deprecated", false, "Exit with a non-zero status if any module source is deprecated. Requires -deprecations")
+	checkReachability := flags.Bool("check-reachability", false, "Check that each registry, git, and local module source can still be fetched")
+	reachabilityTimeout := flags.Duration("reachability-timeout", defaultReachabilityTimeout, "Give up checking a single module source after this duration")
+	checkUpdates := flags.Bool("check-updates", false, "Look up when the version each registry and git module is pinned to was published and record its age in days")
+	staleAfter := flags.Int("stale-after", 0, "Warn about modules pinned to a version published more than this many days ago. Requires -check-updates")
+	cacheTTL := flags.Duration("cache-ttl", defaultRegistryCacheTTL, "Reuse registry lookups made by -check-reachability and -check-updates for this long, from a cache in the user cache directory")
+	noCache := flags.Bool("no-cache", false, "Query the registry for every module instead of using or updating the registry cache")
+	rateLimit := flags.Float64("rate-limit", 0, "Send at most this many registry and GitHub API requests per second, e.g. 5 or 0.5. Defaults to no limit")
+	apiRetries := flags.Int("api-retries", defaultAPIRetries, "Number of times to retry registry and GitHub API calls that fail with a transient error")
+	var redactPatterns stringsFlag
+	var registryTokens stringsFlag
+	flags.Var(&registryTokens, "registry-token", "API token for a private registry, given as HOST=TOKEN, sent by -check-updates and -check-reachability. Can be given more than once. Tokens are also read from the Terraform CLI configuration")
+	flags.Var(&redactPatterns, "redact-pattern", "Rewrite the parts of module and provider sources matching a regular expression before writing, given as REGEX=REPLACEMENT, such as git\\.corp\\.example\\.com=git.example.com. Can be given more than once; rules are applied in order")
+	var privateRegistryHosts stringsFlag
+	flags.Var(&privateRegistryHosts, "private-registry-host", "Hostname of a private module registry; its subdomains also match. Can be given more than once")
+	var variableArgs []variableArg
+	flags.Var(variableArgsFlag{args: &variableArgs, file: true}, "var-file", "Read input variable values from a .tfvars or .tfvars.json file to resolve module sources and versions that reference variables. Can be given more than once; later files and -var flags override earlier ones")
+	flags.Var(variableArgsFlag{args: &variableArgs}, "var", "Set an input variable, as NAME=VALUE, to resolve module sources and versions that reference variables. Can be given more than once")
+	var maxFileSize byteSizeFlag
+	flags.Var(&maxFileSize, "max-file-size", "Skip, with a warning, any .tf, .tf.json, .tofu, or .tofu.json file larger than this size, such as 50MB, instead of loading it. Defaults to no limit")
+	maxRecordsPerFile := flags.Int("max-records-per-file", 0, "Split the SBOM across numbered output files, such as out.1.json and out.2.json, holding at most this many modules each. Defaults to a single file")
+	moduleOnly := flags.Bool("module-only", false, "Only write modules to the SBOM, leaving out providers. Cannot be combined with -provider-only")
+	var excludeNamespaceValues stringsFlag
+	flags.Var(&excludeNamespaceValues, "exclude-provider-namespace", "Leave out the providers in a namespace, such as hashicorp, or of a namespace and type, such as hashicorp/null. Can be given more than once")
+	providerOnly := flags.Bool("provider-only", false, "Only write providers to the SBOM, leaving out modules. Cannot be combined with -module-only")
+	strict := flags.Bool("strict", false, "Fail if any configuration file cannot be parsed instead of recording the rest of the configuration with a warning, or if a provider local name refers to different sources across configs")
+	failOnEmpty := flags.Bool("fail-on-empty", false, "Exit with a non-zero status if the scanned configuration declares no module calls. The empty SBOM is still written")
+	maxModulesPerConfig := flags.Int("max-modules-per-config", 0, "Exit with a non-zero status if any config declares more than this many module calls. 0 means no limit")
+	strictSemver := flags.Bool("strict-semver", false, "Exit with a non-zero status if any module is pinned to a version that is not exactly vMAJOR.MINOR.PATCH")
+	baselinePath := flags.String("baseline", "", "Baseline file of accepted policy findings. Only findings missing from it fail the policy checks")
+	writeBaselinePath := flags.String("write-baseline", "", "Write the current policy findings to this baseline file and exit successfully instead of failing on them")
+	strictConsistency := flags.Bool("strict-consistency", false, "Exit with a non-zero status if the same module source is pinned differently across configs")
+	syslogAudit := flags.Bool("syslog", false, "Send a summary of the scan, with the configs scanned, module count, and policy results, to the system log for audit trails. Ignored with a warning on platforms without one")
+	telemetryFile := flags.String("telemetry-file", "", "Append a JSON event with the duration, counts, and errors of each scan to this file. Nothing is recorded unless this is set")
+	timeout := flags.Duration("timeout", 0, "Abort the scan if it takes longer than this duration, e.g. 30s or 5m. Defaults to no timeout")
+	sign := flags.Bool("sign", false, "Write a detached, cosign-compatible signature of each output file next to it, named with a .sig suffix. Requires -key and a build with the sign tag")
+	keyPath := flags.String("key", "", "PEM-encoded private key used by -sign")
+	printDigest := flags.Bool("print-digest", false, "Print a SHA-256 digest of the SBOM content to stderr. It leaves out the timestamp and serial number, so it only changes when the configuration does")
+	writeDigest := flags.Bool("write-digest", false, "Write the SHA-256 digest of the SBOM content next to the output file, named with a .sha256 suffix")
+	cpuProfile := flags.String("cpuprofile", "", "Write a CPU profile of the scan to this file, for analysis with go tool pprof")
+	memProfile := flags.String("memprofile", "", "Write a memory profile to this file when the scan ends, for analysis with go tool pprof")
+	force := flags.Bool("force", false, "Overwrite the default output file, such as sbom.csv, when it already exists, and replace CSV output rather than append to it")
+	flags.Parse(args)
+
+	if flags.NArg() < 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	configPath := expandPath(flags.Arg(0))
+	outputPath := expandPath(flags.Arg(1))
+	telemetryPath := expandPath(*telemetryFile)
+	if flags.NArg() < 2 {
+		// Without an output path, the SBOM goes to sbom.<ext> in the current directory.
+		// An existing file there is only replaced with -force, or extended with -update.
+		outputPath = defaultOutputPath(strings.ToLower(*outputFormat), *templatePath)
+		if fileExists(outputPath) && !*force && !*update && !*dryRun {
+			log.Fatalf("Error: %s already exists; pass -force to overwrite it or give an output file", outputPath)
+		}
+	}
+
+	if *maxRecordsPerFile < 0 {
+		log.Fatalf("-max-records-per-file must not be negative")
+	}
+	if *maxRecordsPerFile > 0 && *update {
+		log.Fatalf("-max-records-per-file cannot be used with -update")
+	}
+
+	var signer fileSigner
+	if *sign {
+		if *keyPath == "" {
+			log.Fatalf("-sign requires -key")
+		}
+		var err error
+		signer, err = loadFileSigner(expandPath(*keyPath))
+		if err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+	} else if *keyPath != "" {
+		log.Fatalf("-key requires -sign")
+	}
+
+	if *maxModulesPerConfig < 0 {
+		log.Fatalf("-max-modules-per-config must not be negative")
+	}
+
+	remote, isRemote, err := parseRemoteLocation(outputPath)
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	var store objectStore
+	if isRemote {
+		if *update {
+			log.Fatalf("-update cannot be used with a cloud storage output")
+		}
+		store, err = newObjectStore(remote.Store, newAPIClient(nil, *apiRetries))
+		if err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+	}
+
+	if *moduleOnly && *providerOnly {
+		log.Fatalf("-module-only and -provider-only cannot be used together")
+	}
+	if *staleAfter < 0 {
+		log.Fatalf("-stale-after must not be negative")
+	}
+	if *staleAfter > 0 && !*checkUpdates {
+		log.Fatalf("-stale-after requires -check-updates")
+	}
+	if *failOnDeprecated && *deprecationsPath == "" {
+		log.Fatalf("-fail-on-deprecated requires -deprecations")
+	}
+
+	format := strings.ToLower(*outputFormat)
+	if _, ok := outputWriters[format]; !ok && *templatePath == "" {
+		log.Fatalf("Unsupported output format: %s. Supported formats are: %s", *outputFormat, strings.Join(outputFormatNames(), ", "))
+	}
+
+	if *stream {
+		if !*recursive || format != "json" || *templatePath != "" || isRemote || isTarArchive(configPath) {
+			log.Fatalf("Error: -stream requires -recursive, json output, and a local config directory and output file")
+		}
+		var unsupported []string
+		flags.Visit(func(f *flag.Flag) {
+			if !streamFlags[f.Name] {
+				unsupported = append(unsupported, "-"+f.Name)
+			}
+		})
+		if len(unsupported) > 0 {
+			log.Fatalf("Error: -stream cannot be combined with %s", strings.Join(unsupported, ", "))
+		}
+	}
+
+	var owners *codeowners
+	if *codeownersPath != "" {
+		var err error
+		owners, err = loadCodeowners(expandPath(*codeownersPath))
+		if err != nil {
+			log.Fatalf("Error loading CODEOWNERS: %v", err)
+		}
+	}
+
+	var versionOverrides map[string]string
+	if *versionOverridesPath != "" {
+		var err error
+		versionOverrides, err = loadVersionOverrides(expandPath(*versionOverridesPath))
+		if err != nil {
+			log.Fatalf("Error loading version overrides: %v", err)
+		}
+	}
+
+	if (*includeAdded || *includeRemoved) && *onlyChangedPath == "" {
+		log.Fatalf("-include-added and -include-removed require -only-changed-versions")
+	}
+	var previous *SBOM
+	if *onlyChangedPath != "" {
+		if *update {
+			log.Fatalf("-only-changed-versions cannot be used with -update")
+		}
+		var err error
+		previous, err = readSBOM(expandPath(*onlyChangedPath))
+		if err != nil {
+			log.Fatalf("Error reading previous SBOM: %v", err)
+		}
+	}
+
+	var compatTarget *goversion.Version
+	if *tfCompat != "" {
+		if *onlyChangedPath != "" {
+			log.Fatalf("-tf-compat and -only-changed-versions cannot be used together")
+		}
+		var err error
+		compatTarget, err = parseTargetVersion(*tfCompat)
+		if err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+	}
+
+	redactRules, err := parseRedactRules(redactPatterns)
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+
+	excludedNamespaces, err := parseProviderNamespaces(excludeNamespaceValues)
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+
+	var accepted baseline
+	if *baselinePath != "" {
+		var err error
+		accepted, err = loadBaseline(expandPath(*baselinePath))
+		if err != nil {
+			log.Fatalf("Error loading baseline: %v", err)
+		}
+	}
+
+	var policy *sourcePolicy
+	if *allowlist != "" || *denylist != "" {
+		var allowPatterns, denyPatterns []string
+		var err error
+		if *allowlist != "" {
+			allowPatterns, err = loadPatterns(expandPath(*allowlist))
+			if err != nil {
+				log.Fatalf("Error loading allowlist: %v", err)
+			}
+		}
+		if *denylist != "" {
+			denyPatterns, err = loadPatterns(expandPath(*denylist))
+			if err != nil {
+				log.Fatalf("Error loading denylist: %v", err)
+			}
+		}
+		policy = newSourcePolicy(allowPatterns, denyPatterns)
+	}
+
+	var credentials registryCredentials
+	if *checkUpdates || *checkReachability {
+		var err error
+		credentials, err = loadCLICredentials()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: ignoring Terraform CLI credentials: %v\n", err)
+			credentials = make(registryCredentials)
+		}
+		tokens, err := parseRegistryTokens(registryTokens)
+		if err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+		for host, token := range tokens {
+			credentials[host] = token
+		}
+	}
+
+	var deprecations []deprecation
+	if *deprecationsPath != "" {
+		var err error
+		deprecations, err = loadDeprecations(expandPath(*deprecationsPath))
+		if err != nil {
+			log.Fatalf("Error loading deprecations: %v", err)
+		}
+	}
+
+	if *update && (format != "csv" || *templatePath != "" || *fieldsSpec != "") {
+		log.Fatalf("Error: -update is only supported for csv output with all fields")
+	}
+
+	var fields []moduleField
+	if *fieldsSpec != "" {
+		if format != "csv" && format != "json" {
+			log.Fatalf("Error: -fields is only supported for csv and json output")
+		}
+		var err error
+		fields, err = parseFields(*fieldsSpec)
+		if err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+	}
+
+	if *groupByConfig && (format != "json" || *templatePath != "" || *fieldsSpec != "") {
+		log.Fatalf("Error: -group-by-config is only supported for json output with all fields")
+	}
+
+	if *matrix && ((format != "csv" && format != "json") || *templatePath != "" || *fieldsSpec != "" || *groupByConfig || *update || *maxRecordsPerFile > 0) {
+		log.Fatalf("Error: -matrix is only supported for csv and json output, without -template, -fields, -group-by-config, -update, or -max-records-per-file")
+	}
+
+	if *csvMetadataFlag && (format != "csv" || *templatePath != "" || *matrix) {
+		log.Fatalf("Error: -csv-metadata is only supported for csv output, without -template or -matrix")
+	}
+
+	if *since != "" && isTarArchive(configPath) {
+		log.Fatalf("Error: -since is not supported when scanning an archive")
+	}
+	if *fromPlan && (*recursive || *since != "" || *fromManifest || *terragrunt || *tfCompat != "") {
+		log.Fatalf("Error: -from-plan cannot be combined with -recursive, -since, -from-manifest, -terragrunt, or -tf-compat")
+	}
+
+	serialNumber := ""
+	if *serial != "" {
+		var err error
+		serialNumber, err = parseSerialNumber(*serial)
+		if err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+	}
+
+	var tmpl *template.Template
+	if *templatePath != "" {
+		var err error
+		tmpl, err = loadTemplate(expandPath(*templatePath))
+		if err != nil {
+			log.Fatalf("Error loading template: %v", err)
+		}
+	}
+
+	if *watch {
+		if *fromPlan || *tui || isTarArchive(configPath) {
+			log.Fatalf("Error: -watch cannot be combined with -from-plan, -tui, or an archive")
+		}
+
+		// The flags are followed by -watch=false, which overrides -watch, and then the
+		// config and output paths. Scans after the first also pass -force, so that CSV
+		// output, including every file of -max-records-per-file, is replaced rather than
+		// appended to, and a default output file written by the first scan is replaced.
+		flagArgs := append(slices.Clip(args[:len(args)-flags.NArg()]), "-watch=false")
+		scanArgs := append(slices.Clip(flagArgs), flags.Args()...)
+		rescanArgs := scanArgs
+		if !*update {
+			rescanArgs = append(append(slices.Clip(flagArgs), "-force"), flags.Args()...)
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		if err := runWatch(ctx, configPath, *recursive, scanArgs, rescanArgs); err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+		fmt.Fprintln(os.Stderr, "Info: stopped watching")
+		return
+	}
+
+	if *cpuProfile != "" || *memProfile != "" {
+		profiler, err := startProfiling(expandPath(*cpuProfile), expandPath(*memProfile))
+		if err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+		defer profiler.stop()
+	}
+
+	ctx := context.Background()
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+
+	var variables map[string]cty.Value
+	if len(variableArgs) > 0 {
+		variables, err = loadVariables(variableArgs)
+		if err != nil {
+			fatalf("Error loading variables: %v", err)
+		}
+	}
+
+	opts := scanOptions{
+		IncludeOutputs:         *includeOutputs,
+		Terragrunt:             *terragrunt,
+		Metrics:                *metrics,
+		IncludeBackend:         *includeBackend,
+		IncludeLifecycle:       *includeLifecycle,
+		IncludeProviderConfigs: *includeProviderConfigs,
+		IncludeResources:       *includeResources,
+		RequiredVersions:       compatTarget != nil,
+		FromManifest:           *fromManifest,
+		FlattenNested:          *flattenNested,
+		FollowLocalSources:     *followLocal,
+		Explain:                *explain,
+		NoVersionHeuristics:    *noVersionHeuristics,
+		Strict:                 *strict,
+		MaxFileSize:            int64(maxFileSize),
+		SkipFailedConfigs:      *errorsTo != "",
+
+		PrivateRegistryHosts: privateRegistryHosts,
+		Variables:            variables,
+		APIClient:            newAPIClient(nil, *apiRetries).withRateLimit(*rateLimit),
+	}
+
+	start := time.Now()
+	if *stream {
+		var reporter *progressReporter
+		if *progress && isTerminal(os.Stderr) {
+			reporter = newProgressReporter(os.Stderr)
+		}
+		metadata := &SBOM{
+			SerialNumber: serialNumber,
+			Version:      1,
+			Name:         *name,
+			Namespace:    *namespace,
+			Supplier:     *supplier,
+			Timestamp:    start.UTC().Format(time.RFC3339),
+		}
+		if metadata.SerialNumber == "" {
+			metadata.SerialNumber = newSerialNumber()
+		}
+		if metadata.Name == "" {
+			metadata.Name = defaultSBOMName(configPath)
+		}
+
+		var scanned *SBOM
+		err := writeFileAtomic(outputPath, func(w io.Writer) error {
+			var err error
+			scanned, err = writeRecursiveJSON(ctx, w, configPath, opts, reporter, metadata)
+			return err
+		})
+		if errors.Is(err, context.DeadlineExceeded) {
+			fatalf("Error generating SBOM: scan did not complete within %s", *timeout)
+		}
+		if err != nil {
+			fatalf("Error generating SBOM: %v", err)
+		}
+		for _, warning := range scanned.Warnings {
+			fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+		}
+		fmt.Printf("SBOM successfully written to %s\n", outputPath)
+		return
+	}
+
+	var sbom *SBOM
+	if *fromPlan {
+		sbom, err = generateSBOMFromPlan(configPath, opts)
+	} else if isTarArchive(configPath) {
+		var reporter *progressReporter
+		if *recursive && *progress && isTerminal(os.Stderr) {
+			reporter = newProgressReporter(os.Stderr)
+		}
+		sbom, err = generateSBOMFromArchive(ctx, configPath, *recursive, opts, reporter)
+	} else if *since != "" {
+		var base *SBOM
+		if *baseSBOMPath != "" {
+			base, err = readSBOM(expandPath(*baseSBOMPath))
+			if err != nil {
+				fatalf("Error reading base SBOM: %v", err)
+			}
+		}
+		sbom, err = generateIncrementalSBOM(ctx, configPath, *since, base, opts)
+	} else if *recursive {
+		var reporter *progressReporter
+		if *progress && isTerminal(os.Stderr) {
+			reporter = newProgressReporter(os.Stderr)
+		}
+		sbom, err = generateRecursiveSBOM(ctx, configPath, opts, reporter)
+	} else {
+		sbom, err = generateSBOM(ctx, configPath, opts)
+	}
+	if err != nil {
+		recordTelemetry(telemetryPath, start, nil, err)
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		fatalf("Error generating SBOM: scan did not complete within %s", *timeout)
+	}
+	if err != nil {
+		// A config that cannot be loaded at all is still reported, so the report
+		// always describes the last scan.
+		if *errorsTo != "" && !*dryRun {
+			loadErr := loadError{Path: configPath, Message: redactText(err.Error(), redactRules), Severity: loadSeverityError}
+			if err := writeLoadErrors([]loadError{loadErr}, expandPath(*errorsTo)); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			}
+		}
+		fatalf("Error generating SBOM: %v", err)
+	}
+
+	if *errorsTo != "" && !*dryRun {
+		// The report is written before the SBOM is redacted, so it is redacted on its own.
+		if err := writeLoadErrors(redactLoadErrors(sbom.LoadErrors, redactRules), expandPath(*errorsTo)); err != nil {
+			fatalf("Error: %v", err)
+		}
+		fmt.Fprintf(os.Stderr, "Info: %d load error(s) written to %s\n", len(sbom.LoadErrors), *errorsTo)
+	}
+
+	for _, warning := range sbom.Warnings {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+	}
+
+	if *fullAddress {
+		setAddresses(sbom.Modules)
+	}
+
+	sbom.Providers = excludeProviders(sbom.Providers, excludedNamespaces)
+
+	if versionOverrides != nil {
+		changed := applyVersionOverrides(sbom.Modules, versionOverrides)
+		fmt.Fprintf(os.Stderr, "Info: applied version overrides to %d module(s)\n", changed)
+	}
+
+	var violations []policyViolation
+	for _, warning := range pinningWarnings(sbom.Modules) {
+		if *strictConsistency {
+			violations = append(violations, warning)
+			continue
+		}
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+	}
+	for _, warning := range providerConflicts(sbom.Providers) {
+		if *strict {
+			violations = append(violations, warning)
+			continue
+		}
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+	}
+
+	var cache *registryCache
+	if (*checkReachability || *checkUpdates) && !*noCache {
+		cachePath, err := defaultRegistryCachePath()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		} else {
+			cache = loadRegistryCache(cachePath, *cacheTTL)
+		}
+	}
+
+	if *checkReachability {
+		checker := newReachabilityChecker(opts.APIClient, *reachabilityTimeout)
+		checker.credentials = credentials
+		checker.cache = cache
+		checker.checkReachability(ctx, sbom)
+	}
+
+	if *checkUpdates {
+		checker := newAgeChecker(opts.APIClient)
+		checker.credentials = credentials
+		checker.cache = cache
+		for _, warning := range checker.checkAges(ctx, sbom) {
+			fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+		}
+		if *staleAfter > 0 {
+			for _, warning := range staleWarnings(sbom.Modules, *staleAfter) {
+				fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+			}
+		}
+	}
+
+	if cache != nil {
+		if err := cache.save(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
+
+	empty := len(sbom.Modules) == 0
+	if empty {
+		fmt.Fprintf(os.Stderr, "Info: %s was scanned successfully but declares no module calls\n", configPath)
+	}
+
+	if owners != nil {
+		setOwners(sbom.Modules, owners)
+	}
+
+	if *strictSemver {
+		violations = append(violations, strictSemverViolations(sbom.Modules)...)
+	}
+
+	if *failOnInsecure {
+		violations = append(violations, insecureViolations(sbom.Modules)...)
+	}
+
+	if *failOnUnpinned {
+		violations = append(violations, unpinnedViolations(sbom.Modules)...)
+	}
+
+	if *failOnSecrets {
+		violations = append(violations, secretViolations(sbom)...)
+	}
+
+	if *maxModulesPerConfig > 0 {
+		violations = append(violations, moduleCountViolations(sbom.Modules, *maxModulesPerConfig)...)
+	}
+
+	if policy != nil {
+		denied := applySourcePolicy(sbom, policy)
+		if *failOnDenied {
+			violations = append(violations, denied...)
+		}
+	}
+
+	for _, deprecated := range applyDeprecations(sbom.Modules, deprecations) {
+		if *failOnDeprecated {
+			violations = append(violations, deprecated)
+			continue
+		}
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", deprecated)
+	}
+
+	if remaining := filterIgnored(violations, sbom.Modules); len(remaining) < len(violations) {
+		fmt.Fprintf(os.Stderr, "Info: %d finding(s) ignored by sbom:ignore directives\n", len(violations)-len(remaining))
+		violations = remaining
+	}
+
+	// An incremental update of a base SBOM keeps its serial number as a new revision.
+	// Canonical output is given a serial number derived from its content instead of a
+	// random one once the SBOM is complete, so it only changes with the configuration.
+	contentSerial := false
+	if serialNumber != "" {
+		sbom.SerialNumber = serialNumber
+	} else if sbom.SerialNumber == "" && *canonical {
+		contentSerial = true
+	} else if sbom.SerialNumber == "" {
+		sbom.SerialNumber = newSerialNumber()
+	}
+	if sbom.Version == 0 {
+		sbom.Version = 1
+	}
+
+	// Metadata flags override what a base SBOM recorded; the name falls back to the config directory.
+	if *name != "" {
+		sbom.Name = *name
+	} else if sbom.Name == "" {
+		sbom.Name = defaultSBOMName(configPath)
+	}
+	if *namespace != "" {
+		sbom.Namespace = *namespace
+	}
+	if *supplier != "" {
+		sbom.Supplier = *supplier
+	}
+
+	if !*canonical {
+		sbom.Timestamp = time.Now().UTC().Format(time.RFC3339)
+	}
+
+	limitComponents(sbom, *moduleOnly, *providerOnly)
+
+	if previous != nil {
+		onlyChangedVersions(sbom, previous, *includeAdded, *includeRemoved)
+	}
+
+	if compatTarget != nil {
+		total := len(sbom.Configs)
+		for _, warning := range onlyIncompatibleConfigs(sbom, compatTarget) {
+			fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+		}
+		for _, config := range sbom.Configs {
+			fmt.Fprintf(os.Stderr, "Info: %s requires Terraform %s\n", config.Path, config.RequiredVersion)
+		}
+		fmt.Fprintf(os.Stderr, "Info: %d of %d config(s) do not permit Terraform %s\n", len(sbom.Configs), total, compatTarget)
+	}
+
+	if *verbose {
+		printSBOM(os.Stdout, sbom, useColor(os.Stdout, *noColor))
+	}
+
+	applyRedactRules(sbom, redactRules)
+
+	if *noPositions {
+		omitPositions(sbom)
+	}
+
+	// SARIF output reports findings rather than versions, and its checks need the
+	// placeholders to recognize unpinned modules.
+	if format != "sarif" && (*unknownVersionLabel != unknownVersion || *localVersionLabel != localVersion) {
+		relabelVersions(sbom.Modules, *unknownVersionLabel, *localVersionLabel)
+	}
+
+	var digest string
+	if *printDigest || *writeDigest || contentSerial {
+		digest, err = sbomDigest(sbom)
+		if err != nil {
+			fatalf("Error: %v", err)
+		}
+		if *printDigest {
+			fmt.Fprintf(os.Stderr, "SBOM digest: sha256:%s\n", digest)
+		}
+	}
+	if contentSerial {
+		sbom.SerialNumber = contentSerialNumber(digest)
+	}
+
+	chunks := []sbomChunk{{SBOM: sbom, Path: outputPath}}
+	if *maxRecordsPerFile > 0 {
+		chunks = splitSBOM(sbom, outputPath, *maxRecordsPerFile)
+	}
+
+	if *dryRun {
+		planFormat := format
+		if tmpl != nil {
+			planFormat = "template " + *templatePath
+		}
+		for _, chunk := range chunks {
+			printDryRun(os.Stderr, chunk.SBOM, newWritePlan(planFormat, chunk.Path, *update))
+		}
+	} else {
+		// Cloud storage outputs are written to a staging directory first and then
+		// uploaded, so every output format works unchanged.
+		var stagingDir string
+		if isRemote {
+			stagingDir, err = os.MkdirTemp("", "terraform-sbom-*")
+			if err != nil {
+				fatalf("Error creating staging directory: %v", err)
+			}
+			defer os.RemoveAll(stagingDir)
+		}
+
+		var metadata *csvMetadata
+		if *csvMetadataFlag {
+			metadata = &csvMetadata{ToolVersion: version, Timestamp: sbom.Timestamp, ConfigRoot: configPath}
+		}
+
+		err = writeChunks(chunks, func(chunk sbomChunk) error {
+			path := chunk.Path
+			if isRemote {
+				path = filepath.Join(stagingDir, filepath.Base(chunk.Path))
+			}
+
+			var err error
+			if tmpl != nil {
+				err = writeSBOMWithTemplate(chunk.SBOM, tmpl, path)
+			} else if *update {
+				err = updateCSV(chunk.SBOM, path, configPath, metadata)
+			} else if *matrix {
+				err = writeUsageMatrix(chunk.SBOM, format, path)
+			} else if *groupByConfig {
+				err = writeJSON(groupSBOMByConfig(chunk.SBOM), path)
+			} else if *force && format == "csv" {
+				// CSV output is otherwise appended to. The file is replaced atomically, so
+				// it is left untouched if the SBOM cannot be written.
+				csvFields := fields
+				if csvFields == nil {
+					csvFields = moduleFields
+				}
+				err = replaceCSV(chunk.SBOM, path, csvFields, metadata)
+			} else if metadata != nil {
+				csvFields := fields
+				if csvFields == nil {
+					csvFields = moduleFields
+				}
+				err = writeCSV(chunk.SBOM, path, csvFields, metadata)
+			} else if fields != nil {
+				err = writeSBOMWithFields(chunk.SBOM, format, path, fields)
+			} else {
+				err = writeSBOM(chunk.SBOM, format, path)
+			}
+
+			var sigPath string
+			if err == nil && signer != nil {
+				sigPath, err = signer.SignFile(path)
+			}
+			if err == nil && isRemote {
+				loc, _, _ := parseRemoteLocation(chunk.Path)
+				err = uploadFile(ctx, store, path, loc)
+				if err == nil && signer != nil {
+					loc, _, _ = parseRemoteLocation(chunk.Path + signatureExt)
+					err = uploadFile(ctx, store, sigPath, loc)
+				}
+			}
+			if err == nil && signer != nil {
+				fmt.Printf("Signature successfully written to %s\n", chunk.Path+signatureExt)
+			}
+			return err
+		})
+		if err != nil {
+			recordTelemetry(telemetryPath, start, sbom, err)
+			fatalf("Error writing SBOM: %v", err)
+		}
+
+		if *writeDigest {
+			path := outputPath + digestExt
+			if isRemote {
+				path = filepath.Join(stagingDir, filepath.Base(outputPath)+digestExt)
+			}
+			err = writeDigestFile(path, digest)
+			if err == nil && isRemote {
+				loc, _, _ := parseRemoteLocation(outputPath + digestExt)
+				err = uploadFile(ctx, store, path, loc)
+			}
+			if err != nil {
+				fatalf("Error writing digest: %v", err)
+			}
+			fmt.Printf("Digest successfully written to %s\n", outputPath+digestExt)
+		}
+	}
+
+	recordTelemetry(telemetryPath, start, sbom, nil)
+	if *syslogAudit && !*dryRun {
+		recordAudit(newAuditEvent(configPath, outputPath, sbom, violations, accepted))
+	}
+
+	if *tui {
+		if tuiAvailable() {
+			if err := runTUI(sbom); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			}
+		} else {
+			fmt.Fprintln(os.Stderr, "Warning: ignoring -tui because stdin or stdout is not a terminal, or CI is set")
+		}
+	}
+
+	if *failOnEmpty && empty {
+		fatalf("Error: %s declares no module calls", configPath)
+	}
+
+	if *writeBaselinePath != "" {
+		if err := writeBaseline(expandPath(*writeBaselinePath), violations); err != nil {
+			fatalf("Error writing baseline: %v", err)
+		}
+		fmt.Printf("Baseline of %d finding(s) written to %s\n", len(violations), *writeBaselinePath)
+		return
+	}
+
+	if accepted != nil {
+		remaining := accepted.filter(violations)
+		if suppressed := len(violations) - len(remaining); suppressed > 0 {
+			fmt.Fprintf(os.Stderr, "Info: %d finding(s) accepted by the baseline\n", suppressed)
+		}
+		violations = remaining
+	}
+
+	if len(violations) > 0 {
+		for _, violation := range violations {
+			fmt.Fprintln(os.Stderr, violation)
+		}
+		fatalf("Policy check failed with %d violation(s)", len(violations))
+	}
+}
+
+func main() {
+	runCommand(os.Args[1:])
 }
@@ -1,28 +1,46 @@
 package main
 
 import (
+	"bytes"
+	"context"
 	"encoding/csv"
 	"encoding/json"
 	"encoding/xml"
+	"errors"
+	"flag"
 	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-config-inspect/tfconfig"
 )
 
+// update rewrites golden files with the current output instead of comparing against them.
+var update = flag.Bool("update", false, "update golden files")
+
 // mockSBOM creates a mock SBOM for testing purposes.
 func mockSBOM() *SBOM {
 	return &SBOM{
 		Modules: []ModuleInfo{
 			{
-				Name:    "aws_vpc",
-				Source:  "git::https://github.com/terraform-aws-modules/vpc.git?ref=v2.0.0",
-				Version: "v2.0.0",
-				Config:  "/path/to/config",
+				Name:       "aws_vpc",
+				Source:     "git::https://github.com/terraform-aws-modules/vpc.git?ref=v2.0.0",
+				SourceType: sourceTypeGit,
+				Version:    "v2.0.0",
+				Config:     "/path/to/config",
+				ProviderMappings: ProviderMap{
+					"aws": "aws.useast1",
+				},
 			},
 			{
-				Name:    "s3_bucket",
-				Source:  "hashicorp/aws",
-				Version: "N/A",
-				Config:  "/path/to/config",
+				Name:       "s3_bucket",
+				Source:     "hashicorp/aws",
+				SourceType: sourceTypeUnknown,
+				Version:    "N/A",
+				Config:     "/path/to/config",
 			},
 		},
 	}
@@ -58,8 +76,8 @@ func TestWriteSBOMToCSV(t *testing.T) {
 
 	// Expected CSV header and records
 	expected := [][]string{
-		{"/path/to/config", "aws_vpc", "git::https://github.com/terraform-aws-modules/vpc.git?ref=v2.0.0", "v2.0.0"},
-		{"/path/to/config", "s3_bucket", "hashicorp/aws", "N/A"},
+		{"/path/to/config", "aws_vpc", "git::https://github.com/terraform-aws-modules/vpc.git?ref=v2.0.0", "v2.0.0", "", "", "git", "", "", "", "", "", "", "false", "", "aws=aws.useast1", "", "", "", "", "", "", "", "", "", "", "", "", "", "false", "", ""},
+		{"/path/to/config", "s3_bucket", "hashicorp/aws", "N/A", "", "", "unknown", "", "", "", "", "", "", "false", "", "", "", "", "", "", "", "", "", "", "", "", "", "", "", "false", "", ""},
 	}
 
 	for i, record := range records {
@@ -71,6 +89,72 @@ func TestWriteSBOMToCSV(t *testing.T) {
 	}
 }
 
+// TestWriteSBOMToCSVAppend tests that CSV output is appended to a file with the same
+// columns, after its metadata row, and refused for a file with other columns.
+func TestWriteSBOMToCSVAppend(t *testing.T) {
+	sbom := mockSBOM()
+	fields, err := parseFields("config,name,source,version")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	outputPath := filepath.Join(t.TempDir(), "sbom.csv")
+	metadata := &csvMetadata{ToolVersion: "1.0.0", ConfigRoot: "/path/to/config"}
+	for i := 0; i < 2; i++ {
+		if err := writeCSV(sbom, outputPath, fields, metadata); err != nil {
+			t.Fatalf("Failed to write CSV: %v", err)
+		}
+	}
+	parsed, err := readSBOM(outputPath)
+	if err != nil {
+		t.Fatalf("Failed to read CSV: %v", err)
+	}
+	if len(parsed.Modules) != 4 {
+		t.Errorf("Expected 4 modules after appending, got %d", len(parsed.Modules))
+	}
+
+	// A file written with the original columns has a header of its own.
+	legacyPath := filepath.Join(t.TempDir(), "legacy.csv")
+	legacy := "Config Path,Module Name,Source,Version\n/path/to/config,dns,acme/dns/aws,1.0.0\n"
+	if err := os.WriteFile(legacyPath, []byte(legacy), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeSBOMToCSV(sbom, legacyPath); err == nil || !strings.Contains(err.Error(), "columns differ") {
+		t.Errorf("Expected appending to a file with other columns to fail, got %v", err)
+	}
+	content, _ := os.ReadFile(legacyPath)
+	if string(content) != legacy {
+		t.Errorf("Expected the file with other columns to be left unchanged, got %s", content)
+	}
+}
+
+// TestReplaceCSV tests that CSV output written with -force replaces an existing file,
+// whatever its columns, instead of being appended to it.
+func TestReplaceCSV(t *testing.T) {
+	outputPath := filepath.Join(t.TempDir(), "sbom.csv")
+	if err := os.WriteFile(outputPath, []byte("Config Path,Module Name,Source,Version\n/old,dns,acme/dns/aws,1.0.0\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := replaceCSV(mockSBOM(), outputPath, moduleFields, nil); err != nil {
+			t.Fatalf("Failed to replace CSV: %v", err)
+		}
+	}
+
+	parsed, err := readSBOM(outputPath)
+	if err != nil {
+		t.Fatalf("Failed to read CSV: %v", err)
+	}
+	var names []string
+	for _, mod := range parsed.Modules {
+		names = append(names, mod.Name)
+	}
+	if expected := []string{"aws_vpc", "s3_bucket"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("Expected modules %v, got %v", expected, names)
+	}
+}
+
 // TestWriteSBOMToJSON tests JSON output functionality.
 func TestWriteSBOMToJSON(t *testing.T) {
 	sbom := mockSBOM()
@@ -104,7 +188,7 @@ func TestWriteSBOMToJSON(t *testing.T) {
 	}
 
 	for i, mod := range result.Modules {
-		if mod != sbom.Modules[i] {
+		if !reflect.DeepEqual(mod, sbom.Modules[i]) {
 			t.Errorf("JSON content mismatch: expected %v, got %v", sbom.Modules[i], mod)
 		}
 	}
@@ -143,8 +227,666 @@ func TestWriteSBOMToXML(t *testing.T) {
 	}
 
 	for i, mod := range result.Modules {
-		if mod != sbom.Modules[i] {
+		if !reflect.DeepEqual(mod, sbom.Modules[i]) {
 			t.Errorf("XML content mismatch: expected %v, got %v", sbom.Modules[i], mod)
 		}
 	}
 }
+
+// TestGenerateSBOMProviderMappings tests that the providers meta-argument is recorded per module.
+func TestGenerateSBOMProviderMappings(t *testing.T) {
+	sbom, err := generateSBOM(context.Background(), "testdata/aliased-providers", scanOptions{})
+	if err != nil {
+		t.Fatalf("Failed to generate SBOM: %v", err)
+	}
+
+	expected := map[string]ProviderMap{
+		"vpc_west": nil,
+		"vpc_east": {"aws": "aws.useast1"},
+		"peering":  {"aws.requester": "aws", "aws.accepter": "aws.useast1"},
+	}
+
+	if len(sbom.Modules) != len(expected) {
+		t.Fatalf("Expected %d modules, got %d", len(expected), len(sbom.Modules))
+	}
+
+	for _, mod := range sbom.Modules {
+		want, ok := expected[mod.Name]
+		if !ok {
+			t.Errorf("Unexpected module %s", mod.Name)
+			continue
+		}
+		if !reflect.DeepEqual(mod.ProviderMappings, want) {
+			t.Errorf("Provider mappings mismatch for %s: expected %v, got %v", mod.Name, want, mod.ProviderMappings)
+		}
+	}
+}
+
+// TestGenerateSBOMDependsOn tests recording the depends_on meta-argument of module
+// blocks in native and JSON syntax, and leaving it empty when absent.
+func TestGenerateSBOMDependsOn(t *testing.T) {
+	sbom, err := generateSBOM(context.Background(), "testdata/depends-on", scanOptions{})
+	if err != nil {
+		t.Fatalf("Failed to generate SBOM: %v", err)
+	}
+
+	expected := map[string][]string{
+		"network": nil,
+		"cluster": {"module.network", "aws_iam_role.deploy"},
+		"dns":     {"module.cluster"},
+	}
+
+	if len(sbom.Modules) != len(expected) {
+		t.Fatalf("Expected %d modules, got %d", len(expected), len(sbom.Modules))
+	}
+
+	for _, mod := range sbom.Modules {
+		want, ok := expected[mod.Name]
+		if !ok {
+			t.Errorf("Unexpected module %s", mod.Name)
+			continue
+		}
+		if !reflect.DeepEqual(mod.DependsOn, want) {
+			t.Errorf("Depends on mismatch for %s: expected %v, got %v", mod.Name, want, mod.DependsOn)
+		}
+	}
+}
+
+// TestGenerateSBOMCancelled tests that a cancelled scan stops without producing a result.
+func TestGenerateSBOMCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	sbom, err := generateSBOM(ctx, "testdata/aliased-providers", scanOptions{})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled error, got %v", err)
+	}
+	if sbom != nil {
+		t.Errorf("Expected no SBOM from a cancelled scan, got %v", sbom)
+	}
+}
+
+// TestGenerateSBOMTimeout tests that an expired deadline is reported as such.
+func TestGenerateSBOMTimeout(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	<-ctx.Done()
+
+	_, err := generateSBOM(ctx, "testdata/aliased-providers", scanOptions{})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected context.DeadlineExceeded error, got %v", err)
+	}
+}
+
+// TestGenerateSBOMIncludeOutputs tests cataloging outputs with and without the sensitive flag.
+func TestGenerateSBOMIncludeOutputs(t *testing.T) {
+	sbom, err := generateSBOM(context.Background(), "testdata/outputs", scanOptions{IncludeOutputs: true})
+	if err != nil {
+		t.Fatalf("Failed to generate SBOM: %v", err)
+	}
+
+	expected := []OutputInfo{
+		{Name: "db_password", Description: "Password for the database", Sensitive: true, Config: "testdata/outputs"},
+		{Name: "vpc_id", Description: "ID of the VPC", Sensitive: false, Config: "testdata/outputs"},
+	}
+	if !reflect.DeepEqual(sbom.Outputs, expected) {
+		t.Errorf("Outputs mismatch: expected %v, got %v", expected, sbom.Outputs)
+	}
+
+	sbom, err = generateSBOM(context.Background(), "testdata/outputs", scanOptions{})
+	if err != nil {
+		t.Fatalf("Failed to generate SBOM: %v", err)
+	}
+	if len(sbom.Outputs) != 0 {
+		t.Errorf("Expected no outputs without IncludeOutputs, got %v", sbom.Outputs)
+	}
+}
+
+// TestWriteSBOMToCSVOutputs tests that outputs are written as a dedicated CSV section.
+func TestWriteSBOMToCSVOutputs(t *testing.T) {
+	sbom := mockSBOM()
+	sbom.Outputs = []OutputInfo{
+		{Name: "vpc_id", Description: "ID of the VPC", Sensitive: false, Config: "/path/to/config"},
+	}
+
+	outputPath := filepath.Join(t.TempDir(), "sbom.csv")
+	err := writeSBOMToCSV(sbom, outputPath)
+	if err != nil {
+		t.Fatalf("Failed to write SBOM to CSV: %v", err)
+	}
+
+	file, err := os.Open(outputPath)
+	if err != nil {
+		t.Fatalf("Failed to open CSV file: %v", err)
+	}
+	defer file.Close()
+
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to read CSV records: %v", err)
+	}
+
+	expected := [][]string{
+		{"Config Path", "Output Name", "Description", "Sensitive", "", "", "", "", "", "", "", "", "", "", "", "", "", "", "", "", "", "", "", "", "", "", "", "", "", "", "", ""},
+		{"/path/to/config", "vpc_id", "ID of the VPC", "false", "", "", "", "", "", "", "", "", "", "", "", "", "", "", "", "", "", "", "", "", "", "", "", "", "", "", "", ""},
+	}
+	if len(records) != 5 {
+		t.Fatalf("Expected 5 CSV records, got %d", len(records))
+	}
+	if !reflect.DeepEqual(records[3:], expected) {
+		t.Errorf("CSV output section mismatch: expected %v, got %v", expected, records[3:])
+	}
+}
+
+// TestWriteSBOMToJSONGolden tests that JSON output is byte-for-byte stable across runs.
+func TestWriteSBOMToJSONGolden(t *testing.T) {
+	goldenPath := filepath.Join("testdata", "golden", "aliased-providers.json")
+
+	var outputs [][]byte
+	for i := 0; i < 2; i++ {
+		sbom, err := generateSBOM(context.Background(), "testdata/aliased-providers", scanOptions{})
+		if err != nil {
+			t.Fatalf("Failed to generate SBOM: %v", err)
+		}
+
+		outputPath := filepath.Join(t.TempDir(), "sbom.json")
+		err = writeSBOMToJSON(sbom, outputPath)
+		if err != nil {
+			t.Fatalf("Failed to write SBOM to JSON: %v", err)
+		}
+
+		content, err := os.ReadFile(outputPath)
+		if err != nil {
+			t.Fatalf("Failed to read JSON file: %v", err)
+		}
+		outputs = append(outputs, content)
+	}
+
+	if !bytes.Equal(outputs[0], outputs[1]) {
+		t.Fatalf("JSON output differs between runs:\n%s\n%s", outputs[0], outputs[1])
+	}
+
+	if *update {
+		err := os.WriteFile(goldenPath, outputs[0], 0644)
+		if err != nil {
+			t.Fatalf("Failed to update golden file: %v", err)
+		}
+	}
+
+	golden, err := os.ReadFile(goldenPath)
+	if err != nil {
+		t.Fatalf("Failed to read golden file: %v", err)
+	}
+	if !bytes.Equal(outputs[0], golden) {
+		t.Errorf("JSON output does not match %s:\n%s", goldenPath, outputs[0])
+	}
+}
+
+// TestGenerateSBOMPartial tests that a broken file is recorded as a warning unless strict mode is enabled.
+func TestGenerateSBOMPartial(t *testing.T) {
+	sbom, err := generateSBOM(context.Background(), "testdata/partial", scanOptions{})
+	if err != nil {
+		t.Fatalf("Failed to generate SBOM: %v", err)
+	}
+
+	found := false
+	for _, mod := range sbom.Modules {
+		if mod.Name == "vpc" && mod.Version == "5.1.0" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected the vpc module from the valid file, got %v", sbom.Modules)
+	}
+
+	if len(sbom.Warnings) != 1 {
+		t.Fatalf("Expected 1 warning, got %v", sbom.Warnings)
+	}
+	prefix := filepath.Join("testdata", "partial", "broken.tf") + ":1: Unclosed configuration block"
+	if !strings.HasPrefix(sbom.Warnings[0], prefix) {
+		t.Errorf("Expected warning starting with %q, got %q", prefix, sbom.Warnings[0])
+	}
+
+	_, err = generateSBOM(context.Background(), "testdata/partial", scanOptions{Strict: true})
+	if err == nil {
+		t.Errorf("Expected an error in strict mode")
+	}
+}
+
+// TestExpandPath tests expanding set and unset environment variables and the home directory.
+func TestExpandPath(t *testing.T) {
+	t.Setenv("WORKSPACE", "/builds/infra")
+	t.Setenv("HOME", "/home/ci")
+	t.Setenv("SBOM_UNSET_VARIABLE", "")
+	os.Unsetenv("SBOM_UNSET_VARIABLE")
+
+	tests := map[string]string{
+		"$WORKSPACE/network":           "/builds/infra/network",
+		"${WORKSPACE}/network":         "/builds/infra/network",
+		"$SBOM_UNSET_VARIABLE/network": "/network",
+		"~/infra/network":              "/home/ci/infra/network",
+		"~":                            "/home/ci",
+		"infra/~network":               "infra/~network",
+		"relative/path":                "relative/path",
+	}
+	for path, expected := range tests {
+		if got := expandPath(path); got != expected {
+			t.Errorf("expandPath(%q) = %q, expected %q", path, got, expected)
+		}
+	}
+}
+
+// TestWriteSBOMToTOML tests that TOML output decodes back into the same SBOM.
+func TestWriteSBOMToTOML(t *testing.T) {
+	approved := true
+	sbom := mockSBOM()
+	sbom.SerialNumber = "urn:uuid:3e671687-395b-41f5-a30f-a58921a69b79"
+	sbom.Version = 1
+	sbom.Modules[0].Approved = &approved
+	sbom.Outputs = []OutputInfo{
+		{Name: "vpc_id", Description: "ID of the VPC", Sensitive: false, Config: "/path/to/config"},
+	}
+	sbom.Configs = []ConfigInfo{
+		{Path: "/path/to/config", LineCount: 42, Backend: &BackendInfo{Type: "s3", Config: AttributeMap{"bucket": "state"}}},
+	}
+
+	outputPath := filepath.Join(t.TempDir(), "sbom.toml")
+	err := writeSBOMToTOML(sbom, outputPath)
+	if err != nil {
+		t.Fatalf("Failed to write SBOM to TOML: %v", err)
+	}
+
+	result, err := readSBOM(outputPath)
+	if err != nil {
+		t.Fatalf("Failed to read TOML file: %v", err)
+	}
+
+	if !reflect.DeepEqual(result, sbom) {
+		t.Errorf("TOML round trip mismatch:\nexpected %+v\ngot      %+v", sbom, result)
+	}
+}
+
+// TestSBOMMetadata tests that the name, namespace, and supplier are written and read back in each structured format.
+func TestSBOMMetadata(t *testing.T) {
+	sbom := mockSBOM()
+	sbom.Name = "network"
+	sbom.Namespace = "https://example.com/sboms"
+	sbom.Supplier = "Example Corp"
+
+	for _, format := range []string{"json", "xml", "toml"} {
+		outputPath := filepath.Join(t.TempDir(), "sbom."+format)
+		err := writeSBOM(sbom, format, outputPath)
+		if err != nil {
+			t.Fatalf("Failed to write SBOM to %s: %v", format, err)
+		}
+
+		content, err := os.ReadFile(outputPath)
+		if err != nil {
+			t.Fatalf("Failed to read %s file: %v", format, err)
+		}
+		for _, value := range []string{sbom.Name, sbom.Namespace, sbom.Supplier} {
+			if !strings.Contains(string(content), value) {
+				t.Errorf("Expected %s output to contain %q", format, value)
+			}
+		}
+
+		result, err := readSBOM(outputPath)
+		if err != nil {
+			t.Fatalf("Failed to read SBOM from %s: %v", format, err)
+		}
+		if result.Name != sbom.Name || result.Namespace != sbom.Namespace || result.Supplier != sbom.Supplier {
+			t.Errorf("Expected %s metadata %q, %q, %q, got %q, %q, %q", format, sbom.Name, sbom.Namespace, sbom.Supplier, result.Name, result.Namespace, result.Supplier)
+		}
+	}
+}
+
+// TestDefaultSBOMName tests that the default SBOM name is the base name of the config path.
+func TestDefaultSBOMName(t *testing.T) {
+	tests := map[string]string{
+		"testdata/outputs":   "outputs",
+		"/path/to/network/":  "network",
+		"/path/to/app/../db": "db",
+	}
+
+	for path, expected := range tests {
+		if got := defaultSBOMName(path); got != expected {
+			t.Errorf("defaultSBOMName(%q) = %q, expected %q", path, got, expected)
+		}
+	}
+}
+
+// TestWriteSBOMToYAML tests that YAML output uses lowercase keys and decodes back into the same SBOM.
+func TestWriteSBOMToYAML(t *testing.T) {
+	approved := false
+	sbom := mockSBOM()
+	sbom.SerialNumber = "urn:uuid:3e671687-395b-41f5-a30f-a58921a69b79"
+	sbom.Version = 1
+	sbom.Name = "network"
+	sbom.Modules[0].Approved = &approved
+	sbom.Modules[0].Owners = []string{"@acme/network"}
+	sbom.Providers = []ProviderInfo{
+		{Name: "aws", Source: "hashicorp/aws", VersionConstraint: "~> 5.0", LockedVersion: "5.31.0", Config: "/path/to/config"},
+	}
+	sbom.Outputs = []OutputInfo{
+		{Name: "vpc_id", Description: "ID of the VPC", Sensitive: true, Config: "/path/to/config"},
+	}
+	sbom.Configs = []ConfigInfo{
+		{Path: "/path/to/config", LineCount: 42, Backend: &BackendInfo{Type: "s3", Config: AttributeMap{"bucket": "state"}}, ProviderConfigs: []ProviderConfigInfo{{Name: "aws", Alias: "west"}}},
+	}
+	sbom.Warnings = []string{"/path/to/config/broken.tf:1,1-2: Argument or block definition required"}
+
+	outputPath := filepath.Join(t.TempDir(), "sbom.yaml")
+	err := writeSBOMToYAML(sbom, outputPath)
+	if err != nil {
+		t.Fatalf("Failed to write SBOM to YAML: %v", err)
+	}
+
+	content, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("Failed to read YAML file: %v", err)
+	}
+	for _, key := range []string{"serial_number: ", "modules:", "source_type: git", "provider_mappings:", "approved: false", "locked_version: 5.31.0", "provider_configs:"} {
+		if !strings.Contains(string(content), key) {
+			t.Errorf("Expected YAML output to contain %q", key)
+		}
+	}
+	if strings.Contains(string(content), "reachable:") {
+		t.Errorf("Expected unset optional fields to be omitted from YAML output")
+	}
+
+	result, err := readSBOM(outputPath)
+	if err != nil {
+		t.Fatalf("Failed to read YAML file: %v", err)
+	}
+
+	if !reflect.DeepEqual(result, sbom) {
+		t.Errorf("YAML round trip mismatch:\nexpected %+v\ngot      %+v", sbom, result)
+	}
+}
+
+// TestWriteEmptySBOM tests that a configuration without module calls still produces valid, empty output.
+func TestWriteEmptySBOM(t *testing.T) {
+	sbom, err := generateSBOM(context.Background(), "testdata/empty", scanOptions{})
+	if err != nil {
+		t.Fatalf("Failed to generate SBOM: %v", err)
+	}
+	if len(sbom.Modules) != 0 {
+		t.Fatalf("Expected no modules, got %v", sbom.Modules)
+	}
+
+	dir := t.TempDir()
+
+	jsonPath := filepath.Join(dir, "sbom.json")
+	err = writeSBOMToJSON(sbom, jsonPath)
+	if err != nil {
+		t.Fatalf("Failed to write SBOM to JSON: %v", err)
+	}
+	content, err := os.ReadFile(jsonPath)
+	if err != nil {
+		t.Fatalf("Failed to read JSON file: %v", err)
+	}
+	if !strings.Contains(string(content), `"modules": []`) {
+		t.Errorf("Expected an empty modules list in JSON output, got %s", content)
+	}
+
+	csvPath := filepath.Join(dir, "sbom.csv")
+	err = writeSBOMToCSV(sbom, csvPath)
+	if err != nil {
+		t.Fatalf("Failed to write SBOM to CSV: %v", err)
+	}
+	file, err := os.Open(csvPath)
+	if err != nil {
+		t.Fatalf("Failed to open CSV file: %v", err)
+	}
+	defer file.Close()
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to read CSV records: %v", err)
+	}
+	// The provider inferred from the resource follows the header, with no module rows in between.
+	expected := [][]string{
+		csvHeader,
+		padCSVRecord(csvProviderHeader, len(csvHeader)),
+		padCSVRecord([]string{"testdata/empty", "aws", "", "", "", "pkg:terraform/hashicorp/aws"}, len(csvHeader)),
+	}
+	if !reflect.DeepEqual(records, expected) {
+		t.Errorf("Expected no module records in CSV output:\nexpected %v\ngot      %v", expected, records)
+	}
+}
+
+// TestLimitComponents tests that -module-only and -provider-only each leave out the other component type in every readable format.
+func TestLimitComponents(t *testing.T) {
+	tests := []struct {
+		name         string
+		moduleOnly   bool
+		providerOnly bool
+		modules      int
+		providers    int
+	}{
+		{"module-only", true, false, 2, 0},
+		{"provider-only", false, true, 0, 1},
+	}
+
+	for _, tt := range tests {
+		for _, format := range []string{"csv", "json", "xml", "toml", "yaml"} {
+			sbom := mockSBOM()
+			sbom.Providers = []ProviderInfo{{Name: "aws", Source: "hashicorp/aws", VersionConstraint: "~> 5.0", Config: "/path/to/config"}}
+			limitComponents(sbom, tt.moduleOnly, tt.providerOnly)
+
+			path := filepath.Join(t.TempDir(), "sbom."+format)
+			if err := writeSBOM(sbom, format, path); err != nil {
+				t.Fatalf("Failed to write %s SBOM: %v", format, err)
+			}
+			written, err := readSBOM(path)
+			if err != nil {
+				t.Fatalf("Failed to read %s SBOM: %v", format, err)
+			}
+
+			if len(written.Modules) != tt.modules || len(written.Providers) != tt.providers {
+				t.Errorf("%s %s: expected %d modules and %d providers, got %d and %d", tt.name, format, tt.modules, tt.providers, len(written.Modules), len(written.Providers))
+			}
+		}
+	}
+}
+
+// TestExtractVersion tests the precedence of refs and version arguments, and that
+// pre-release tags, build metadata, and pseudo-versions are returned in full.
+func TestExtractVersion(t *testing.T) {
+	tests := []struct {
+		source   string
+		version  string
+		expected string
+	}{
+		{"terraform-aws-modules/vpc/aws", "5.1.0", "5.1.0"},
+		{"terraform-aws-modules/vpc/aws", "~> 5.0", "~> 5.0"},
+		{"terraform-aws-modules/vpc/aws", "1.0.0-rc.1", "1.0.0-rc.1"},
+		{"terraform-aws-modules/vpc/aws", "", "N/A"},
+		{"git::https://github.com/acme/vpc.git?ref=v1.2.0", "", "v1.2.0"},
+		{"git::https://github.com/acme/vpc.git?ref=v1.0.0-rc.1", "", "v1.0.0-rc.1"},
+		{"git::https://github.com/acme/vpc.git?ref=v1.0.0+build.5", "", "v1.0.0+build.5"},
+		{"git::https://github.com/acme/vpc.git?ref=v1.0.0-beta.2+exp.sha.5114f85", "", "v1.0.0-beta.2+exp.sha.5114f85"},
+		{"git::https://github.com/acme/vpc.git?ref=v0.0.0-20210101000000-abcdef123456", "", "v0.0.0-20210101000000-abcdef123456"},
+		{"git::https://github.com/acme/vpc.git//modules/vpc?ref=v1.2.0&depth=1", "", "v1.2.0"},
+		{"git::https://github.com/acme/vpc.git?depth=1&ref=v1.2.0", "", "v1.2.0"},
+		{"git::https://github.com/acme/vpc.git?ref=v1.2.0", "5.1.0", "v1.2.0"},
+		{"git::https://github.com/acme/vpc.git?depth=1", "", "N/A"},
+		{"git::https://github.com/acme/vpc.git?ref=", "", "N/A"},
+		{"./modules/vpc", "", "local"},
+	}
+
+	for _, tt := range tests {
+		got := extractVersion(&tfconfig.ModuleCall{Source: tt.source, Version: tt.version})
+		if got != tt.expected {
+			t.Errorf("extractVersion(%q, %q) = %q, expected %q", tt.source, tt.version, got, tt.expected)
+		}
+	}
+}
+
+// TestVersionSource tests the provenance note recorded for each way a version is derived.
+func TestVersionSource(t *testing.T) {
+	tests := []struct {
+		source   string
+		version  string
+		expected string
+	}{
+		{"terraform-aws-modules/vpc/aws", "5.1.0", versionSourceAttribute},
+		{"terraform-aws-modules/vpc/aws", "~> 5.0", versionSourceAttribute},
+		{"git::https://github.com/acme/vpc.git?ref=v1.2.0", "", versionSourceRefQuery},
+		{"git::https://github.com/acme/vpc.git//modules/vpc?depth=1&ref=v1.2.0", "", versionSourceRefQuery},
+		{"git::https://github.com/acme/vpc.git?ref=v1.2.0", "5.1.0", versionSourceRefQuery},
+		{"./modules/vpc", "", versionSourceLocal},
+		{"../shared", "", versionSourceLocal},
+		{"git::https://github.com/acme/vpc.git", "", versionSourceUnknown},
+		{"terraform-aws-modules/vpc/aws", "", versionSourceUnknown},
+	}
+
+	for _, tt := range tests {
+		mod := newModuleInfo(&tfconfig.ModuleCall{Name: "vpc", Source: tt.source, Version: tt.version}, "app", nil, nil)
+		if got := versionSource(mod); got != tt.expected {
+			t.Errorf("versionSource(%q, %q) = %q, expected %q", tt.source, tt.version, got, tt.expected)
+		}
+	}
+}
+
+// TestGenerateSBOMExplain tests that version sources are only recorded with -explain.
+func TestGenerateSBOMExplain(t *testing.T) {
+	sbom, err := generateSBOM(context.Background(), "testdata/aliased-providers", scanOptions{})
+	if err != nil {
+		t.Fatalf("Failed to generate SBOM: %v", err)
+	}
+	for _, mod := range sbom.Modules {
+		if mod.VersionSource != "" {
+			t.Errorf("Expected no version source for %s without -explain, got %q", mod.Name, mod.VersionSource)
+		}
+	}
+
+	sbom, err = generateSBOM(context.Background(), "testdata/aliased-providers", scanOptions{Explain: true})
+	if err != nil {
+		t.Fatalf("Failed to generate SBOM: %v", err)
+	}
+	expected := map[string]string{"peering": versionSourceLocal, "vpc_east": versionSourceAttribute, "vpc_west": versionSourceAttribute}
+	for _, mod := range sbom.Modules {
+		if mod.VersionSource != expected[mod.Name] {
+			t.Errorf("Expected version source %q for %s, got %q", expected[mod.Name], mod.Name, mod.VersionSource)
+		}
+	}
+}
+
+// TestGenerateSBOMNoVersionHeuristics tests that only version arguments are recorded
+// with -no-version-heuristics, bypassing refs and the local and N/A placeholders.
+func TestGenerateSBOMNoVersionHeuristics(t *testing.T) {
+	sbom, err := generateSBOM(context.Background(), "testdata/version-heuristics", scanOptions{})
+	if err != nil {
+		t.Fatalf("Failed to generate SBOM: %v", err)
+	}
+	versions := make(map[string]string)
+	for _, mod := range sbom.Modules {
+		versions[mod.Name] = mod.Version
+	}
+	expected := map[string]string{"vpc": "~> 5.0", "labels": "v1.2.0", "dns": "main", "service": localVersion, "cache": unknownVersion}
+	if !reflect.DeepEqual(versions, expected) {
+		t.Errorf("Expected heuristic versions %v, got %v", expected, versions)
+	}
+
+	sbom, err = generateSBOM(context.Background(), "testdata/version-heuristics", scanOptions{NoVersionHeuristics: true, Explain: true})
+	if err != nil {
+		t.Fatalf("Failed to generate SBOM: %v", err)
+	}
+	versions = make(map[string]string)
+	for _, mod := range sbom.Modules {
+		versions[mod.Name] = mod.Version
+		if mod.Name == "vpc" {
+			if mod.VersionSource != versionSourceAttribute || mod.NormalizedVersion != "~> 5.0" {
+				t.Errorf("Expected the version argument of vpc to be explained and normalized, got %q and %q", mod.VersionSource, mod.NormalizedVersion)
+			}
+		} else if mod.VersionSource != versionSourceUnknown {
+			t.Errorf("Expected version source %q for %s, got %q", versionSourceUnknown, mod.Name, mod.VersionSource)
+		}
+	}
+	expected = map[string]string{"vpc": "~> 5.0", "labels": "", "dns": "", "service": "", "cache": ""}
+	if !reflect.DeepEqual(versions, expected) {
+		t.Errorf("Expected only version arguments %v, got %v", expected, versions)
+	}
+
+	sbom, err = generateRecursiveSBOM(context.Background(), "testdata/terragrunt", scanOptions{Terragrunt: true, NoVersionHeuristics: true}, nil)
+	if err != nil {
+		t.Fatalf("Failed to generate SBOM: %v", err)
+	}
+	for _, mod := range sbom.Modules {
+		if mod.Version != "" {
+			t.Errorf("Expected no version for Terragrunt source %s, got %q", mod.Source, mod.Version)
+		}
+	}
+}
+
+// TestRelabelVersions tests that custom placeholders for unknown and local versions
+// propagate to every output format that records versions.
+func TestRelabelVersions(t *testing.T) {
+	sbom := &SBOM{Modules: []ModuleInfo{
+		{Name: "dns", Source: "github.com/acme/dns", SourceType: sourceTypeGitHub, Version: unknownVersion, Config: "app"},
+		{Name: "service", Source: "./modules/service", SourceType: sourceTypeLocal, Version: localVersion, Config: "app"},
+		{Name: "vpc", Source: "terraform-aws-modules/vpc/aws", SourceType: sourceTypeRegistry, Version: "5.1.0", OriginalVersion: unknownVersion, Config: "app"},
+	}}
+	relabelVersions(sbom.Modules, "unknown", "local-path")
+
+	var versions []string
+	for _, mod := range sbom.Modules {
+		versions = append(versions, mod.Version, mod.OriginalVersion)
+	}
+	if expected := []string{"unknown", "", "local-path", "", "5.1.0", "unknown"}; !reflect.DeepEqual(versions, expected) {
+		t.Fatalf("Expected versions %v, got %v", expected, versions)
+	}
+
+	dir := t.TempDir()
+	for _, format := range []string{"csv", "json", "xml", "toml", "yaml", "protojson", "mermaid"} {
+		path := filepath.Join(dir, "sbom."+format)
+		if err := outputWriters[format](sbom, path); err != nil {
+			t.Fatalf("Failed to write %s: %v", format, err)
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", format, err)
+		}
+		if !strings.Contains(string(content), "unknown") || !strings.Contains(string(content), "local-path") {
+			t.Errorf("Expected the %s output to use the custom labels, got:\n%s", format, content)
+		}
+		if strings.Contains(string(content), unknownVersion) {
+			t.Errorf("Expected the %s output not to contain %s, got:\n%s", format, unknownVersion, content)
+		}
+	}
+}
+
+// TestDefaultOutputPath tests the file name used when no output path is given.
+func TestDefaultOutputPath(t *testing.T) {
+	tests := []struct {
+		format       string
+		templatePath string
+		expected     string
+	}{
+		{"csv", "", "sbom.csv"},
+		{"json", "", "sbom.json"},
+		{"xml", "", "sbom.xml"},
+		{"intoto", "", "sbom.intoto.json"},
+		{"mermaid", "", "sbom.mmd"},
+		{"toml", "", "sbom.toml"},
+		{"protobuf", "", "sbom.pb"},
+		{"protojson", "", "sbom.pb.json"},
+		{"sarif", "", "sbom.sarif"},
+		{"yaml", "", "sbom.yaml"},
+		{"csv", "templates/report.md.tmpl", "sbom.md"},
+		{"csv", "templates/report.html", "sbom.html"},
+		{"csv", "templates/report.tmpl", "sbom.txt"},
+	}
+
+	for _, tt := range tests {
+		if got := defaultOutputPath(tt.format, tt.templatePath); got != tt.expected {
+			t.Errorf("defaultOutputPath(%q, %q) = %q, expected %q", tt.format, tt.templatePath, got, tt.expected)
+		}
+	}
+
+	for format := range outputWriters {
+		if _, ok := outputExtensions[format]; !ok {
+			t.Errorf("Expected a default extension for the %s format", format)
+		}
+	}
+}
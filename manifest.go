@@ -0,0 +1,74 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/terraform-config-inspect/tfconfig"
+)
+
+// moduleManifestPath is where terraform init records the modules it installed,
+// relative to the configuration directory.
+var moduleManifestPath = filepath.Join(".terraform", "modules", "modules.json")
+
+// moduleManifest is the structure of the modules.json file written by terraform init.
+type moduleManifest struct {
+	Modules []moduleManifestEntry `json:"Modules"`
+}
+
+// moduleManifestEntry describes a single installed module. Key is the dotted path of
+// module call names from the root module, e.g. "vpc.subnets"; the root module has
+// an empty key.
+type moduleManifestEntry struct {
+	Key     string `json:"Key"`
+	Source  string `json:"Source"`
+	Version string `json:"Version"`
+	Dir     string `json:"Dir"` // Installation directory, relative to the configuration
+}
+
+// readModuleManifest builds module entries from the modules.json manifest of an
+// initialized configuration. Unlike the module calls found by tfconfig, the manifest
+// includes the modules called by other modules, with their sources fully resolved.
+// Nested modules are named by their dotted key.
+func readModuleManifest(configPath string, providerMappings map[string]map[string]string, dependsOn map[string][]string) ([]ModuleInfo, error) {
+	content, err := os.ReadFile(filepath.Join(configPath, moduleManifestPath))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read module manifest (has terraform init been run?): %v", err)
+	}
+
+	var manifest moduleManifest
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse module manifest: %v", err)
+	}
+
+	var modules []ModuleInfo
+	for _, entry := range manifest.Modules {
+		if entry.Key == "" {
+			continue
+		}
+
+		source, subdir := splitSubdir(entry.Source)
+		modInfo := ModuleInfo{
+			Name:       entry.Key,
+			Source:     source,
+			Subdir:     subdir,
+			SourceType: sourceType(entry.Source),
+			Version:    extractVersion(&tfconfig.ModuleCall{Source: entry.Source, Version: entry.Version}),
+			Config:     configPath,
+		}
+
+		// Only calls in the root module are parsed for their providers and depends_on
+		// meta-arguments.
+		if !strings.Contains(entry.Key, ".") {
+			modInfo.ProviderMappings = providerMappings[entry.Key]
+			modInfo.DependsOn = dependsOn[entry.Key]
+		}
+
+		modules = append(modules, modInfo)
+	}
+
+	return modules, nil
+}
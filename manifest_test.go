@@ -0,0 +1,33 @@
+package main
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+// TestGenerateSBOMFromManifest tests that nested modules from modules.json are included with resolved versions.
+func TestGenerateSBOMFromManifest(t *testing.T) {
+	sbom, err := generateSBOM(context.Background(), "testdata/manifest", scanOptions{FromManifest: true})
+	if err != nil {
+		t.Fatalf("Failed to generate SBOM: %v", err)
+	}
+
+	expected := []ModuleInfo{
+		{Name: "app", Source: "./modules/app", SourceType: sourceTypeLocal, Version: "local", Config: "testdata/manifest"},
+		{Name: "app.database", Source: "git::https://github.com/acme/terraform-db.git?ref=v1.4.2", SourceType: sourceTypeGit, Version: "v1.4.2", PURL: "pkg:terraform/acme/terraform-db@v1.4.2?vcs_url=git%2Bhttps:%2F%2Fgithub.com%2Facme%2Fterraform-db.git", Config: "testdata/manifest", Organization: "acme"},
+		{Name: "app.database.subnets", Source: "registry.terraform.io/acme/subnets/aws", NormalizedSource: "acme/subnets/aws", Subdir: "modules/private", SourceType: sourceTypeRegistry, Version: "2.3.0", NormalizedVersion: "= 2.3.0", PURL: "pkg:terraform/acme/subnets/aws@2.3.0#modules/private", Config: "testdata/manifest", Registry: "registry.terraform.io", Organization: "acme"},
+		{Name: "vpc", Source: "registry.terraform.io/terraform-aws-modules/vpc/aws", NormalizedSource: "terraform-aws-modules/vpc/aws", SourceType: sourceTypeRegistry, Version: "5.1.2", NormalizedVersion: "= 5.1.2", PURL: "pkg:terraform/terraform-aws-modules/vpc/aws@5.1.2", Config: "testdata/manifest", Registry: "registry.terraform.io", Organization: "terraform-aws-modules"},
+	}
+	if !reflect.DeepEqual(sbom.Modules, expected) {
+		t.Errorf("Modules mismatch:\nexpected %v\ngot      %v", expected, sbom.Modules)
+	}
+}
+
+// TestGenerateSBOMFromManifestMissing tests that an uninitialized configuration is reported as an error.
+func TestGenerateSBOMFromManifestMissing(t *testing.T) {
+	_, err := generateSBOM(context.Background(), "testdata/outputs", scanOptions{FromManifest: true})
+	if err == nil {
+		t.Fatalf("Expected an error for a configuration without a module manifest")
+	}
+}
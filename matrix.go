@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// usageMatrix is a pivot of module usage across configs: one row per config and one
+// column per distinct module source, counting the module calls of each config to each
+// source. Rows and columns are sorted so the output only changes with the configs.
+type usageMatrix struct {
+	Sources []string    `json:"sources"`
+	Rows    []matrixRow `json:"rows"`
+}
+
+// matrixRow is the row of a single config, with one count per source of the matrix.
+type matrixRow struct {
+	Config string `json:"config"`
+	Counts []int  `json:"counts"`
+}
+
+// newUsageMatrix counts the module calls of each config by source. Modules are
+// grouped by their normalized source address without query parameters, so different
+// pins and spellings of the same source share a column, and a subdir is kept as part
+// of the source.
+func newUsageMatrix(sbom *SBOM) *usageMatrix {
+	counts := make(map[string]map[string]int)
+	sourceSet := make(map[string]bool)
+	for _, mod := range sbom.Modules {
+		source := matrixSource(mod)
+		sourceSet[source] = true
+		if counts[mod.Config] == nil {
+			counts[mod.Config] = make(map[string]int)
+		}
+		counts[mod.Config][source]++
+	}
+
+	matrix := &usageMatrix{Sources: make([]string, 0, len(sourceSet)), Rows: []matrixRow{}}
+	for source := range sourceSet {
+		matrix.Sources = append(matrix.Sources, source)
+	}
+	sort.Strings(matrix.Sources)
+
+	configs := make([]string, 0, len(counts))
+	for config := range counts {
+		configs = append(configs, config)
+	}
+	sort.Strings(configs)
+
+	for _, config := range configs {
+		row := matrixRow{Config: config, Counts: make([]int, len(matrix.Sources))}
+		for i, source := range matrix.Sources {
+			row.Counts[i] = counts[config][source]
+		}
+		matrix.Rows = append(matrix.Rows, row)
+	}
+	return matrix
+}
+
+// matrixSource returns the column a module is counted in.
+func matrixSource(mod ModuleInfo) string {
+	source := mod.Source
+	if mod.Subdir != "" {
+		source += "//" + mod.Subdir
+	}
+	source = normalizeSource(source)
+	if query := strings.Index(source, "?"); query > -1 {
+		source = source[:query]
+	}
+	return source
+}
+
+// writeUsageMatrix writes the usage matrix of the SBOM as CSV, with a Config Path
+// column followed by one column per source, or as JSON.
+func writeUsageMatrix(sbom *SBOM, format string, outputPath string) error {
+	matrix := newUsageMatrix(sbom)
+
+	var write func(w io.Writer) error
+	switch format {
+	case "csv":
+		write = func(w io.Writer) error {
+			writer := csv.NewWriter(w)
+			writer.Write(append([]string{"Config Path"}, matrix.Sources...))
+			for _, row := range matrix.Rows {
+				record := []string{row.Config}
+				for _, count := range row.Counts {
+					record = append(record, strconv.Itoa(count))
+				}
+				writer.Write(record)
+			}
+			writer.Flush()
+			return writer.Error()
+		}
+	case "json":
+		write = func(w io.Writer) error {
+			encoder := json.NewEncoder(w)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(matrix)
+		}
+	default:
+		return fmt.Errorf("usage matrix is only supported for csv and json output, not %s", format)
+	}
+
+	if err := writeFileAtomic(outputPath, write); err != nil {
+		return fmt.Errorf("failed to write usage matrix: %v", err)
+	}
+
+	fmt.Printf("Usage matrix successfully written to %s\n", outputPath)
+	return nil
+}
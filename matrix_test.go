@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestWriteUsageMatrix tests the usage matrix of a recursive scan in CSV and JSON,
+// with sorted rows and columns and different pins of a source sharing a column.
+func TestWriteUsageMatrix(t *testing.T) {
+	sbom, err := generateRecursiveSBOM(context.Background(), "testdata/matrix", scanOptions{}, nil)
+	if err != nil {
+		t.Fatalf("Failed to generate SBOM: %v", err)
+	}
+
+	dir := t.TempDir()
+	csvPath := filepath.Join(dir, "matrix.csv")
+	if err := writeUsageMatrix(sbom, "csv", csvPath); err != nil {
+		t.Fatalf("Failed to write CSV matrix: %v", err)
+	}
+	file, err := os.Open(csvPath)
+	if err != nil {
+		t.Fatalf("Failed to open CSV matrix: %v", err)
+	}
+	defer file.Close()
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to read CSV matrix: %v", err)
+	}
+
+	expected := [][]string{
+		{"Config Path", "./modules/flow-logs", "git::https://github.com/acme/terraform-vpc.git", "terraform-aws-modules/route53/aws//modules/zones"},
+		{"testdata/matrix/app", "0", "2", "1"},
+		{"testdata/matrix/network", "1", "1", "0"},
+	}
+	if !reflect.DeepEqual(records, expected) {
+		t.Errorf("Expected CSV matrix %v, got %v", expected, records)
+	}
+
+	jsonPath := filepath.Join(dir, "matrix.json")
+	if err := writeUsageMatrix(sbom, "json", jsonPath); err != nil {
+		t.Fatalf("Failed to write JSON matrix: %v", err)
+	}
+	content, err := os.ReadFile(jsonPath)
+	if err != nil {
+		t.Fatalf("Failed to read JSON matrix: %v", err)
+	}
+	var matrix usageMatrix
+	if err := json.Unmarshal(content, &matrix); err != nil {
+		t.Fatalf("Failed to parse JSON matrix: %v", err)
+	}
+	if !reflect.DeepEqual(matrix.Sources, expected[0][1:]) {
+		t.Errorf("Expected sources %v, got %v", expected[0][1:], matrix.Sources)
+	}
+	expectedRows := []matrixRow{
+		{Config: "testdata/matrix/app", Counts: []int{0, 2, 1}},
+		{Config: "testdata/matrix/network", Counts: []int{1, 1, 0}},
+	}
+	if !reflect.DeepEqual(matrix.Rows, expectedRows) {
+		t.Errorf("Expected rows %+v, got %+v", expectedRows, matrix.Rows)
+	}
+}
+
+// TestWriteUsageMatrixUnsupportedFormat tests that formats other than CSV and JSON are rejected.
+func TestWriteUsageMatrixUnsupportedFormat(t *testing.T) {
+	if err := writeUsageMatrix(mockSBOM(), "xml", filepath.Join(t.TempDir(), "matrix.xml")); err == nil {
+		t.Errorf("Expected an error writing the usage matrix as XML")
+	}
+}
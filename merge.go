@@ -0,0 +1,82 @@
+package main
+
+// moduleKey identifies a module call within a set of SBOMs.
+type moduleKey struct {
+	Config string
+	Name   string
+	Path   string
+}
+
+// keyOf returns the key identifying a module call: its full address when it has one,
+// which tells apart nested calls of the same name, and otherwise its config, name,
+// and the call path -flatten-nested records, which does the same.
+func keyOf(mod ModuleInfo) moduleKey {
+	if mod.Address != "" {
+		return moduleKey{Name: mod.Address}
+	}
+	return moduleKey{Config: mod.Config, Name: mod.Name, Path: mod.Path}
+}
+
+// mergeSBOMs combines several SBOMs into one. When the same module call, output, or
+// config appears in more than one input, the entry from the later input wins, so
+// merging a fresh scan after an older SBOM updates its entries.
+func mergeSBOMs(sboms ...*SBOM) *SBOM {
+	var merged SBOM
+
+	modules := make(map[moduleKey]int)
+	providers := make(map[moduleKey]int)
+	outputs := make(map[moduleKey]int)
+	configs := make(map[string]int)
+	warnings := make(map[string]bool)
+
+	for _, sbom := range sboms {
+		for _, mod := range sbom.Modules {
+			if i, ok := modules[keyOf(mod)]; ok {
+				merged.Modules[i] = mod
+				continue
+			}
+			modules[keyOf(mod)] = len(merged.Modules)
+			merged.Modules = append(merged.Modules, mod)
+		}
+
+		for _, provider := range sbom.Providers {
+			key := moduleKey{Config: provider.Config, Name: provider.Name}
+			if i, ok := providers[key]; ok {
+				merged.Providers[i] = provider
+				continue
+			}
+			providers[key] = len(merged.Providers)
+			merged.Providers = append(merged.Providers, provider)
+		}
+
+		for _, output := range sbom.Outputs {
+			key := moduleKey{Config: output.Config, Name: output.Name}
+			if i, ok := outputs[key]; ok {
+				merged.Outputs[i] = output
+				continue
+			}
+			outputs[key] = len(merged.Outputs)
+			merged.Outputs = append(merged.Outputs, output)
+		}
+
+		for _, config := range sbom.Configs {
+			if i, ok := configs[config.Path]; ok {
+				merged.Configs[i] = config
+				continue
+			}
+			configs[config.Path] = len(merged.Configs)
+			merged.Configs = append(merged.Configs, config)
+		}
+
+		for _, warning := range sbom.Warnings {
+			if !warnings[warning] {
+				warnings[warning] = true
+				merged.Warnings = append(merged.Warnings, warning)
+			}
+		}
+	}
+
+	sortSBOM(&merged)
+
+	return &merged
+}
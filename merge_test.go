@@ -0,0 +1,66 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestMergeSBOMs tests that later inputs replace matching entries and new entries are added.
+func TestMergeSBOMs(t *testing.T) {
+	first := &SBOM{
+		Modules: []ModuleInfo{
+			{Name: "vpc", Source: "terraform-aws-modules/vpc/aws", Version: "5.0.0", Config: "network"},
+			{Name: "dns", Source: "./modules/dns", Version: "local", Config: "network"},
+		},
+		Configs: []ConfigInfo{{Path: "network", LineCount: 10}},
+	}
+	second := &SBOM{
+		Modules: []ModuleInfo{
+			{Name: "vpc", Source: "terraform-aws-modules/vpc/aws", Version: "5.1.0", Config: "network"},
+			{Name: "app", Source: "./modules/app", Version: "local", Config: "app"},
+		},
+		Configs: []ConfigInfo{{Path: "network", LineCount: 12}},
+	}
+
+	merged := mergeSBOMs(first, second)
+
+	expected := []ModuleInfo{
+		{Name: "app", Source: "./modules/app", Version: "local", Config: "app"},
+		{Name: "dns", Source: "./modules/dns", Version: "local", Config: "network"},
+		{Name: "vpc", Source: "terraform-aws-modules/vpc/aws", Version: "5.1.0", Config: "network"},
+	}
+	if !reflect.DeepEqual(merged.Modules, expected) {
+		t.Errorf("Merged modules mismatch:\nexpected %v\ngot      %v", expected, merged.Modules)
+	}
+
+	expectedConfigs := []ConfigInfo{{Path: "network", LineCount: 12}}
+	if !reflect.DeepEqual(merged.Configs, expectedConfigs) {
+		t.Errorf("Merged configs mismatch: expected %v, got %v", expectedConfigs, merged.Configs)
+	}
+}
+
+// TestReadSBOM tests reading back SBOMs written as CSV, JSON, and XML.
+func TestReadSBOM(t *testing.T) {
+	sbom := mockSBOM()
+	dir := t.TempDir()
+
+	for format, write := range map[string]func(*SBOM, string) error{"csv": writeSBOMToCSV, "json": writeSBOMToJSON, "xml": writeSBOMToXML} {
+		path := filepath.Join(dir, "sbom."+format)
+		if err := write(sbom, path); err != nil {
+			t.Fatalf("Failed to write %s SBOM: %v", format, err)
+		}
+
+		result, err := readSBOM(path)
+		if err != nil {
+			t.Fatalf("Failed to read %s SBOM: %v", format, err)
+		}
+		if !reflect.DeepEqual(result.Modules, sbom.Modules) {
+			t.Errorf("%s SBOM mismatch: expected %v, got %v", format, sbom.Modules, result.Modules)
+		}
+	}
+
+	if _, err := readSBOM(filepath.Join(dir, "sbom.txt")); err == nil {
+		t.Error("Expected an error reading an unsupported SBOM file")
+	}
+}
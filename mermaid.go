@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// mermaidUnsafeChars matches characters that are not allowed in Mermaid node identifiers.
+var mermaidUnsafeChars = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// mermaidLabelEscaper escapes characters that would end or break a quoted Mermaid label.
+var mermaidLabelEscaper = strings.NewReplacer(`"`, "#quot;", "<", "#lt;", ">", "#gt;")
+
+// mermaidIDs assigns unique, syntax-safe node identifiers to arbitrary names.
+type mermaidIDs struct {
+	ids  map[string]string
+	used map[string]bool
+}
+
+// newMermaidIDs creates an empty identifier table.
+func newMermaidIDs() *mermaidIDs {
+	return &mermaidIDs{ids: make(map[string]string), used: make(map[string]bool)}
+}
+
+// id returns the identifier for a name, creating it on first use. Unsafe characters
+// are replaced with underscores and a numeric suffix keeps identifiers unique when
+// different names sanitize to the same string.
+func (m *mermaidIDs) id(prefix, name string) string {
+	key := prefix + "\x00" + name
+	if id, ok := m.ids[key]; ok {
+		return id
+	}
+
+	base := prefix + "_" + mermaidUnsafeChars.ReplaceAllString(name, "_")
+	id := base
+	for i := 2; m.used[id]; i++ {
+		id = fmt.Sprintf("%s_%d", base, i)
+	}
+
+	m.ids[key] = id
+	m.used[id] = true
+	return id
+}
+
+// mermaidLabel quotes a node label, escaping characters Mermaid would misinterpret.
+// Lines are separated with <br/>, which Mermaid renders as a line break.
+func mermaidLabel(lines ...string) string {
+	for i, line := range lines {
+		lines[i] = mermaidLabelEscaper.Replace(line)
+	}
+	return `"` + strings.Join(lines, "<br/>") + `"`
+}
+
+// writeSBOMToMermaid writes the SBOM as a Mermaid graph TD diagram linking each config
+// to the modules it calls. Modules read from a module manifest are named by their
+// dotted call path and are linked to the module that calls them instead.
+func writeSBOMToMermaid(sbom *SBOM, outputPath string) error {
+	err := writeFileAtomic(outputPath, func(file io.Writer) error {
+		w := bufio.NewWriter(file)
+		writeMermaidGraph(w, sbom)
+		return w.Flush()
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write Mermaid file: %v", err)
+	}
+
+	fmt.Printf("SBOM successfully written to %s\n", outputPath)
+	return nil
+}
+
+// writeMermaidGraph writes the graph TD diagram of the SBOM modules.
+func writeMermaidGraph(w io.Writer, sbom *SBOM) {
+	ids := newMermaidIDs()
+
+	fmt.Fprintln(w, "graph TD")
+
+	declared := make(map[string]bool)
+	modules := make(map[moduleKey]bool)
+	for _, mod := range sbom.Modules {
+		modules[keyOf(mod)] = true
+	}
+
+	for _, mod := range sbom.Modules {
+		configID := ids.id("config", mod.Config)
+		if !declared[configID] {
+			fmt.Fprintf(w, "    %s[%s]\n", configID, mermaidLabel(mod.Config))
+			declared[configID] = true
+		}
+
+		parentID := configID
+		if i := strings.LastIndex(mod.Name, "."); i > -1 {
+			parent := moduleKey{Config: mod.Config, Name: mod.Name[:i]}
+			if modules[parent] {
+				parentID = ids.id("module", parent.Config+"/"+parent.Name)
+			}
+		}
+
+		moduleID := ids.id("module", mod.Config+"/"+mod.Name)
+		fmt.Fprintf(w, "    %s --> %s[%s]\n", parentID, moduleID, mermaidLabel(mod.Name, mod.Version))
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestWriteSBOMToMermaid tests the diagram for a manifest scan, including nested modules.
+func TestWriteSBOMToMermaid(t *testing.T) {
+	sbom, err := generateSBOM(context.Background(), "testdata/manifest", scanOptions{FromManifest: true})
+	if err != nil {
+		t.Fatalf("Failed to generate SBOM: %v", err)
+	}
+
+	outputPath := filepath.Join(t.TempDir(), "sbom.mmd")
+	if err := writeSBOMToMermaid(sbom, outputPath); err != nil {
+		t.Fatalf("Failed to write SBOM to Mermaid: %v", err)
+	}
+
+	content, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("Failed to read Mermaid file: %v", err)
+	}
+
+	expected := `graph TD
+    config_testdata_manifest["testdata/manifest"]
+    config_testdata_manifest --> module_testdata_manifest_app["app<br/>local"]
+    module_testdata_manifest_app --> module_testdata_manifest_app_database["app.database<br/>v1.4.2"]
+    module_testdata_manifest_app_database --> module_testdata_manifest_app_database_subnets["app.database.subnets<br/>2.3.0"]
+    config_testdata_manifest --> module_testdata_manifest_vpc["vpc<br/>5.1.2"]
+`
+	if string(content) != expected {
+		t.Errorf("Mermaid output mismatch:\nexpected:\n%s\ngot:\n%s", expected, content)
+	}
+}
+
+// TestMermaidIDs tests that identifiers are sanitized and stay unique.
+func TestMermaidIDs(t *testing.T) {
+	ids := newMermaidIDs()
+
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"live/vpc-east", "config_live_vpc_east"},
+		{"live/vpc_east", "config_live_vpc_east_2"},
+		{"live/vpc-east", "config_live_vpc_east"},
+		{"live/vpc east", "config_live_vpc_east_3"},
+	}
+	for _, tt := range tests {
+		if got := ids.id("config", tt.name); got != tt.expected {
+			t.Errorf("id(%q) = %q, expected %q", tt.name, got, tt.expected)
+		}
+	}
+}
+
+// TestMermaidLabel tests escaping of characters that would break a quoted label.
+func TestMermaidLabel(t *testing.T) {
+	got := mermaidLabel(`say "hi"`, "<1.0")
+	expected := `"say #quot;hi#quot;<br/>#lt;1.0"`
+	if got != expected {
+		t.Errorf("mermaidLabel = %s, expected %s", got, expected)
+	}
+}
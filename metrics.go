@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// terraformVersionFile pins the Terraform CLI version of a config for version managers
+// such as tfenv.
+const terraformVersionFile = ".terraform-version"
+
+// countLines returns the number of non-empty lines across the given files.
+func countLines(paths []string) (int, error) {
+	total := 0
+	for _, path := range paths {
+		file, err := os.Open(path)
+		if err != nil {
+			return 0, fmt.Errorf("failed to open %s: %v", path, err)
+		}
+
+		scanner := bufio.NewScanner(file)
+		scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+		for scanner.Scan() {
+			if strings.TrimSpace(scanner.Text()) != "" {
+				total++
+			}
+		}
+		err = scanner.Err()
+		file.Close()
+		if err != nil {
+			return 0, fmt.Errorf("failed to read %s: %v", path, err)
+		}
+	}
+
+	return total, nil
+}
+
+// setModuleDocs records whether each local module has a README and, if so, the
+// description it gives. Only local modules are checked, since the source of remote
+// modules is not available without downloading them.
+func setModuleDocs(modules []ModuleInfo) {
+	for i := range modules {
+		if modules[i].SourceType != sourceTypeLocal {
+			continue
+		}
+		dir := filepath.Join(modules[i].Config, modules[i].Source, modules[i].Subdir)
+		description, hasReadme := moduleDocs(dir)
+		modules[i].Description = description
+		modules[i].HasReadme = &hasReadme
+	}
+}
+
+// moduleDocs looks for a README.md in a module directory, matching the file name
+// case-insensitively, and returns the first paragraph of text after its title as the
+// module description. Terraform modules have no description attribute of their own,
+// and the registry shows the README in the same way.
+func moduleDocs(dir string) (string, bool) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", false
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.EqualFold(entry.Name(), "README.md") {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return "", true
+		}
+		return readmeDescription(string(content)), true
+	}
+
+	return "", false
+}
+
+// readmeDescription returns the first paragraph of a Markdown document that is not a
+// heading, badge, or HTML, joined into a single line.
+func readmeDescription(content string) string {
+	var paragraph []string
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "":
+			if len(paragraph) > 0 {
+				return strings.Join(paragraph, " ")
+			}
+		case len(paragraph) == 0 && (strings.HasPrefix(line, "#") || strings.HasPrefix(line, "[![") ||
+			strings.HasPrefix(line, "![") || strings.HasPrefix(line, "<")):
+			// Skip headings, badges, images, and HTML before the first paragraph.
+		default:
+			paragraph = append(paragraph, line)
+		}
+	}
+	return strings.Join(paragraph, " ")
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestCountLines tests that blank and whitespace-only lines are not counted.
+func TestCountLines(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"main.tf":      "module \"vpc\" {\n\n  source = \"./vpc\"\n   \n}\n",
+		"outputs.tf":   "output \"id\" {\n  value = 1\n}",
+		"vars.tf.json": "{\n  \"variable\": {}\n}\n\n",
+	}
+
+	var paths []string
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+
+	count, err := countLines(paths)
+	if err != nil {
+		t.Fatalf("Failed to count lines: %v", err)
+	}
+	if count != 9 {
+		t.Errorf("Expected 9 lines, got %d", count)
+	}
+}
+
+// TestGenerateSBOMMetrics tests that per-config metrics are only collected when requested.
+func TestGenerateSBOMMetrics(t *testing.T) {
+	sbom, err := generateSBOM(context.Background(), "testdata/outputs", scanOptions{})
+	if err != nil {
+		t.Fatalf("Failed to generate SBOM: %v", err)
+	}
+	if len(sbom.Configs) != 0 {
+		t.Errorf("Expected no config metrics by default, got %v", sbom.Configs)
+	}
+
+	sbom, err = generateSBOM(context.Background(), "testdata/outputs", scanOptions{Metrics: true})
+	if err != nil {
+		t.Fatalf("Failed to generate SBOM: %v", err)
+	}
+	if len(sbom.Configs) != 1 {
+		t.Fatalf("Expected 1 config, got %d", len(sbom.Configs))
+	}
+	if sbom.Configs[0].LineCount != 13 {
+		t.Errorf("Expected 13 lines, got %d", sbom.Configs[0].LineCount)
+	}
+}
+
+// TestGenerateSBOMCLIVersion tests recording the Terraform CLI version pinned in a
+// .terraform-version file with -metrics, and leaving it empty without one.
+func TestGenerateSBOMCLIVersion(t *testing.T) {
+	sbom, err := generateSBOM(context.Background(), "testdata/terraform-version", scanOptions{Metrics: true})
+	if err != nil {
+		t.Fatalf("Failed to generate SBOM: %v", err)
+	}
+	if len(sbom.Configs) != 1 || sbom.Configs[0].CLIVersion != "1.7.5" {
+		t.Errorf("Expected CLI version 1.7.5, got %+v", sbom.Configs)
+	}
+
+	sbom, err = generateSBOM(context.Background(), "testdata/outputs", scanOptions{Metrics: true})
+	if err != nil {
+		t.Fatalf("Failed to generate SBOM: %v", err)
+	}
+	if sbom.Configs[0].CLIVersion != "" {
+		t.Errorf("Expected no CLI version without a .terraform-version file, got %q", sbom.Configs[0].CLIVersion)
+	}
+}
+
+// TestGenerateSBOMModuleDocs tests README detection and descriptions for local modules.
+func TestGenerateSBOMModuleDocs(t *testing.T) {
+	sbom, err := generateSBOM(context.Background(), "testdata/docs", scanOptions{Metrics: true})
+	if err != nil {
+		t.Fatalf("Failed to generate SBOM: %v", err)
+	}
+
+	yes, no := true, false
+	expected := map[string]struct {
+		description string
+		hasReadme   *bool
+	}{
+		"documented":   {"Creates the shared network for an environment, including public and private subnets.", &yes},
+		"undocumented": {"", &no},
+		"vpc":          {"", nil},
+	}
+	for _, mod := range sbom.Modules {
+		want := expected[mod.Name]
+		if mod.Description != want.description || !reflect.DeepEqual(mod.HasReadme, want.hasReadme) {
+			t.Errorf("Docs mismatch for %s: expected %q (README %v), got %q (README %v)",
+				mod.Name, want.description, optionalBool(want.hasReadme), mod.Description, optionalBool(mod.HasReadme))
+		}
+	}
+
+	sbom, err = generateSBOM(context.Background(), "testdata/docs", scanOptions{})
+	if err != nil {
+		t.Fatalf("Failed to generate SBOM: %v", err)
+	}
+	for _, mod := range sbom.Modules {
+		if mod.HasReadme != nil || mod.Description != "" {
+			t.Errorf("Expected no docs for %s without metrics, got %+v", mod.Name, mod)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sort"
+)
+
+// flattenNestedModules expands the module calls of a config with the calls made by
+// its local modules, and by the local modules those call in turn, into one flat list.
+// Each entry records its position in Path, such as root > networking > subnet, and
+// belongs to the scanned config. Remote modules are listed but not descended into,
+// since their contents are not on disk. A local module that calls back into one of
+// its callers is reported as a warning instead of being expanded again. Files of local
+// modules larger than maxFileSize are skipped with a warning, as in the config itself.
+func flattenNestedModules(ctx context.Context, configPath string, modules []ModuleInfo, maxFileSize int64) ([]ModuleInfo, []string, error) {
+	root := filepath.Clean(configPath)
+
+	var flattened []ModuleInfo
+	var warnings []string
+
+	var walk func(dir string, calls []ModuleInfo, parent string, stack []string) error
+	walk = func(dir string, calls []ModuleInfo, parent string, stack []string) error {
+		sort.Slice(calls, func(i, j int) bool {
+			return calls[i].Name < calls[j].Name
+		})
+
+		for _, mod := range calls {
+			if err := ctx.Err(); err != nil {
+				return fmt.Errorf("scan aborted: %w", err)
+			}
+
+			mod.Path = parent + " > " + mod.Name
+			flattened = append(flattened, mod)
+
+			if mod.SourceType != sourceTypeLocal {
+				continue
+			}
+
+			child := filepath.Join(dir, mod.Source)
+			if slices.Contains(stack, child) {
+				warnings = append(warnings, fmt.Sprintf("%s: module %s calls %s, which is already in its call path; skipping its nested modules", configPath, mod.Path, mod.Source))
+				continue
+			}
+
+			childCalls, childWarnings := localModuleCalls(child, configPath, maxFileSize)
+			warnings = append(warnings, childWarnings...)
+			if err := walk(child, childCalls, mod.Path, append(slices.Clip(stack), child)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := walk(root, slices.Clone(modules), "root", []string{root}); err != nil {
+		return nil, nil, err
+	}
+
+	return flattened, warnings, nil
+}
+
+// localModuleCalls loads the module calls declared by a local module. The calls are
+// recorded against the scanned config; problems loading the module are returned as
+// warnings, since the caller's own entry is still valid.
+func localModuleCalls(dir string, configPath string, maxFileSize int64) ([]ModuleInfo, []string) {
+	if !isConfigDir(dir) {
+		return nil, []string{fmt.Sprintf("%s: no Terraform configuration found in local module %s", configPath, dir)}
+	}
+
+	module, diag := loadModule(dir, maxFileSize)
+	warnings := oversizedFiles(dir, maxFileSize)
+	for _, d := range diag {
+		warnings = append(warnings, diagnosticString(dir, d))
+	}
+	if module == nil {
+		return nil, warnings
+	}
+
+	rawFiles := parseRawFiles(dir, maxFileSize)
+	providerMappings := moduleProviderMappings(rawFiles)
+	dependsOn := moduleDependsOn(rawFiles)
+
+	warnings = append(warnings, credentialWarnings(module, dir)...)
+
+	var calls []ModuleInfo
+	for _, modCall := range module.ModuleCalls {
+		calls = append(calls, newModuleInfo(modCall, configPath, providerMappings, dependsOn))
+	}
+	warnings = append(warnings, setIgnoredRules(calls, moduleIgnoreDirectives(rawFiles))...)
+	return calls, warnings
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+// TestFlattenNested tests that local modules are expanded into a flat list with their call paths.
+func TestFlattenNested(t *testing.T) {
+	sbom, err := generateSBOM(context.Background(), "testdata/nested", scanOptions{FlattenNested: true})
+	if err != nil {
+		t.Fatalf("Failed to generate SBOM: %v", err)
+	}
+
+	var paths []string
+	for _, mod := range sbom.Modules {
+		if mod.Config != "testdata/nested" {
+			t.Errorf("Expected module %s to belong to testdata/nested, got %s", mod.Path, mod.Config)
+		}
+		paths = append(paths, mod.Path)
+	}
+
+	expected := []string{
+		"root > networking",
+		"root > networking > dns",
+		"root > networking > subnet",
+		"root > networking > subnet > label",
+		"root > vpc",
+	}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Errorf("Call paths mismatch:\nexpected %v\ngot      %v", expected, paths)
+	}
+
+	label := sbom.Modules[3]
+	if label.Name != "label" || label.SourceType != sourceTypeGit || label.Version != "v1.0.0" || label.Organization != "acme" {
+		t.Errorf("Unexpected nested remote module: %+v", label)
+	}
+	if len(sbom.Warnings) != 0 {
+		t.Errorf("Expected no warnings, got %v", sbom.Warnings)
+	}
+}
+
+// TestFlattenNestedCycle tests that a local module calling back into its caller is reported once.
+func TestFlattenNestedCycle(t *testing.T) {
+	sbom, err := generateSBOM(context.Background(), "testdata/nested-cycle", scanOptions{FlattenNested: true})
+	if err != nil {
+		t.Fatalf("Failed to generate SBOM: %v", err)
+	}
+
+	var paths []string
+	for _, mod := range sbom.Modules {
+		paths = append(paths, mod.Path)
+	}
+
+	expected := []string{"root > a", "root > a > b", "root > a > b > a"}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Errorf("Call paths mismatch:\nexpected %v\ngot      %v", expected, paths)
+	}
+
+	warnings := []string{"testdata/nested-cycle: module root > a > b > a calls ../a, which is already in its call path; skipping its nested modules"}
+	if !reflect.DeepEqual(sbom.Warnings, warnings) {
+		t.Errorf("Warnings mismatch:\nexpected %v\ngot      %v", warnings, sbom.Warnings)
+	}
+}
+
+// TestFlattenNestedSharedName tests that nested calls of the same name reached through
+// different callers are kept apart when merging and when applying ignore directives.
+func TestFlattenNestedSharedName(t *testing.T) {
+	sbom, err := generateSBOM(context.Background(), "testdata/nested-shared", scanOptions{FlattenNested: true})
+	if err != nil {
+		t.Fatalf("Failed to generate SBOM: %v", err)
+	}
+
+	var paths []string
+	for _, mod := range mergeSBOMs(sbom, sbom).Modules {
+		paths = append(paths, mod.Path)
+	}
+	expected := []string{"root > a", "root > a > vpc", "root > b", "root > b > vpc"}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Errorf("Merged call paths mismatch:\nexpected %v\ngot      %v", expected, paths)
+	}
+
+	var failing []string
+	for _, violation := range filterIgnored(unpinnedViolations(sbom.Modules), sbom.Modules) {
+		failing = append(failing, violation.Path)
+	}
+	if !reflect.DeepEqual(failing, []string{"root > b > vpc"}) {
+		t.Errorf("Expected only the vpc called by b to be unpinned, got %v", failing)
+	}
+}
+
+// TestFlattenNestedDisabled tests that only the root module calls are recorded by default.
+func TestFlattenNestedDisabled(t *testing.T) {
+	sbom, err := generateSBOM(context.Background(), "testdata/nested", scanOptions{})
+	if err != nil {
+		t.Fatalf("Failed to generate SBOM: %v", err)
+	}
+
+	if len(sbom.Modules) != 2 || sbom.Modules[0].Path != "" {
+		t.Errorf("Expected 2 root modules without call paths, got %+v", sbom.Modules)
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// loadVersionOverrides reads a file mapping module sources to the versions they should
+// be modeled at, one whitespace-separated source and version pair per line, such as
+// terraform-aws-modules/vpc/aws 5.2.0. Blank lines and lines starting with # are
+// ignored. Any query string, such as ?ref=v1.0.0, is dropped from the source.
+func loadVersionOverrides(path string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open version overrides: %v", err)
+	}
+	defer file.Close()
+
+	overrides := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		fields := strings.Fields(text)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("%s:%d: expected a module source and a version", path, line)
+		}
+		overrides[overrideKey(fields[0])] = fields[1]
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read version overrides: %v", err)
+	}
+
+	return overrides, nil
+}
+
+// overrideKey returns the part of a module source that overrides are matched on: its
+// normalized address without the query string.
+func overrideKey(source string) string {
+	source = normalizeSource(source)
+	if query := strings.Index(source, "?"); query > -1 {
+		return source[:query]
+	}
+	return source
+}
+
+// applyVersionOverrides sets the version of every module whose source has an
+// override, keeping the version the configuration declares in OriginalVersion, and
+// returns the number of modules changed. Local modules are not versioned and are
+// left alone. Registry modules have their normalized constraint recomputed, and every
+// changed module its package URL and, with -explain, its version source.
+func applyVersionOverrides(modules []ModuleInfo, overrides map[string]string) int {
+	changed := 0
+	for i := range modules {
+		mod := &modules[i]
+		if mod.SourceType == sourceTypeLocal {
+			continue
+		}
+
+		version, ok := overrides[overrideKey(mod.Source)]
+		if !ok || version == mod.Version {
+			continue
+		}
+
+		if mod.OriginalVersion == "" {
+			mod.OriginalVersion = mod.Version
+		}
+		mod.Version = version
+		changed++
+
+		if mod.SourceType == sourceTypeRegistry {
+			mod.NormalizedVersion, _ = normalizeConstraint(version)
+		}
+		mod.PURL = modulePURL(*mod)
+		if mod.VersionSource != "" {
+			mod.VersionSource = versionSourceOverride
+		}
+	}
+	return changed
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestLoadVersionOverrides tests reading source and version pairs, ignoring comments and query strings.
+func TestLoadVersionOverrides(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "overrides.txt")
+	content := "# Bump everything\nterraform-aws-modules/vpc/aws  5.2.0\n\ngit::https://github.com/acme/labels.git?ref=v1.0.0 v2.0.0\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write overrides: %v", err)
+	}
+
+	overrides, err := loadVersionOverrides(path)
+	if err != nil {
+		t.Fatalf("Failed to load overrides: %v", err)
+	}
+
+	expected := map[string]string{
+		"terraform-aws-modules/vpc/aws":           "5.2.0",
+		"git::https://github.com/acme/labels.git": "v2.0.0",
+	}
+	if !reflect.DeepEqual(overrides, expected) {
+		t.Errorf("Overrides mismatch: expected %v, got %v", expected, overrides)
+	}
+}
+
+// TestLoadVersionOverridesInvalid tests that a line without a version is reported.
+func TestLoadVersionOverridesInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "overrides.txt")
+	if err := os.WriteFile(path, []byte("terraform-aws-modules/vpc/aws\n"), 0644); err != nil {
+		t.Fatalf("Failed to write overrides: %v", err)
+	}
+
+	if _, err := loadVersionOverrides(path); err == nil {
+		t.Errorf("Expected an error for a line without a version")
+	}
+}
+
+// TestApplyVersionOverrides tests that only modules with a matching source are changed.
+func TestApplyVersionOverrides(t *testing.T) {
+	modules := []ModuleInfo{
+		{Name: "vpc", Source: "terraform-aws-modules/vpc/aws", SourceType: sourceTypeRegistry, Version: "~> 5.0", NormalizedVersion: "~> 5.0"},
+		{Name: "labels", Source: "git::https://github.com/acme/labels.git?ref=v1.0.0", SourceType: sourceTypeGit, Version: "v1.0.0", VersionSource: versionSourceRefQuery},
+		{Name: "dns", Source: "acme/dns/aws", SourceType: sourceTypeRegistry, Version: "1.2.0", NormalizedVersion: "= 1.2.0"},
+		{Name: "eks", Source: "terraform-aws-modules/eks/aws", SourceType: sourceTypeRegistry, Version: "20.0.0", NormalizedVersion: "= 20.0.0"},
+		{Name: "service", Source: "./modules/service", SourceType: sourceTypeLocal, Version: "local"},
+	}
+	overrides := map[string]string{
+		"terraform-aws-modules/vpc/aws":           "5.2.0",
+		"git::https://github.com/acme/labels.git": "v2.0.0",
+		"terraform-aws-modules/eks/aws":           "20.0.0",
+		"./modules/service":                       "1.0.0",
+	}
+
+	if changed := applyVersionOverrides(modules, overrides); changed != 2 {
+		t.Errorf("Expected 2 modules changed, got %d", changed)
+	}
+
+	expected := []ModuleInfo{
+		{Name: "vpc", Source: "terraform-aws-modules/vpc/aws", SourceType: sourceTypeRegistry, Version: "5.2.0", OriginalVersion: "~> 5.0", NormalizedVersion: "= 5.2.0", PURL: "pkg:terraform/terraform-aws-modules/vpc/aws@5.2.0"},
+		{Name: "labels", Source: "git::https://github.com/acme/labels.git?ref=v1.0.0", SourceType: sourceTypeGit, Version: "v2.0.0", VersionSource: versionSourceOverride, OriginalVersion: "v1.0.0", PURL: "pkg:terraform/acme/labels@v2.0.0?vcs_url=git%2Bhttps:%2F%2Fgithub.com%2Facme%2Flabels.git"},
+		{Name: "dns", Source: "acme/dns/aws", SourceType: sourceTypeRegistry, Version: "1.2.0", NormalizedVersion: "= 1.2.0"},
+		{Name: "eks", Source: "terraform-aws-modules/eks/aws", SourceType: sourceTypeRegistry, Version: "20.0.0", NormalizedVersion: "= 20.0.0"},
+		{Name: "service", Source: "./modules/service", SourceType: sourceTypeLocal, Version: "local"},
+	}
+	if !reflect.DeepEqual(modules, expected) {
+		t.Errorf("Modules mismatch:\nexpected %+v\ngot      %+v", expected, modules)
+	}
+}
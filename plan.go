@@ -0,0 +1,164 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-config-inspect/tfconfig"
+)
+
+// terraformPlan is the part of the JSON representation of a saved plan, as printed by
+// terraform show -json, that describes the configuration and the resources it changes.
+type terraformPlan struct {
+	FormatVersion   string               `json:"format_version"`
+	Configuration   planConfiguration    `json:"configuration"`
+	ResourceChanges []planResourceChange `json:"resource_changes"`
+}
+
+type planConfiguration struct {
+	ProviderConfig map[string]planProviderConfig `json:"provider_config"`
+	RootModule     planModule                    `json:"root_module"`
+}
+
+// planProviderConfig is a provider configuration. ModuleAddress is empty for
+// providers configured in the root module.
+type planProviderConfig struct {
+	Name              string `json:"name"`
+	FullName          string `json:"full_name"`
+	VersionConstraint string `json:"version_constraint"`
+	ModuleAddress     string `json:"module_address"`
+}
+
+type planModule struct {
+	ModuleCalls map[string]planModuleCall `json:"module_calls"`
+}
+
+// planModuleCall is a module call, with the configuration of the called module.
+type planModuleCall struct {
+	Source            string     `json:"source"`
+	VersionConstraint string     `json:"version_constraint"`
+	Module            planModule `json:"module"`
+}
+
+// planResourceChange is a change the plan makes to a resource instance.
+type planResourceChange struct {
+	Mode         string `json:"mode"`
+	ProviderName string `json:"provider_name"`
+	Change       struct {
+		Actions []string `json:"actions"`
+	} `json:"change"`
+}
+
+// generateSBOMFromPlan generates an SBOM from the JSON representation of a saved plan,
+// as printed by terraform show -json plan.out. The modules are every module call in
+// the plan's configuration, nested ones included, with their call path such as
+// root > networking > subnet. The providers are the root module's provider
+// configurations, and the managed resources the plan creates, updates, or keeps are
+// counted per provider, so the SBOM reflects what will actually be applied. Resources
+// the plan only deletes are not counted.
+func generateSBOMFromPlan(planPath string, opts scanOptions) (*SBOM, error) {
+	content, err := os.ReadFile(planPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plan: %v", err)
+	}
+
+	var plan terraformPlan
+	if err := json.Unmarshal(content, &plan); err != nil {
+		return nil, fmt.Errorf("failed to parse plan: %v", err)
+	}
+	if plan.FormatVersion == "" {
+		return nil, fmt.Errorf("failed to parse plan: %s is not the output of terraform show -json", planPath)
+	}
+
+	var sbom SBOM
+	sbom.Modules = planModules(plan.Configuration.RootModule, planPath, "root")
+
+	providerNames := make(map[string]string)
+	for _, config := range plan.Configuration.ProviderConfig {
+		providerNames[config.FullName] = config.Name
+		if config.ModuleAddress != "" {
+			continue
+		}
+		sbom.Providers = append(sbom.Providers, ProviderInfo{
+			Name:              config.Name,
+			Source:            strings.TrimPrefix(config.FullName, defaultRegistryHost+"/"),
+			VersionConstraint: config.VersionConstraint,
+			Config:            planPath,
+		})
+	}
+
+	if counts := planResourceCounts(plan.ResourceChanges, providerNames); counts != nil {
+		sbom.Configs = append(sbom.Configs, ConfigInfo{Path: planPath, ResourceCounts: counts})
+	}
+
+	setRegistries(sbom.Modules, opts.PrivateRegistryHosts)
+	if opts.NoVersionHeuristics {
+		dropVersionHeuristics(sbom.Modules)
+	}
+	if opts.Explain {
+		setVersionSources(sbom.Modules)
+	}
+	setNormalizedVersions(sbom.Modules)
+	setOrganizations(sbom.Modules)
+	setInsecure(sbom.Modules)
+	redactSources(&sbom)
+	setNormalizedSources(sbom.Modules)
+	setPURLs(&sbom)
+	sortSBOM(&sbom)
+
+	return &sbom, nil
+}
+
+// planModules returns the module calls of a module in the plan's configuration and,
+// recursively, of the modules they call. Each is given its call path below parent.
+func planModules(module planModule, planPath, parent string) []ModuleInfo {
+	names := make([]string, 0, len(module.ModuleCalls))
+	for name := range module.ModuleCalls {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var modules []ModuleInfo
+	for _, name := range names {
+		call := module.ModuleCalls[name]
+		source, subdir := splitSubdir(call.Source)
+		path := parent + " > " + name
+		modules = append(modules, ModuleInfo{
+			Name:       name,
+			Path:       path,
+			Source:     source,
+			Subdir:     subdir,
+			SourceType: sourceType(call.Source),
+			Version:    extractVersion(&tfconfig.ModuleCall{Source: call.Source, Version: call.VersionConstraint}),
+			Config:     planPath,
+		})
+		modules = append(modules, planModules(call.Module, planPath, path)...)
+	}
+	return modules
+}
+
+// planResourceCounts counts the managed resource instances the plan keeps, by the
+// local name of their provider. Providers without a configuration in the plan are
+// counted by their type.
+func planResourceCounts(changes []planResourceChange, providerNames map[string]string) ResourceCounts {
+	counts := make(ResourceCounts)
+	for _, change := range changes {
+		if change.Mode != "managed" || slices.Equal(change.Change.Actions, []string{"delete"}) {
+			continue
+		}
+
+		name, ok := providerNames[change.ProviderName]
+		if !ok {
+			name = change.ProviderName[strings.LastIndex(change.ProviderName, "/")+1:]
+		}
+		counts[name]++
+	}
+	if len(counts) == 0 {
+		return nil
+	}
+	return counts
+}
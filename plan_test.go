@@ -0,0 +1,67 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestGenerateSBOMFromPlan tests extracting nested modules, providers, and the
+// resources a plan applies from the JSON output of terraform show.
+func TestGenerateSBOMFromPlan(t *testing.T) {
+	planPath := "testdata/plan/plan.json"
+	sbom, err := generateSBOMFromPlan(planPath, scanOptions{})
+	if err != nil {
+		t.Fatalf("Failed to generate SBOM from plan: %v", err)
+	}
+
+	type module struct {
+		Name, Path, Source, SourceType, Version string
+	}
+	var modules []module
+	for _, mod := range sbom.Modules {
+		modules = append(modules, module{mod.Name, mod.Path, mod.Source, mod.SourceType, mod.Version})
+		if mod.Config != planPath {
+			t.Errorf("Expected module %s to be recorded in %s, got %s", mod.Name, planPath, mod.Config)
+		}
+	}
+	expectedModules := []module{
+		{"dns", "root > dns", "git::https://github.com/acme/terraform-dns.git?ref=v1.2.0", sourceTypeGit, "v1.2.0"},
+		{"network", "root > network", "terraform-aws-modules/vpc/aws", sourceTypeRegistry, "5.1.0"},
+		{"subnets", "root > network > subnets", "./modules/subnets", sourceTypeLocal, "local"},
+	}
+	if !reflect.DeepEqual(modules, expectedModules) {
+		t.Errorf("Expected modules %+v, got %+v", expectedModules, modules)
+	}
+
+	expectedProviders := []ProviderInfo{
+		{Name: "aws", Source: "hashicorp/aws", VersionConstraint: "~> 5.0", PURL: "pkg:terraform/hashicorp/aws", Config: planPath},
+		{Name: "random", Source: "hashicorp/random", VersionConstraint: "3.5.1", PURL: "pkg:terraform/hashicorp/random@3.5.1", Config: planPath},
+	}
+	if !reflect.DeepEqual(sbom.Providers, expectedProviders) {
+		t.Errorf("Expected providers %+v, got %+v", expectedProviders, sbom.Providers)
+	}
+
+	expectedConfigs := []ConfigInfo{{Path: planPath, ResourceCounts: ResourceCounts{"aws": 3, "random": 1}}}
+	if !reflect.DeepEqual(sbom.Configs, expectedConfigs) {
+		t.Errorf("Expected configs %+v, got %+v", expectedConfigs, sbom.Configs)
+	}
+}
+
+// TestGenerateSBOMFromPlanInvalid tests that files other than plan JSON are rejected.
+func TestGenerateSBOMFromPlanInvalid(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"state.json": `{"version": 4, "resources": []}`,
+		"plan.out":   "PK\x03\x04",
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := generateSBOMFromPlan(path, scanOptions{}); err == nil {
+			t.Errorf("Expected an error reading %s as a plan", name)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// sourcePolicy decides whether module sources are approved, based on an optional
+// allowlist and denylist of glob patterns. In a pattern, * matches any run of
+// characters (including /) and ? matches a single character.
+type sourcePolicy struct {
+	allow []*regexp.Regexp
+	deny  []*regexp.Regexp
+}
+
+// loadPatterns reads newline-separated source patterns from a file, ignoring
+// blank lines and lines starting with #.
+func loadPatterns(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open pattern file: %v", err)
+	}
+	defer file.Close()
+
+	var patterns []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read pattern file: %v", err)
+	}
+
+	return patterns, nil
+}
+
+// newSourcePolicy compiles the allowlist and denylist patterns into a policy.
+func newSourcePolicy(allowPatterns, denyPatterns []string) *sourcePolicy {
+	policy := &sourcePolicy{}
+	for _, pattern := range allowPatterns {
+		policy.allow = append(policy.allow, globRegexp(pattern))
+	}
+	for _, pattern := range denyPatterns {
+		policy.deny = append(policy.deny, globRegexp(pattern))
+	}
+	return policy
+}
+
+// globRegexp converts a glob pattern into an anchored regular expression.
+func globRegexp(pattern string) *regexp.Regexp {
+	expr := regexp.QuoteMeta(pattern)
+	expr = strings.ReplaceAll(expr, `\*`, `.*`)
+	expr = strings.ReplaceAll(expr, `\?`, `.`)
+	return regexp.MustCompile("^" + expr + "$")
+}
+
+// Approved reports whether a module is approved. A module is rejected when its source
+// matches the denylist, or when an allowlist is given and the source matches none of
+// its patterns. Local modules live alongside the configuration, so they are only
+// subject to the denylist.
+func (p *sourcePolicy) Approved(mod ModuleInfo) bool {
+	if matchesAny(p.deny, mod.Source) {
+		return false
+	}
+	if len(p.allow) == 0 || mod.SourceType == sourceTypeLocal {
+		return true
+	}
+	return matchesAny(p.allow, mod.Source)
+}
+
+// matchesAny reports whether the value matches any of the patterns.
+func matchesAny(patterns []*regexp.Regexp, value string) bool {
+	for _, pattern := range patterns {
+		if pattern.MatchString(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// applySourcePolicy sets the Approved flag of every module in the SBOM and returns
+// a violation for each module that was not approved.
+func applySourcePolicy(sbom *SBOM, policy *sourcePolicy) []policyViolation {
+	var violations []policyViolation
+	for i := range sbom.Modules {
+		mod := &sbom.Modules[i]
+		approved := policy.Approved(*mod)
+		mod.Approved = &approved
+		if !approved {
+			violations = append(violations, unapprovedViolation(*mod))
+		}
+	}
+	return violations
+}
+
+// unapprovedViolation returns the violation for a module whose source is not approved.
+func unapprovedViolation(mod ModuleInfo) policyViolation {
+	return policyViolation{
+		Rule:    ruleUnapprovedSource,
+		Config:  mod.Config,
+		Module:  mod.Name,
+		Path:    mod.Path,
+		Message: fmt.Sprintf("%s: module %s uses unapproved source %s", mod.Config, mod.Name, mod.Source),
+	}
+}
+
+// insecureViolations returns a violation for each module fetched over an unencrypted transport.
+func insecureViolations(modules []ModuleInfo) []policyViolation {
+	var violations []policyViolation
+	for _, mod := range modules {
+		if !mod.Insecure {
+			continue
+		}
+		violations = append(violations, policyViolation{
+			Rule:    ruleInsecureSource,
+			Config:  mod.Config,
+			Module:  mod.Name,
+			Path:    mod.Path,
+			Message: fmt.Sprintf("%s: module %s is fetched over an insecure transport from %s", mod.Config, mod.Name, mod.Source),
+		})
+	}
+	return violations
+}
+
+// moduleCountViolations returns a violation for each config that declares more than
+// maxModules module calls, in the order the configs were scanned, to catch accidental
+// blow-ups such as a generated file that repeats its module blocks. The violation has
+// no module name, so a baseline accepts any number of calls in that config.
+func moduleCountViolations(modules []ModuleInfo, maxModules int) []policyViolation {
+	var configs []string
+	counts := make(map[string]int)
+	for _, mod := range modules {
+		if counts[mod.Config] == 0 {
+			configs = append(configs, mod.Config)
+		}
+		counts[mod.Config]++
+	}
+
+	var violations []policyViolation
+	for _, config := range configs {
+		if counts[config] <= maxModules {
+			continue
+		}
+		violations = append(violations, policyViolation{
+			Rule:    ruleTooManyModules,
+			Config:  config,
+			Message: fmt.Sprintf("%s: declares %d module calls, more than the maximum of %d per config", config, counts[config], maxModules),
+		})
+	}
+	return violations
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// policyModules returns modules covering registry, git, and local sources.
+func policyModules() []ModuleInfo {
+	return []ModuleInfo{
+		{Name: "vpc", Source: "terraform-aws-modules/vpc/aws", SourceType: sourceTypeRegistry, Config: "network"},
+		{Name: "bucket", Source: "git::https://github.com/example-corp/s3-bucket.git?ref=v1.0.0", SourceType: sourceTypeGit, Config: "storage"},
+		{Name: "legacy", Source: "git::https://github.com/old-org/legacy.git", SourceType: sourceTypeGit, Config: "storage"},
+		{Name: "local", Source: "./modules/local", SourceType: sourceTypeLocal, Config: "app"},
+	}
+}
+
+// approvals returns the Approved flag of each module by name.
+func approvals(sbom *SBOM) map[string]bool {
+	result := make(map[string]bool)
+	for _, mod := range sbom.Modules {
+		result[mod.Name] = *mod.Approved
+	}
+	return result
+}
+
+// TestApplySourcePolicyAllowlist tests that only allowlisted and local sources are approved.
+func TestApplySourcePolicyAllowlist(t *testing.T) {
+	sbom := &SBOM{Modules: policyModules()}
+	policy := newSourcePolicy([]string{"terraform-aws-modules/*", "git::https://github.com/example-corp/*"}, nil)
+
+	violations := applySourcePolicy(sbom, policy)
+
+	expected := map[string]bool{"vpc": true, "bucket": true, "legacy": false, "local": true}
+	if !reflect.DeepEqual(approvals(sbom), expected) {
+		t.Errorf("Approvals mismatch: expected %v, got %v", expected, approvals(sbom))
+	}
+	if len(violations) != 1 {
+		t.Errorf("Expected 1 violation, got %v", violations)
+	}
+}
+
+// TestApplySourcePolicyDenylist tests that denylisted sources are rejected, including local ones.
+func TestApplySourcePolicyDenylist(t *testing.T) {
+	sbom := &SBOM{Modules: policyModules()}
+	policy := newSourcePolicy(nil, []string{"*old-org*", "./modules/loc?l"})
+
+	violations := applySourcePolicy(sbom, policy)
+
+	expected := map[string]bool{"vpc": true, "bucket": true, "legacy": false, "local": false}
+	if !reflect.DeepEqual(approvals(sbom), expected) {
+		t.Errorf("Approvals mismatch: expected %v, got %v", expected, approvals(sbom))
+	}
+	if len(violations) != 2 {
+		t.Errorf("Expected 2 violations, got %v", violations)
+	}
+}
+
+// TestApplySourcePolicyBothLists tests that the denylist takes precedence over the allowlist.
+func TestApplySourcePolicyBothLists(t *testing.T) {
+	sbom := &SBOM{Modules: policyModules()}
+	policy := newSourcePolicy([]string{"*github.com*"}, []string{"*example-corp*"})
+
+	applySourcePolicy(sbom, policy)
+
+	expected := map[string]bool{"vpc": false, "bucket": false, "legacy": true, "local": true}
+	if !reflect.DeepEqual(approvals(sbom), expected) {
+		t.Errorf("Approvals mismatch: expected %v, got %v", expected, approvals(sbom))
+	}
+}
+
+// TestLoadPatterns tests that blank lines and comments are skipped.
+func TestLoadPatterns(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "allowlist.txt")
+	content := "# Approved modules\nterraform-aws-modules/*\n\n  git::https://github.com/example-corp/*  \n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	patterns, err := loadPatterns(path)
+	if err != nil {
+		t.Fatalf("Failed to load patterns: %v", err)
+	}
+
+	expected := []string{"terraform-aws-modules/*", "git::https://github.com/example-corp/*"}
+	if !reflect.DeepEqual(patterns, expected) {
+		t.Errorf("Patterns mismatch: expected %v, got %v", expected, patterns)
+	}
+}
+
+// TestInsecureViolations tests that only modules fetched over insecure transports are reported.
+func TestInsecureViolations(t *testing.T) {
+	modules := []ModuleInfo{
+		{Name: "vpc", Source: "terraform-aws-modules/vpc/aws", Config: "app"},
+		{Name: "legacy", Source: "git::http://git.example.com/legacy.git", Config: "app"},
+	}
+	setInsecure(modules)
+
+	expected := []string{"app: module legacy is fetched over an insecure transport from git::http://git.example.com/legacy.git"}
+	if violations := violationMessages(insecureViolations(modules)); !reflect.DeepEqual(violations, expected) {
+		t.Errorf("Violations mismatch:\nexpected %v\ngot      %v", expected, violations)
+	}
+}
+
+// TestModuleCountViolations tests that only configs with more module calls than the
+// limit are reported, and that a baseline accepts them.
+func TestModuleCountViolations(t *testing.T) {
+	sbom, err := generateRecursiveSBOM(context.Background(), "testdata/matrix", scanOptions{}, nil)
+	if err != nil {
+		t.Fatalf("Failed to generate SBOM: %v", err)
+	}
+
+	violations := moduleCountViolations(sbom.Modules, 2)
+	expected := []string{"testdata/matrix/app: declares 3 module calls, more than the maximum of 2 per config"}
+	if messages := violationMessages(violations); !reflect.DeepEqual(messages, expected) {
+		t.Errorf("Violations mismatch:\nexpected %v\ngot      %v", expected, messages)
+	}
+
+	if violations := moduleCountViolations(sbom.Modules, 3); len(violations) != 0 {
+		t.Errorf("Expected no violations at the limit, got %v", violationMessages(violations))
+	}
+
+	accepted := baseline{violations[0].key(): true}
+	if remaining := accepted.filter(moduleCountViolations(sbom.Modules, 1)); len(remaining) != 1 || remaining[0].Config != "testdata/matrix/network" {
+		t.Errorf("Expected only the network config to remain after the baseline, got %v", violationMessages(remaining))
+	}
+}
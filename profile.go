@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"runtime"
+	"runtime/pprof"
+	"sync"
+	"syscall"
+)
+
+// exitHooks are run by fatalf before the process exits, since log.Fatalf skips
+// deferred calls.
+var exitHooks []func()
+
+// fatalf runs the exit hooks and then logs the message and exits like log.Fatalf.
+func fatalf(format string, v ...any) {
+	for _, hook := range exitHooks {
+		hook()
+	}
+	log.Fatalf(format, v...)
+}
+
+// profiler writes a CPU profile, a memory profile, or both, of a scan in the format
+// read by go tool pprof.
+type profiler struct {
+	cpuFile *os.File
+	memPath string
+	once    sync.Once
+}
+
+// startProfiling starts writing a CPU profile to cpuPath and arranges for a memory
+// profile to be written to memPath when the profiler stops. Either path may be empty.
+// The profiles are also written when the scan exits through fatalf or is interrupted.
+func startProfiling(cpuPath, memPath string) (*profiler, error) {
+	p := &profiler{memPath: memPath}
+	if cpuPath != "" {
+		file, err := os.Create(cpuPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create CPU profile: %v", err)
+		}
+		if err := pprof.StartCPUProfile(file); err != nil {
+			file.Close()
+			return nil, fmt.Errorf("failed to start CPU profile: %v", err)
+		}
+		p.cpuFile = file
+	}
+
+	exitHooks = append(exitHooks, p.stop)
+
+	interrupted := make(chan os.Signal, 1)
+	signal.Notify(interrupted, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-interrupted
+		p.stop()
+		os.Exit(130)
+	}()
+
+	return p, nil
+}
+
+// stop finishes the CPU profile and writes the memory profile. Only the first call
+// has any effect, and failures are reported as warnings so they do not mask the
+// outcome of the scan.
+func (p *profiler) stop() {
+	p.once.Do(func() {
+		if p.cpuFile != nil {
+			pprof.StopCPUProfile()
+			if err := p.cpuFile.Close(); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to write CPU profile: %v\n", err)
+			}
+		}
+		if p.memPath != "" {
+			if err := writeMemProfile(p.memPath); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			}
+		}
+	})
+}
+
+// writeMemProfile writes the allocations made so far, after a garbage collection so
+// that the in-use figures are current, like go test -memprofile.
+func writeMemProfile(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create memory profile: %v", err)
+	}
+	runtime.GC()
+	if err := pprof.Lookup("allocs").WriteTo(file, 0); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write memory profile: %v", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write memory profile: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestStartProfiling tests that stopping the profiler writes both profiles, and
+// that it is registered to run before fatalf exits.
+func TestStartProfiling(t *testing.T) {
+	defer func(hooks []func()) { exitHooks = hooks }(exitHooks)
+
+	dir := t.TempDir()
+	cpuPath := filepath.Join(dir, "cpu.pprof")
+	memPath := filepath.Join(dir, "mem.pprof")
+
+	profiler, err := startProfiling(cpuPath, memPath)
+	if err != nil {
+		t.Fatalf("Failed to start profiling: %v", err)
+	}
+	if len(exitHooks) != 1 {
+		t.Fatalf("Expected the profiler to register 1 exit hook, got %d", len(exitHooks))
+	}
+
+	// The exit hook and the deferred stop both run on some paths; only the first writes.
+	exitHooks[0]()
+	profiler.stop()
+
+	for _, path := range []string{cpuPath, memPath} {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatalf("Expected profile %s to be written: %v", path, err)
+		}
+		if info.Size() == 0 {
+			t.Errorf("Expected profile %s to not be empty", path)
+		}
+	}
+}
+
+// TestStartProfilingError tests that a profile that cannot be created is reported.
+func TestStartProfilingError(t *testing.T) {
+	defer func(hooks []func()) { exitHooks = hooks }(exitHooks)
+
+	if _, err := startProfiling(filepath.Join(t.TempDir(), "missing", "cpu.pprof"), ""); err == nil {
+		t.Error("Expected an error for a CPU profile in a missing directory")
+	}
+}
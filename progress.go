@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// progressReporter writes a single, continuously updated progress line such as
+// "scanned 340/1200 configs" while a recursive scan runs. It is safe for
+// concurrent use, and a nil *progressReporter is a valid no-op reporter.
+type progressReporter struct {
+	mu    sync.Mutex
+	w     io.Writer
+	total int
+	done  int
+}
+
+// newProgressReporter creates a progress reporter writing to w.
+func newProgressReporter(w io.Writer) *progressReporter {
+	return &progressReporter{w: w}
+}
+
+// Start resets the reporter for a scan of total configurations.
+func (p *progressReporter) Start(total int) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.total = total
+	p.done = 0
+	p.render()
+}
+
+// Increment records that one more configuration has been scanned.
+func (p *progressReporter) Increment() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.done++
+	p.render()
+}
+
+// Finish terminates the progress line so subsequent output starts on a new line.
+func (p *progressReporter) Finish() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	fmt.Fprintln(p.w)
+}
+
+// render redraws the progress line in place. The caller must hold p.mu.
+func (p *progressReporter) render() {
+	fmt.Fprintf(p.w, "\rscanned %d/%d configs", p.done, p.total)
+}
+
+// isTerminal reports whether the file is attached to a terminal.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+// TestProgressReporter tests the progress line rendering.
+func TestProgressReporter(t *testing.T) {
+	var buf bytes.Buffer
+	progress := newProgressReporter(&buf)
+
+	progress.Start(3)
+	progress.Increment()
+	progress.Increment()
+	progress.Finish()
+
+	expected := "\rscanned 0/3 configs\rscanned 1/3 configs\rscanned 2/3 configs\n"
+	if buf.String() != expected {
+		t.Errorf("Progress output mismatch: expected %q, got %q", expected, buf.String())
+	}
+}
+
+// TestProgressReporterNil tests that a nil reporter is a no-op.
+func TestProgressReporterNil(t *testing.T) {
+	var progress *progressReporter
+	progress.Start(1)
+	progress.Increment()
+	progress.Finish()
+}
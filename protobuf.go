@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"rodstewart/terraform-sbom/sbompb"
+)
+
+// toProtoSBOM converts the SBOM to its protocol buffer message, defined in
+// sbompb/sbom.proto. Outputs and per-config details are not part of the schema.
+func toProtoSBOM(sbom *SBOM) *sbompb.SBOM {
+	msg := &sbompb.SBOM{
+		SerialNumber: sbom.SerialNumber,
+		Version:      int64(sbom.Version),
+		Name:         sbom.Name,
+		Namespace:    sbom.Namespace,
+		Supplier:     sbom.Supplier,
+		Timestamp:    sbom.Timestamp,
+		Warnings:     sbom.Warnings,
+	}
+
+	for _, mod := range sbom.Modules {
+		msg.Modules = append(msg.Modules, &sbompb.ModuleInfo{
+			Name:              mod.Name,
+			Source:            mod.Source,
+			Subdir:            mod.Subdir,
+			SourceType:        mod.SourceType,
+			Version:           mod.Version,
+			VersionSource:     mod.VersionSource,
+			OriginalVersion:   mod.OriginalVersion,
+			NormalizedVersion: mod.NormalizedVersion,
+			Purl:              mod.PURL,
+			Config:            mod.Config,
+			Path:              mod.Path,
+			ProviderMappings:  mod.ProviderMappings,
+			Registry:          mod.Registry,
+			Private:           mod.Private,
+			Insecure:          mod.Insecure,
+			Organization:      mod.Organization,
+			Owners:            mod.Owners,
+			Description:       mod.Description,
+			HasReadme:         mod.HasReadme,
+			Approved:          mod.Approved,
+			Reachable:         mod.Reachable,
+			ReachabilityError: mod.ReachabilityError,
+			AgeDays:           optionalInt32(mod.AgeDays),
+			LocalVersion:      mod.LocalVersion,
+			RequiredVersion:   mod.RequiredVersion,
+			Change:            mod.Change,
+			PreviousVersion:   mod.PreviousVersion,
+			DependsOn:         mod.DependsOn,
+			Address:           mod.Address,
+			Deprecated:        mod.Deprecated,
+			Replacement:       mod.Replacement,
+			NormalizedSource:  mod.NormalizedSource,
+		})
+	}
+
+	for _, provider := range sbom.Providers {
+		msg.Providers = append(msg.Providers, &sbompb.ProviderInfo{
+			Name:              provider.Name,
+			Source:            provider.Source,
+			VersionConstraint: provider.VersionConstraint,
+			LockedVersion:     provider.LockedVersion,
+			Purl:              provider.PURL,
+			Config:            provider.Config,
+		})
+	}
+
+	return msg
+}
+
+// writeSBOMToProtobuf writes the SBOM in the protocol buffer binary wire format.
+func writeSBOMToProtobuf(sbom *SBOM, outputPath string) error {
+	content, err := proto.MarshalOptions{Deterministic: true}.Marshal(toProtoSBOM(sbom))
+	if err != nil {
+		return fmt.Errorf("failed to encode protobuf: %v", err)
+	}
+
+	err = writeFileAtomic(outputPath, func(w io.Writer) error {
+		_, err := w.Write(content)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write protobuf file: %v", err)
+	}
+
+	fmt.Printf("SBOM successfully written to %s\n", outputPath)
+	return nil
+}
+
+// writeSBOMToProtoJSON writes the SBOM protocol buffer message in its canonical JSON
+// mapping, for consumers that parse it with a protobuf library.
+func writeSBOMToProtoJSON(sbom *SBOM, outputPath string) error {
+	content, err := protojson.MarshalOptions{Multiline: true, Indent: "  "}.Marshal(toProtoSBOM(sbom))
+	if err != nil {
+		return fmt.Errorf("failed to encode protojson: %v", err)
+	}
+
+	err = writeFileAtomic(outputPath, func(w io.Writer) error {
+		_, err := fmt.Fprintf(w, "%s\n", content)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write protojson file: %v", err)
+	}
+
+	fmt.Printf("SBOM successfully written to %s\n", outputPath)
+	return nil
+}
+
+// optionalInt32 converts an optional int to the int32 of an optional protobuf field.
+func optionalInt32(i *int) *int32 {
+	if i == nil {
+		return nil
+	}
+	v := int32(*i)
+	return &v
+}
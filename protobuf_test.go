@@ -0,0 +1,92 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"rodstewart/terraform-sbom/sbompb"
+)
+
+// mockProtoSBOM returns an SBOM exercising every field of the protobuf schema.
+func mockProtoSBOM() *SBOM {
+	approved := false
+	sbom := mockSBOM()
+	sbom.SerialNumber = "urn:uuid:3e671687-395b-41f5-a30f-a58921a69b79"
+	sbom.Version = 2
+	sbom.Name = "network"
+	sbom.Modules[0].Approved = &approved
+	sbom.Modules[0].Owners = []string{"@acme/network", "@alice"}
+	sbom.Providers = []ProviderInfo{
+		{Name: "aws", Source: "hashicorp/aws", VersionConstraint: "~> 5.0", LockedVersion: "5.31.0", Config: "/path/to/config"},
+	}
+	sbom.Warnings = []string{"/path/to/config/broken.tf:1,1-2: Argument or block definition required"}
+	return sbom
+}
+
+// TestWriteSBOMToProtobuf tests that protobuf output unmarshals back into the same message.
+func TestWriteSBOMToProtobuf(t *testing.T) {
+	sbom := mockProtoSBOM()
+
+	outputPath := filepath.Join(t.TempDir(), "sbom.pb")
+	err := writeSBOMToProtobuf(sbom, outputPath)
+	if err != nil {
+		t.Fatalf("Failed to write SBOM to protobuf: %v", err)
+	}
+
+	content, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("Failed to read protobuf file: %v", err)
+	}
+
+	var result sbompb.SBOM
+	err = proto.Unmarshal(content, &result)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal protobuf: %v", err)
+	}
+
+	if !proto.Equal(&result, toProtoSBOM(sbom)) {
+		t.Errorf("Protobuf round trip mismatch:\nexpected %v\ngot      %v", toProtoSBOM(sbom), &result)
+	}
+	if len(result.Modules) != 2 || result.Modules[0].GetProviderMappings()["aws"] != "aws.useast1" {
+		t.Errorf("Expected module provider mappings to be kept, got %v", result.Modules)
+	}
+	if result.Modules[0].Approved == nil || result.Modules[0].GetApproved() {
+		t.Errorf("Expected approved to be present and false, got %v", result.Modules[0].Approved)
+	}
+	if result.Modules[1].Approved != nil {
+		t.Errorf("Expected approved to be absent, got %v", result.Modules[1].GetApproved())
+	}
+	if result.GetProviders()[0].GetLockedVersion() != "5.31.0" {
+		t.Errorf("Expected locked version 5.31.0, got %q", result.GetProviders()[0].GetLockedVersion())
+	}
+}
+
+// TestWriteSBOMToProtoJSON tests that protojson output unmarshals back into the same message.
+func TestWriteSBOMToProtoJSON(t *testing.T) {
+	sbom := mockProtoSBOM()
+
+	outputPath := filepath.Join(t.TempDir(), "sbom.json")
+	err := writeSBOMToProtoJSON(sbom, outputPath)
+	if err != nil {
+		t.Fatalf("Failed to write SBOM to protojson: %v", err)
+	}
+
+	content, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("Failed to read protojson file: %v", err)
+	}
+
+	var result sbompb.SBOM
+	err = protojson.Unmarshal(content, &result)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal protojson: %v", err)
+	}
+
+	if !proto.Equal(&result, toProtoSBOM(sbom)) {
+		t.Errorf("Protojson round trip mismatch:\nexpected %v\ngot      %v", toProtoSBOM(sbom), &result)
+	}
+}
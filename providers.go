@@ -0,0 +1,259 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/hashicorp/terraform-config-inspect/tfconfig"
+)
+
+// lockFileName is the dependency lock file written by terraform init.
+const lockFileName = ".terraform.lock.hcl"
+
+// defaultProviderNamespace is the namespace Terraform assumes for providers without a source.
+const defaultProviderNamespace = "hashicorp"
+
+// exactVersionPattern matches a version constraint that allows a single version,
+// including pre-release versions and versions with build metadata.
+var exactVersionPattern = regexp.MustCompile(`^=?\s*v?\d+\.\d+\.\d+(-[0-9A-Za-z.-]+)?(\+[0-9A-Za-z.-]+)?$`)
+
+// lockFileSchema describes the provider blocks of a dependency lock file.
+var lockFileSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: "provider", LabelNames: []string{"source"}},
+	},
+}
+
+// providerBlockSchema describes the top-level provider blocks of a configuration.
+var providerBlockSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: "provider", LabelNames: []string{"name"}},
+	},
+}
+
+// providerMetaSchema describes the provider block meta-arguments we record.
+var providerMetaSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{Name: "alias"},
+	},
+}
+
+// requiredProvidersBlockSchema describes the required_providers blocks inside terraform {}.
+var requiredProvidersBlockSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: "required_providers"},
+	},
+}
+
+// extractProviders collects the providers required by a Terraform module. Providers
+// that are used without a required_providers entry are included too, as tfconfig
+// infers them from provider blocks and resources. When the configuration has a
+// dependency lock file, providers whose constraint allows more than one version
+// record the version that is actually locked.
+func extractProviders(module *tfconfig.Module, configPath string, files []*hcl.File) []ProviderInfo {
+	locked := readLockFile(configPath)
+	positions := requiredProviderPositions(files)
+
+	var providers []ProviderInfo
+	for name, req := range module.RequiredProviders {
+		provider := ProviderInfo{
+			Name:              name,
+			Source:            req.Source,
+			VersionConstraint: strings.Join(req.VersionConstraints, ", "),
+			Config:            configPath,
+			File:              positions[name].Filename,
+			Line:              positions[name].Start.Line,
+		}
+		if !isExactConstraint(req.VersionConstraints) {
+			provider.LockedVersion = locked[providerAddress(name, req.Source)]
+		}
+		providers = append(providers, provider)
+	}
+
+	sort.Slice(providers, func(i, j int) bool {
+		return providers[i].Name < providers[j].Name
+	})
+	return providers
+}
+
+// requiredProviderPositions returns where each provider is declared in the
+// required_providers blocks of the given files, keyed by local name.
+func requiredProviderPositions(files []*hcl.File) map[string]hcl.Range {
+	positions := make(map[string]hcl.Range)
+	for _, file := range files {
+		content, _, _ := file.Body.PartialContent(terraformBlockSchema)
+		for _, block := range content.Blocks {
+			inner, _, _ := block.Body.PartialContent(requiredProvidersBlockSchema)
+			for _, required := range inner.Blocks {
+				attrs, _ := required.Body.JustAttributes()
+				for name, attr := range attrs {
+					positions[name] = attr.NameRange
+				}
+			}
+		}
+	}
+	return positions
+}
+
+// providerAddress returns the fully qualified address of a provider as written in the
+// lock file, e.g. registry.terraform.io/hashicorp/aws. Providers without a source
+// default to the hashicorp namespace of the public registry.
+func providerAddress(name string, source string) string {
+	if source == "" {
+		source = defaultProviderNamespace + "/" + name
+	}
+	source = strings.ToLower(source)
+	if strings.Count(source, "/") == 1 {
+		source = defaultRegistryHost + "/" + source
+	}
+	return source
+}
+
+// parseProviderNamespaces checks the patterns given with -exclude-provider-namespace:
+// a namespace such as hashicorp, a namespace and type such as hashicorp/null, or a
+// full provider address such as registry.terraform.io/hashicorp/null. Patterns are
+// matched case-insensitively, as provider addresses are.
+func parseProviderNamespaces(values []string) ([]string, error) {
+	var patterns []string
+	for _, value := range values {
+		pattern := strings.ToLower(strings.Trim(strings.TrimSpace(value), "/"))
+		parts := strings.Split(pattern, "/")
+		if pattern == "" || len(parts) > 3 || slices.Contains(parts, "") {
+			return nil, fmt.Errorf("invalid provider namespace %q: expected NAMESPACE, NAMESPACE/TYPE, or HOSTNAME/NAMESPACE/TYPE", value)
+		}
+		patterns = append(patterns, pattern)
+	}
+	return patterns, nil
+}
+
+// excludeProviders returns the providers whose address matches none of the patterns
+// parsed by parseProviderNamespaces.
+func excludeProviders(providers []ProviderInfo, patterns []string) []ProviderInfo {
+	if len(patterns) == 0 {
+		return providers
+	}
+
+	var kept []ProviderInfo
+	for _, provider := range providers {
+		if !matchesProviderNamespace(providerAddress(provider.Name, provider.Source), patterns) {
+			kept = append(kept, provider)
+		}
+	}
+	return kept
+}
+
+// matchesProviderNamespace reports whether a provider address matches any of the
+// patterns. A namespace matches every provider in it, while longer patterns match the
+// trailing parts of the address, so hashicorp/null matches
+// registry.terraform.io/hashicorp/null.
+func matchesProviderNamespace(address string, patterns []string) bool {
+	parts := strings.Split(address, "/")
+	if len(parts) != 3 {
+		return false
+	}
+	for _, pattern := range patterns {
+		want := strings.Count(pattern, "/") + 1
+		if want == 1 && parts[1] == pattern || want > 1 && strings.Join(parts[3-want:], "/") == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+// isExactConstraint reports whether the version constraints pin a single version.
+// A provider without constraints accepts any version.
+func isExactConstraint(constraints []string) bool {
+	for _, constraint := range constraints {
+		for _, part := range strings.Split(constraint, ",") {
+			if exactVersionPattern.MatchString(strings.TrimSpace(part)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// readLockFile returns the locked version of each provider in the dependency lock
+// file of a configuration, keyed by provider address. Reading is best-effort: a
+// missing or malformed lock file yields no versions.
+func readLockFile(configPath string) map[string]string {
+	locked := make(map[string]string)
+
+	file, diags := hclparse.NewParser().ParseHCLFile(filepath.Join(configPath, lockFileName))
+	if diags.HasErrors() || file == nil {
+		return locked
+	}
+
+	content, _, _ := file.Body.PartialContent(lockFileSchema)
+	for _, block := range content.Blocks {
+		attrs, _ := block.Body.JustAttributes()
+		if attr, ok := attrs["version"]; ok {
+			locked[strings.ToLower(block.Labels[0])] = attributeValue(file.Bytes, attr.Expr)
+		}
+	}
+
+	return locked
+}
+
+// parseProviderBlocks returns the provider configurations declared in the given files,
+// ordered by name and alias. Only the alias is read from each block, so credentials
+// set in provider blocks are never recorded.
+func parseProviderBlocks(files []*hcl.File) []ProviderConfigInfo {
+	var configs []ProviderConfigInfo
+	for _, file := range files {
+		content, _, _ := file.Body.PartialContent(providerBlockSchema)
+		for _, block := range content.Blocks {
+			config := ProviderConfigInfo{Name: block.Labels[0]}
+			meta, _, _ := block.Body.PartialContent(providerMetaSchema)
+			if attr, ok := meta.Attributes["alias"]; ok {
+				config.Alias = attributeValue(file.Bytes, attr.Expr)
+			}
+			configs = append(configs, config)
+		}
+	}
+
+	sort.Slice(configs, func(i, j int) bool {
+		if configs[i].Name != configs[j].Name {
+			return configs[i].Name < configs[j].Name
+		}
+		return configs[i].Alias < configs[j].Alias
+	})
+	return configs
+}
+
+// String returns the provider configuration address, e.g. aws or aws.west.
+func (p ProviderConfigInfo) String() string {
+	if p.Alias == "" {
+		return p.Name
+	}
+	return p.Name + "." + p.Alias
+}
+
+// providerConfigsString renders provider configurations for CSV output as
+// semicolon-separated addresses.
+func providerConfigsString(configs []ProviderConfigInfo) string {
+	addresses := make([]string, len(configs))
+	for i, config := range configs {
+		addresses[i] = config.String()
+	}
+	return strings.Join(addresses, ";")
+}
+
+// parseProviderConfigs parses the provider configurations written by providerConfigsString.
+func parseProviderConfigs(s string) []ProviderConfigInfo {
+	if s == "" {
+		return nil
+	}
+	var configs []ProviderConfigInfo
+	for _, address := range strings.Split(s, ";") {
+		name, alias, _ := strings.Cut(address, ".")
+		configs = append(configs, ProviderConfigInfo{Name: name, Alias: alias})
+	}
+	return configs
+}
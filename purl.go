@@ -0,0 +1,177 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// purlType is the package URL type used for Terraform modules and providers.
+const purlType = "terraform"
+
+// modulePURL returns the package URL identifying a module, following the proposed
+// terraform purl type:
+//
+//	pkg:terraform/terraform-aws-modules/vpc/aws@5.1.0
+//	pkg:terraform/acme/vpc@v1.2.0?vcs_url=git%2Bhttps:%2F%2Fgithub.com%2Facme%2Fvpc.git#modules/subnet
+//
+// Registry modules are named by their namespace, name, and provider, with the
+// registry host as a repository_url qualifier unless it is the public registry.
+// Git, GitHub, and Bitbucket modules are named by the path of their repository and
+// record its URL as a vcs_url qualifier. The version is included when the module is
+// pinned to a single version, and the subdir becomes the subpath. Local modules and
+// other sources are not packages and return an empty string.
+func modulePURL(mod ModuleInfo) string {
+	version := mod.Version
+	if version == unknownVersion {
+		version = ""
+	}
+
+	address := mod.Source
+	if query := strings.Index(address, "?"); query > -1 {
+		address = address[:query]
+	}
+
+	switch mod.SourceType {
+	case sourceTypeRegistry:
+		host, path := splitRegistryAddress(address)
+		var qualifiers [][2]string
+		if !strings.EqualFold(host, defaultRegistryHost) {
+			qualifiers = append(qualifiers, [2]string{"repository_url", host})
+		}
+		return formatPURL(strings.Split(path, "/"), exactVersion(version), qualifiers, mod.Subdir)
+
+	case sourceTypeGit, sourceTypeGitHub, sourceTypeBitbucket:
+		url := strings.Replace(gitRemoteURL(address), redactedSecret+"@", "", 1)
+		segments := gitRepoPath(url)
+		if len(segments) == 0 {
+			return ""
+		}
+		qualifiers := [][2]string{{"vcs_url", "git+" + url}}
+		return formatPURL(segments, version, qualifiers, mod.Subdir)
+	}
+
+	return ""
+}
+
+// providerPURL returns the package URL identifying a provider, such as
+// pkg:terraform/hashicorp/aws@5.31.0. The version is the locked version when there is
+// one, or otherwise the version constraint if it pins a single version. Providers
+// from a registry other than the public one record its host as a repository_url
+// qualifier.
+func providerPURL(provider ProviderInfo) string {
+	host, path, _ := strings.Cut(providerAddress(provider.Name, provider.Source), "/")
+
+	version := provider.LockedVersion
+	if version == "" {
+		version = exactVersion(provider.VersionConstraint)
+	}
+
+	var qualifiers [][2]string
+	if host != defaultRegistryHost {
+		qualifiers = append(qualifiers, [2]string{"repository_url", host})
+	}
+	return formatPURL(strings.Split(path, "/"), version, qualifiers, "")
+}
+
+// setPURLs records the package URL of every module and provider. It runs after
+// credentials are redacted, and drops the redacted placeholder, so no secret or
+// placeholder ends up in a package URL.
+func setPURLs(sbom *SBOM) {
+	for i := range sbom.Modules {
+		sbom.Modules[i].PURL = modulePURL(sbom.Modules[i])
+	}
+	for i := range sbom.Providers {
+		sbom.Providers[i].PURL = providerPURL(sbom.Providers[i])
+	}
+}
+
+// exactVersion returns the version pinned by a constraint such as "= 1.2.0" or
+// ">= 1.0, 1.2.0", or an empty string if it allows a range of versions.
+func exactVersion(constraint string) string {
+	for _, part := range strings.Split(constraint, ",") {
+		part = strings.TrimSpace(part)
+		if exactVersionPattern.MatchString(part) {
+			return strings.TrimSpace(strings.TrimPrefix(part, "="))
+		}
+	}
+	return ""
+}
+
+// gitRepoPath returns the path segments of a git repository URL after its host,
+// without the .git suffix, such as [acme vpc] for https://github.com/acme/vpc.git
+// or git@github.com:acme/vpc.git.
+func gitRepoPath(url string) []string {
+	if idx := strings.Index(url, "://"); idx > -1 {
+		url = url[idx+len("://"):]
+	} else if colon := strings.Index(url, ":"); colon > -1 && !strings.Contains(url[:colon], "/") {
+		url = url[:colon] + "/" + url[colon+1:]
+	}
+
+	_, path, ok := strings.Cut(url, "/")
+	if !ok {
+		return nil
+	}
+
+	var segments []string
+	for _, segment := range strings.Split(strings.TrimSuffix(path, ".git"), "/") {
+		if segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+	return segments
+}
+
+// formatPURL builds a package URL of the terraform type from the namespace and name
+// segments, the version, the qualifiers in the order given, and the subpath. Every
+// component is percent-encoded.
+func formatPURL(segments []string, version string, qualifiers [][2]string, subpath string) string {
+	var b strings.Builder
+	b.WriteString("pkg:" + purlType)
+	for _, segment := range segments {
+		b.WriteString("/" + purlEscape(segment))
+	}
+
+	if version != "" {
+		b.WriteString("@" + purlEscape(version))
+	}
+
+	for i, qualifier := range qualifiers {
+		if i == 0 {
+			b.WriteString("?")
+		} else {
+			b.WriteString("&")
+		}
+		b.WriteString(qualifier[0] + "=" + purlEscape(qualifier[1]))
+	}
+
+	if subpath != "" {
+		var parts []string
+		for _, part := range strings.Split(subpath, "/") {
+			if part != "" && part != "." && part != ".." {
+				parts = append(parts, purlEscape(part))
+			}
+		}
+		if len(parts) > 0 {
+			b.WriteString("#" + strings.Join(parts, "/"))
+		}
+	}
+
+	return b.String()
+}
+
+// purlEscape percent-encodes every character of a package URL component except the
+// unreserved characters and the colon, which the purl specification leaves as is.
+func purlEscape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9',
+			c == '-', c == '.', c == '_', c == '~', c == ':':
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,115 @@
+package main
+
+import "testing"
+
+// TestModulePURL tests package URLs for registry, git, and local module sources.
+func TestModulePURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		mod      ModuleInfo
+		expected string
+	}{
+		{
+			"registry pinned",
+			ModuleInfo{Source: "terraform-aws-modules/vpc/aws", SourceType: sourceTypeRegistry, Version: "5.1.0"},
+			"pkg:terraform/terraform-aws-modules/vpc/aws@5.1.0",
+		},
+		{
+			"registry exact constraint",
+			ModuleInfo{Source: "terraform-aws-modules/vpc/aws", SourceType: sourceTypeRegistry, Version: "= 5.1.0"},
+			"pkg:terraform/terraform-aws-modules/vpc/aws@5.1.0",
+		},
+		{
+			"registry range",
+			ModuleInfo{Source: "terraform-aws-modules/vpc/aws", SourceType: sourceTypeRegistry, Version: "~> 5.0"},
+			"pkg:terraform/terraform-aws-modules/vpc/aws",
+		},
+		{
+			"registry unpinned",
+			ModuleInfo{Source: "terraform-aws-modules/vpc/aws", SourceType: sourceTypeRegistry, Version: "N/A"},
+			"pkg:terraform/terraform-aws-modules/vpc/aws",
+		},
+		{
+			"registry private host with subdir",
+			ModuleInfo{Source: "app.terraform.io/acme/k8s/azurerm", Subdir: "modules/node-pool", SourceType: sourceTypeRegistry, Version: "1.0.0+build.5"},
+			"pkg:terraform/acme/k8s/azurerm@1.0.0%2Bbuild.5?repository_url=app.terraform.io#modules/node-pool",
+		},
+		{
+			"git https",
+			ModuleInfo{Source: "git::https://github.com/acme/vpc.git?ref=v1.2.0", SourceType: sourceTypeGit, Version: "v1.2.0"},
+			"pkg:terraform/acme/vpc@v1.2.0?vcs_url=git%2Bhttps:%2F%2Fgithub.com%2Facme%2Fvpc.git",
+		},
+		{
+			"git ssh with subdir",
+			ModuleInfo{Source: "git@gitlab.com:acme/platform/vpc.git?ref=release/2.0", Subdir: "modules/subnet", SourceType: sourceTypeGit, Version: "release/2.0"},
+			"pkg:terraform/acme/platform/vpc@release%2F2.0?vcs_url=git%2Bgit%40gitlab.com:acme%2Fplatform%2Fvpc.git#modules/subnet",
+		},
+		{
+			"git redacted credentials",
+			ModuleInfo{Source: "git::https://***@github.com/acme/vpc.git?ref=v1.2.0", SourceType: sourceTypeGit, Version: "v1.2.0"},
+			"pkg:terraform/acme/vpc@v1.2.0?vcs_url=git%2Bhttps:%2F%2Fgithub.com%2Facme%2Fvpc.git",
+		},
+		{
+			"github shorthand unpinned",
+			ModuleInfo{Source: "github.com/acme/dns", SourceType: sourceTypeGitHub, Version: "N/A"},
+			"pkg:terraform/acme/dns?vcs_url=git%2Bhttps:%2F%2Fgithub.com%2Facme%2Fdns.git",
+		},
+		{
+			"local",
+			ModuleInfo{Source: "./modules/app", SourceType: sourceTypeLocal, Version: "local"},
+			"",
+		},
+		{
+			"http archive",
+			ModuleInfo{Source: "https://example.com/vpc.zip", SourceType: sourceTypeHTTP, Version: "N/A"},
+			"",
+		},
+	}
+
+	for _, tt := range tests {
+		if got := modulePURL(tt.mod); got != tt.expected {
+			t.Errorf("%s: modulePURL() = %q, expected %q", tt.name, got, tt.expected)
+		}
+	}
+}
+
+// TestProviderPURL tests package URLs for providers.
+func TestProviderPURL(t *testing.T) {
+	tests := []struct {
+		provider ProviderInfo
+		expected string
+	}{
+		{ProviderInfo{Name: "aws", Source: "hashicorp/aws", VersionConstraint: "~> 5.0", LockedVersion: "5.31.0"}, "pkg:terraform/hashicorp/aws@5.31.0"},
+		{ProviderInfo{Name: "random", Source: "hashicorp/random", VersionConstraint: ">= 3.0, = 3.5.1"}, "pkg:terraform/hashicorp/random@3.5.1"},
+		{ProviderInfo{Name: "null", VersionConstraint: ">= 3.0"}, "pkg:terraform/hashicorp/null"},
+		{ProviderInfo{Name: "internal", Source: "tf.example.com/acme/internal", VersionConstraint: "1.0.0-rc.1"}, "pkg:terraform/acme/internal@1.0.0-rc.1?repository_url=tf.example.com"},
+	}
+
+	for _, tt := range tests {
+		if got := providerPURL(tt.provider); got != tt.expected {
+			t.Errorf("providerPURL(%+v) = %q, expected %q", tt.provider, got, tt.expected)
+		}
+	}
+}
+
+// TestPURLEscape tests the percent-encoding of package URL components.
+func TestPURLEscape(t *testing.T) {
+	tests := map[string]string{
+		"vpc":            "vpc",
+		"v1.0.0-rc.1":    "v1.0.0-rc.1",
+		"1.0.0+build.5":  "1.0.0%2Bbuild.5",
+		"release/2.0":    "release%2F2.0",
+		"my module":      "my%20module",
+		"a@b?c#d&e=f":    "a%40b%3Fc%23d%26e%3Df",
+		"https://host":   "https:%2F%2Fhost",
+		"100%":           "100%25",
+		"naïve":          "na%C3%AFve",
+		"under_score~ok": "under_score~ok",
+	}
+
+	for s, expected := range tests {
+		if got := purlEscape(s); got != expected {
+			t.Errorf("purlEscape(%q) = %q, expected %q", s, got, expected)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+)
+
+// moduleBlockSchema describes the top-level module blocks we inspect directly.
+// tfconfig does not expose the meta-arguments of a module call, so they are
+// read from the raw HCL body instead.
+var moduleBlockSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: "module", LabelNames: []string{"name"}},
+	},
+}
+
+// moduleMetaSchema describes the module block meta-arguments we record.
+var moduleMetaSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{Name: "providers"},
+		{Name: "depends_on"},
+	},
+}
+
+// configFiles returns the Terraform and OpenTofu configuration files (.tf, .tf.json,
+// .tofu, and .tofu.json) in a directory, skipping the same editor swap and hidden
+// files that tfconfig ignores, and the Terraform files replaced by OpenTofu ones.
+func configFiles(configPath string) []string {
+	entries, err := os.ReadDir(configPath)
+	if err != nil {
+		return nil
+	}
+
+	names := configFileNames(entries)
+	var files []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || isIgnoredFile(name) || isReplacedByTofu(name, names) {
+			continue
+		}
+		if isConfigFile(name) {
+			files = append(files, filepath.Join(configPath, name))
+		}
+	}
+
+	return files
+}
+
+// parseRawFiles parses every Terraform configuration file in the given directory,
+// except those larger than maxFileSize when it is not zero. Parsing is best-effort:
+// files that fail to parse are skipped, since tfconfig.LoadModule is responsible
+// for reporting configuration errors.
+func parseRawFiles(configPath string, maxFileSize int64) []*hcl.File {
+	parser := hclparse.NewParser()
+	var files []*hcl.File
+
+	for _, path := range configFiles(configPath) {
+		if info, err := os.Stat(path); err == nil && isOversized(info, maxFileSize) {
+			continue
+		}
+
+		var file *hcl.File
+		var diags hcl.Diagnostics
+		if isJSONConfigFile(path) {
+			file, diags = parser.ParseJSONFile(path)
+		} else {
+			file, diags = parser.ParseHCLFile(path)
+		}
+
+		if diags.HasErrors() || file == nil {
+			continue
+		}
+		files = append(files, file)
+	}
+
+	return files
+}
+
+// isIgnoredFile reports whether a file name belongs to an editor swap or hidden
+// file, matching the files tfconfig skips when loading a module.
+func isIgnoredFile(name string) bool {
+	return strings.HasPrefix(name, ".") ||
+		strings.HasSuffix(name, "~") ||
+		strings.HasPrefix(name, "#") && strings.HasSuffix(name, "#")
+}
+
+// moduleProviderMappings returns the providers meta-argument of each module block,
+// keyed by module name. Each mapping associates the provider name expected by
+// the child module with the provider configuration passed in by the caller.
+func moduleProviderMappings(files []*hcl.File) map[string]map[string]string {
+	mappings := make(map[string]map[string]string)
+
+	for _, file := range files {
+		content, _, _ := file.Body.PartialContent(moduleBlockSchema)
+		for _, block := range content.Blocks {
+			meta, _, _ := block.Body.PartialContent(moduleMetaSchema)
+			attr, ok := meta.Attributes["providers"]
+			if !ok {
+				continue
+			}
+
+			pairs, diags := hcl.ExprMap(attr.Expr)
+			if diags.HasErrors() {
+				continue
+			}
+
+			providers := make(map[string]string)
+			for _, pair := range pairs {
+				key, diags := hcl.AbsTraversalForExpr(pair.Key)
+				if diags.HasErrors() {
+					continue
+				}
+				value, diags := hcl.AbsTraversalForExpr(pair.Value)
+				if diags.HasErrors() {
+					continue
+				}
+				providers[traversalString(key)] = traversalString(value)
+			}
+
+			if len(providers) > 0 {
+				mappings[block.Labels[0]] = providers
+			}
+		}
+	}
+
+	return mappings
+}
+
+// moduleDependsOn returns the addresses listed in the depends_on meta-argument of
+// each module block, such as module.network or aws_iam_role.deploy, keyed by module
+// name. Entries that are not plain references are skipped.
+func moduleDependsOn(files []*hcl.File) map[string][]string {
+	dependsOn := make(map[string][]string)
+
+	for _, file := range files {
+		content, _, _ := file.Body.PartialContent(moduleBlockSchema)
+		for _, block := range content.Blocks {
+			meta, _, _ := block.Body.PartialContent(moduleMetaSchema)
+			attr, ok := meta.Attributes["depends_on"]
+			if !ok {
+				continue
+			}
+
+			exprs, diags := hcl.ExprList(attr.Expr)
+			if diags.HasErrors() {
+				continue
+			}
+
+			var addresses []string
+			for _, expr := range exprs {
+				traversal, diags := hcl.AbsTraversalForExpr(expr)
+				if diags.HasErrors() {
+					continue
+				}
+				addresses = append(addresses, traversalString(traversal))
+			}
+
+			if len(addresses) > 0 {
+				dependsOn[block.Labels[0]] = addresses
+			}
+		}
+	}
+
+	return dependsOn
+}
+
+// traversalString renders a simple traversal such as aws.useast1 back to its
+// source form.
+func traversalString(traversal hcl.Traversal) string {
+	var parts []string
+	for _, step := range traversal {
+		switch s := step.(type) {
+		case hcl.TraverseRoot:
+			parts = append(parts, s.Name)
+		case hcl.TraverseAttr:
+			parts = append(parts, s.Name)
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+// sortedKeys returns the keys of a string map in sorted order.
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// defaultReachabilityTimeout bounds each individual reachability check.
+const defaultReachabilityTimeout = 10 * time.Second
+
+// reachabilityChecker verifies that module sources can still be fetched: registry
+// modules through the registry API, git sources with git ls-remote, and local
+// modules by checking that their directory exists. Other source types are not checked.
+type reachabilityChecker struct {
+	client      *apiClient
+	timeout     time.Duration
+	cache       *registryCache      // Reuses earlier registry lookups when set
+	credentials registryCredentials // Tokens sent to private registries
+
+	// lsRemote runs git ls-remote against a repository URL. It is replaced in tests.
+	lsRemote func(ctx context.Context, url string) error
+}
+
+// newReachabilityChecker creates a checker sending registry requests through client
+// and giving up on each source after timeout.
+func newReachabilityChecker(client *apiClient, timeout time.Duration) *reachabilityChecker {
+	return &reachabilityChecker{
+		client:   client,
+		timeout:  timeout,
+		lsRemote: gitLsRemote,
+	}
+}
+
+// checkReachability records whether each module's source is reachable. Sources
+// shared by several modules, including ones spelling them differently, are only
+// checked once.
+func (c *reachabilityChecker) checkReachability(ctx context.Context, sbom *SBOM) {
+	results := make(map[string]error)
+
+	for i := range sbom.Modules {
+		mod := &sbom.Modules[i]
+
+		key := mod.SourceType + " " + normalizeSource(mod.Source)
+		if mod.SourceType == sourceTypeLocal {
+			key += " " + mod.Config
+		}
+
+		err, ok := results[key]
+		if !ok {
+			var checked bool
+			checked, err = c.check(ctx, *mod)
+			if !checked {
+				continue
+			}
+			results[key] = err
+		}
+
+		reachable := err == nil
+		mod.Reachable = &reachable
+		mod.ReachabilityError = ""
+		if err != nil {
+			mod.ReachabilityError = err.Error()
+		}
+	}
+}
+
+// check verifies a single module source. It reports false if the source type is not checked.
+func (c *reachabilityChecker) check(ctx context.Context, mod ModuleInfo) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	switch mod.SourceType {
+	case sourceTypeLocal:
+		return true, checkLocalSource(mod)
+	case sourceTypeRegistry:
+		return true, c.checkRegistrySource(ctx, mod.Source)
+	case sourceTypeGit, sourceTypeGitHub, sourceTypeBitbucket:
+		return true, c.lsRemote(ctx, gitRemoteURL(mod.Source))
+	}
+
+	return false, nil
+}
+
+// checkLocalSource verifies that a local module's directory exists relative to its config.
+func checkLocalSource(mod ModuleInfo) error {
+	info, err := os.Stat(filepath.Join(mod.Config, mod.Source, mod.Subdir))
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", mod.Source)
+	}
+	return nil
+}
+
+// checkRegistrySource verifies that a registry module exists by requesting its
+// version list from the registry's module API, or from the cache if it was looked
+// up recently. Only found and not found answers are cached.
+func (c *reachabilityChecker) checkRegistrySource(ctx context.Context, source string) error {
+	host, address := splitRegistryAddress(source)
+
+	key := host + "/" + address
+	if c.cache != nil {
+		if entry, ok := c.cache.get(key); ok {
+			return entry.err()
+		}
+	}
+
+	url := fmt.Sprintf("https://%s/v1/modules/%s/versions", host, address)
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create registry request: %v", err)
+	}
+	c.credentials.authorize(req)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("registry request failed: %v", err)
+	}
+	closeResponse(resp)
+
+	if resp.StatusCode != http.StatusOK {
+		err = fmt.Errorf("registry returned %s", resp.Status)
+	}
+	if c.cache != nil && isCacheableStatus(resp.StatusCode) {
+		c.cache.put(key, err)
+	}
+	return err
+}
+
+// gitRemoteURL converts a git, GitHub, or Bitbucket module address to a URL git
+// can use, dropping the forced getter prefix and query parameters such as ref.
+func gitRemoteURL(source string) string {
+	address := strings.TrimPrefix(source, "git::")
+	if query := strings.Index(address, "?"); query > -1 {
+		address = address[:query]
+	}
+
+	if strings.HasPrefix(address, "github.com/") || strings.HasPrefix(address, "bitbucket.org/") {
+		address = "https://" + address
+		if !strings.HasSuffix(address, ".git") {
+			address += ".git"
+		}
+	}
+
+	return address
+}
+
+// gitLsRemote checks that a git repository can be listed. The URL comes from the
+// scanned configuration, so one git would read as an option is refused.
+func gitLsRemote(ctx context.Context, url string) error {
+	if strings.HasPrefix(url, "-") {
+		return fmt.Errorf("refusing to list %q: a git URL cannot start with -", url)
+	}
+	_, err := runGit(ctx, ".", "ls-remote", "--heads", "--", url)
+	return err
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// TestCheckReachability tests recording reachability for each checked source type using stubbed externals.
+func TestCheckReachability(t *testing.T) {
+	var requests []string
+	client := newAPIClient(roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		requests = append(requests, req.Method+" "+req.URL.String())
+		if req.URL.Path == "/v1/modules/terraform-aws-modules/vpc/aws/versions" {
+			return stubResponse(http.StatusOK, nil), nil
+		}
+		return &http.Response{Status: "404 Not Found", StatusCode: http.StatusNotFound, Header: make(http.Header), Body: http.NoBody}, nil
+	}), 0)
+
+	var remotes []string
+	checker := newReachabilityChecker(client, time.Second)
+	checker.lsRemote = func(ctx context.Context, url string) error {
+		remotes = append(remotes, url)
+		if url == "https://github.com/acme/missing.git" {
+			return errors.New("repository not found")
+		}
+		return nil
+	}
+
+	sbom := &SBOM{Modules: []ModuleInfo{
+		{Name: "vpc", Source: "terraform-aws-modules/vpc/aws", SourceType: sourceTypeRegistry, Config: "network"},
+		{Name: "vpc_dr", Source: "terraform-aws-modules/vpc/aws", SourceType: sourceTypeRegistry, Config: "network-dr"},
+		{Name: "gone", Source: "app.terraform.io/acme/gone/aws", SourceType: sourceTypeRegistry, Config: "network"},
+		{Name: "db", Source: "git::https://github.com/acme/terraform-db.git?ref=v1.4.2", SourceType: sourceTypeGit, Config: "app"},
+		{Name: "missing", Source: "github.com/acme/missing", SourceType: sourceTypeGitHub, Config: "app"},
+		{Name: "service", Source: "./modules/service", SourceType: sourceTypeLocal, Config: "testdata/recursive/app"},
+		{Name: "dns", Source: "./modules/dns", SourceType: sourceTypeLocal, Config: "testdata/recursive/app"},
+		{Name: "archive", Source: "https://example.com/module.zip", SourceType: sourceTypeHTTP, Config: "app"},
+	}}
+
+	checker.checkReachability(context.Background(), sbom)
+
+	type result struct {
+		Reachable *bool
+		Error     string
+	}
+	yes, no := true, false
+	expected := map[string]result{
+		"vpc":     {&yes, ""},
+		"vpc_dr":  {&yes, ""},
+		"gone":    {&no, "registry returned 404 Not Found"},
+		"db":      {&yes, ""},
+		"missing": {&no, "repository not found"},
+		"service": {&yes, ""},
+		"dns":     {&no, "stat testdata/recursive/app/modules/dns: no such file or directory"},
+		"archive": {nil, ""},
+	}
+	for _, mod := range sbom.Modules {
+		got := result{mod.Reachable, mod.ReachabilityError}
+		if !reflect.DeepEqual(got, expected[mod.Name]) {
+			t.Errorf("Reachability mismatch for %s: expected %v, got %v", mod.Name, expected[mod.Name], got)
+		}
+	}
+
+	expectedRequests := []string{
+		"HEAD https://registry.terraform.io/v1/modules/terraform-aws-modules/vpc/aws/versions",
+		"HEAD https://app.terraform.io/v1/modules/acme/gone/aws/versions",
+	}
+	if !reflect.DeepEqual(requests, expectedRequests) {
+		t.Errorf("Registry requests mismatch: expected %v, got %v", expectedRequests, requests)
+	}
+
+	expectedRemotes := []string{"https://github.com/acme/terraform-db.git", "https://github.com/acme/missing.git"}
+	if !reflect.DeepEqual(remotes, expectedRemotes) {
+		t.Errorf("git ls-remote URLs mismatch: expected %v, got %v", expectedRemotes, remotes)
+	}
+}
+
+// TestGitLsRemoteOptionInjection tests that a URL from the scanned configuration that git
+// would read as an option, such as --upload-pack, is refused instead of run.
+func TestGitLsRemoteOptionInjection(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not available")
+	}
+
+	dir := t.TempDir()
+	marker := filepath.Join(dir, "pwned")
+	script := filepath.Join(dir, "evil.sh")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\ntouch "+marker+"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := gitLsRemote(context.Background(), "--upload-pack="+script); err == nil {
+		t.Errorf("Expected the URL to be refused")
+	}
+	if _, err := os.Stat(marker); err == nil {
+		t.Errorf("Expected the --upload-pack command not to run")
+	}
+}
+
+// TestGitRemoteURL tests converting module addresses to URLs for git ls-remote.
+func TestGitRemoteURL(t *testing.T) {
+	tests := map[string]string{
+		"git::https://github.com/acme/db.git?ref=v1": "https://github.com/acme/db.git",
+		"git::ssh://git@example.com/acme/db.git":     "ssh://git@example.com/acme/db.git",
+		"git@github.com:acme/db.git":                 "git@github.com:acme/db.git",
+		"github.com/acme/db?ref=v2":                  "https://github.com/acme/db.git",
+		"bitbucket.org/acme/db":                      "https://bitbucket.org/acme/db.git",
+	}
+	for source, expected := range tests {
+		if got := gitRemoteURL(source); got != expected {
+			t.Errorf("gitRemoteURL(%q) = %q, expected %q", source, got, expected)
+		}
+	}
+}
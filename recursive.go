@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// findConfigDirs walks the directory tree under root and returns every directory
+// containing Terraform configuration files, or terragrunt.hcl files when Terragrunt
+// support is enabled. Hidden directories, including the .terraform directory created
+// by terraform init, are skipped.
+func findConfigDirs(ctx context.Context, root string, opts scanOptions) ([]string, error) {
+	var dirs []string
+
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != root && strings.HasPrefix(d.Name(), ".") {
+			return filepath.SkipDir
+		}
+		if isConfigDir(path) || opts.Terragrunt && hasTerragruntConfig(path) {
+			dirs = append(dirs, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk %s: %w", root, err)
+	}
+
+	return dirs, nil
+}
+
+// generateRecursiveSBOM generates a single SBOM covering every Terraform configuration
+// found under root. The progress reporter, if not nil, is advanced as each
+// configuration completes. A configuration that fails to load stops the scan, unless
+// opts.SkipFailedConfigs is set.
+func generateRecursiveSBOM(ctx context.Context, root string, opts scanOptions, progress *progressReporter) (*SBOM, error) {
+	var modules []ModuleInfo
+	sbom, err := scanRecursive(ctx, root, opts, progress, func(configModules []ModuleInfo) error {
+		modules = append(modules, configModules...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sbom.Modules = modules
+	return sbom, nil
+}
+
+// scanRecursive scans every Terraform configuration found under root, passing the
+// modules of each to emit as soon as it is scanned, so that they never have to be
+// held in memory together. The configurations are scanned in the order of their
+// paths, so emit receives the modules in the order sortSBOM gives them. The returned
+// SBOM holds everything else the configurations declare.
+func scanRecursive(ctx context.Context, root string, opts scanOptions, progress *progressReporter, emit func(modules []ModuleInfo) error) (*SBOM, error) {
+	dirs, err := findConfigDirs(ctx, root, opts)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(dirs)
+
+	progress.Start(len(dirs))
+	defer progress.Finish()
+
+	var sbom SBOM
+	for _, dir := range dirs {
+		configSBOM, err := generateSBOM(ctx, dir, opts)
+		if err != nil && opts.SkipFailedConfigs && ctx.Err() == nil {
+			sbom.Warnings = append(sbom.Warnings, fmt.Sprintf("%s: skipped because it failed to load: %v", dir, err))
+			sbom.LoadErrors = append(sbom.LoadErrors, loadError{Path: dir, Message: err.Error(), Severity: loadSeverityError})
+			progress.Increment()
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", dir, err)
+		}
+
+		if err := emit(configSBOM.Modules); err != nil {
+			return nil, err
+		}
+		sbom.Providers = append(sbom.Providers, configSBOM.Providers...)
+		sbom.Outputs = append(sbom.Outputs, configSBOM.Outputs...)
+		sbom.Configs = append(sbom.Configs, configSBOM.Configs...)
+		sbom.Warnings = append(sbom.Warnings, configSBOM.Warnings...)
+		sbom.LoadErrors = append(sbom.LoadErrors, configSBOM.LoadErrors...)
+		progress.Increment()
+	}
+
+	sortSBOM(&sbom)
+
+	return &sbom, nil
+}
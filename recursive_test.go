@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// TestFindConfigDirs tests that configuration directories are discovered and hidden directories skipped.
+func TestFindConfigDirs(t *testing.T) {
+	dirs, err := findConfigDirs(context.Background(), "testdata/recursive", scanOptions{})
+	if err != nil {
+		t.Fatalf("Failed to find config dirs: %v", err)
+	}
+
+	expected := []string{
+		filepath.Join("testdata", "recursive", "app"),
+		filepath.Join("testdata", "recursive", "app", "modules", "service"),
+		filepath.Join("testdata", "recursive", "network"),
+	}
+	if !reflect.DeepEqual(dirs, expected) {
+		t.Errorf("Config dirs mismatch: expected %v, got %v", expected, dirs)
+	}
+}
+
+// TestGenerateRecursiveSBOM tests that modules from every configuration are combined.
+func TestGenerateRecursiveSBOM(t *testing.T) {
+	sbom, err := generateRecursiveSBOM(context.Background(), "testdata/recursive", scanOptions{}, nil)
+	if err != nil {
+		t.Fatalf("Failed to generate SBOM: %v", err)
+	}
+
+	var names []string
+	for _, mod := range sbom.Modules {
+		names = append(names, mod.Name)
+	}
+	sort.Strings(names)
+
+	expected := []string{"bucket", "service", "vpc"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("Module names mismatch: expected %v, got %v", expected, names)
+	}
+}
+
+// TestGenerateRecursiveSBOMCancelled tests that cancelling mid-scan stops the walk without a result.
+func TestGenerateRecursiveSBOMCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	progress := &cancellingProgress{cancel: cancel}
+
+	sbom, err := generateRecursiveSBOM(ctx, "testdata/recursive", scanOptions{}, progress.reporter())
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled error, got %v", err)
+	}
+	if sbom != nil {
+		t.Errorf("Expected no SBOM from a cancelled scan, got %v", sbom)
+	}
+	if progress.writes == 0 {
+		t.Error("Expected the scan to have started before it was cancelled")
+	}
+}
+
+// cancellingProgress cancels the scan as soon as the first configuration completes.
+type cancellingProgress struct {
+	cancel context.CancelFunc
+	writes int
+}
+
+func (c *cancellingProgress) reporter() *progressReporter {
+	return newProgressReporter(c)
+}
+
+func (c *cancellingProgress) Write(p []byte) (int, error) {
+	c.writes++
+	// The first write is the initial 0/N line, the second follows the first completed config.
+	if c.writes == 2 {
+		c.cancel()
+	}
+	return len(p), nil
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// redactRule rewrites the parts of a source matching a regular expression, such as
+// an internal hostname, before the SBOM is shared outside the organization.
+type redactRule struct {
+	Pattern     *regexp.Regexp
+	Replacement string
+}
+
+// parseRedactRule parses a rule given as REGEX=REPLACEMENT. The rule is split at the
+// first = that is not escaped as \=, so the replacement may contain = while the
+// expression can match one with \=. The replacement may refer to capture groups of
+// the expression, such as $1.
+func parseRedactRule(value string) (redactRule, error) {
+	split := -1
+	for i := 0; i < len(value); i++ {
+		if value[i] == '\\' {
+			i++
+			continue
+		}
+		if value[i] == '=' {
+			split = i
+			break
+		}
+	}
+	if split < 1 {
+		return redactRule{}, fmt.Errorf("invalid redact pattern %q: expected REGEX=REPLACEMENT", value)
+	}
+
+	pattern, err := regexp.Compile(value[:split])
+	if err != nil {
+		return redactRule{}, fmt.Errorf("invalid redact pattern %q: %v", value, err)
+	}
+	return redactRule{Pattern: pattern, Replacement: value[split+1:]}, nil
+}
+
+// parseRedactRules parses every rule given with -redact-pattern, keeping their order.
+func parseRedactRules(values []string) ([]redactRule, error) {
+	var rules []redactRule
+	for _, value := range values {
+		rule, err := parseRedactRule(value)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// redactText applies each rule in turn, so a later rule sees the output of the
+// earlier ones.
+func redactText(text string, rules []redactRule) string {
+	for _, rule := range rules {
+		text = rule.Pattern.ReplaceAllString(text, rule.Replacement)
+	}
+	return text
+}
+
+// applyRedactRules rewrites the sources and normalized sources of every module, the
+// sources of every provider, the registry of registry modules, and the warnings of an
+// SBOM with the given rules, along with the texts that can quote a source: the
+// replacement of deprecated modules, reachability errors, and load errors. Package URLs are derived from the sources again, so the
+// original hosts do not remain in them.
+// It runs after every check, so the scan itself sees the real sources.
+func applyRedactRules(sbom *SBOM, rules []redactRule) {
+	if len(rules) == 0 {
+		return
+	}
+
+	for i := range sbom.Modules {
+		mod := &sbom.Modules[i]
+		mod.Source = redactText(mod.Source, rules)
+		if mod.NormalizedSource != "" {
+			mod.NormalizedSource = redactText(mod.NormalizedSource, rules)
+		}
+		if mod.Registry != "" {
+			mod.Registry = redactText(mod.Registry, rules)
+		}
+		if mod.Replacement != "" {
+			mod.Replacement = redactText(mod.Replacement, rules)
+		}
+		if mod.ReachabilityError != "" {
+			mod.ReachabilityError = redactText(mod.ReachabilityError, rules)
+		}
+	}
+	for i := range sbom.Providers {
+		if sbom.Providers[i].Source != "" {
+			sbom.Providers[i].Source = redactText(sbom.Providers[i].Source, rules)
+		}
+	}
+	for i, warning := range sbom.Warnings {
+		sbom.Warnings[i] = redactText(warning, rules)
+	}
+	sbom.LoadErrors = redactLoadErrors(sbom.LoadErrors, rules)
+	setPURLs(sbom)
+}
+
+// redactLoadErrors returns a copy of load errors with the rules applied to their
+// messages, which quote the sources that failed to load.
+func redactLoadErrors(loadErrors []loadError, rules []redactRule) []loadError {
+	if len(rules) == 0 {
+		return loadErrors
+	}
+
+	redacted := make([]loadError, len(loadErrors))
+	for i, loadErr := range loadErrors {
+		loadErr.Message = redactText(loadErr.Message, rules)
+		redacted[i] = loadErr
+	}
+	return redacted
+}
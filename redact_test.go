@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestApplyRedactRules tests that rules rewrite sources in order, that package URLs
+// follow the rewritten sources, and that non-matching sources are left untouched.
+func TestApplyRedactRules(t *testing.T) {
+	rules, err := parseRedactRules([]string{
+		`git\.corp\.example\.com=git.example.com`,
+		`git\.example\.com/platform/(\w+)=git.example.com/$1`,
+		`registry\.corp\.example\.com=registry.example.com`,
+	})
+	if err != nil {
+		t.Fatalf("Failed to parse rules: %v", err)
+	}
+
+	sbom := &SBOM{
+		Modules: []ModuleInfo{
+			{Name: "db", Source: "git::https://git.corp.example.com/platform/db.git?ref=v1.0.0", SourceType: sourceTypeGit, Version: "v1.0.0"},
+			{Name: "dns", Source: "registry.corp.example.com/acme/dns/aws", SourceType: sourceTypeRegistry, Version: "1.2.0", Registry: "registry.corp.example.com"},
+			{Name: "vpc", Source: "terraform-aws-modules/vpc/aws", SourceType: sourceTypeRegistry, Version: "5.1.0", Registry: defaultRegistryHost},
+		},
+		Providers: []ProviderInfo{
+			{Name: "aws", Source: "hashicorp/aws"},
+			{Name: "internal", Source: "registry.corp.example.com/acme/internal"},
+		},
+		Warnings: []string{"app: module db is pinned inconsistently in git.corp.example.com/platform/db.git"},
+	}
+	setPURLs(sbom)
+	applyRedactRules(sbom, rules)
+
+	var sources []string
+	for _, mod := range sbom.Modules {
+		sources = append(sources, mod.Source, mod.Registry, mod.PURL)
+	}
+	for _, provider := range sbom.Providers {
+		sources = append(sources, provider.Source)
+	}
+	expected := []string{
+		"git::https://git.example.com/db.git?ref=v1.0.0", "", "pkg:terraform/db@v1.0.0?vcs_url=git%2Bhttps:%2F%2Fgit.example.com%2Fdb.git",
+		"registry.example.com/acme/dns/aws", "registry.example.com", "pkg:terraform/acme/dns/aws@1.2.0?repository_url=registry.example.com",
+		"terraform-aws-modules/vpc/aws", defaultRegistryHost, "pkg:terraform/terraform-aws-modules/vpc/aws@5.1.0",
+		"hashicorp/aws",
+		"registry.example.com/acme/internal",
+	}
+	if !reflect.DeepEqual(sources, expected) {
+		t.Errorf("Redacted sources mismatch:\nexpected %q\ngot      %q", expected, sources)
+	}
+
+	if expected := "app: module db is pinned inconsistently in git.example.com/db.git"; sbom.Warnings[0] != expected {
+		t.Errorf("Expected warning %q, got %q", expected, sbom.Warnings[0])
+	}
+}
+
+// TestApplyRedactRulesErrors tests that the host of a source is also redacted from the
+// error of a failed reachability check, the replacement of a deprecated module, and
+// load errors.
+func TestApplyRedactRulesErrors(t *testing.T) {
+	rules, err := parseRedactRules([]string{`git\.corp\.example\.com=git.example.com`})
+	if err != nil {
+		t.Fatalf("Failed to parse rules: %v", err)
+	}
+
+	checker := newReachabilityChecker(nil, time.Second)
+	checker.lsRemote = func(ctx context.Context, url string) error {
+		return fmt.Errorf("git ls-remote --heads -- %s failed: exit status 128: fatal: unable to access '%s/'", url, url)
+	}
+	sbom := &SBOM{
+		Modules: []ModuleInfo{{
+			Name:        "db",
+			Source:      "git::https://git.corp.example.com/platform/db.git?ref=v1.0.0",
+			SourceType:  sourceTypeGit,
+			Version:     "v1.0.0",
+			Deprecated:  true,
+			Replacement: "git::https://git.corp.example.com/platform/database.git",
+		}},
+		LoadErrors: []loadError{{Path: "app", Message: "failed to fetch git::https://git.corp.example.com/platform/db.git", Severity: loadSeverityError}},
+	}
+	checker.checkReachability(context.Background(), sbom)
+	if !strings.Contains(sbom.Modules[0].ReachabilityError, "git.corp.example.com") {
+		t.Fatalf("Expected the reachability check to fail on the real host, got %q", sbom.Modules[0].ReachabilityError)
+	}
+
+	applyRedactRules(sbom, rules)
+
+	mod := sbom.Modules[0]
+	for _, text := range []string{mod.ReachabilityError, mod.Replacement, sbom.LoadErrors[0].Message} {
+		if strings.Contains(text, "corp") {
+			t.Errorf("Expected the internal host to be redacted, got %q", text)
+		}
+	}
+	if expected := "git ls-remote --heads -- https://git.example.com/platform/db.git failed: exit status 128: fatal: unable to access 'https://git.example.com/platform/db.git/'"; mod.ReachabilityError != expected {
+		t.Errorf("Expected reachability error %q, got %q", expected, mod.ReachabilityError)
+	}
+}
+
+// TestParseRedactRule tests splitting rules at the first unescaped = and rejecting invalid ones.
+func TestParseRedactRule(t *testing.T) {
+	rule, err := parseRedactRule(`\?ref\=main=?ref=v1.0.0`)
+	if err != nil {
+		t.Fatalf("Failed to parse rule: %v", err)
+	}
+	if rule.Pattern.String() != `\?ref\=main` || rule.Replacement != "?ref=v1.0.0" {
+		t.Errorf("Expected pattern \\?ref\\=main and replacement ?ref=v1.0.0, got %s and %s", rule.Pattern, rule.Replacement)
+	}
+	if got := redactText("github.com/acme/dns?ref=main", []redactRule{rule}); got != "github.com/acme/dns?ref=v1.0.0" {
+		t.Errorf("Expected the ref to be rewritten, got %s", got)
+	}
+
+	for _, value := range []string{"git.corp.example.com", "=git.example.com", "git.corp.(example=git.example.com"} {
+		if _, err := parseRedactRule(value); err == nil {
+			t.Errorf("Expected an error parsing %q", value)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Locations of the Terraform CLI configuration that registry credentials are read from.
+const (
+	cliConfigFileEnv    = "TF_CLI_CONFIG_FILE"
+	cliConfigFileName   = ".terraformrc"
+	credentialsFileName = "credentials.tfrc.json"
+)
+
+// cliConfigSchema selects the credentials blocks of a Terraform CLI configuration file.
+var cliConfigSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{{Type: "credentials", LabelNames: []string{"host"}}},
+}
+
+// credentialsBlockSchema selects the token of a credentials block.
+var credentialsBlockSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{{Name: "token"}},
+}
+
+// registryCredentials holds the API token of each private registry, keyed by its
+// lowercased hostname, including the port of a registry on a non-standard one.
+type registryCredentials map[string]string
+
+// parseRegistryTokens parses every token given with -registry-token as HOST=TOKEN.
+func parseRegistryTokens(values []string) (registryCredentials, error) {
+	credentials := make(registryCredentials)
+	for _, value := range values {
+		host, token, ok := strings.Cut(value, "=")
+		if !ok || host == "" || token == "" {
+			// The value is left out of the error, as it may be a token given without a host.
+			return nil, fmt.Errorf("invalid registry token: expected HOST=TOKEN")
+		}
+		credentials[strings.ToLower(host)] = token
+	}
+	return credentials, nil
+}
+
+// loadCLICredentials reads the registry tokens the Terraform CLI would use from the
+// credentials blocks of its configuration file, which is named by TF_CLI_CONFIG_FILE
+// or is ~/.terraformrc, and from ~/.terraform.d/credentials.tfrc.json, where
+// terraform login stores them. A token in the latter wins. Missing files are skipped.
+func loadCLICredentials() (registryCredentials, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find the home directory: %v", err)
+	}
+
+	configPath := os.Getenv(cliConfigFileEnv)
+	if configPath == "" {
+		configPath = filepath.Join(home, cliConfigFileName)
+	}
+	credentials, err := readCLIConfigCredentials(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	stored, err := readCredentialsFile(filepath.Join(home, ".terraform.d", credentialsFileName))
+	if err != nil {
+		return nil, err
+	}
+	for host, token := range stored {
+		credentials[host] = token
+	}
+	return credentials, nil
+}
+
+// readCLIConfigCredentials returns the tokens of the credentials blocks in a Terraform
+// CLI configuration file, such as credentials "tfe.example.com" { token = "..." }.
+func readCLIConfigCredentials(path string) (registryCredentials, error) {
+	credentials := make(registryCredentials)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return credentials, nil
+	}
+
+	parser := hclparse.NewParser()
+	var file *hcl.File
+	var diags hcl.Diagnostics
+	if strings.HasSuffix(path, ".json") {
+		file, diags = parser.ParseJSONFile(path)
+	} else {
+		file, diags = parser.ParseHCLFile(path)
+	}
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("failed to read CLI configuration %s: %v", path, diags.Error())
+	}
+
+	content, _, diags := file.Body.PartialContent(cliConfigSchema)
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("failed to read CLI configuration %s: %v", path, diags.Error())
+	}
+	for _, block := range content.Blocks {
+		attrs, _, _ := block.Body.PartialContent(credentialsBlockSchema)
+		attr, ok := attrs.Attributes["token"]
+		if !ok {
+			continue
+		}
+		value, diags := attr.Expr.Value(nil)
+		if diags.HasErrors() || value.IsNull() || !value.IsKnown() || value.Type() != cty.String {
+			return nil, fmt.Errorf("failed to read CLI configuration %s: the token for %s is not a string", path, block.Labels[0])
+		}
+		credentials[strings.ToLower(block.Labels[0])] = value.AsString()
+	}
+	return credentials, nil
+}
+
+// readCredentialsFile returns the tokens stored by terraform login in a
+// credentials.tfrc.json file.
+func readCredentialsFile(path string) (registryCredentials, error) {
+	credentials := make(registryCredentials)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return credentials, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", path, err)
+	}
+
+	var file struct {
+		Credentials map[string]struct {
+			Token string `json:"token"`
+		} `json:"credentials"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", path, err)
+	}
+	for host, entry := range file.Credentials {
+		if entry.Token != "" {
+			credentials[strings.ToLower(host)] = entry.Token
+		}
+	}
+	return credentials, nil
+}
+
+// authorize adds the bearer token of the request's host, if there is one. Requests to
+// the public registry are always sent without credentials.
+func (c registryCredentials) authorize(req *http.Request) {
+	if strings.ToLower(req.URL.Hostname()) == defaultRegistryHost {
+		return
+	}
+	if token, ok := c[strings.ToLower(req.URL.Host)]; ok {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestParseRegistryTokens tests parsing HOST=TOKEN values, and that an invalid one is
+// reported without echoing it.
+func TestParseRegistryTokens(t *testing.T) {
+	credentials, err := parseRegistryTokens([]string{"TFE.example.com=abc=123", "registry.example.com:8443=def"})
+	if err != nil {
+		t.Fatalf("Failed to parse registry tokens: %v", err)
+	}
+	expected := registryCredentials{"tfe.example.com": "abc=123", "registry.example.com:8443": "def"}
+	if !reflect.DeepEqual(credentials, expected) {
+		t.Errorf("Expected %v, got %v", expected, credentials)
+	}
+
+	for _, value := range []string{"s3cr3t-token", "=s3cr3t-token", "tfe.example.com="} {
+		_, err := parseRegistryTokens([]string{value})
+		if err == nil {
+			t.Errorf("Expected an error for %q", value)
+		} else if strings.Contains(err.Error(), "s3cr3t") {
+			t.Errorf("Expected the error for %q not to contain the token, got %v", value, err)
+		}
+	}
+}
+
+// TestLoadCLICredentials tests reading tokens from the CLI configuration file and from
+// credentials.tfrc.json, which wins for a host in both.
+func TestLoadCLICredentials(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(cliConfigFileEnv, "")
+
+	config := `plugin_cache_dir = "$HOME/.terraform.d/plugin-cache"
+
+credentials "TFE.example.com" {
+  token = "from-terraformrc"
+}
+
+credentials "app.terraform.io" {
+  token = "overridden"
+}
+`
+	if err := os.WriteFile(filepath.Join(home, cliConfigFileName), []byte(config), 0644); err != nil {
+		t.Fatalf("Failed to write CLI configuration: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(home, ".terraform.d"), 0755); err != nil {
+		t.Fatalf("Failed to create .terraform.d: %v", err)
+	}
+	stored := `{"credentials": {"app.terraform.io": {"token": "from-login"}}}`
+	if err := os.WriteFile(filepath.Join(home, ".terraform.d", credentialsFileName), []byte(stored), 0600); err != nil {
+		t.Fatalf("Failed to write credentials file: %v", err)
+	}
+
+	credentials, err := loadCLICredentials()
+	if err != nil {
+		t.Fatalf("Failed to load CLI credentials: %v", err)
+	}
+	expected := registryCredentials{"tfe.example.com": "from-terraformrc", "app.terraform.io": "from-login"}
+	if !reflect.DeepEqual(credentials, expected) {
+		t.Errorf("Expected %v, got %v", expected, credentials)
+	}
+
+	// TF_CLI_CONFIG_FILE replaces ~/.terraformrc, and a missing file has no credentials.
+	t.Setenv(cliConfigFileEnv, filepath.Join(home, "missing.tfrc"))
+	credentials, err = loadCLICredentials()
+	if err != nil {
+		t.Fatalf("Failed to load CLI credentials: %v", err)
+	}
+	expected = registryCredentials{"app.terraform.io": "from-login"}
+	if !reflect.DeepEqual(credentials, expected) {
+		t.Errorf("Expected %v, got %v", expected, credentials)
+	}
+}
+
+// TestCheckAgesAuthenticated tests that the token of a private registry is sent to it,
+// while the public registry is queried without credentials.
+func TestCheckAgesAuthenticated(t *testing.T) {
+	authorization := make(map[string]string)
+	client := newAPIClient(roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		authorization[req.URL.Host] = req.Header.Get("Authorization")
+		if req.URL.Host == "tfe.example.com" && req.Header.Get("Authorization") != "Bearer private-token" {
+			return &http.Response{Status: "401 Unauthorized", StatusCode: http.StatusUnauthorized, Header: make(http.Header), Body: http.NoBody}, nil
+		}
+		resp := stubResponse(http.StatusOK, nil)
+		resp.Body = io.NopCloser(strings.NewReader(`{"published_at": "2024-01-01T00:00:00Z"}`))
+		return resp, nil
+	}), 0)
+
+	sbom := &SBOM{Modules: []ModuleInfo{
+		{Name: "vpc", Source: "tfe.example.com/acme/vpc/aws", SourceType: sourceTypeRegistry, Version: "1.0.0", Config: "network"},
+		{Name: "eks", Source: "terraform-aws-modules/eks/aws", SourceType: sourceTypeRegistry, Version: "20.0.0", Config: "network"},
+	}}
+
+	checker := newAgeChecker(client)
+	checker.now = func() time.Time { return time.Date(2024, 1, 11, 0, 0, 0, 0, time.UTC) }
+	checker.credentials = registryCredentials{"tfe.example.com": "private-token", "registry.terraform.io": "public-token"}
+
+	if warnings := checker.checkAges(context.Background(), sbom); len(warnings) > 0 {
+		t.Errorf("Expected no warnings, got %v", warnings)
+	}
+	for _, mod := range sbom.Modules {
+		if mod.AgeDays == nil || *mod.AgeDays != 10 {
+			t.Errorf("Expected module %s to be 10 days old, got %v", mod.Name, mod.AgeDays)
+		}
+	}
+
+	expected := map[string]string{"tfe.example.com": "Bearer private-token", "registry.terraform.io": ""}
+	if !reflect.DeepEqual(authorization, expected) {
+		t.Errorf("Expected Authorization headers %v, got %v", expected, authorization)
+	}
+
+	// Without credentials, the private registry rejects the lookup.
+	sbom.Modules[0].AgeDays = nil
+	checker.credentials = nil
+	warnings := checker.checkAges(context.Background(), &SBOM{Modules: sbom.Modules[:1]})
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "401 Unauthorized") {
+		t.Errorf("Expected a 401 warning without credentials, got %v", warnings)
+	}
+}
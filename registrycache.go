@@ -0,0 +1,154 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// defaultRegistryCacheTTL is how long a registry lookup is reused before the registry
+// is queried again.
+const defaultRegistryCacheTTL = 24 * time.Hour
+
+// registryCacheFile is the name of the cache file inside the user cache directory.
+const registryCacheFile = "registry.json"
+
+// registryCacheEntry records the result of looking up one module in a registry.
+type registryCacheEntry struct {
+	CheckedAt   time.Time  `json:"checked_at"`
+	Error       string     `json:"error,omitempty"`        // Why the module could not be found, empty if it exists
+	PublishedAt *time.Time `json:"published_at,omitempty"` // When a module version was published, for -check-updates
+}
+
+// err returns the cached lookup failure, or nil if the module was found.
+func (e registryCacheEntry) err() error {
+	if e.Error == "" {
+		return nil
+	}
+	return errors.New(e.Error)
+}
+
+// registryCache stores the results of registry API lookups on disk, keyed by module
+// address, so that scans repeated within the TTL do not query the registry for the
+// same modules again. Only found and not found answers are cached; network failures
+// and other statuses, such as 401 or 503, are retried on the next scan.
+type registryCache struct {
+	path    string
+	ttl     time.Duration
+	entries map[string]registryCacheEntry
+	changed bool
+
+	// now returns the current time. It is replaced in tests.
+	now func() time.Time
+}
+
+// isCacheableStatus reports whether a registry response status is a lasting answer
+// that can be cached: the module was found, or the registry does not know it.
+func isCacheableStatus(status int) bool {
+	return status == http.StatusOK || status == http.StatusNotFound
+}
+
+// defaultRegistryCachePath returns the cache file location under the OS user cache
+// directory, such as ~/.cache on Linux.
+func defaultRegistryCachePath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find user cache directory: %v", err)
+	}
+	return filepath.Join(dir, "terraform-sbom", registryCacheFile), nil
+}
+
+// loadRegistryCache reads the cache at path. A missing file yields an empty cache, and
+// an unreadable one is replaced when the cache is saved.
+func loadRegistryCache(path string, ttl time.Duration) *registryCache {
+	cache := &registryCache{
+		path:    path,
+		ttl:     ttl,
+		entries: make(map[string]registryCacheEntry),
+		now:     time.Now,
+	}
+
+	content, err := os.ReadFile(path)
+	if err == nil {
+		json.Unmarshal(content, &cache.entries)
+	}
+	if cache.entries == nil {
+		cache.entries = make(map[string]registryCacheEntry)
+	}
+
+	return cache
+}
+
+// published returns when the cached module version was published, or the zero time if
+// it is not known.
+func (e registryCacheEntry) published() time.Time {
+	if e.PublishedAt == nil {
+		return time.Time{}
+	}
+	return *e.PublishedAt
+}
+
+// get returns the cached lookup of a module address if it is younger than the TTL.
+func (c *registryCache) get(address string) (registryCacheEntry, bool) {
+	entry, ok := c.entries[address]
+	if !ok || c.now().Sub(entry.CheckedAt) >= c.ttl {
+		return registryCacheEntry{}, false
+	}
+	return entry, true
+}
+
+// put records the result of looking up a module address.
+func (c *registryCache) put(address string, err error) {
+	entry := registryCacheEntry{CheckedAt: c.now().UTC()}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	c.entries[address] = entry
+	c.changed = true
+}
+
+// putPublished records when a module version was published, keyed by its address and
+// version, or why the registry could not report it.
+func (c *registryCache) putPublished(key string, published time.Time, err error) {
+	c.put(key, err)
+	if err == nil {
+		entry := c.entries[key]
+		entry.PublishedAt = &published
+		c.entries[key] = entry
+	}
+}
+
+// save writes the cache back to disk if any lookups were added, dropping expired entries.
+func (c *registryCache) save() error {
+	if !c.changed {
+		return nil
+	}
+
+	for address, entry := range c.entries {
+		if c.now().Sub(entry.CheckedAt) >= c.ttl {
+			delete(c.entries, address)
+		}
+	}
+
+	err := os.MkdirAll(filepath.Dir(c.path), 0755)
+	if err != nil {
+		return fmt.Errorf("failed to create cache directory: %v", err)
+	}
+
+	err = writeFileAtomic(c.path, func(w io.Writer) error {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(c.entries)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write registry cache: %v", err)
+	}
+
+	c.changed = false
+	return nil
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestRegistryCacheReachability tests that cached registry lookups avoid network calls until they expire.
+func TestRegistryCacheReachability(t *testing.T) {
+	requests := 0
+	client := newAPIClient(roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		requests++
+		if req.URL.Path == "/v1/modules/terraform-aws-modules/vpc/aws/versions" {
+			return stubResponse(http.StatusOK, nil), nil
+		}
+		return &http.Response{Status: "404 Not Found", StatusCode: http.StatusNotFound, Header: make(http.Header), Body: http.NoBody}, nil
+	}), 0)
+
+	clock := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	cachePath := filepath.Join(t.TempDir(), "cache", registryCacheFile)
+	newCache := func() *registryCache {
+		cache := loadRegistryCache(cachePath, time.Hour)
+		cache.now = func() time.Time { return clock }
+		return cache
+	}
+
+	check := func(cache *registryCache) *SBOM {
+		checker := newReachabilityChecker(client, time.Second)
+		checker.cache = cache
+		sbom := &SBOM{Modules: []ModuleInfo{
+			{Name: "vpc", Source: "terraform-aws-modules/vpc/aws", SourceType: sourceTypeRegistry, Config: "network"},
+			{Name: "gone", Source: "app.terraform.io/acme/gone/aws", SourceType: sourceTypeRegistry, Config: "network"},
+		}}
+		checker.checkReachability(context.Background(), sbom)
+		return sbom
+	}
+
+	cache := newCache()
+	check(cache)
+	if requests != 2 {
+		t.Fatalf("Expected 2 registry requests on a cold cache, got %d", requests)
+	}
+	if err := cache.save(); err != nil {
+		t.Fatalf("Failed to save registry cache: %v", err)
+	}
+
+	clock = clock.Add(30 * time.Minute)
+	sbom := check(newCache())
+	if requests != 2 {
+		t.Errorf("Expected cached lookups to avoid registry requests, got %d requests", requests)
+	}
+	if !*sbom.Modules[0].Reachable {
+		t.Errorf("Expected cached vpc lookup to be reachable")
+	}
+	if *sbom.Modules[1].Reachable || sbom.Modules[1].ReachabilityError != "registry returned 404 Not Found" {
+		t.Errorf("Expected cached gone lookup to keep its error, got %q", sbom.Modules[1].ReachabilityError)
+	}
+
+	clock = clock.Add(time.Hour)
+	check(newCache())
+	if requests != 4 {
+		t.Errorf("Expected expired lookups to query the registry again, got %d requests", requests)
+	}
+}
+
+// TestRegistryCacheNetworkError tests that lookups failing with a network error are not cached.
+func TestRegistryCacheNetworkError(t *testing.T) {
+	client := newAPIClient(roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return nil, errors.New("connection refused")
+	}), 0)
+
+	cache := loadRegistryCache(filepath.Join(t.TempDir(), registryCacheFile), time.Hour)
+	checker := newReachabilityChecker(client, time.Second)
+	checker.cache = cache
+
+	err := checker.checkRegistrySource(context.Background(), "terraform-aws-modules/vpc/aws")
+	if err == nil {
+		t.Fatalf("Expected the lookup to fail")
+	}
+	if _, ok := cache.get("registry.terraform.io/terraform-aws-modules/vpc/aws"); ok {
+		t.Errorf("Expected the network error not to be cached")
+	}
+}
+
+// TestRegistryCacheTransientStatus tests that registry lookups answered with a status
+// other than found or not found, such as 401 or 503, are retried on the next lookup.
+func TestRegistryCacheTransientStatus(t *testing.T) {
+	for _, status := range []int{http.StatusUnauthorized, http.StatusServiceUnavailable} {
+		requests := 0
+		client := newAPIClient(roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			requests++
+			return stubResponse(status, nil), nil
+		}), 0)
+
+		cache := loadRegistryCache(filepath.Join(t.TempDir(), registryCacheFile), time.Hour)
+		reachability := newReachabilityChecker(client, time.Second)
+		reachability.cache = cache
+		ages := newAgeChecker(client)
+		ages.cache = cache
+
+		for i := 0; i < 2; i++ {
+			if err := reachability.checkRegistrySource(context.Background(), "terraform-aws-modules/vpc/aws"); err == nil {
+				t.Fatalf("Expected the reachability lookup to fail with status %d", status)
+			}
+			if _, err := ages.registryPublished(context.Background(), "terraform-aws-modules/vpc/aws", "5.1.0"); err == nil {
+				t.Fatalf("Expected the publish date lookup to fail with status %d", status)
+			}
+		}
+		if requests != 4 {
+			t.Errorf("Expected status %d to be retried on each lookup, got %d requests", status, requests)
+		}
+		if len(cache.entries) != 0 {
+			t.Errorf("Expected status %d not to be cached, got %v", status, cache.entries)
+		}
+	}
+}
+
+// TestRegistryCacheSave tests that expired entries are dropped when the cache is saved.
+func TestRegistryCacheSave(t *testing.T) {
+	clock := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	cachePath := filepath.Join(t.TempDir(), registryCacheFile)
+
+	cache := loadRegistryCache(cachePath, time.Hour)
+	cache.now = func() time.Time { return clock }
+	cache.put("registry.terraform.io/acme/old/aws", nil)
+	clock = clock.Add(2 * time.Hour)
+	cache.put("registry.terraform.io/acme/new/aws", errors.New("registry returned 404 Not Found"))
+
+	if err := cache.save(); err != nil {
+		t.Fatalf("Failed to save registry cache: %v", err)
+	}
+
+	reloaded := loadRegistryCache(cachePath, time.Hour)
+	reloaded.now = cache.now
+	if len(reloaded.entries) != 1 {
+		t.Fatalf("Expected 1 cache entry, got %d", len(reloaded.entries))
+	}
+	entry, ok := reloaded.get("registry.terraform.io/acme/new/aws")
+	if !ok || entry.err() == nil || entry.err().Error() != "registry returned 404 Not Found" {
+		t.Errorf("Expected the cached lookup failure to be reloaded, got %+v", entry)
+	}
+}
+
+// TestRegistryCacheAges tests that the publish dates looked up by -check-updates are
+// served from the cache, along with versions the registry does not know.
+func TestRegistryCacheAges(t *testing.T) {
+	requests := 0
+	client := newAPIClient(roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		requests++
+		if req.URL.Path == "/v1/modules/terraform-aws-modules/vpc/aws/5.1.0" {
+			resp := stubResponse(http.StatusOK, nil)
+			resp.Body = io.NopCloser(strings.NewReader(`{"published_at": "2024-01-01T12:00:00Z"}`))
+			return resp, nil
+		}
+		return &http.Response{Status: "404 Not Found", StatusCode: http.StatusNotFound, Header: make(http.Header), Body: http.NoBody}, nil
+	}), 0)
+
+	clock := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	cachePath := filepath.Join(t.TempDir(), registryCacheFile)
+
+	check := func() *SBOM {
+		cache := loadRegistryCache(cachePath, time.Hour)
+		cache.now = func() time.Time { return clock }
+		checker := newAgeChecker(client)
+		checker.now = cache.now
+		checker.cache = cache
+		sbom := &SBOM{Modules: []ModuleInfo{
+			{Name: "vpc", Source: "terraform-aws-modules/vpc/aws", SourceType: sourceTypeRegistry, Version: "5.1.0", Config: "network"},
+			{Name: "eks", Source: "terraform-aws-modules/eks/aws", SourceType: sourceTypeRegistry, Version: "99.0.0", Config: "network"},
+		}}
+		checker.checkAges(context.Background(), sbom)
+		if err := cache.save(); err != nil {
+			t.Fatalf("Failed to save registry cache: %v", err)
+		}
+		return sbom
+	}
+
+	check()
+	if requests != 2 {
+		t.Fatalf("Expected 2 registry requests on a cold cache, got %d", requests)
+	}
+
+	clock = clock.Add(30 * time.Minute)
+	sbom := check()
+	if requests != 2 {
+		t.Errorf("Expected cached lookups to avoid registry requests, got %d requests", requests)
+	}
+	if sbom.Modules[0].AgeDays == nil || *sbom.Modules[0].AgeDays != 59 {
+		t.Errorf("Expected the cached publish date to give an age of 59 days, got %v", sbom.Modules[0].AgeDays)
+	}
+	if sbom.Modules[1].AgeDays != nil {
+		t.Errorf("Expected the cached lookup failure to leave eks without an age")
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-config-inspect/tfconfig"
+)
+
+// countResources counts the managed resource blocks of a Terraform module by provider.
+// Resources are attributed to the provider set by their provider meta-argument, or
+// otherwise to the provider named by their type prefix. Data sources are not counted.
+func countResources(module *tfconfig.Module) ResourceCounts {
+	counts := make(ResourceCounts)
+	for _, resource := range module.ManagedResources {
+		counts[resource.Provider.Name]++
+	}
+	if len(counts) == 0 {
+		return nil
+	}
+	return counts
+}
+
+// parseResourceCounts parses the counts written by ResourceCounts.String.
+func parseResourceCounts(s string) ResourceCounts {
+	if s == "" {
+		return nil
+	}
+	counts := make(ResourceCounts)
+	for _, pair := range strings.Split(s, ";") {
+		name, value, _ := strings.Cut(pair, "=")
+		count, err := strconv.Atoi(value)
+		if err != nil {
+			continue
+		}
+		counts[name] = count
+	}
+	return counts
+}
+
+// collectResources lists the managed resources declared by a module and, following
+// local module sources, by its local child modules and theirs in turn. Each resource
+// records the address of the module declaring it, such as module.network.module.subnet,
+// which is empty for the module itself. Remote modules are not followed, since their
+// contents are not on disk, and a local module already in the call path is not
+// followed again. Local modules that cannot be loaded are skipped; -flatten-nested
+// reports them.
+func collectResources(module *tfconfig.Module, dir string, maxFileSize int64) []ResourceInfo {
+	var resources []ResourceInfo
+
+	var walk func(module *tfconfig.Module, dir, address string, stack []string)
+	walk = func(module *tfconfig.Module, dir, address string, stack []string) {
+		for _, resource := range module.ManagedResources {
+			resources = append(resources, ResourceInfo{
+				Type:     resource.Type,
+				Name:     resource.Name,
+				Provider: resource.Provider.Name,
+				Module:   address,
+			})
+		}
+
+		for name, call := range module.ModuleCalls {
+			if sourceType(call.Source) != sourceTypeLocal {
+				continue
+			}
+			child := filepath.Join(dir, call.Source)
+			if slices.Contains(stack, child) || !isConfigDir(child) {
+				continue
+			}
+			childModule, _ := loadModule(child, maxFileSize)
+			if childModule == nil {
+				continue
+			}
+
+			childAddress := "module." + name
+			if address != "" {
+				childAddress = address + "." + childAddress
+			}
+			walk(childModule, child, childAddress, append(slices.Clip(stack), child))
+		}
+	}
+
+	root := filepath.Clean(dir)
+	walk(module, root, "", []string{root})
+
+	sort.Slice(resources, func(i, j int) bool {
+		if resources[i].Module != resources[j].Module {
+			return resources[i].Module < resources[j].Module
+		}
+		return resources[i].Address() < resources[j].Address()
+	})
+	return resources
+}
+
+// resourcesString renders resources for CSV output as semicolon-separated
+// address=provider pairs.
+func resourcesString(resources []ResourceInfo) string {
+	pairs := make([]string, len(resources))
+	for i, resource := range resources {
+		pairs[i] = resource.Address() + "=" + resource.Provider
+	}
+	return strings.Join(pairs, ";")
+}
+
+// parseResources parses the resources written by resourcesString.
+func parseResources(s string) []ResourceInfo {
+	if s == "" {
+		return nil
+	}
+	var resources []ResourceInfo
+	for _, pair := range strings.Split(s, ";") {
+		address, provider, _ := strings.Cut(pair, "=")
+		steps := strings.Split(address, ".")
+		if len(steps) < 2 {
+			continue
+		}
+		resources = append(resources, ResourceInfo{
+			Type:     steps[len(steps)-2],
+			Name:     steps[len(steps)-1],
+			Provider: provider,
+			Module:   strings.Join(steps[:len(steps)-2], "."),
+		})
+	}
+	return resources
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"encoding/xml"
+	"reflect"
+	"testing"
+)
+
+// TestGenerateSBOMResourceCounts tests counting managed resources by provider.
+func TestGenerateSBOMResourceCounts(t *testing.T) {
+	sbom, err := generateSBOM(context.Background(), "testdata/resources", scanOptions{IncludeResources: true})
+	if err != nil {
+		t.Fatalf("Failed to generate SBOM: %v", err)
+	}
+	if len(sbom.Configs) != 1 {
+		t.Fatalf("Expected 1 config, got %d", len(sbom.Configs))
+	}
+
+	expected := ResourceCounts{"aws": 4, "datadog": 1, "google-beta": 1}
+	if !reflect.DeepEqual(sbom.Configs[0].ResourceCounts, expected) {
+		t.Errorf("Resource counts mismatch: expected %v, got %v", expected, sbom.Configs[0].ResourceCounts)
+	}
+
+	sbom, err = generateSBOM(context.Background(), "testdata/resources", scanOptions{})
+	if err != nil {
+		t.Fatalf("Failed to generate SBOM: %v", err)
+	}
+	if len(sbom.Configs) != 0 {
+		t.Errorf("Expected no configs without IncludeResources, got %v", sbom.Configs)
+	}
+}
+
+// TestResourceCountsString tests that resource counts render in provider order and parse back.
+func TestResourceCountsString(t *testing.T) {
+	counts := ResourceCounts{"datadog": 3, "aws": 12}
+
+	s := counts.String()
+	if s != "aws=12;datadog=3" {
+		t.Errorf("Expected aws=12;datadog=3, got %q", s)
+	}
+	if result := parseResourceCounts(s); !reflect.DeepEqual(result, counts) {
+		t.Errorf("Expected %v, got %v", counts, result)
+	}
+	if result := parseResourceCounts(""); result != nil {
+		t.Errorf("Expected no resource counts, got %v", result)
+	}
+}
+
+// TestResourceCountsXML tests that resource counts survive an XML round trip.
+func TestResourceCountsXML(t *testing.T) {
+	config := ConfigInfo{Path: "/path/to/config", ResourceCounts: ResourceCounts{"aws": 12, "datadog": 3}}
+
+	content, err := xml.Marshal(config)
+	if err != nil {
+		t.Fatalf("Failed to marshal XML: %v", err)
+	}
+
+	var result ConfigInfo
+	err = xml.Unmarshal(content, &result)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal XML: %v", err)
+	}
+	if !reflect.DeepEqual(result, config) {
+		t.Errorf("XML round trip mismatch: expected %v, got %v", config, result)
+	}
+}
+
+// TestGenerateSBOMResourceModules tests attributing the resources of local child
+// modules, nested two deep, to the module declaring them.
+func TestGenerateSBOMResourceModules(t *testing.T) {
+	sbom, err := generateSBOM(context.Background(), "testdata/resources-nested", scanOptions{IncludeResources: true})
+	if err != nil {
+		t.Fatalf("Failed to generate SBOM: %v", err)
+	}
+	if len(sbom.Configs) != 1 {
+		t.Fatalf("Expected 1 config, got %d", len(sbom.Configs))
+	}
+
+	expected := []ResourceInfo{
+		{Type: "aws_vpc", Name: "main", Provider: "aws"},
+		{Type: "aws_internet_gateway", Name: "this", Provider: "aws", Module: "module.network"},
+		{Type: "aws_subnet", Name: "private", Provider: "aws", Module: "module.network.module.subnet"},
+		{Type: "datadog_monitor", Name: "subnet", Provider: "datadog", Module: "module.network.module.subnet"},
+	}
+	if !reflect.DeepEqual(sbom.Configs[0].Resources, expected) {
+		t.Errorf("Resources mismatch:\nexpected %+v\ngot      %+v", expected, sbom.Configs[0].Resources)
+	}
+
+	counts := ResourceCounts{"aws": 1}
+	if !reflect.DeepEqual(sbom.Configs[0].ResourceCounts, counts) {
+		t.Errorf("Expected resource counts of the config itself %v, got %v", counts, sbom.Configs[0].ResourceCounts)
+	}
+}
+
+// TestResourcesString tests that resources render as addresses and parse back.
+func TestResourcesString(t *testing.T) {
+	resources := []ResourceInfo{
+		{Type: "aws_vpc", Name: "main", Provider: "aws"},
+		{Type: "aws_subnet", Name: "private", Provider: "aws", Module: "module.network.module.subnet"},
+	}
+
+	s := resourcesString(resources)
+	if s != "aws_vpc.main=aws;module.network.module.subnet.aws_subnet.private=aws" {
+		t.Errorf("Unexpected resources string %q", s)
+	}
+	if result := parseResources(s); !reflect.DeepEqual(result, resources) {
+		t.Errorf("Expected %v, got %v", resources, result)
+	}
+	if result := parseResources(""); result != nil {
+		t.Errorf("Expected no resources, got %v", result)
+	}
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SARIF 2.1.0 identifiers written at the top of every log.
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://docs.oasis-open.org/sarif/sarif/v2.1.0/errata01/os/schemas/sarif-schema-2.1.0.json"
+)
+
+// sarifLog is the subset of a SARIF 2.1.0 log written for the findings of a scan.
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri,omitempty"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string             `json:"id"`
+	Name                 string             `json:"name"`
+	ShortDescription     sarifMessage       `json:"shortDescription"`
+	DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+// writeSBOMToSARIF writes the findings of every policy check on the SBOM as a SARIF
+// 2.1.0 log, for code scanning tools that annotate the module and provider blocks
+// the findings were reported for.
+func writeSBOMToSARIF(sbom *SBOM, outputPath string) error {
+	err := writeFileAtomic(outputPath, func(w io.Writer) error {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(newSARIFLog(sbom, sbomFindings(sbom)))
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write SARIF file: %v", err)
+	}
+
+	fmt.Printf("SBOM successfully written to %s\n", outputPath)
+	return nil
+}
+
+// newSARIFLog builds a SARIF log with a single run reporting the findings against the
+// rules of findingRules.
+func newSARIFLog(sbom *SBOM, findings []policyViolation) sarifLog {
+	driver := sarifDriver{
+		Name:           "terraform-sbom",
+		Version:        version,
+		InformationURI: "https://github.com/rodmhgl/terraform-sbom",
+		Rules:          make([]sarifRule, len(findingRules)),
+	}
+	ruleIndex := make(map[string]int, len(findingRules))
+	for i, rule := range findingRules {
+		driver.Rules[i] = sarifRule{
+			ID:                   rule.ID,
+			Name:                 rule.Name,
+			ShortDescription:     sarifMessage{Text: rule.Description},
+			DefaultConfiguration: sarifConfiguration{Level: string(rule.Severity)},
+		}
+		ruleIndex[rule.ID] = i
+	}
+
+	results := make([]sarifResult, 0, len(findings))
+	for _, finding := range findings {
+		results = append(results, sarifResult{
+			RuleID:    finding.Rule,
+			RuleIndex: ruleIndex[finding.Rule],
+			Level:     string(finding.Severity()),
+			Message:   sarifMessage{Text: finding.Message},
+			Locations: findingLocations(sbom, finding),
+		})
+	}
+
+	return sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs:    []sarifRun{{Tool: sarifTool{Driver: driver}, Results: results}},
+	}
+}
+
+// findingLocations returns where a finding was reported: the module block or
+// required_providers entry it names, every call of the source for an inconsistent
+// pin, or every required_providers entry of the local name for a conflicting provider
+// source. When the file is unknown, such as for an SBOM read back from CSV, the config
+// path is used without a line.
+func findingLocations(sbom *SBOM, finding policyViolation) []sarifLocation {
+	var locations []sarifLocation
+	add := func(config, file string, line int) {
+		locations = append(locations, sarifFileLocation(config, file, line))
+	}
+
+	if finding.Rule == ruleInconsistentPin {
+		for _, mod := range sbom.Modules {
+			address := mod.Source
+			if query := strings.Index(address, "?"); query > -1 {
+				address = address[:query]
+			}
+			if address == finding.Module {
+				add(mod.Config, mod.File, mod.Line)
+			}
+		}
+		return locations
+	}
+
+	if finding.Rule == ruleConflictingProvider {
+		for _, provider := range sbom.Providers {
+			if provider.Name == finding.Module {
+				add(provider.Config, provider.File, provider.Line)
+			}
+		}
+		return locations
+	}
+
+	if finding.Rule != ruleUnofficialProvider {
+		for _, mod := range sbom.Modules {
+			if mod.Config == finding.Config && mod.Name == finding.Module {
+				add(mod.Config, mod.File, mod.Line)
+				return locations
+			}
+		}
+	}
+	for _, provider := range sbom.Providers {
+		if provider.Config == finding.Config && provider.Name == finding.Module {
+			add(provider.Config, provider.File, provider.Line)
+			return locations
+		}
+	}
+	if finding.Config != "" {
+		add(finding.Config, "", 0)
+	}
+	return locations
+}
+
+// sarifFileLocation returns the location of a line in a file, falling back to the
+// config path when the file is unknown. Absolute paths below the working directory
+// are made relative so that code scanning tools can match them to the repository.
+func sarifFileLocation(config, file string, line int) sarifLocation {
+	if file == "" {
+		file, line = config, 0
+	}
+	if filepath.IsAbs(file) {
+		if wd, err := os.Getwd(); err == nil {
+			if rel, err := filepath.Rel(wd, file); err == nil && filepath.IsLocal(rel) {
+				file = rel
+			}
+		}
+	}
+
+	location := sarifLocation{
+		PhysicalLocation: sarifPhysicalLocation{
+			ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(filepath.Clean(file))},
+		},
+	}
+	if line > 0 {
+		location.PhysicalLocation.Region = &sarifRegion{StartLine: line}
+	}
+	return location
+}
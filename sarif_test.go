@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// sarifTestConfig declares an unpinned module, an insecure module, and a provider
+// outside the hashicorp namespace.
+const sarifTestConfig = `module "network" {
+  source = "git::https://github.com/acme/network.git"
+}
+
+module "legacy" {
+  source = "http://example.com/legacy.zip"
+}
+
+terraform {
+  required_providers {
+    aws = {
+      source = "hashicorp/aws"
+    }
+    datadog = {
+      source = "DataDog/datadog"
+    }
+  }
+}
+`
+
+// TestWriteSBOMToSARIF tests that findings are written as a SARIF log that is valid
+// against the SARIF 2.1.0 schema, with the level and location of each finding.
+func TestWriteSBOMToSARIF(t *testing.T) {
+	configPath := t.TempDir()
+	if err := os.WriteFile(filepath.Join(configPath, "main.tf"), []byte(sarifTestConfig), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	sbom, err := generateSBOM(context.Background(), configPath, scanOptions{})
+	if err != nil {
+		t.Fatalf("Failed to generate SBOM: %v", err)
+	}
+
+	outputPath := filepath.Join(t.TempDir(), "findings.sarif")
+	if err := writeSBOMToSARIF(sbom, outputPath); err != nil {
+		t.Fatalf("Failed to write SARIF: %v", err)
+	}
+
+	content, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("Failed to read SARIF: %v", err)
+	}
+
+	schema, err := jsonschema.Compile("testdata/sarif-schema-2.1.0.json")
+	if err != nil {
+		t.Fatalf("Failed to compile SARIF schema: %v", err)
+	}
+	var document interface{}
+	if err := json.Unmarshal(content, &document); err != nil {
+		t.Fatalf("Failed to parse SARIF: %v", err)
+	}
+	if err := schema.Validate(document); err != nil {
+		t.Fatalf("SARIF output does not match the schema: %v", err)
+	}
+
+	var log sarifLog
+	if err := json.Unmarshal(content, &log); err != nil {
+		t.Fatalf("Failed to parse SARIF: %v", err)
+	}
+	if len(log.Runs) != 1 {
+		t.Fatalf("Expected 1 run, got %d", len(log.Runs))
+	}
+	run := log.Runs[0]
+	if len(run.Tool.Driver.Rules) != len(findingRules) {
+		t.Errorf("Expected %d rules, got %d", len(findingRules), len(run.Tool.Driver.Rules))
+	}
+
+	file := filepath.ToSlash(filepath.Join(configPath, "main.tf"))
+	expected := []struct {
+		rule  string
+		level string
+		line  int
+	}{
+		{ruleInsecureSource, "error", 5},
+		{ruleUnpinnedModule, "warning", 5},
+		{ruleUnpinnedModule, "warning", 1},
+		{ruleUnofficialProvider, "note", 14},
+	}
+	if len(run.Results) != len(expected) {
+		t.Fatalf("Expected %d results, got %+v", len(expected), run.Results)
+	}
+	for i, result := range run.Results {
+		if result.RuleID != expected[i].rule || result.Level != expected[i].level {
+			t.Errorf("Expected result %d to be %s at level %s, got %s at level %s", i, expected[i].rule, expected[i].level, result.RuleID, result.Level)
+		}
+		if run.Tool.Driver.Rules[result.RuleIndex].ID != result.RuleID {
+			t.Errorf("Expected result %d to index rule %s, got %s", i, result.RuleID, run.Tool.Driver.Rules[result.RuleIndex].ID)
+		}
+		if len(result.Locations) != 1 {
+			t.Fatalf("Expected 1 location for result %d, got %+v", i, result.Locations)
+		}
+		location := result.Locations[0].PhysicalLocation
+		if location.ArtifactLocation.URI != file {
+			t.Errorf("Expected result %d in %s, got %s", i, file, location.ArtifactLocation.URI)
+		}
+		if location.Region == nil || location.Region.StartLine != expected[i].line {
+			t.Errorf("Expected result %d on line %d, got %+v", i, expected[i].line, location.Region)
+		}
+	}
+}
+
+// TestFindingLocationsInconsistentPin tests that an inconsistent pin is located at
+// every call of the module source, and that the config path is used without a file.
+func TestFindingLocationsInconsistentPin(t *testing.T) {
+	sbom := &SBOM{Modules: []ModuleInfo{
+		{Name: "vpc", Source: "git::https://github.com/acme/vpc.git?ref=v1.0.0", SourceType: sourceTypeGit, Version: "v1.0.0", Config: "app", File: "app/main.tf", Line: 3},
+		{Name: "vpc", Source: "git::https://github.com/acme/vpc.git?ref=main", SourceType: sourceTypeGit, Version: "main", Config: "network"},
+	}}
+
+	warnings := pinningWarnings(sbom.Modules)
+	if len(warnings) != 1 {
+		t.Fatalf("Expected 1 warning, got %+v", warnings)
+	}
+
+	locations := findingLocations(sbom, warnings[0])
+	if len(locations) != 2 {
+		t.Fatalf("Expected 2 locations, got %+v", locations)
+	}
+	if uri := locations[0].PhysicalLocation.ArtifactLocation.URI; uri != "app/main.tf" {
+		t.Errorf("Expected first location in app/main.tf, got %s", uri)
+	}
+	if region := locations[0].PhysicalLocation.Region; region == nil || region.StartLine != 3 {
+		t.Errorf("Expected first location on line 3, got %+v", region)
+	}
+	if uri := locations[1].PhysicalLocation.ArtifactLocation.URI; uri != "network" {
+		t.Errorf("Expected second location at the config path network, got %s", uri)
+	}
+	if region := locations[1].PhysicalLocation.Region; region != nil {
+		t.Errorf("Expected no region without a file, got %+v", region)
+	}
+}
+
+// TestWriteSBOMToSARIFOmitPositions tests that without positions, findings are located
+// at their config and the output does not change when blocks move to other lines.
+func TestWriteSBOMToSARIFOmitPositions(t *testing.T) {
+	configPath := t.TempDir()
+	outputDir := t.TempDir()
+
+	write := func(config string, name string) []byte {
+		if err := os.WriteFile(filepath.Join(configPath, "main.tf"), []byte(config), 0644); err != nil {
+			t.Fatalf("Failed to write config: %v", err)
+		}
+		sbom, err := generateSBOM(context.Background(), configPath, scanOptions{})
+		if err != nil {
+			t.Fatalf("Failed to generate SBOM: %v", err)
+		}
+		omitPositions(sbom)
+
+		outputPath := filepath.Join(outputDir, name)
+		if err := writeSBOMToSARIF(sbom, outputPath); err != nil {
+			t.Fatalf("Failed to write SARIF: %v", err)
+		}
+		content, err := os.ReadFile(outputPath)
+		if err != nil {
+			t.Fatalf("Failed to read SARIF: %v", err)
+		}
+		return content
+	}
+
+	original := write(sarifTestConfig, "original.sarif")
+	reformatted := write("\n\n"+sarifTestConfig, "reformatted.sarif")
+	if string(original) != string(reformatted) {
+		t.Errorf("Expected the same output after lines moved:\n%s\n%s", original, reformatted)
+	}
+
+	var log sarifLog
+	if err := json.Unmarshal(original, &log); err != nil {
+		t.Fatalf("Failed to parse SARIF: %v", err)
+	}
+	for i, result := range log.Runs[0].Results {
+		location := result.Locations[0].PhysicalLocation
+		if location.ArtifactLocation.URI != filepath.ToSlash(configPath) || location.Region != nil {
+			t.Errorf("Expected result %d at %s without a line, got %+v", i, configPath, location)
+		}
+	}
+}
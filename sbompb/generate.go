@@ -0,0 +1,5 @@
+// Package sbompb holds the protocol buffer schema of the SBOM and the Go types
+// generated from it, used by the protobuf and protojson output formats.
+package sbompb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative sbom.proto
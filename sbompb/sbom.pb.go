@@ -0,0 +1,673 @@
+// Protocol buffer schema for the SBOMs written by terraform-sbom with -output protobuf.
+// Field names match the keys of the JSON output.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        (unknown)
+// source: sbom.proto
+
+package sbompb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// SBOM is a Software Bill of Materials for one or more Terraform configurations.
+type SBOM struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// urn:uuid identifying this SBOM across revisions.
+	SerialNumber string `protobuf:"bytes,1,opt,name=serial_number,json=serialNumber,proto3" json:"serial_number,omitempty"`
+	// Revision of the SBOM, starting at 1.
+	Version int64 `protobuf:"varint,2,opt,name=version,proto3" json:"version,omitempty"`
+	// Name of the system the SBOM describes.
+	Name string `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	// URI that qualifies the name.
+	Namespace string `protobuf:"bytes,4,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	// Organization that supplies the system.
+	Supplier string `protobuf:"bytes,5,opt,name=supplier,proto3" json:"supplier,omitempty"`
+	// Generation time in RFC 3339 format, empty in canonical output.
+	Timestamp string          `protobuf:"bytes,6,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Modules   []*ModuleInfo   `protobuf:"bytes,7,rep,name=modules,proto3" json:"modules,omitempty"`
+	Providers []*ProviderInfo `protobuf:"bytes,8,rep,name=providers,proto3" json:"providers,omitempty"`
+	// Problems that did not stop the scan.
+	Warnings      []string `protobuf:"bytes,9,rep,name=warnings,proto3" json:"warnings,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SBOM) Reset() {
+	*x = SBOM{}
+	mi := &file_sbom_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SBOM) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SBOM) ProtoMessage() {}
+
+func (x *SBOM) ProtoReflect() protoreflect.Message {
+	mi := &file_sbom_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SBOM.ProtoReflect.Descriptor instead.
+func (*SBOM) Descriptor() ([]byte, []int) {
+	return file_sbom_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *SBOM) GetSerialNumber() string {
+	if x != nil {
+		return x.SerialNumber
+	}
+	return ""
+}
+
+func (x *SBOM) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *SBOM) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *SBOM) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *SBOM) GetSupplier() string {
+	if x != nil {
+		return x.Supplier
+	}
+	return ""
+}
+
+func (x *SBOM) GetTimestamp() string {
+	if x != nil {
+		return x.Timestamp
+	}
+	return ""
+}
+
+func (x *SBOM) GetModules() []*ModuleInfo {
+	if x != nil {
+		return x.Modules
+	}
+	return nil
+}
+
+func (x *SBOM) GetProviders() []*ProviderInfo {
+	if x != nil {
+		return x.Providers
+	}
+	return nil
+}
+
+func (x *SBOM) GetWarnings() []string {
+	if x != nil {
+		return x.Warnings
+	}
+	return nil
+}
+
+// ModuleInfo describes a module called by a Terraform configuration.
+type ModuleInfo struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Name       string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Source     string                 `protobuf:"bytes,2,opt,name=source,proto3" json:"source,omitempty"`
+	Subdir     string                 `protobuf:"bytes,3,opt,name=subdir,proto3" json:"subdir,omitempty"`
+	SourceType string                 `protobuf:"bytes,4,opt,name=source_type,json=sourceType,proto3" json:"source_type,omitempty"`
+	Version    string                 `protobuf:"bytes,5,opt,name=version,proto3" json:"version,omitempty"`
+	// Canonical form of a registry module's version constraint.
+	NormalizedVersion string `protobuf:"bytes,6,opt,name=normalized_version,json=normalizedVersion,proto3" json:"normalized_version,omitempty"`
+	// Path of the configuration calling the module.
+	Config string `protobuf:"bytes,7,opt,name=config,proto3" json:"config,omitempty"`
+	// Providers passed to the module, keyed by the name the module expects.
+	ProviderMappings map[string]string `protobuf:"bytes,8,rep,name=provider_mappings,json=providerMappings,proto3" json:"provider_mappings,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// Hostname of the registry serving a registry module.
+	Registry    string `protobuf:"bytes,9,opt,name=registry,proto3" json:"registry,omitempty"`
+	Private     bool   `protobuf:"varint,10,opt,name=private,proto3" json:"private,omitempty"`
+	Description string `protobuf:"bytes,11,opt,name=description,proto3" json:"description,omitempty"`
+	// Set for local modules when -metrics is given.
+	HasReadme *bool `protobuf:"varint,12,opt,name=has_readme,json=hasReadme,proto3,oneof" json:"has_readme,omitempty"`
+	// Set only when an allowlist or denylist is given.
+	Approved *bool `protobuf:"varint,13,opt,name=approved,proto3,oneof" json:"approved,omitempty"`
+	// Set only when -check-reachability is given.
+	Reachable         *bool  `protobuf:"varint,14,opt,name=reachable,proto3,oneof" json:"reachable,omitempty"`
+	ReachabilityError string `protobuf:"bytes,15,opt,name=reachability_error,json=reachabilityError,proto3" json:"reachability_error,omitempty"`
+	// Teams or users owning the config, from -codeowners.
+	Owners []string `protobuf:"bytes,16,rep,name=owners,proto3" json:"owners,omitempty"`
+	// Registry namespace or GitHub/git organization the source is published by.
+	Organization string `protobuf:"bytes,17,opt,name=organization,proto3" json:"organization,omitempty"`
+	// Call path from the root module, e.g. "root > networking > subnet", set with -flatten-nested.
+	Path string `protobuf:"bytes,18,opt,name=path,proto3" json:"path,omitempty"`
+	// Set when the source is fetched over an unencrypted transport such as http:// or git://.
+	Insecure bool `protobuf:"varint,19,opt,name=insecure,proto3" json:"insecure,omitempty"`
+	// Version declared by the configuration, kept when -version-overrides replaces it.
+	OriginalVersion string `protobuf:"bytes,20,opt,name=original_version,json=originalVersion,proto3" json:"original_version,omitempty"`
+	// Package URL, such as pkg:terraform/terraform-aws-modules/vpc/aws@5.1.0.
+	Purl string `protobuf:"bytes,21,opt,name=purl,proto3" json:"purl,omitempty"`
+	// How the version was derived, such as "ref-query", set with -explain.
+	VersionSource string `protobuf:"bytes,22,opt,name=version_source,json=versionSource,proto3" json:"version_source,omitempty"`
+	// Days since the pinned version was published, set with -check-updates.
+	AgeDays *int32 `protobuf:"varint,23,opt,name=age_days,json=ageDays,proto3,oneof" json:"age_days,omitempty"`
+	// From the VERSION file of a local module, set with -follow-local-sources.
+	LocalVersion string `protobuf:"bytes,24,opt,name=local_version,json=localVersion,proto3" json:"local_version,omitempty"`
+	// Terraform versions a local module requires, set with -follow-local-sources.
+	RequiredVersion string `protobuf:"bytes,25,opt,name=required_version,json=requiredVersion,proto3" json:"required_version,omitempty"`
+	// added, changed, or removed, set with -only-changed-versions.
+	Change string `protobuf:"bytes,26,opt,name=change,proto3" json:"change,omitempty"`
+	// Version in the previous SBOM, set with -only-changed-versions.
+	PreviousVersion string `protobuf:"bytes,27,opt,name=previous_version,json=previousVersion,proto3" json:"previous_version,omitempty"`
+	// Addresses in the depends_on meta-argument of the module block.
+	DependsOn []string `protobuf:"bytes,28,rep,name=depends_on,json=dependsOn,proto3" json:"depends_on,omitempty"`
+	// Full address including the config, e.g. network/module.vpc, set with -full-address.
+	Address string `protobuf:"bytes,29,opt,name=address,proto3" json:"address,omitempty"`
+	// Set when the source matches a -deprecations pattern.
+	Deprecated bool `protobuf:"varint,30,opt,name=deprecated,proto3" json:"deprecated,omitempty"`
+	// Source to migrate a deprecated module to.
+	Replacement string `protobuf:"bytes,31,opt,name=replacement,proto3" json:"replacement,omitempty"`
+	// Source with case and slash variants normalized, set only when it differs.
+	NormalizedSource string `protobuf:"bytes,32,opt,name=normalized_source,json=normalizedSource,proto3" json:"normalized_source,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *ModuleInfo) Reset() {
+	*x = ModuleInfo{}
+	mi := &file_sbom_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ModuleInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ModuleInfo) ProtoMessage() {}
+
+func (x *ModuleInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_sbom_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ModuleInfo.ProtoReflect.Descriptor instead.
+func (*ModuleInfo) Descriptor() ([]byte, []int) {
+	return file_sbom_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ModuleInfo) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ModuleInfo) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *ModuleInfo) GetSubdir() string {
+	if x != nil {
+		return x.Subdir
+	}
+	return ""
+}
+
+func (x *ModuleInfo) GetSourceType() string {
+	if x != nil {
+		return x.SourceType
+	}
+	return ""
+}
+
+func (x *ModuleInfo) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *ModuleInfo) GetNormalizedVersion() string {
+	if x != nil {
+		return x.NormalizedVersion
+	}
+	return ""
+}
+
+func (x *ModuleInfo) GetConfig() string {
+	if x != nil {
+		return x.Config
+	}
+	return ""
+}
+
+func (x *ModuleInfo) GetProviderMappings() map[string]string {
+	if x != nil {
+		return x.ProviderMappings
+	}
+	return nil
+}
+
+func (x *ModuleInfo) GetRegistry() string {
+	if x != nil {
+		return x.Registry
+	}
+	return ""
+}
+
+func (x *ModuleInfo) GetPrivate() bool {
+	if x != nil {
+		return x.Private
+	}
+	return false
+}
+
+func (x *ModuleInfo) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *ModuleInfo) GetHasReadme() bool {
+	if x != nil && x.HasReadme != nil {
+		return *x.HasReadme
+	}
+	return false
+}
+
+func (x *ModuleInfo) GetApproved() bool {
+	if x != nil && x.Approved != nil {
+		return *x.Approved
+	}
+	return false
+}
+
+func (x *ModuleInfo) GetReachable() bool {
+	if x != nil && x.Reachable != nil {
+		return *x.Reachable
+	}
+	return false
+}
+
+func (x *ModuleInfo) GetReachabilityError() string {
+	if x != nil {
+		return x.ReachabilityError
+	}
+	return ""
+}
+
+func (x *ModuleInfo) GetOwners() []string {
+	if x != nil {
+		return x.Owners
+	}
+	return nil
+}
+
+func (x *ModuleInfo) GetOrganization() string {
+	if x != nil {
+		return x.Organization
+	}
+	return ""
+}
+
+func (x *ModuleInfo) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *ModuleInfo) GetInsecure() bool {
+	if x != nil {
+		return x.Insecure
+	}
+	return false
+}
+
+func (x *ModuleInfo) GetOriginalVersion() string {
+	if x != nil {
+		return x.OriginalVersion
+	}
+	return ""
+}
+
+func (x *ModuleInfo) GetPurl() string {
+	if x != nil {
+		return x.Purl
+	}
+	return ""
+}
+
+func (x *ModuleInfo) GetVersionSource() string {
+	if x != nil {
+		return x.VersionSource
+	}
+	return ""
+}
+
+func (x *ModuleInfo) GetAgeDays() int32 {
+	if x != nil && x.AgeDays != nil {
+		return *x.AgeDays
+	}
+	return 0
+}
+
+func (x *ModuleInfo) GetLocalVersion() string {
+	if x != nil {
+		return x.LocalVersion
+	}
+	return ""
+}
+
+func (x *ModuleInfo) GetRequiredVersion() string {
+	if x != nil {
+		return x.RequiredVersion
+	}
+	return ""
+}
+
+func (x *ModuleInfo) GetChange() string {
+	if x != nil {
+		return x.Change
+	}
+	return ""
+}
+
+func (x *ModuleInfo) GetPreviousVersion() string {
+	if x != nil {
+		return x.PreviousVersion
+	}
+	return ""
+}
+
+func (x *ModuleInfo) GetDependsOn() []string {
+	if x != nil {
+		return x.DependsOn
+	}
+	return nil
+}
+
+func (x *ModuleInfo) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *ModuleInfo) GetDeprecated() bool {
+	if x != nil {
+		return x.Deprecated
+	}
+	return false
+}
+
+func (x *ModuleInfo) GetReplacement() string {
+	if x != nil {
+		return x.Replacement
+	}
+	return ""
+}
+
+func (x *ModuleInfo) GetNormalizedSource() string {
+	if x != nil {
+		return x.NormalizedSource
+	}
+	return ""
+}
+
+// ProviderInfo describes a provider required by a Terraform configuration.
+type ProviderInfo struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Name              string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Source            string                 `protobuf:"bytes,2,opt,name=source,proto3" json:"source,omitempty"`
+	VersionConstraint string                 `protobuf:"bytes,3,opt,name=version_constraint,json=versionConstraint,proto3" json:"version_constraint,omitempty"`
+	// Version from .terraform.lock.hcl when the constraint is a range.
+	LockedVersion string `protobuf:"bytes,4,opt,name=locked_version,json=lockedVersion,proto3" json:"locked_version,omitempty"`
+	// Path of the configuration requiring the provider.
+	Config string `protobuf:"bytes,5,opt,name=config,proto3" json:"config,omitempty"`
+	// Package URL, such as pkg:terraform/hashicorp/aws@5.31.0.
+	Purl          string `protobuf:"bytes,6,opt,name=purl,proto3" json:"purl,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProviderInfo) Reset() {
+	*x = ProviderInfo{}
+	mi := &file_sbom_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProviderInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProviderInfo) ProtoMessage() {}
+
+func (x *ProviderInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_sbom_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProviderInfo.ProtoReflect.Descriptor instead.
+func (*ProviderInfo) Descriptor() ([]byte, []int) {
+	return file_sbom_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ProviderInfo) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ProviderInfo) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *ProviderInfo) GetVersionConstraint() string {
+	if x != nil {
+		return x.VersionConstraint
+	}
+	return ""
+}
+
+func (x *ProviderInfo) GetLockedVersion() string {
+	if x != nil {
+		return x.LockedVersion
+	}
+	return ""
+}
+
+func (x *ProviderInfo) GetConfig() string {
+	if x != nil {
+		return x.Config
+	}
+	return ""
+}
+
+func (x *ProviderInfo) GetPurl() string {
+	if x != nil {
+		return x.Purl
+	}
+	return ""
+}
+
+var File_sbom_proto protoreflect.FileDescriptor
+
+const file_sbom_proto_rawDesc = "" +
+	"\n" +
+	"\n" +
+	"sbom.proto\x12\x10terraformsbom.v1\"\xc3\x02\n" +
+	"\x04SBOM\x12#\n" +
+	"\rserial_number\x18\x01 \x01(\tR\fserialNumber\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\x03R\aversion\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x12\x1c\n" +
+	"\tnamespace\x18\x04 \x01(\tR\tnamespace\x12\x1a\n" +
+	"\bsupplier\x18\x05 \x01(\tR\bsupplier\x12\x1c\n" +
+	"\ttimestamp\x18\x06 \x01(\tR\ttimestamp\x126\n" +
+	"\amodules\x18\a \x03(\v2\x1c.terraformsbom.v1.ModuleInfoR\amodules\x12<\n" +
+	"\tproviders\x18\b \x03(\v2\x1e.terraformsbom.v1.ProviderInfoR\tproviders\x12\x1a\n" +
+	"\bwarnings\x18\t \x03(\tR\bwarnings\"\xcb\t\n" +
+	"\n" +
+	"ModuleInfo\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x16\n" +
+	"\x06source\x18\x02 \x01(\tR\x06source\x12\x16\n" +
+	"\x06subdir\x18\x03 \x01(\tR\x06subdir\x12\x1f\n" +
+	"\vsource_type\x18\x04 \x01(\tR\n" +
+	"sourceType\x12\x18\n" +
+	"\aversion\x18\x05 \x01(\tR\aversion\x12-\n" +
+	"\x12normalized_version\x18\x06 \x01(\tR\x11normalizedVersion\x12\x16\n" +
+	"\x06config\x18\a \x01(\tR\x06config\x12_\n" +
+	"\x11provider_mappings\x18\b \x03(\v22.terraformsbom.v1.ModuleInfo.ProviderMappingsEntryR\x10providerMappings\x12\x1a\n" +
+	"\bregistry\x18\t \x01(\tR\bregistry\x12\x18\n" +
+	"\aprivate\x18\n" +
+	" \x01(\bR\aprivate\x12 \n" +
+	"\vdescription\x18\v \x01(\tR\vdescription\x12\"\n" +
+	"\n" +
+	"has_readme\x18\f \x01(\bH\x00R\thasReadme\x88\x01\x01\x12\x1f\n" +
+	"\bapproved\x18\r \x01(\bH\x01R\bapproved\x88\x01\x01\x12!\n" +
+	"\treachable\x18\x0e \x01(\bH\x02R\treachable\x88\x01\x01\x12-\n" +
+	"\x12reachability_error\x18\x0f \x01(\tR\x11reachabilityError\x12\x16\n" +
+	"\x06owners\x18\x10 \x03(\tR\x06owners\x12\"\n" +
+	"\forganization\x18\x11 \x01(\tR\forganization\x12\x12\n" +
+	"\x04path\x18\x12 \x01(\tR\x04path\x12\x1a\n" +
+	"\binsecure\x18\x13 \x01(\bR\binsecure\x12)\n" +
+	"\x10original_version\x18\x14 \x01(\tR\x0foriginalVersion\x12\x12\n" +
+	"\x04purl\x18\x15 \x01(\tR\x04purl\x12%\n" +
+	"\x0eversion_source\x18\x16 \x01(\tR\rversionSource\x12\x1e\n" +
+	"\bage_days\x18\x17 \x01(\x05H\x03R\aageDays\x88\x01\x01\x12#\n" +
+	"\rlocal_version\x18\x18 \x01(\tR\flocalVersion\x12)\n" +
+	"\x10required_version\x18\x19 \x01(\tR\x0frequiredVersion\x12\x16\n" +
+	"\x06change\x18\x1a \x01(\tR\x06change\x12)\n" +
+	"\x10previous_version\x18\x1b \x01(\tR\x0fpreviousVersion\x12\x1d\n" +
+	"\n" +
+	"depends_on\x18\x1c \x03(\tR\tdependsOn\x12\x18\n" +
+	"\aaddress\x18\x1d \x01(\tR\aaddress\x12\x1e\n" +
+	"\n" +
+	"deprecated\x18\x1e \x01(\bR\n" +
+	"deprecated\x12 \n" +
+	"\vreplacement\x18\x1f \x01(\tR\vreplacement\x12+\n" +
+	"\x11normalized_source\x18  \x01(\tR\x10normalizedSource\x1aC\n" +
+	"\x15ProviderMappingsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01B\r\n" +
+	"\v_has_readmeB\v\n" +
+	"\t_approvedB\f\n" +
+	"\n" +
+	"_reachableB\v\n" +
+	"\t_age_days\"\xbc\x01\n" +
+	"\fProviderInfo\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x16\n" +
+	"\x06source\x18\x02 \x01(\tR\x06source\x12-\n" +
+	"\x12version_constraint\x18\x03 \x01(\tR\x11versionConstraint\x12%\n" +
+	"\x0elocked_version\x18\x04 \x01(\tR\rlockedVersion\x12\x16\n" +
+	"\x06config\x18\x05 \x01(\tR\x06config\x12\x12\n" +
+	"\x04purl\x18\x06 \x01(\tR\x04purlB\"Z rodstewart/terraform-sbom/sbompbb\x06proto3"
+
+var (
+	file_sbom_proto_rawDescOnce sync.Once
+	file_sbom_proto_rawDescData []byte
+)
+
+func file_sbom_proto_rawDescGZIP() []byte {
+	file_sbom_proto_rawDescOnce.Do(func() {
+		file_sbom_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_sbom_proto_rawDesc), len(file_sbom_proto_rawDesc)))
+	})
+	return file_sbom_proto_rawDescData
+}
+
+var file_sbom_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_sbom_proto_goTypes = []any{
+	(*SBOM)(nil),         // 0: terraformsbom.v1.SBOM
+	(*ModuleInfo)(nil),   // 1: terraformsbom.v1.ModuleInfo
+	(*ProviderInfo)(nil), // 2: terraformsbom.v1.ProviderInfo
+	nil,                  // 3: terraformsbom.v1.ModuleInfo.ProviderMappingsEntry
+}
+var file_sbom_proto_depIdxs = []int32{
+	1, // 0: terraformsbom.v1.SBOM.modules:type_name -> terraformsbom.v1.ModuleInfo
+	2, // 1: terraformsbom.v1.SBOM.providers:type_name -> terraformsbom.v1.ProviderInfo
+	3, // 2: terraformsbom.v1.ModuleInfo.provider_mappings:type_name -> terraformsbom.v1.ModuleInfo.ProviderMappingsEntry
+	3, // [3:3] is the sub-list for method output_type
+	3, // [3:3] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_sbom_proto_init() }
+func file_sbom_proto_init() {
+	if File_sbom_proto != nil {
+		return
+	}
+	file_sbom_proto_msgTypes[1].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_sbom_proto_rawDesc), len(file_sbom_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   4,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_sbom_proto_goTypes,
+		DependencyIndexes: file_sbom_proto_depIdxs,
+		MessageInfos:      file_sbom_proto_msgTypes,
+	}.Build()
+	File_sbom_proto = out.File
+	file_sbom_proto_goTypes = nil
+	file_sbom_proto_depIdxs = nil
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// serialNumberPrefix is the URN namespace of SBOM serial numbers, as used by CycloneDX.
+const serialNumberPrefix = "urn:uuid:"
+
+// newSerialNumber returns a random serial number identifying a new SBOM.
+func newSerialNumber() string {
+	return serialNumberPrefix + uuid.NewString()
+}
+
+// contentSerialNumber returns a serial number derived from an SBOM's content digest, as
+// a name-based UUID, so that canonical output without -serial keeps the same serial
+// number until its content changes.
+func contentSerialNumber(digest string) string {
+	return serialNumberPrefix + uuid.NewSHA1(uuid.NameSpaceURL, []byte("sha256:"+digest)).String()
+}
+
+// parseSerialNumber validates a serial number given either as a urn:uuid URN or as
+// a bare UUID, and returns it in URN form.
+func parseSerialNumber(serial string) (string, error) {
+	id, err := uuid.Parse(serial)
+	if err != nil {
+		return "", fmt.Errorf("invalid serial number %q: %v", serial, err)
+	}
+	return serialNumberPrefix + id.String(), nil
+}
+
+// isValidSerialNumber reports whether a serial number is a lowercase urn:uuid URN.
+func isValidSerialNumber(serial string) bool {
+	if !strings.HasPrefix(serial, serialNumberPrefix) {
+		return false
+	}
+	id, err := uuid.Parse(strings.TrimPrefix(serial, serialNumberPrefix))
+	return err == nil && serialNumberPrefix+id.String() == serial
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"regexp"
+	"testing"
+)
+
+// serialNumberPattern matches a urn:uuid URN with a lowercase, version 4 UUID.
+var serialNumberPattern = regexp.MustCompile(`^urn:uuid:[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+// TestNewSerialNumber tests that generated serial numbers are unique urn:uuid URNs.
+func TestNewSerialNumber(t *testing.T) {
+	first, second := newSerialNumber(), newSerialNumber()
+	for _, serial := range []string{first, second} {
+		if !serialNumberPattern.MatchString(serial) {
+			t.Errorf("Serial number %q is not a urn:uuid URN", serial)
+		}
+		if !isValidSerialNumber(serial) {
+			t.Errorf("Expected %q to be a valid serial number", serial)
+		}
+	}
+	if first == second {
+		t.Errorf("Expected unique serial numbers, got %q twice", first)
+	}
+}
+
+// TestContentSerialNumber tests that serial numbers derived from the content of an SBOM
+// are valid, stay the same for the same content, and change with it.
+func TestContentSerialNumber(t *testing.T) {
+	sbom := &SBOM{Name: "network", Modules: []ModuleInfo{{Name: "vpc", Source: "terraform-aws-modules/vpc/aws", Version: "5.1.0", Config: "network"}}}
+	digest, err := sbomDigest(sbom)
+	if err != nil {
+		t.Fatalf("Failed to compute digest: %v", err)
+	}
+
+	serial := contentSerialNumber(digest)
+	if !isValidSerialNumber(serial) {
+		t.Errorf("Expected %q to be a valid serial number", serial)
+	}
+	if again := contentSerialNumber(digest); again != serial {
+		t.Errorf("Expected the same serial number for the same content, got %q and %q", serial, again)
+	}
+
+	sbom.Modules[0].Version = "5.2.0"
+	changed, err := sbomDigest(sbom)
+	if err != nil {
+		t.Fatalf("Failed to compute digest: %v", err)
+	}
+	if contentSerialNumber(changed) == serial {
+		t.Errorf("Expected the serial number to change with the content")
+	}
+}
+
+// TestParseSerialNumber tests normalizing serial numbers given on the command line.
+func TestParseSerialNumber(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		valid    bool
+	}{
+		{"3e671687-395b-41f5-a30f-a58921a69b79", "urn:uuid:3e671687-395b-41f5-a30f-a58921a69b79", true},
+		{"urn:uuid:3e671687-395b-41f5-a30f-a58921a69b79", "urn:uuid:3e671687-395b-41f5-a30f-a58921a69b79", true},
+		{"3E671687-395B-41F5-A30F-A58921A69B79", "urn:uuid:3e671687-395b-41f5-a30f-a58921a69b79", true},
+		{"not-a-uuid", "", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		serial, err := parseSerialNumber(tt.input)
+		if (err == nil) != tt.valid {
+			t.Errorf("parseSerialNumber(%q) error = %v, expected valid %t", tt.input, err, tt.valid)
+			continue
+		}
+		if serial != tt.expected {
+			t.Errorf("parseSerialNumber(%q) = %q, expected %q", tt.input, serial, tt.expected)
+		}
+	}
+}
+
+// TestIsValidSerialNumber tests that only lowercase urn:uuid URNs are accepted.
+func TestIsValidSerialNumber(t *testing.T) {
+	tests := map[string]bool{
+		"urn:uuid:3e671687-395b-41f5-a30f-a58921a69b79": true,
+		"urn:uuid:3E671687-395B-41F5-A30F-A58921A69B79": false,
+		"3e671687-395b-41f5-a30f-a58921a69b79":          false,
+		"urn:uuid:3e671687":                             false,
+	}
+
+	for serial, expected := range tests {
+		if got := isValidSerialNumber(serial); got != expected {
+			t.Errorf("isValidSerialNumber(%q) = %t, expected %t", serial, got, expected)
+		}
+	}
+}
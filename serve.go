@@ -0,0 +1,180 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// serveCacheTTL is how long serve reuses a generated SBOM before scanning again.
+const serveCacheTTL = 5 * time.Minute
+
+// serveFormat is an output format that serve can return, with its content type.
+type serveFormat struct {
+	contentType string
+	encode      func(w io.Writer, sbom *SBOM) error
+}
+
+// serveFormats lists the formats serve returns for the format query parameter.
+var serveFormats = map[string]serveFormat{
+	"json": {"application/json", encodeSBOMJSON},
+	"xml":  {"application/xml", encodeSBOMXML},
+	"yaml": {"application/yaml", encodeSBOMYAML},
+	"toml": {"application/toml", encodeSBOMTOML},
+	"csv": {"text/csv", func(w io.Writer, sbom *SBOM) error {
+		return writeCSVRecords(w, sbom, moduleFields, true, nil)
+	}},
+}
+
+// serveFormatNames returns the formats serve supports in sorted order.
+func serveFormatNames() []string {
+	names := make([]string, 0, len(serveFormats))
+	for name := range serveFormats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// sbomServer serves the SBOM of a configuration over HTTP. A generated SBOM is
+// reused until it is older than ttl; requests that arrive while a scan is running
+// wait for it rather than starting scans of their own.
+type sbomServer struct {
+	generate func(ctx context.Context) (*SBOM, error)
+	ttl      time.Duration
+	now      func() time.Time
+
+	mu          sync.Mutex
+	cached      *SBOM
+	generatedAt time.Time
+}
+
+// newSBOMServer returns a server that generates SBOMs with generate.
+func newSBOMServer(generate func(ctx context.Context) (*SBOM, error), ttl time.Duration) *sbomServer {
+	return &sbomServer{generate: generate, ttl: ttl, now: time.Now}
+}
+
+// sbom returns the cached SBOM and when it was generated, or generates a new one if
+// there is none yet or it has expired. A failed scan is not cached, so the next
+// request tries again.
+func (s *sbomServer) sbom(ctx context.Context) (*SBOM, time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cached != nil && s.now().Sub(s.generatedAt) < s.ttl {
+		return s.cached, s.generatedAt, nil
+	}
+
+	sbom, err := s.generate(ctx)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	s.cached, s.generatedAt = sbom, s.now()
+	return sbom, s.generatedAt, nil
+}
+
+// handler routes GET /sbom and GET /healthz.
+func (s *sbomServer) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /sbom", s.handleSBOM)
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		fmt.Fprintln(w, "ok")
+	})
+	return mux
+}
+
+// handleSBOM returns the SBOM in the format given by the format query parameter,
+// JSON by default. The SBOM is encoded in full before anything is written, so a
+// failure is reported with an error status rather than a truncated body.
+func (s *sbomServer) handleSBOM(w http.ResponseWriter, r *http.Request) {
+	name := strings.ToLower(r.URL.Query().Get("format"))
+	if name == "" {
+		name = "json"
+	}
+	format, ok := serveFormats[name]
+	if !ok {
+		http.Error(w, fmt.Sprintf("unsupported format %q; supported formats are %s", name, strings.Join(serveFormatNames(), ", ")), http.StatusBadRequest)
+		return
+	}
+
+	sbom, generatedAt, err := s.sbom(r.Context())
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to generate SBOM: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	var body bytes.Buffer
+	if err := format.encode(&body, sbom); err != nil {
+		http.Error(w, fmt.Sprintf("failed to encode SBOM: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", format.contentType)
+	w.Header().Set("Last-Modified", generatedAt.UTC().Format(http.TimeFormat))
+	w.Write(body.Bytes())
+}
+
+// runServe implements the serve command, which serves the SBOM of a configuration
+// over HTTP for dashboards that poll it.
+func runServe(args []string) {
+	flags := newFlagSet("serve", "")
+	addr := flags.String("addr", ":8080", "Address to listen on")
+	root := flags.String("root", "", "Terraform configuration to serve the SBOM of")
+	recursive := flags.Bool("recursive", false, "Scan every Terraform configuration below the root")
+	cacheTTL := flags.Duration("cache-ttl", serveCacheTTL, "How long to serve a generated SBOM before scanning again, e.g. 30s or 1h")
+	flags.Parse(args)
+
+	if *root == "" || flags.NArg() > 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	configPath := expandPath(*root)
+	if info, err := os.Stat(configPath); err != nil || !info.IsDir() {
+		log.Fatalf("Error: %s is not a directory", configPath)
+	}
+
+	server := newSBOMServer(func(ctx context.Context) (*SBOM, error) {
+		var sbom *SBOM
+		var err error
+		if *recursive {
+			sbom, err = generateRecursiveSBOM(ctx, configPath, scanOptions{}, nil)
+		} else {
+			sbom, err = generateSBOM(ctx, configPath, scanOptions{})
+		}
+		if err != nil {
+			return nil, err
+		}
+		sbom.Name = defaultSBOMName(configPath)
+		sbom.SerialNumber = newSerialNumber()
+		sbom.Version = 1
+		sbom.Timestamp = time.Now().UTC().Format(time.RFC3339)
+		return sbom, nil
+	}, *cacheTTL)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	httpServer := &http.Server{Addr: *addr, Handler: server.handler(), ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		httpServer.Shutdown(shutdownCtx)
+	}()
+
+	fmt.Fprintf(os.Stderr, "Info: serving the SBOM of %s on %s\n", configPath, *addr)
+	if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Fatalf("Error: %v", err)
+	}
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestServeSBOM tests serving the SBOM in the requested format with its content type.
+func TestServeSBOM(t *testing.T) {
+	server := newSBOMServer(func(ctx context.Context) (*SBOM, error) {
+		return mockSBOM(), nil
+	}, time.Minute)
+	handler := server.handler()
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/sbom?format=json", nil))
+	if recorder.Code != http.StatusOK || recorder.Header().Get("Content-Type") != "application/json" {
+		t.Fatalf("Expected a JSON response, got %d %q", recorder.Code, recorder.Header().Get("Content-Type"))
+	}
+	var sbom SBOM
+	if err := json.Unmarshal(recorder.Body.Bytes(), &sbom); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(sbom.Modules) != len(mockSBOM().Modules) {
+		t.Errorf("Expected %d modules, got %d", len(mockSBOM().Modules), len(sbom.Modules))
+	}
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/sbom?format=csv", nil))
+	if recorder.Header().Get("Content-Type") != "text/csv" || !strings.HasPrefix(recorder.Body.String(), "Config Path,Module Name,") {
+		t.Errorf("Expected a CSV response, got %q: %s", recorder.Header().Get("Content-Type"), recorder.Body.String())
+	}
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/sbom?format=pdf", nil))
+	if recorder.Code != http.StatusBadRequest || !strings.Contains(recorder.Body.String(), "csv, json, toml, xml, yaml") {
+		t.Errorf("Expected a bad request listing the formats, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/sbom", nil))
+	if recorder.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected POST to be rejected, got %d", recorder.Code)
+	}
+}
+
+// TestServeSBOMMatchesOutput tests that every format is served exactly as scan writes
+// it to a file.
+func TestServeSBOMMatchesOutput(t *testing.T) {
+	server := newSBOMServer(func(ctx context.Context) (*SBOM, error) {
+		return mockSBOM(), nil
+	}, time.Minute)
+	handler := server.handler()
+
+	for _, format := range []string{"json", "xml", "yaml", "toml", "csv"} {
+		outputPath := filepath.Join(t.TempDir(), "sbom."+format)
+		if err := writeSBOM(mockSBOM(), format, outputPath); err != nil {
+			t.Fatalf("Failed to write %s: %v", format, err)
+		}
+		written, err := os.ReadFile(outputPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/sbom?format="+format, nil))
+		if recorder.Body.String() != string(written) {
+			t.Errorf("Served %s differs from the written file:\n%s\n%s", format, recorder.Body.String(), written)
+		}
+	}
+}
+
+// TestServeSBOMLastModified tests that concurrent requests, each regenerating the SBOM,
+// are answered with the generation time of the SBOM in their own body.
+func TestServeSBOMLastModified(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	scans := 0
+	server := newSBOMServer(func(ctx context.Context) (*SBOM, error) {
+		scans++
+		return &SBOM{Name: strconv.Itoa(scans)}, nil
+	}, 0)
+	server.now = func() time.Time { return start.Add(time.Duration(scans) * time.Second) }
+	handler := server.handler()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/sbom", nil))
+
+			var sbom SBOM
+			if err := json.Unmarshal(recorder.Body.Bytes(), &sbom); err != nil {
+				t.Errorf("Failed to decode response: %v", err)
+				return
+			}
+			scan, _ := strconv.Atoi(sbom.Name)
+			expected := start.Add(time.Duration(scan) * time.Second).Format(http.TimeFormat)
+			if lastModified := recorder.Header().Get("Last-Modified"); lastModified != expected {
+				t.Errorf("Expected Last-Modified %s for scan %d, got %s", expected, scan, lastModified)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// TestServeSBOMCache tests that the SBOM is only generated again once the cache
+// expires, and that a failed scan is reported and not cached.
+func TestServeSBOMCache(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	scans := 0
+	var scanErr error
+	server := newSBOMServer(func(ctx context.Context) (*SBOM, error) {
+		scans++
+		if scanErr != nil {
+			return nil, scanErr
+		}
+		return mockSBOM(), nil
+	}, time.Minute)
+	server.now = func() time.Time { return now }
+	handler := server.handler()
+
+	get := func() int {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/sbom", nil))
+		return recorder.Code
+	}
+
+	get()
+	now = now.Add(30 * time.Second)
+	get()
+	if scans != 1 {
+		t.Errorf("Expected one scan within the TTL, got %d", scans)
+	}
+
+	now = now.Add(time.Minute)
+	scanErr = errors.New("failed to load Terraform module")
+	if code := get(); code != http.StatusInternalServerError {
+		t.Errorf("Expected a failed scan to return 500, got %d", code)
+	}
+
+	scanErr = nil
+	if code := get(); code != http.StatusOK || scans != 3 {
+		t.Errorf("Expected a new scan after the failure, got %d after %d scans", code, scans)
+	}
+}
+
+// TestServeHealthz tests the health check endpoint.
+func TestServeHealthz(t *testing.T) {
+	server := newSBOMServer(func(ctx context.Context) (*SBOM, error) {
+		t.Fatal("Expected no scan for a health check")
+		return nil, nil
+	}, time.Minute)
+
+	recorder := httptest.NewRecorder()
+	server.handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if recorder.Code != http.StatusOK || strings.TrimSpace(recorder.Body.String()) != "ok" {
+		t.Errorf("Expected ok, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+}
@@ -0,0 +1,28 @@
+package main
+
+import "fmt"
+
+// signatureExt is appended to the path of an output file to name its detached
+// signature, as cosign sign-blob --output-signature is usually given.
+const signatureExt = ".sig"
+
+// fileSigner writes detached signatures of output files.
+type fileSigner interface {
+	// SignFile signs the contents of the file at path and writes the signature to
+	// path with signatureExt appended, returning the signature's path.
+	SignFile(path string) (string, error)
+}
+
+// newFileSigner loads the private key at keyPath into a signer. It is nil unless
+// the binary is built with the sign tag, which keeps key handling out of the builds
+// that do not need it.
+var newFileSigner func(keyPath string) (fileSigner, error)
+
+// loadFileSigner returns a signer for -sign, or an error naming the build tag to
+// compile signing in with.
+func loadFileSigner(keyPath string) (fileSigner, error) {
+	if newFileSigner == nil {
+		return nil, fmt.Errorf("-sign is not supported by this build; rebuild with -tags sign")
+	}
+	return newFileSigner(keyPath)
+}
@@ -0,0 +1,100 @@
+//go:build sign
+
+package main
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+func init() {
+	newFileSigner = func(keyPath string) (fileSigner, error) {
+		key, err := loadSigningKey(keyPath)
+		if err != nil {
+			return nil, err
+		}
+		return cosignSigner{key: key}, nil
+	}
+}
+
+// cosignSigner writes signatures in the format of cosign sign-blob: the base64-encoded
+// signature of the file's contents, which cosign verify-blob --key checks against the
+// matching public key. ECDSA and RSA keys sign the SHA-256 digest of the contents, and
+// Ed25519 keys the contents themselves, as cosign does.
+type cosignSigner struct {
+	key crypto.Signer
+}
+
+func (s cosignSigner) SignFile(path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s to sign it: %v", path, err)
+	}
+
+	message, opts := content, crypto.SignerOpts(crypto.Hash(0))
+	if _, ok := s.key.(ed25519.PrivateKey); !ok {
+		digest := sha256.Sum256(content)
+		message, opts = digest[:], crypto.SHA256
+	}
+	signature, err := s.key.Sign(rand.Reader, message, opts)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign %s: %v", path, err)
+	}
+
+	sigPath := path + signatureExt
+	err = writeFileAtomic(sigPath, func(w io.Writer) error {
+		_, err := io.WriteString(w, base64.StdEncoding.EncodeToString(signature))
+		return err
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to write signature: %v", err)
+	}
+	return sigPath, nil
+}
+
+// loadSigningKey reads an unencrypted PEM private key: a PKCS #8 ECDSA, RSA, or
+// Ed25519 key, or an ECDSA or RSA key in its traditional SEC 1 or PKCS #1 form. The
+// encrypted keys written by cosign generate-key-pair are not supported.
+func loadSigningKey(path string) (crypto.Signer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing key: %v", err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s is not a PEM-encoded private key", path)
+	}
+
+	var key any
+	switch {
+	case strings.HasPrefix(block.Type, "ENCRYPTED"):
+		return nil, fmt.Errorf("%s is an encrypted %s; sign with an unencrypted PKCS #8 key instead", path, strings.ToLower(strings.TrimPrefix(block.Type, "ENCRYPTED ")))
+	case block.Type == "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case block.Type == "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	case block.Type == "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("%s holds a %s, not a private key", path, block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse signing key %s: %v", path, err)
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("%s holds an unsupported type of private key", path)
+	}
+	return signer, nil
+}
@@ -0,0 +1,123 @@
+//go:build sign
+
+package main
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeTestKey writes a private key to a PEM file of the given type in dir.
+func writeTestKey(t *testing.T, dir, blockType string, der []byte) string {
+	path := filepath.Join(dir, "cosign.key")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0600); err != nil {
+		t.Fatalf("Failed to write key: %v", err)
+	}
+	return path
+}
+
+// TestCosignSigner tests signing an SBOM with ECDSA, RSA, and Ed25519 keys, and
+// verifying each signature the way cosign verify-blob does.
+func TestCosignSigner(t *testing.T) {
+	ecdsaKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	_, ed25519Key, _ := ed25519.GenerateKey(rand.Reader)
+
+	ecdsaDER, _ := x509.MarshalPKCS8PrivateKey(ecdsaKey)
+	ed25519DER, _ := x509.MarshalPKCS8PrivateKey(ed25519Key)
+	sec1DER, _ := x509.MarshalECPrivateKey(ecdsaKey)
+
+	tests := []struct {
+		name      string
+		blockType string
+		der       []byte
+		verify    func(content, signature []byte) bool
+	}{
+		{"ecdsa", "PRIVATE KEY", ecdsaDER, func(content, signature []byte) bool {
+			digest := sha256.Sum256(content)
+			return ecdsa.VerifyASN1(&ecdsaKey.PublicKey, digest[:], signature)
+		}},
+		{"ecdsa-sec1", "EC PRIVATE KEY", sec1DER, func(content, signature []byte) bool {
+			digest := sha256.Sum256(content)
+			return ecdsa.VerifyASN1(&ecdsaKey.PublicKey, digest[:], signature)
+		}},
+		{"rsa", "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKey), func(content, signature []byte) bool {
+			digest := sha256.Sum256(content)
+			return rsa.VerifyPKCS1v15(&rsaKey.PublicKey, crypto.SHA256, digest[:], signature) == nil
+		}},
+		{"ed25519", "PRIVATE KEY", ed25519DER, func(content, signature []byte) bool {
+			return ed25519.Verify(ed25519Key.Public().(ed25519.PublicKey), content, signature)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			signer, err := loadFileSigner(writeTestKey(t, dir, tt.blockType, tt.der))
+			if err != nil {
+				t.Fatalf("Failed to load key: %v", err)
+			}
+
+			sbomPath := filepath.Join(dir, "sbom.json")
+			content := []byte(`{"modules": [{"name": "vpc", "version": "5.1.0"}]}`)
+			if err := os.WriteFile(sbomPath, content, 0644); err != nil {
+				t.Fatalf("Failed to write SBOM: %v", err)
+			}
+
+			sigPath, err := signer.SignFile(sbomPath)
+			if err != nil {
+				t.Fatalf("Failed to sign: %v", err)
+			}
+			if sigPath != sbomPath+".sig" {
+				t.Errorf("Expected signature at %s.sig, got %s", sbomPath, sigPath)
+			}
+
+			encoded, err := os.ReadFile(sigPath)
+			if err != nil {
+				t.Fatalf("Failed to read signature: %v", err)
+			}
+			signature, err := base64.StdEncoding.DecodeString(string(encoded))
+			if err != nil {
+				t.Fatalf("Expected a base64 signature, got %q: %v", encoded, err)
+			}
+			if !tt.verify(content, signature) {
+				t.Error("Expected the signature to verify against the public key")
+			}
+			if tt.verify(append(content, ' '), signature) {
+				t.Error("Expected the signature not to verify against changed content")
+			}
+		})
+	}
+}
+
+// TestLoadSigningKeyErrors tests rejecting encrypted cosign keys and PEM blocks
+// that are not private keys.
+func TestLoadSigningKeyErrors(t *testing.T) {
+	tests := []struct {
+		blockType string
+		expected  string
+	}{
+		{"ENCRYPTED SIGSTORE PRIVATE KEY", "encrypted sigstore private key"},
+		{"PUBLIC KEY", "holds a PUBLIC KEY, not a private key"},
+		{"PRIVATE KEY", "failed to parse signing key"},
+	}
+
+	for _, tt := range tests {
+		path := writeTestKey(t, t.TempDir(), tt.blockType, []byte("not a key"))
+		if _, err := loadSigningKey(path); err == nil || !strings.Contains(err.Error(), tt.expected) {
+			t.Errorf("Expected an error containing %q for a %s, got %v", tt.expected, tt.blockType, err)
+		}
+	}
+}
@@ -0,0 +1,16 @@
+//go:build !sign
+
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestLoadFileSignerWithoutTag tests that -sign names the build tag it needs.
+func TestLoadFileSignerWithoutTag(t *testing.T) {
+	_, err := loadFileSigner("cosign.pem")
+	if err == nil || !strings.Contains(err.Error(), "-tags sign") {
+		t.Errorf("Expected an error naming the sign build tag, got %v", err)
+	}
+}
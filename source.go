@@ -0,0 +1,263 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Source types reported in ModuleInfo.SourceType.
+const (
+	sourceTypeLocal      = "local"
+	sourceTypeRegistry   = "registry"
+	sourceTypeGitHub     = "github"
+	sourceTypeBitbucket  = "bitbucket"
+	sourceTypeGit        = "git"
+	sourceTypeMercurial  = "hg"
+	sourceTypeHTTP       = "http"
+	sourceTypeS3         = "s3"
+	sourceTypeGCS        = "gcs"
+	sourceTypeTerragrunt = "terragrunt"
+	sourceTypeUnknown    = "unknown"
+)
+
+// registrySourcePattern matches registry module addresses of the form
+// [hostname/]namespace/name/provider.
+var registrySourcePattern = regexp.MustCompile(`^([0-9A-Za-z-]+(\.[0-9A-Za-z-]+)+/)?[0-9A-Za-z_-]+/[0-9A-Za-z_-]+/[0-9a-z]+$`)
+
+// defaultRegistryHost is the registry used for module addresses without a hostname.
+const defaultRegistryHost = "registry.terraform.io"
+
+// isLocalSource reports whether a module source refers to a local path.
+func isLocalSource(source string) bool {
+	return strings.HasPrefix(source, "./") || strings.HasPrefix(source, "../")
+}
+
+// splitSubdir separates the //subdir portion of a module source from the package address,
+// following the same rules Terraform uses: the first double slash after any scheme
+// separator starts the subdirectory, and a trailing query string stays with the address.
+// For example, git::https://github.com/org/repo.git//modules/vpc?ref=v1 is split into
+// git::https://github.com/org/repo.git?ref=v1 and modules/vpc.
+func splitSubdir(source string) (string, string) {
+	if isLocalSource(source) {
+		return source, ""
+	}
+
+	offset := 0
+	if idx := strings.Index(source, "://"); idx > -1 {
+		offset = idx + len("://")
+	}
+
+	idx := strings.Index(source[offset:], "//")
+	if idx == -1 {
+		return source, ""
+	}
+	idx += offset
+
+	address := source[:idx]
+	subdir := source[idx+len("//"):]
+
+	if query := strings.Index(subdir, "?"); query > -1 {
+		address += subdir[query:]
+		subdir = subdir[:query]
+	}
+
+	return address, subdir
+}
+
+// normalizeSource returns the spelling of a module source that equivalent spellings
+// share, so that modules can be grouped by source. Trailing slashes are trimmed from
+// the address and from its //subdir, and repeated slashes are collapsed inside the
+// subdir and in local paths, where they carry no meaning. Registry addresses are
+// case-insensitive, so they are lowercased and the public registry hostname is
+// dropped, as in terraform-aws-modules/vpc/aws. Other sources keep their case, since
+// git and HTTP paths can be case-sensitive, and query strings such as ref are kept.
+func normalizeSource(source string) string {
+	if isLocalSource(source) {
+		normalized := collapseSlashes(strings.TrimRight(source, "/"))
+		if !isLocalSource(normalized) {
+			// ./ and ../ cannot be trimmed any further.
+			return source
+		}
+		return normalized
+	}
+
+	address, query, hasQuery := strings.Cut(source, "?")
+	address, subdir := splitSubdir(address)
+	address = strings.TrimRight(address, "/")
+	subdir = collapseSlashes(strings.Trim(subdir, "/"))
+
+	if sourceType(strings.ToLower(address)) == sourceTypeRegistry {
+		address = strings.TrimPrefix(strings.ToLower(address), defaultRegistryHost+"/")
+	}
+
+	if subdir != "" {
+		address += "//" + subdir
+	}
+	if hasQuery {
+		address += "?" + query
+	}
+	return address
+}
+
+// collapseSlashes replaces every run of slashes in a path with a single one.
+func collapseSlashes(path string) string {
+	for strings.Contains(path, "//") {
+		path = strings.ReplaceAll(path, "//", "/")
+	}
+	return path
+}
+
+// setNormalizedSources records the normalized source of each module whose source is
+// spelled differently from it. Modules already spelled that way are left without one.
+func setNormalizedSources(modules []ModuleInfo) {
+	for i := range modules {
+		if normalized := normalizeSource(modules[i].Source); normalized != modules[i].Source {
+			modules[i].NormalizedSource = normalized
+		}
+	}
+}
+
+// sourceType classifies a module source using the same address forms Terraform
+// accepts: local paths, registry addresses, and the go-getter style remote sources.
+func sourceType(source string) string {
+	if isLocalSource(source) {
+		return sourceTypeLocal
+	}
+
+	address, _ := splitSubdir(source)
+	if query := strings.Index(address, "?"); query > -1 {
+		address = address[:query]
+	}
+
+	switch {
+	case strings.HasPrefix(address, "git::"), strings.HasPrefix(address, "git@"):
+		return sourceTypeGit
+	case strings.HasPrefix(address, "hg::"):
+		return sourceTypeMercurial
+	case strings.HasPrefix(address, "s3::"), strings.Contains(address, ".amazonaws.com/"):
+		return sourceTypeS3
+	case strings.HasPrefix(address, "gcs::"), strings.HasPrefix(address, "www.googleapis.com/storage/"):
+		return sourceTypeGCS
+	case strings.HasPrefix(address, "github.com/"):
+		return sourceTypeGitHub
+	case strings.HasPrefix(address, "bitbucket.org/"):
+		return sourceTypeBitbucket
+	case strings.HasPrefix(address, "http://"), strings.HasPrefix(address, "https://"):
+		return sourceTypeHTTP
+	case registrySourcePattern.MatchString(address):
+		return sourceTypeRegistry
+	}
+
+	return sourceTypeUnknown
+}
+
+// splitRegistryAddress separates the hostname of a registry module address from its
+// namespace/name/provider path. Shorthand addresses use the public Terraform registry.
+func splitRegistryAddress(address string) (string, string) {
+	if parts := strings.SplitN(address, "/", 4); len(parts) == 4 {
+		return parts[0], strings.Join(parts[1:], "/")
+	}
+	return defaultRegistryHost, address
+}
+
+// isPrivateRegistry reports whether a registry hostname is one of the given private
+// hosts or a subdomain of one of them. Hostnames are compared case-insensitively.
+func isPrivateRegistry(host string, privateHosts []string) bool {
+	host = strings.ToLower(host)
+	for _, private := range privateHosts {
+		private = strings.ToLower(strings.TrimPrefix(private, "."))
+		if host == private || strings.HasSuffix(host, "."+private) {
+			return true
+		}
+	}
+	return false
+}
+
+// sourceOrganization infers the organization publishing a module: the namespace of a
+// registry address, or the first path segment after the host of a GitHub, Bitbucket,
+// or git URL, including SSH URLs such as git@github.com:org/repo.git. Other sources
+// have no organization and return an empty string.
+func sourceOrganization(source string) string {
+	kind := sourceType(source)
+
+	address, _ := splitSubdir(source)
+	if query := strings.Index(address, "?"); query > -1 {
+		address = address[:query]
+	}
+
+	switch kind {
+	case sourceTypeRegistry:
+		_, path := splitRegistryAddress(address)
+		namespace, _, _ := strings.Cut(path, "/")
+		return namespace
+	case sourceTypeGitHub, sourceTypeBitbucket, sourceTypeGit:
+		address = strings.TrimPrefix(address, "git::")
+		if idx := strings.Index(address, "://"); idx > -1 {
+			address = address[idx+len("://"):]
+		} else if colon := strings.Index(address, ":"); colon > -1 && !strings.Contains(address[:colon], "/") {
+			// An scp-like address such as git@github.com:org/repo.git separates the
+			// host from the path with a colon.
+			address = address[:colon] + "/" + address[colon+1:]
+		}
+
+		parts := strings.Split(address, "/")
+		if len(parts) < 3 {
+			return ""
+		}
+		return parts[1]
+	}
+
+	return ""
+}
+
+// insecureSchemes are the URL schemes that fetch a module without encryption.
+var insecureSchemes = []string{"http://", "git://", "ftp://"}
+
+// isInsecureSource reports whether a module source is fetched over an unencrypted
+// transport, such as plain http:// or the git:// protocol, including behind a forced
+// getter such as git::http://. Registry and local sources are always fetched securely
+// or not at all, so they are never insecure.
+func isInsecureSource(source string) bool {
+	if isLocalSource(source) {
+		return false
+	}
+
+	address := strings.ToLower(source)
+	if getter, rest, ok := strings.Cut(address, "::"); ok && !strings.Contains(getter, "/") {
+		address = rest
+	}
+
+	for _, scheme := range insecureSchemes {
+		if strings.HasPrefix(address, scheme) {
+			return true
+		}
+	}
+	return false
+}
+
+// setInsecure records whether each module is fetched over an unencrypted transport.
+func setInsecure(modules []ModuleInfo) {
+	for i := range modules {
+		modules[i].Insecure = isInsecureSource(modules[i].Source)
+	}
+}
+
+// setOrganizations records the organization inferred from the source of each module.
+func setOrganizations(modules []ModuleInfo) {
+	for i := range modules {
+		modules[i].Organization = sourceOrganization(modules[i].Source)
+	}
+}
+
+// setRegistries records the registry hostname of each registry module and whether it
+// is one of the private registry hosts.
+func setRegistries(modules []ModuleInfo, privateHosts []string) {
+	for i := range modules {
+		if modules[i].SourceType != sourceTypeRegistry {
+			continue
+		}
+		host, _ := splitRegistryAddress(modules[i].Source)
+		modules[i].Registry = host
+		modules[i].Private = isPrivateRegistry(host, privateHosts)
+	}
+}
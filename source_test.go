@@ -0,0 +1,211 @@
+package main
+
+import "testing"
+
+// TestSplitSubdir tests separating the subdirectory from module sources.
+func TestSplitSubdir(t *testing.T) {
+	tests := []struct {
+		source  string
+		address string
+		subdir  string
+	}{
+		{"git::https://github.com/org/repo.git//modules/vpc?ref=v1", "git::https://github.com/org/repo.git?ref=v1", "modules/vpc"},
+		{"git::https://github.com/org/repo.git//modules/vpc", "git::https://github.com/org/repo.git", "modules/vpc"},
+		{"git::https://github.com/org/repo.git?ref=v1", "git::https://github.com/org/repo.git?ref=v1", ""},
+		{"git@github.com:org/repo.git//modules/vpc?ref=v1", "git@github.com:org/repo.git?ref=v1", "modules/vpc"},
+		{"hashicorp/consul/aws//modules/consul-cluster", "hashicorp/consul/aws", "modules/consul-cluster"},
+		{"terraform-aws-modules/vpc/aws", "terraform-aws-modules/vpc/aws", ""},
+		{"./modules/vpc", "./modules/vpc", ""},
+	}
+
+	for _, tt := range tests {
+		address, subdir := splitSubdir(tt.source)
+		if address != tt.address || subdir != tt.subdir {
+			t.Errorf("splitSubdir(%q) = (%q, %q), expected (%q, %q)", tt.source, address, subdir, tt.address, tt.subdir)
+		}
+	}
+}
+
+// TestNormalizeSource tests that registry addresses are lowercased while git and other
+// case-sensitive sources keep their case, with slash variants normalized in both.
+func TestNormalizeSource(t *testing.T) {
+	tests := []struct {
+		source   string
+		expected string
+	}{
+		// Registry addresses are case-insensitive.
+		{"Terraform-AWS-Modules/VPC/aws", "terraform-aws-modules/vpc/aws"},
+		{"terraform-aws-modules/vpc/aws/", "terraform-aws-modules/vpc/aws"},
+		{"registry.terraform.io/terraform-aws-modules/vpc/aws", "terraform-aws-modules/vpc/aws"},
+		{"Registry.Terraform.io/Terraform-AWS-Modules/VPC/aws", "terraform-aws-modules/vpc/aws"},
+		{"TFE.Example.com/Acme/Network/AWS", "tfe.example.com/acme/network/aws"},
+		{"hashicorp/consul/aws//modules/consul-cluster/", "hashicorp/consul/aws//modules/consul-cluster"},
+		{"hashicorp/consul/aws//modules//consul-cluster", "hashicorp/consul/aws//modules/consul-cluster"},
+		{"terraform-aws-modules/vpc/aws", "terraform-aws-modules/vpc/aws"},
+
+		// Git and other remote sources keep their case.
+		{"git::https://github.com/Acme/Terraform-VPC.git", "git::https://github.com/Acme/Terraform-VPC.git"},
+		{"git::https://github.com/Acme/Terraform-VPC.git/", "git::https://github.com/Acme/Terraform-VPC.git"},
+		{"git::https://github.com/Acme/VPC.git//Modules//Private/?ref=V1.0.0", "git::https://github.com/Acme/VPC.git//Modules/Private?ref=V1.0.0"},
+		{"git@github.com:Acme/VPC.git?ref=Main", "git@github.com:Acme/VPC.git?ref=Main"},
+		{"github.com/Acme/VPC/", "github.com/Acme/VPC"},
+		{"https://example.com/Modules/VPC.zip", "https://example.com/Modules/VPC.zip"},
+
+		// Local paths only have their slashes normalized.
+		{"./Modules//VPC/", "./Modules/VPC"},
+		{"../shared/", "../shared"},
+		{"./", "./"},
+	}
+
+	for _, tt := range tests {
+		if got := normalizeSource(tt.source); got != tt.expected {
+			t.Errorf("normalizeSource(%q) = %q, expected %q", tt.source, got, tt.expected)
+		}
+	}
+}
+
+// TestSetNormalizedSources tests that the normalized source is only recorded when it
+// differs from the source, which is kept as written.
+func TestSetNormalizedSources(t *testing.T) {
+	modules := []ModuleInfo{
+		{Name: "vpc", Source: "Terraform-AWS-Modules/VPC/aws"},
+		{Name: "dns", Source: "acme/dns/aws"},
+		{Name: "labels", Source: "git::https://github.com/Acme/Labels.git?ref=v1.0.0"},
+	}
+
+	setNormalizedSources(modules)
+
+	if modules[0].Source != "Terraform-AWS-Modules/VPC/aws" || modules[0].NormalizedSource != "terraform-aws-modules/vpc/aws" {
+		t.Errorf("Unexpected sources for vpc: %q and %q", modules[0].Source, modules[0].NormalizedSource)
+	}
+	for _, mod := range modules[1:] {
+		if mod.NormalizedSource != "" {
+			t.Errorf("Expected no normalized source for %s, got %q", mod.Name, mod.NormalizedSource)
+		}
+	}
+}
+
+// TestSourceType tests classification of module sources.
+func TestSourceType(t *testing.T) {
+	tests := []struct {
+		source   string
+		expected string
+	}{
+		{"./modules/vpc", sourceTypeLocal},
+		{"../shared", sourceTypeLocal},
+		{"terraform-aws-modules/vpc/aws", sourceTypeRegistry},
+		{"app.terraform.io/example-corp/k8s-cluster/azurerm", sourceTypeRegistry},
+		{"hashicorp/consul/aws//modules/consul-cluster", sourceTypeRegistry},
+		{"github.com/hashicorp/example?ref=v1.2.0", sourceTypeGitHub},
+		{"bitbucket.org/hashicorp/terraform-consul-aws", sourceTypeBitbucket},
+		{"git::https://example.com/vpc.git?ref=v1.2.0", sourceTypeGit},
+		{"git@github.com:hashicorp/example.git", sourceTypeGit},
+		{"hg::http://example.com/vpc.hg", sourceTypeMercurial},
+		{"https://example.com/vpc-module.zip", sourceTypeHTTP},
+		{"s3::https://s3-eu-west-1.amazonaws.com/examplecorp-terraform-modules/vpc.zip", sourceTypeS3},
+		{"gcs::https://www.googleapis.com/storage/v1/modules/foomodule.zip", sourceTypeGCS},
+		{"not a source", sourceTypeUnknown},
+	}
+
+	for _, tt := range tests {
+		if got := sourceType(tt.source); got != tt.expected {
+			t.Errorf("sourceType(%q) = %q, expected %q", tt.source, got, tt.expected)
+		}
+	}
+}
+
+// TestSetRegistries tests recording the registry host and privacy of registry modules.
+func TestSetRegistries(t *testing.T) {
+	modules := []ModuleInfo{
+		{Name: "shorthand", Source: "terraform-aws-modules/vpc/aws", SourceType: sourceTypeRegistry},
+		{Name: "public", Source: "registry.terraform.io/terraform-aws-modules/vpc/aws", SourceType: sourceTypeRegistry},
+		{Name: "private", Source: "tfe.corp.net/platform/network/aws", SourceType: sourceTypeRegistry},
+		{Name: "subdomain", Source: "eu.TFE.corp.net/platform/network/aws", SourceType: sourceTypeRegistry},
+		{Name: "lookalike", Source: "nottfe.corp.net/platform/network/aws", SourceType: sourceTypeRegistry},
+		{Name: "git", Source: "git::https://tfe.corp.net/platform/network.git", SourceType: sourceTypeGit},
+	}
+
+	setRegistries(modules, []string{"tfe.corp.net"})
+
+	expected := map[string]struct {
+		registry string
+		private  bool
+	}{
+		"shorthand": {"registry.terraform.io", false},
+		"public":    {"registry.terraform.io", false},
+		"private":   {"tfe.corp.net", true},
+		"subdomain": {"eu.TFE.corp.net", true},
+		"lookalike": {"nottfe.corp.net", false},
+		"git":       {"", false},
+	}
+	for _, mod := range modules {
+		want := expected[mod.Name]
+		if mod.Registry != want.registry || mod.Private != want.private {
+			t.Errorf("Registry mismatch for %s: expected %s (private %t), got %s (private %t)",
+				mod.Name, want.registry, want.private, mod.Registry, mod.Private)
+		}
+	}
+}
+
+// TestSourceOrganization tests inferring the publishing organization of module sources.
+func TestSourceOrganization(t *testing.T) {
+	tests := []struct {
+		source   string
+		expected string
+	}{
+		{"terraform-aws-modules/vpc/aws", "terraform-aws-modules"},
+		{"app.terraform.io/example-corp/k8s-cluster/azurerm", "example-corp"},
+		{"hashicorp/consul/aws//modules/consul-cluster", "hashicorp"},
+		{"github.com/hashicorp/example?ref=v1.2.0", "hashicorp"},
+		{"bitbucket.org/acme/terraform-modules//vpc", "acme"},
+		{"git::https://github.com/acme/terraform-labels.git?ref=v2.0.0", "acme"},
+		{"git::https://gitlab.com/platform/networking/vpc.git", "platform"},
+		{"git::ssh://git@github.com/acme/vpc.git?ref=v1", "acme"},
+		{"git::ssh://git@git.example.com:2222/infra/vpc.git", "infra"},
+		{"git@github.com:acme/vpc.git//modules/subnets?ref=v1", "acme"},
+		{"git::git@bitbucket.org:acme/vpc.git", "acme"},
+		{"git::https://example.com/vpc.git", ""},
+		{"https://example.com/vpc-module.zip", ""},
+		{"s3::https://s3-eu-west-1.amazonaws.com/examplecorp-terraform-modules/vpc.zip", ""},
+		{"./modules/vpc", ""},
+		{"not a source", ""},
+	}
+
+	for _, tt := range tests {
+		if got := sourceOrganization(tt.source); got != tt.expected {
+			t.Errorf("sourceOrganization(%q) = %q, expected %q", tt.source, got, tt.expected)
+		}
+	}
+}
+
+// TestIsInsecureSource tests detecting module sources fetched over unencrypted transports.
+func TestIsInsecureSource(t *testing.T) {
+	tests := []struct {
+		source   string
+		expected bool
+	}{
+		{"http://example.com/vpc-module.zip", true},
+		{"HTTP://example.com/vpc-module.zip", true},
+		{"https://example.com/vpc-module.zip", false},
+		{"git::http://git.example.com/vpc.git?ref=v1.0.0", true},
+		{"git::https://github.com/acme/vpc.git?ref=v1.0.0", false},
+		{"git://git.example.com/vpc.git", true},
+		{"git::git://git.example.com/vpc.git", true},
+		{"git::ssh://git@github.com/acme/vpc.git", false},
+		{"git@github.com:acme/vpc.git", false},
+		{"hg::http://hg.example.com/vpc", true},
+		{"s3::http://s3.amazonaws.com/bucket/vpc.zip", true},
+		{"s3::https://s3-eu-west-1.amazonaws.com/bucket/vpc.zip", false},
+		{"ftp://example.com/vpc.zip", true},
+		{"github.com/acme/vpc", false},
+		{"terraform-aws-modules/vpc/aws", false},
+		{"app.terraform.io/example-corp/k8s-cluster/azurerm", false},
+		{"./modules/vpc", false},
+	}
+
+	for _, tt := range tests {
+		if got := isInsecureSource(tt.source); got != tt.expected {
+			t.Errorf("isInsecureSource(%q) = %t, expected %t", tt.source, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// telemetryEventName identifies scan events among the events of other tools.
+const telemetryEventName = "terraform_sbom.scan"
+
+// telemetryEvent is a structured record of a single scan, written as one JSON line.
+// It only holds counts and error messages, never module sources or other SBOM content.
+type telemetryEvent struct {
+	Name           string   `json:"name"`
+	Timestamp      string   `json:"timestamp"`
+	DurationMs     int64    `json:"duration_ms"`
+	Version        string   `json:"version"`
+	Status         string   `json:"status"` // "ok" or "error"
+	ConfigsScanned int      `json:"configs_scanned"`
+	ModuleCount    int      `json:"module_count"`
+	WarningCount   int      `json:"warning_count"`
+	Errors         []string `json:"errors,omitempty"`
+}
+
+// newTelemetryEvent builds the event for a scan that started at start and produced
+// sbom, or failed with err. sbom may be nil when the scan failed.
+func newTelemetryEvent(start time.Time, end time.Time, sbom *SBOM, err error) telemetryEvent {
+	event := telemetryEvent{
+		Name:       telemetryEventName,
+		Timestamp:  start.UTC().Format(time.RFC3339),
+		DurationMs: end.Sub(start).Milliseconds(),
+		Version:    version,
+		Status:     "ok",
+	}
+
+	if sbom != nil {
+		configs := make(map[string]bool)
+		for _, mod := range sbom.Modules {
+			configs[mod.Config] = true
+		}
+		for _, config := range sbom.Configs {
+			configs[config.Path] = true
+		}
+		event.ConfigsScanned = len(configs)
+		event.ModuleCount = len(sbom.Modules)
+		event.WarningCount = len(sbom.Warnings)
+	}
+
+	if err != nil {
+		event.Status = "error"
+		event.Errors = []string{err.Error()}
+	}
+
+	return event
+}
+
+// appendTelemetryEvent appends the event to a JSON Lines file, creating it if needed.
+func appendTelemetryEvent(path string, event telemetryEvent) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode telemetry event: %v", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open telemetry file: %v", err)
+	}
+	defer file.Close()
+
+	_, err = file.Write(append(line, '\n'))
+	if err != nil {
+		return fmt.Errorf("failed to write telemetry file: %v", err)
+	}
+	return nil
+}
+
+// recordTelemetry appends a scan event to path if telemetry is enabled. Telemetry is
+// best-effort: a failure to write it is reported on stderr but never fails the scan.
+func recordTelemetry(path string, start time.Time, sbom *SBOM, err error) {
+	if path == "" {
+		return
+	}
+	if werr := appendTelemetryEvent(path, newTelemetryEvent(start, time.Now(), sbom, err)); werr != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", werr)
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// TestNewTelemetryEvent tests the counts and status recorded for successful and failed scans.
+func TestNewTelemetryEvent(t *testing.T) {
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	end := start.Add(1500 * time.Millisecond)
+
+	sbom := mockSBOM()
+	sbom.Modules = append(sbom.Modules, ModuleInfo{Name: "dns", Config: "/path/to/other"})
+	sbom.Warnings = []string{"main.tf:1: Unclosed configuration block"}
+
+	expected := telemetryEvent{
+		Name:           telemetryEventName,
+		Timestamp:      "2024-05-01T12:00:00Z",
+		DurationMs:     1500,
+		Version:        version,
+		Status:         "ok",
+		ConfigsScanned: 2,
+		ModuleCount:    3,
+		WarningCount:   1,
+	}
+	if got := newTelemetryEvent(start, end, sbom, nil); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Event mismatch:\nexpected %+v\ngot      %+v", expected, got)
+	}
+
+	failed := newTelemetryEvent(start, end, nil, errors.New("failed to load Terraform module"))
+	if failed.Status != "error" || !reflect.DeepEqual(failed.Errors, []string{"failed to load Terraform module"}) || failed.ModuleCount != 0 {
+		t.Errorf("Unexpected event for a failed scan: %+v", failed)
+	}
+}
+
+// TestAppendTelemetryEvent tests that each event is appended as its own JSON line.
+func TestAppendTelemetryEvent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "telemetry.jsonl")
+
+	for i := 1; i <= 2; i++ {
+		if err := appendTelemetryEvent(path, telemetryEvent{Name: telemetryEventName, ModuleCount: i}); err != nil {
+			t.Fatalf("Failed to append telemetry event: %v", err)
+		}
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open telemetry file: %v", err)
+	}
+	defer file.Close()
+
+	var counts []int
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var event telemetryEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			t.Fatalf("Failed to parse telemetry line %q: %v", scanner.Text(), err)
+		}
+		counts = append(counts, event.ModuleCount)
+	}
+	if !reflect.DeepEqual(counts, []int{1, 2}) {
+		t.Errorf("Expected two events with module counts [1 2], got %v", counts)
+	}
+}
+
+// TestRecordTelemetryFailure tests that an unwritable telemetry file does not panic or exit.
+func TestRecordTelemetryFailure(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing", "telemetry.jsonl")
+	recordTelemetry(path, time.Now(), mockSBOM(), nil)
+	recordTelemetry("", time.Now(), mockSBOM(), nil)
+
+	if fileExists(path) {
+		t.Errorf("Expected no telemetry file to be created")
+	}
+}
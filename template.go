@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// templateFuncs are the helper functions available to custom output templates.
+var templateFuncs = template.FuncMap{
+	"join":      strings.Join,
+	"lower":     strings.ToLower,
+	"upper":     strings.ToUpper,
+	"replace":   strings.ReplaceAll,
+	"hasPrefix": strings.HasPrefix,
+	"trimSpace": strings.TrimSpace,
+}
+
+// loadTemplate parses a user-supplied Go text/template used to render the SBOM.
+// It is called before scanning so that template errors are reported up front.
+func loadTemplate(templatePath string) (*template.Template, error) {
+	content, err := os.ReadFile(templatePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template file: %v", err)
+	}
+
+	tmpl, err := template.New(filepath.Base(templatePath)).Funcs(templateFuncs).Parse(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template: %v", err)
+	}
+
+	return tmpl, nil
+}
+
+// writeSBOMWithTemplate renders the SBOM through the given template and writes the result to a file.
+func writeSBOMWithTemplate(sbom *SBOM, tmpl *template.Template, outputPath string) error {
+	err := writeFileAtomic(outputPath, func(w io.Writer) error {
+		return tmpl.Execute(w, sbom)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to render template: %v", err)
+	}
+
+	fmt.Printf("SBOM successfully written to %s\n", outputPath)
+	return nil
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestWriteSBOMWithTemplate tests rendering the SBOM through a custom template.
+func TestWriteSBOMWithTemplate(t *testing.T) {
+	dir := t.TempDir()
+	templatePath := filepath.Join(dir, "sbom.tmpl")
+	outputPath := filepath.Join(dir, "sbom.md")
+
+	content := "| Name | Source |\n|---|---|\n{{ range .Modules }}| {{ upper .Name }} | {{ .Source }} |\n{{ end }}"
+	err := os.WriteFile(templatePath, []byte(content), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tmpl, err := loadTemplate(templatePath)
+	if err != nil {
+		t.Fatalf("Failed to load template: %v", err)
+	}
+
+	err = writeSBOMWithTemplate(mockSBOM(), tmpl, outputPath)
+	if err != nil {
+		t.Fatalf("Failed to write SBOM with template: %v", err)
+	}
+
+	output, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("Failed to read output file: %v", err)
+	}
+
+	expected := "| Name | Source |\n|---|---|\n" +
+		"| AWS_VPC | git::https://github.com/terraform-aws-modules/vpc.git?ref=v2.0.0 |\n" +
+		"| S3_BUCKET | hashicorp/aws |\n"
+	if string(output) != expected {
+		t.Errorf("Template output mismatch: expected %q, got %q", expected, string(output))
+	}
+}
+
+// TestLoadTemplateParseError tests that invalid templates are rejected before rendering.
+func TestLoadTemplateParseError(t *testing.T) {
+	templatePath := filepath.Join(t.TempDir(), "broken.tmpl")
+	err := os.WriteFile(templatePath, []byte("{{ range .Modules }}{{ .Name }"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = loadTemplate(templatePath)
+	if err == nil {
+		t.Fatal("Expected an error for an invalid template")
+	}
+	if !strings.Contains(err.Error(), "failed to parse template") {
+		t.Errorf("Unexpected error message: %v", err)
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/terraform-config-inspect/tfconfig"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// terragruntFileName is the configuration file Terragrunt reads in each unit directory.
+const terragruntFileName = "terragrunt.hcl"
+
+// terragruntBlocks maps the Terragrunt blocks we record to the attribute holding their source.
+var terragruntBlocks = map[string]string{
+	"terraform":  "source",
+	"include":    "path",
+	"dependency": "config_path",
+}
+
+// hasTerragruntConfig reports whether the directory contains a terragrunt.hcl file.
+func hasTerragruntConfig(dir string) bool {
+	return fileExists(filepath.Join(dir, terragruntFileName))
+}
+
+// parseTerragrunt extracts the module source, includes, and dependencies declared in the
+// terragrunt.hcl file of a directory. Each is returned as a ModuleInfo with SourceType
+// "terragrunt". Directories without a terragrunt.hcl file yield no modules.
+func parseTerragrunt(configPath string) ([]ModuleInfo, error) {
+	path := filepath.Join(configPath, terragruntFileName)
+
+	src, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", path, err)
+	}
+
+	file, diags := hclsyntax.ParseConfig(src, path, hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("failed to parse %s: %v", path, diags.Error())
+	}
+
+	var modules []ModuleInfo
+	for _, block := range file.Body.(*hclsyntax.Body).Blocks {
+		attrName, ok := terragruntBlocks[block.Type]
+		if !ok {
+			continue
+		}
+
+		attr, ok := block.Body.Attributes[attrName]
+		if !ok {
+			continue
+		}
+
+		name := block.Type
+		if len(block.Labels) > 0 {
+			name += "." + block.Labels[0]
+		}
+
+		source := expressionString(src, attr.Expr)
+		address, subdir := splitSubdir(source)
+
+		modules = append(modules, ModuleInfo{
+			Name:       name,
+			Source:     address,
+			Subdir:     subdir,
+			SourceType: sourceTypeTerragrunt,
+			Version:    terragruntVersion(source),
+			Config:     configPath,
+		})
+	}
+
+	return modules, nil
+}
+
+// expressionString returns the string value of an expression when it can be evaluated
+// statically, and otherwise its source text. Terragrunt sources commonly call functions
+// such as find_in_parent_folders() that only Terragrunt itself can evaluate.
+func expressionString(src []byte, expr hclsyntax.Expression) string {
+	value, diags := expr.Value(nil)
+	if !diags.HasErrors() && value.IsKnown() && !value.IsNull() && value.Type() == cty.String {
+		return value.AsString()
+	}
+
+	return strings.Trim(string(expr.Range().SliceBytes(src)), `"`)
+}
+
+// terragruntVersion extracts the version of a Terragrunt source. In addition to the
+// forms extractVersion understands, registry sources using the tfr:// scheme carry
+// their version in a version query parameter.
+func terragruntVersion(source string) string {
+	if version := sourceQueryParam(source, "version"); version != "" {
+		return version
+	}
+	return extractVersion(&tfconfig.ModuleCall{Source: source})
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestParseTerragrunt tests extracting sources, includes, and dependencies from terragrunt.hcl.
+func TestParseTerragrunt(t *testing.T) {
+	configPath := filepath.Join("testdata", "terragrunt", "live", "app")
+
+	modules, err := parseTerragrunt(configPath)
+	if err != nil {
+		t.Fatalf("Failed to parse terragrunt.hcl: %v", err)
+	}
+
+	expected := []ModuleInfo{
+		{Name: "include", Source: "find_in_parent_folders()", SourceType: sourceTypeTerragrunt, Version: "N/A", Config: configPath},
+		{Name: "terraform", Source: "tfr:///terraform-aws-modules/ec2-instance/aws?version=5.2.1", SourceType: sourceTypeTerragrunt, Version: "5.2.1", Config: configPath},
+		{Name: "dependency.vpc", Source: "../vpc", SourceType: sourceTypeTerragrunt, Version: "local", Config: configPath},
+	}
+	if !reflect.DeepEqual(modules, expected) {
+		t.Errorf("Terragrunt modules mismatch:\nexpected %+v\ngot      %+v", expected, modules)
+	}
+}
+
+// TestParseTerragruntSubdir tests that git sources with a subdirectory and ref are split.
+func TestParseTerragruntSubdir(t *testing.T) {
+	configPath := filepath.Join("testdata", "terragrunt", "live", "vpc")
+
+	modules, err := parseTerragrunt(configPath)
+	if err != nil {
+		t.Fatalf("Failed to parse terragrunt.hcl: %v", err)
+	}
+
+	if len(modules) != 2 {
+		t.Fatalf("Expected 2 modules, got %d", len(modules))
+	}
+
+	expected := ModuleInfo{
+		Name:       "terraform",
+		Source:     "git::https://github.com/terraform-aws-modules/terraform-aws-vpc.git?ref=v5.1.0",
+		Subdir:     "modules/vpc-endpoints",
+		SourceType: sourceTypeTerragrunt,
+		Version:    "v5.1.0",
+		Config:     configPath,
+	}
+	if !reflect.DeepEqual(modules[1], expected) {
+		t.Errorf("Terragrunt module mismatch:\nexpected %+v\ngot      %+v", expected, modules[1])
+	}
+}
+
+// TestGenerateRecursiveSBOMTerragrunt tests that Terragrunt units are only scanned when enabled.
+func TestGenerateRecursiveSBOMTerragrunt(t *testing.T) {
+	sbom, err := generateRecursiveSBOM(context.Background(), "testdata/terragrunt", scanOptions{}, nil)
+	if err != nil {
+		t.Fatalf("Failed to generate SBOM: %v", err)
+	}
+	if len(sbom.Modules) != 0 {
+		t.Errorf("Expected no modules without Terragrunt support, got %d", len(sbom.Modules))
+	}
+
+	sbom, err = generateRecursiveSBOM(context.Background(), "testdata/terragrunt", scanOptions{Terragrunt: true}, nil)
+	if err != nil {
+		t.Fatalf("Failed to generate SBOM: %v", err)
+	}
+	if len(sbom.Modules) != 5 {
+		t.Errorf("Expected 5 Terragrunt modules, got %d", len(sbom.Modules))
+	}
+}
@@ -0,0 +1,31 @@
+provider "aws" {
+  region = "us-west-2"
+}
+
+provider "aws" {
+  alias  = "useast1"
+  region = "us-east-1"
+}
+
+module "vpc_west" {
+  source  = "terraform-aws-modules/vpc/aws"
+  version = "5.1.0"
+}
+
+module "vpc_east" {
+  source  = "terraform-aws-modules/vpc/aws"
+  version = "5.1.0"
+
+  providers = {
+    aws = aws.useast1
+  }
+}
+
+module "peering" {
+  source = "./modules/peering"
+
+  providers = {
+    aws.requester = aws
+    aws.accepter  = aws.useast1
+  }
+}
@@ -0,0 +1,9 @@
+terraform {
+  backend "local" {
+    path = "state/terraform.tfstate"
+  }
+}
+
+module "network" {
+  source = "./modules/network"
+}
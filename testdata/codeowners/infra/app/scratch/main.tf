@@ -0,0 +1,3 @@
+module "labels" {
+  source = "./modules/labels"
+}
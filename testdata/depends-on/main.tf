@@ -0,0 +1,16 @@
+resource "aws_iam_role" "deploy" {
+  name               = "deploy"
+  assume_role_policy = "{}"
+}
+
+module "network" {
+  source  = "terraform-aws-modules/vpc/aws"
+  version = "5.1.0"
+}
+
+module "cluster" {
+  source  = "terraform-aws-modules/eks/aws"
+  version = "20.8.4"
+
+  depends_on = [module.network, aws_iam_role.deploy]
+}
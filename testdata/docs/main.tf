@@ -0,0 +1,12 @@
+module "documented" {
+  source = "./modules/documented"
+}
+
+module "undocumented" {
+  source = "./modules/undocumented"
+}
+
+module "vpc" {
+  source  = "terraform-aws-modules/vpc/aws"
+  version = "5.1.0"
+}
@@ -0,0 +1,4 @@
+variable "cidr_block" {
+  description = "CIDR block of the network"
+  type        = string
+}
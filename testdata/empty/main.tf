@@ -0,0 +1,11 @@
+variable "bucket_name" {
+  type = string
+}
+
+resource "aws_s3_bucket" "logs" {
+  bucket = var.bucket_name
+}
+
+output "bucket_arn" {
+  value = aws_s3_bucket.logs.arn
+}
@@ -0,0 +1,25 @@
+terraform {
+  experiments = [module_variable_optional_attrs]
+
+  required_providers {
+    aws = {
+      source = "hashicorp/aws"
+    }
+  }
+}
+
+variable "role_arn" {
+  type = string
+}
+
+locals {
+  role    = provider::aws::arn_parse(var.role_arn)
+  account = provider::aws::arn_parse(var.role_arn).account_id
+}
+
+module "labels" {
+  source  = "cloudposse/label/null"
+  version = "0.25.0"
+
+  name = upper(provider::time::rfc3339_parse(timestamp()).year)
+}
@@ -0,0 +1,12 @@
+module "network" {
+  source = "./modules/network"
+}
+
+module "vpc" {
+  source  = "terraform-aws-modules/vpc/aws"
+  version = "5.1.0"
+}
+
+module "self" {
+  source = "./"
+}
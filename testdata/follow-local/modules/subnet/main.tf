@@ -0,0 +1,7 @@
+terraform {
+  required_version = ">= 1.3, < 2.0"
+}
+
+variable "cidr_block" {
+  type = string
+}
@@ -0,0 +1,30 @@
+# Tracks main on purpose until the platform team cuts its first release.
+# sbom:ignore=unpinned-module
+module "platform" {
+  source = "git::https://github.com/acme/terraform-platform.git"
+}
+
+module "dns" {
+  source = "git::https://github.com/acme/terraform-dns.git"
+}
+
+// sbom:ignore=non-semver-pin,unpinned-module pinned to a release branch
+module "db" {
+  source = "git::https://github.com/acme/terraform-db.git?ref=release-2024"
+}
+
+# sbom:ignore=unpinned-module
+
+module "cache" {
+  source = "git::https://github.com/acme/terraform-cache.git"
+}
+
+# sbom:ignore=unpinned
+module "queue" {
+  source = "git::https://github.com/acme/terraform-queue.git"
+}
+
+# sbom:ignore=pinned
+module "search" {
+  source = "git::https://github.com/acme/terraform-search.git?ref=v1.0.0"
+}
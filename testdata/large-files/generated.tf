@@ -0,0 +1,73 @@
+# Generated file; stands in for a machine-generated configuration that is too large to load.
+
+module "generated" {
+  source  = "acme/generated/aws"
+  version = "1.0.0"
+}
+
+locals {
+  generated_000 = "value-000"
+  generated_001 = "value-001"
+  generated_002 = "value-002"
+  generated_003 = "value-003"
+  generated_004 = "value-004"
+  generated_005 = "value-005"
+  generated_006 = "value-006"
+  generated_007 = "value-007"
+  generated_008 = "value-008"
+  generated_009 = "value-009"
+  generated_010 = "value-010"
+  generated_011 = "value-011"
+  generated_012 = "value-012"
+  generated_013 = "value-013"
+  generated_014 = "value-014"
+  generated_015 = "value-015"
+  generated_016 = "value-016"
+  generated_017 = "value-017"
+  generated_018 = "value-018"
+  generated_019 = "value-019"
+  generated_020 = "value-020"
+  generated_021 = "value-021"
+  generated_022 = "value-022"
+  generated_023 = "value-023"
+  generated_024 = "value-024"
+  generated_025 = "value-025"
+  generated_026 = "value-026"
+  generated_027 = "value-027"
+  generated_028 = "value-028"
+  generated_029 = "value-029"
+  generated_030 = "value-030"
+  generated_031 = "value-031"
+  generated_032 = "value-032"
+  generated_033 = "value-033"
+  generated_034 = "value-034"
+  generated_035 = "value-035"
+  generated_036 = "value-036"
+  generated_037 = "value-037"
+  generated_038 = "value-038"
+  generated_039 = "value-039"
+  generated_040 = "value-040"
+  generated_041 = "value-041"
+  generated_042 = "value-042"
+  generated_043 = "value-043"
+  generated_044 = "value-044"
+  generated_045 = "value-045"
+  generated_046 = "value-046"
+  generated_047 = "value-047"
+  generated_048 = "value-048"
+  generated_049 = "value-049"
+  generated_050 = "value-050"
+  generated_051 = "value-051"
+  generated_052 = "value-052"
+  generated_053 = "value-053"
+  generated_054 = "value-054"
+  generated_055 = "value-055"
+  generated_056 = "value-056"
+  generated_057 = "value-057"
+  generated_058 = "value-058"
+  generated_059 = "value-059"
+  generated_060 = "value-060"
+  generated_061 = "value-061"
+  generated_062 = "value-062"
+  generated_063 = "value-063"
+}
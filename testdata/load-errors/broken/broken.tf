@@ -0,0 +1,2 @@
+module "labels" {
+  source = "git::https://github.com/acme/terraform-labels.git?ref=v1.0.0"
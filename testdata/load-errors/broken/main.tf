@@ -0,0 +1,4 @@
+module "dns" {
+  source  = "acme/dns/aws"
+  version = "1.2.0"
+}
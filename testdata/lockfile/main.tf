@@ -0,0 +1,17 @@
+terraform {
+  required_providers {
+    aws = {
+      source  = "hashicorp/aws"
+      version = "~> 5.0"
+    }
+    random = {
+      source  = "hashicorp/random"
+      version = "= 3.5.1"
+    }
+    null = {
+      source = "hashicorp/null"
+    }
+  }
+}
+
+resource "null_resource" "example" {}
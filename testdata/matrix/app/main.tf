@@ -0,0 +1,12 @@
+module "vpc_primary" {
+  source = "git::https://github.com/acme/terraform-vpc.git?ref=v2.0.0"
+}
+
+module "vpc_secondary" {
+  source = "git::https://github.com/acme/terraform-vpc.git?ref=v2.1.0"
+}
+
+module "dns" {
+  source  = "terraform-aws-modules/route53/aws//modules/zones"
+  version = "2.10.2"
+}
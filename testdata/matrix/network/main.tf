@@ -0,0 +1,7 @@
+module "vpc" {
+  source = "git::https://github.com/acme/terraform-vpc.git?ref=v2.0.0"
+}
+
+module "flow_logs" {
+  source = "./modules/flow-logs"
+}
@@ -0,0 +1,5 @@
+# Follows the vendor's main branch until it is released.
+# sbom:ignore=unpinned-module
+module "vpc" {
+  source = "git::https://github.com/acme/terraform-vpc.git"
+}
@@ -0,0 +1,8 @@
+module "networking" {
+  source = "./modules/networking"
+}
+
+module "vpc" {
+  source  = "terraform-aws-modules/vpc/aws"
+  version = "5.1.0"
+}
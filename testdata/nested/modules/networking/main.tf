@@ -0,0 +1,7 @@
+module "subnet" {
+  source = "../subnet"
+}
+
+module "dns" {
+  source = "../dns"
+}
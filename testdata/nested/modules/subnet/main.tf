@@ -0,0 +1,3 @@
+module "label" {
+  source = "git::https://github.com/acme/terraform-label.git?ref=v1.0.0"
+}
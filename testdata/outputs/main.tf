@@ -0,0 +1,15 @@
+module "vpc" {
+  source  = "terraform-aws-modules/vpc/aws"
+  version = "5.1.0"
+}
+
+output "vpc_id" {
+  description = "ID of the VPC"
+  value       = module.vpc.vpc_id
+}
+
+output "db_password" {
+  description = "Password for the database"
+  value       = "example"
+  sensitive   = true
+}
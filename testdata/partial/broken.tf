@@ -0,0 +1,6 @@
+module "dns" {
+  source = "./modules/dns"
+
+module "cdn" {
+  source = "./modules/cdn"
+}
@@ -0,0 +1,13 @@
+module "labels" {
+  source = "git::https://github.com/acme/terraform-labels.git?ref=main"
+}
+
+module "vpc" {
+  source  = "terraform-aws-modules/vpc/aws"
+  version = "~> 5.0"
+}
+
+module "dns" {
+  source  = "acme/dns/aws"
+  version = "1.2.0"
+}
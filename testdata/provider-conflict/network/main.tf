@@ -0,0 +1,11 @@
+terraform {
+  required_providers {
+    aws = {
+      source  = "acme-forks/aws"
+      version = "~> 5.0"
+    }
+    random = {
+      source = "registry.terraform.io/hashicorp/random"
+    }
+  }
+}
@@ -0,0 +1,3 @@
+module "bucket" {
+  source = "git::https://github.com/terraform-aws-modules/terraform-aws-s3-bucket.git?ref=v3.15.1"
+}
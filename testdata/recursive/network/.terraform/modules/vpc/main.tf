@@ -0,0 +1,3 @@
+module "ignored" {
+  source = "./ignored"
+}
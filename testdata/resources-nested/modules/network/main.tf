@@ -0,0 +1,12 @@
+variable "vpc_id" {
+  type = string
+}
+
+resource "aws_internet_gateway" "this" {
+  vpc_id = var.vpc_id
+}
+
+module "subnet" {
+  source = "./subnet"
+  vpc_id = var.vpc_id
+}
@@ -0,0 +1,15 @@
+variable "vpc_id" {
+  type = string
+}
+
+resource "aws_subnet" "private" {
+  vpc_id     = var.vpc_id
+  cidr_block = "10.0.1.0/24"
+}
+
+resource "datadog_monitor" "subnet" {
+  name    = "subnet capacity"
+  type    = "metric alert"
+  query   = "avg(last_5m):avg:aws.vpc.subnet.available_ips{*} < 10"
+  message = "Subnet is running out of addresses"
+}
@@ -0,0 +1,24 @@
+provider "aws" {
+  alias  = "west"
+  region = "us-west-2"
+}
+
+resource "aws_vpc" "main" {
+  cidr_block = "10.0.0.0/16"
+}
+
+resource "aws_subnet" "private" {
+  count  = 2
+  vpc_id = aws_vpc.main.id
+}
+
+resource "aws_s3_bucket" "logs" {
+  provider = aws.west
+  bucket   = "acme-logs"
+}
+
+resource "aws_iam_role" "deployer" {
+  name = "deployer"
+}
+
+data "aws_caller_identity" "current" {}
@@ -0,0 +1,9 @@
+resource "datadog_monitor" "cpu" {
+  name = "High CPU"
+  type = "metric alert"
+}
+
+resource "google_storage_bucket" "archive" {
+  provider = google-beta
+  name     = "acme-archive"
+}
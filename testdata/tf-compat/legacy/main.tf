@@ -0,0 +1,8 @@
+terraform {
+  required_version = "~> 0.14.0"
+}
+
+module "vpc" {
+  source  = "terraform-aws-modules/vpc/aws"
+  version = "2.78.0"
+}
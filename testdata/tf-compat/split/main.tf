@@ -0,0 +1,8 @@
+terraform {
+  required_version = ">= 1.0.0"
+}
+
+module "eks" {
+  source  = "terraform-aws-modules/eks/aws"
+  version = "19.0.0"
+}
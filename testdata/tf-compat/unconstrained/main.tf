@@ -0,0 +1,3 @@
+module "labels" {
+  source = "git::https://github.com/acme/labels.git?ref=v1.0.0"
+}
@@ -0,0 +1,46 @@
+terraform {
+  required_providers {
+    aws = {
+      source  = "hashicorp/aws"
+      version = "~> 5.0"
+    }
+  }
+
+  encryption {
+    key_provider "pbkdf2" "state" {
+      passphrase = var.passphrase
+    }
+    method "aes_gcm" "state" {
+      keys = key_provider.pbkdf2.state
+    }
+    state {
+      method = method.aes_gcm.state
+    }
+  }
+}
+
+variable "passphrase" {
+  type      = string
+  sensitive = true
+}
+
+variable "regions" {
+  type    = set(string)
+  default = ["us-east-1", "eu-west-1"]
+}
+
+provider "aws" {
+  alias    = "by_region"
+  for_each = var.regions
+  region   = each.value
+}
+
+module "vpc" {
+  source   = "terraform-aws-modules/vpc/aws"
+  version  = "5.1.0"
+  for_each = var.regions
+
+  providers = {
+    aws = aws.by_region[each.key]
+  }
+}
@@ -0,0 +1,5 @@
+# Replaced by main.tofu, so OpenTofu never loads this module.
+module "terraform_only" {
+  source  = "terraform-aws-modules/s3-bucket/aws"
+  version = "4.0.0"
+}
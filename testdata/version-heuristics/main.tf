@@ -0,0 +1,20 @@
+module "vpc" {
+  source  = "terraform-aws-modules/vpc/aws"
+  version = "~> 5.0"
+}
+
+module "labels" {
+  source = "git::https://github.com/acme/terraform-labels.git?ref=v1.2.0"
+}
+
+module "dns" {
+  source = "github.com/acme/terraform-dns?ref=main"
+}
+
+module "service" {
+  source = "./modules/service"
+}
+
+module "cache" {
+  source = "github.com/acme/terraform-cache"
+}
@@ -0,0 +1,17 @@
+variable "network_ref" {
+  type    = string
+  default = "v1.0.0"
+}
+
+variable "vpc_version" {
+  type = string
+}
+
+module "network" {
+  source = "git::https://github.com/acme/network.git?ref=${var.network_ref}"
+}
+
+module "vpc" {
+  source  = "terraform-aws-modules/vpc/aws"
+  version = var.vpc_version
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+
+	goversion "github.com/hashicorp/go-version"
+)
+
+// parseTargetVersion parses the Terraform version given with -tf-compat, such as 1.9.0.
+func parseTargetVersion(value string) (*goversion.Version, error) {
+	target, err := goversion.NewVersion(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Terraform version %q: %v", value, err)
+	}
+	return target, nil
+}
+
+// permitsVersion reports whether a config's required_version constraints, as recorded
+// in RequiredVersion, accept the target version. A config without constraints accepts
+// every version.
+func permitsVersion(requiredVersion string, target *goversion.Version) (bool, error) {
+	if requiredVersion == "" {
+		return true, nil
+	}
+	constraints, err := goversion.NewConstraint(requiredVersion)
+	if err != nil {
+		return false, err
+	}
+	return constraints.Check(target), nil
+}
+
+// onlyIncompatibleConfigs reduces an SBOM to the configs whose required_version does
+// not permit the target Terraform version, for scoping an upgrade. Their modules,
+// providers, and outputs are kept, with the per-config details recording the
+// constraints. A config whose constraints cannot be parsed is kept as well, since
+// Terraform rejects it whatever the version, and a warning is returned for it.
+func onlyIncompatibleConfigs(sbom *SBOM, target *goversion.Version) []string {
+	var warnings []string
+	incompatible := make(map[string]bool)
+	var configs []ConfigInfo
+	for _, config := range sbom.Configs {
+		permitted, err := permitsVersion(config.RequiredVersion, target)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("%s: invalid required_version %q: %v", config.Path, config.RequiredVersion, err))
+		}
+		if permitted {
+			continue
+		}
+		incompatible[config.Path] = true
+		configs = append(configs, config)
+	}
+
+	var modules []ModuleInfo
+	for _, mod := range sbom.Modules {
+		if incompatible[mod.Config] {
+			modules = append(modules, mod)
+		}
+	}
+	var providers []ProviderInfo
+	for _, provider := range sbom.Providers {
+		if incompatible[provider.Config] {
+			providers = append(providers, provider)
+		}
+	}
+	var outputs []OutputInfo
+	for _, output := range sbom.Outputs {
+		if incompatible[output.Config] {
+			outputs = append(outputs, output)
+		}
+	}
+
+	sbom.Modules = modules
+	sbom.Providers = providers
+	sbom.Outputs = outputs
+	sbom.Configs = configs
+	return warnings
+}
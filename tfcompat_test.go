@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+// TestPermitsVersion tests checking required_version constraints against target versions.
+func TestPermitsVersion(t *testing.T) {
+	tests := []struct {
+		requiredVersion string
+		target          string
+		permitted       bool
+	}{
+		{"", "1.9.0", true},
+		{">= 1.5.0", "1.9.0", true},
+		{">= 1.5.0, < 2.0.0", "2.0.0", false},
+		{"~> 0.14.0", "0.14.11", true},
+		{"~> 0.14.0", "1.0.0", false},
+		{"~> 1.5", "1.9.2", true},
+		{"= 1.6.6", "1.7.0", false},
+		{">= 1.0.0, < 1.8.0", "1.7.5", true},
+		{">= 1.0.0, < 1.8.0", "1.8.0", false},
+	}
+
+	for _, tt := range tests {
+		target, err := parseTargetVersion(tt.target)
+		if err != nil {
+			t.Fatalf("Failed to parse %s: %v", tt.target, err)
+		}
+		permitted, err := permitsVersion(tt.requiredVersion, target)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", tt.requiredVersion, err)
+			continue
+		}
+		if permitted != tt.permitted {
+			t.Errorf("%q with %s: expected permitted %v, got %v", tt.requiredVersion, tt.target, tt.permitted, permitted)
+		}
+	}
+
+	if _, err := parseTargetVersion("latest"); err == nil {
+		t.Errorf("Expected an error for an invalid target version")
+	}
+}
+
+// TestOnlyIncompatibleConfigs tests that a recursive scan is reduced to the configs that
+// do not permit each target version, with their modules and constraints.
+func TestOnlyIncompatibleConfigs(t *testing.T) {
+	tests := []struct {
+		target      string
+		configs     []string
+		constraints []string
+		modules     []string
+	}{
+		{"0.14.5", []string{"testdata/tf-compat/current", "testdata/tf-compat/split"}, []string{">= 1.5.0, < 2.0.0", ">= 1.0.0, < 1.8.0"}, []string{"vpc", "eks"}},
+		{"1.7.0", []string{"testdata/tf-compat/legacy"}, []string{"~> 0.14.0"}, []string{"vpc"}},
+		{"1.9.0", []string{"testdata/tf-compat/legacy", "testdata/tf-compat/split"}, []string{"~> 0.14.0", ">= 1.0.0, < 1.8.0"}, []string{"vpc", "eks"}},
+		{"2.0.0", []string{"testdata/tf-compat/current", "testdata/tf-compat/legacy", "testdata/tf-compat/split"}, []string{">= 1.5.0, < 2.0.0", "~> 0.14.0", ">= 1.0.0, < 1.8.0"}, []string{"vpc", "vpc", "eks"}},
+	}
+
+	for _, tt := range tests {
+		sbom, err := generateRecursiveSBOM(context.Background(), "testdata/tf-compat", scanOptions{RequiredVersions: true}, nil)
+		if err != nil {
+			t.Fatalf("Failed to generate SBOM: %v", err)
+		}
+		target, _ := parseTargetVersion(tt.target)
+
+		if warnings := onlyIncompatibleConfigs(sbom, target); len(warnings) > 0 {
+			t.Errorf("%s: expected no warnings, got %v", tt.target, warnings)
+		}
+
+		var configs, constraints, modules []string
+		for _, config := range sbom.Configs {
+			configs = append(configs, config.Path)
+			constraints = append(constraints, config.RequiredVersion)
+		}
+		for _, mod := range sbom.Modules {
+			modules = append(modules, mod.Name)
+		}
+		if !reflect.DeepEqual(configs, tt.configs) || !reflect.DeepEqual(constraints, tt.constraints) {
+			t.Errorf("%s: expected configs %v with %v, got %v with %v", tt.target, tt.configs, tt.constraints, configs, constraints)
+		}
+		if !reflect.DeepEqual(modules, tt.modules) {
+			t.Errorf("%s: expected modules %v, got %v", tt.target, tt.modules, modules)
+		}
+	}
+}
+
+// TestOnlyIncompatibleConfigsInvalid tests that a config with an invalid constraint is
+// kept and warned about.
+func TestOnlyIncompatibleConfigsInvalid(t *testing.T) {
+	sbom := &SBOM{
+		Modules: []ModuleInfo{{Name: "vpc", Config: "broken"}, {Name: "dns", Config: "fine"}},
+		Configs: []ConfigInfo{{Path: "broken", RequiredVersion: "not a version"}, {Path: "fine", RequiredVersion: ">= 1.0.0"}},
+	}
+	target, _ := parseTargetVersion("1.9.0")
+
+	warnings := onlyIncompatibleConfigs(sbom, target)
+	if len(warnings) != 1 {
+		t.Errorf("Expected a warning for the invalid constraint, got %v", warnings)
+	}
+	if len(sbom.Configs) != 1 || sbom.Configs[0].Path != "broken" || len(sbom.Modules) != 1 || sbom.Modules[0].Name != "vpc" {
+		t.Errorf("Expected only the broken config to be kept, got %+v and %+v", sbom.Configs, sbom.Modules)
+	}
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"os"
+	"strings"
+
+	"github.com/hashicorp/terraform-config-inspect/tfconfig"
+)
+
+// OpenTofu also loads configuration from .tofu and .tofu.json files. A .tofu file
+// replaces the .tf file of the same name, and a .tofu.json file the .tf.json file,
+// so that a module can carry OpenTofu-specific configuration next to the Terraform
+// configuration it overrides.
+const (
+	tofuExt     = ".tofu"
+	tofuJSONExt = ".tofu.json"
+)
+
+// isConfigFile reports whether a file name has a Terraform or OpenTofu
+// configuration extension: .tf, .tf.json, .tofu, or .tofu.json.
+func isConfigFile(name string) bool {
+	return strings.HasSuffix(name, ".tf") || strings.HasSuffix(name, ".tf.json") ||
+		strings.HasSuffix(name, tofuExt) || strings.HasSuffix(name, tofuJSONExt)
+}
+
+// isJSONConfigFile reports whether a configuration file uses the JSON syntax.
+func isJSONConfigFile(name string) bool {
+	return strings.HasSuffix(name, ".tf.json") || strings.HasSuffix(name, tofuJSONExt)
+}
+
+// isReplacedByTofu reports whether a Terraform configuration file is ignored by
+// OpenTofu because the directory also holds its .tofu or .tofu.json counterpart.
+// names holds the names of the files in the directory.
+func isReplacedByTofu(name string, names map[string]bool) bool {
+	if base, ok := strings.CutSuffix(name, ".tf.json"); ok {
+		return names[base+tofuJSONExt]
+	}
+	if base, ok := strings.CutSuffix(name, ".tf"); ok {
+		return names[base+tofuExt]
+	}
+	return false
+}
+
+// tofuFS presents a directory to tfconfig the way OpenTofu reads it. tfconfig only
+// loads .tf and .tf.json files, so each .tofu or .tofu.json file is listed under an
+// alias with the matching Terraform extension appended, such as main.tofu.tf, and
+// the Terraform files they replace are hidden.
+type tofuFS struct {
+	tfconfig.FS
+}
+
+// tofuFileInfo is a file listed under its alias.
+type tofuFileInfo struct {
+	os.FileInfo
+	name string
+}
+
+func (info tofuFileInfo) Name() string {
+	return info.name
+}
+
+func (fs tofuFS) ReadDir(dirname string) ([]os.FileInfo, error) {
+	infos, err := fs.FS.ReadDir(dirname)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]bool, len(infos))
+	for _, info := range infos {
+		if !info.IsDir() {
+			names[info.Name()] = true
+		}
+	}
+
+	kept := infos[:0]
+	for _, info := range infos {
+		name := info.Name()
+		switch {
+		case info.IsDir():
+		case strings.HasSuffix(name, tofuJSONExt):
+			info = tofuFileInfo{FileInfo: info, name: name + ".tf.json"}
+		case strings.HasSuffix(name, tofuExt):
+			info = tofuFileInfo{FileInfo: info, name: name + ".tf"}
+		case isReplacedByTofu(name, names):
+			continue
+		}
+		kept = append(kept, info)
+	}
+	return kept, nil
+}
+
+func (fs tofuFS) Open(name string) (tfconfig.File, error) {
+	return fs.FS.Open(tofuFilename(name))
+}
+
+func (fs tofuFS) ReadFile(name string) ([]byte, error) {
+	return fs.FS.ReadFile(tofuFilename(name))
+}
+
+// tofuFilename returns the real path of a file listed by tofuFS, which is the path
+// itself for every file but the aliased OpenTofu ones.
+func tofuFilename(path string) string {
+	if base, ok := strings.CutSuffix(path, ".tf.json"); ok && strings.HasSuffix(base, tofuJSONExt) {
+		return base
+	}
+	if base, ok := strings.CutSuffix(path, ".tf"); ok && strings.HasSuffix(base, tofuExt) {
+		return base
+	}
+	return path
+}
+
+// restoreTofuFilenames replaces the aliases tofuFS gave OpenTofu files with their
+// real paths in the positions of a module loaded through it.
+func restoreTofuFilenames(module *tfconfig.Module) {
+	for _, call := range module.ModuleCalls {
+		call.Pos.Filename = tofuFilename(call.Pos.Filename)
+	}
+	for _, variable := range module.Variables {
+		variable.Pos.Filename = tofuFilename(variable.Pos.Filename)
+	}
+	for _, output := range module.Outputs {
+		output.Pos.Filename = tofuFilename(output.Pos.Filename)
+	}
+	for _, resource := range module.ManagedResources {
+		resource.Pos.Filename = tofuFilename(resource.Pos.Filename)
+	}
+	for _, resource := range module.DataResources {
+		resource.Pos.Filename = tofuFilename(resource.Pos.Filename)
+	}
+	for i := range module.Diagnostics {
+		if pos := module.Diagnostics[i].Pos; pos != nil {
+			pos.Filename = tofuFilename(pos.Filename)
+		}
+	}
+}
+
+// isConfigDir reports whether a directory holds Terraform or OpenTofu configuration
+// files, like tfconfig.IsModuleDir.
+func isConfigDir(dir string) bool {
+	return tfconfig.IsModuleDirOnFilesystem(tofuFS{tfconfig.NewOsFs()}, dir)
+}
+
+// configFileNames returns the names of the files in a directory, for isReplacedByTofu.
+func configFileNames(entries []os.DirEntry) map[string]bool {
+	names := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			names[entry.Name()] = true
+		}
+	}
+	return names
+}
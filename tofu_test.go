@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestGenerateSBOMOpenTofu tests that a config written in .tofu and .tofu.json files,
+// with OpenTofu-only blocks, yields the same modules as its Terraform twin, and that
+// a .tf file replaced by a .tofu file of the same name is not loaded.
+func TestGenerateSBOMOpenTofu(t *testing.T) {
+	tofu, err := generateSBOM(context.Background(), "testdata/tofu", scanOptions{})
+	if err != nil {
+		t.Fatalf("Failed to generate SBOM: %v", err)
+	}
+	terraform, err := generateSBOM(context.Background(), "testdata/tofu-terraform", scanOptions{})
+	if err != nil {
+		t.Fatalf("Failed to generate SBOM: %v", err)
+	}
+	if len(tofu.Warnings) > 0 {
+		t.Errorf("Expected OpenTofu-only blocks to load without warnings, got %v", tofu.Warnings)
+	}
+
+	files := make(map[string]string)
+	for i := range tofu.Modules {
+		files[tofu.Modules[i].Name] = filepath.Base(tofu.Modules[i].File)
+		tofu.Modules[i].Config, tofu.Modules[i].File = "", ""
+	}
+	for i := range terraform.Modules {
+		terraform.Modules[i].Config, terraform.Modules[i].File = "", ""
+	}
+	if !reflect.DeepEqual(tofu.Modules, terraform.Modules) {
+		t.Errorf("Expected the same modules as the Terraform config:\n%+v\ngot:\n%+v", terraform.Modules, tofu.Modules)
+	}
+
+	expectedFiles := map[string]string{"dns": "dns.tofu.json", "network": "network.tf", "vpc": "main.tofu"}
+	if !reflect.DeepEqual(files, expectedFiles) {
+		t.Errorf("Expected module files %v, got %v", expectedFiles, files)
+	}
+}
+
+// TestConfigFilesOpenTofu tests listing OpenTofu files alongside Terraform ones,
+// without the Terraform files they replace.
+func TestConfigFilesOpenTofu(t *testing.T) {
+	var names []string
+	for _, path := range configFiles("testdata/tofu") {
+		names = append(names, filepath.Base(path))
+	}
+	expected := []string{"dns.tofu.json", "main.tofu", "network.tf"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("Expected config files %v, got %v", expected, names)
+	}
+	if !isConfigDir("testdata/tofu") {
+		t.Error("Expected testdata/tofu to be a config directory")
+	}
+}
+
+// TestTofuFilename tests mapping the aliases tofuFS lists back to real paths.
+func TestTofuFilename(t *testing.T) {
+	tests := map[string]string{
+		"dir/main.tofu.tf":          "dir/main.tofu",
+		"dir/dns.tofu.json.tf.json": "dir/dns.tofu.json",
+		"dir/main.tf":               "dir/main.tf",
+		"dir/main.tf.json":          "dir/main.tf.json",
+	}
+	for alias, expected := range tests {
+		if got := tofuFilename(alias); got != expected {
+			t.Errorf("tofuFilename(%q) = %q, expected %q", alias, got, expected)
+		}
+	}
+}
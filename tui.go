@@ -0,0 +1,167 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// tuiColumns are the module columns shown by the interactive browser.
+var tuiColumns = []table.Column{
+	{Title: "Config Path", Width: 28},
+	{Title: "Module Name", Width: 22},
+	{Title: "Source", Width: 52},
+	{Title: "Source Type", Width: 11},
+	{Title: "Version", Width: 14},
+}
+
+// tuiAvailable reports whether the interactive browser can be started: both stdin
+// and stdout must be terminals, and the CI environment variable set by most CI
+// systems must be unset, so that headless runs never block waiting for input.
+func tuiAvailable() bool {
+	return os.Getenv("CI") == "" && isTerminal(os.Stdin) && isTerminal(os.Stdout)
+}
+
+// runTUI opens a full-screen browser over the modules of an SBOM, which can be
+// scrolled and filtered by name or source until the user quits.
+func runTUI(sbom *SBOM) error {
+	_, err := tea.NewProgram(newTUIModel(sbom), tea.WithAltScreen()).Run()
+	if err != nil {
+		return fmt.Errorf("failed to run interactive browser: %v", err)
+	}
+	return nil
+}
+
+// tuiModel is the state of the interactive browser.
+type tuiModel struct {
+	modules   []ModuleInfo
+	shown     int // Number of modules matching the search
+	table     table.Model
+	search    textinput.Model
+	searching bool // Set while the search box has focus
+}
+
+// newTUIModel creates a browser showing every module of the SBOM.
+func newTUIModel(sbom *SBOM) tuiModel {
+	search := textinput.New()
+	search.Prompt = "/"
+	search.Placeholder = "search name or source"
+
+	m := tuiModel{
+		modules: sbom.Modules,
+		table:   table.New(table.WithColumns(tuiColumns), table.WithFocused(true), table.WithHeight(20)),
+		search:  search,
+	}
+	m.applyFilter()
+	return m
+}
+
+// Init implements tea.Model.
+func (m tuiModel) Init() tea.Cmd {
+	return nil
+}
+
+// Update implements tea.Model. Typing / starts a search that filters the table as
+// it is typed, enter keeps the filter, esc clears it, and q quits.
+func (m tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.table.SetWidth(msg.Width)
+		m.table.SetHeight(max(msg.Height-3, 1))
+		return m, nil
+
+	case tea.KeyMsg:
+		if m.searching {
+			switch msg.Type {
+			case tea.KeyEnter:
+				m.endSearch()
+				return m, nil
+			case tea.KeyEsc:
+				m.search.SetValue("")
+				m.applyFilter()
+				m.endSearch()
+				return m, nil
+			case tea.KeyCtrlC:
+				return m, tea.Quit
+			}
+
+			var cmd tea.Cmd
+			m.search, cmd = m.search.Update(msg)
+			m.applyFilter()
+			return m, cmd
+		}
+
+		switch msg.String() {
+		case "q", "ctrl+c":
+			return m, tea.Quit
+		case "/":
+			m.searching = true
+			m.table.Blur()
+			return m, m.search.Focus()
+		case "esc":
+			m.search.SetValue("")
+			m.applyFilter()
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	m.table, cmd = m.table.Update(msg)
+	return m, cmd
+}
+
+// View implements tea.Model.
+func (m tuiModel) View() string {
+	var b strings.Builder
+	if m.searching || m.search.Value() != "" {
+		b.WriteString(m.search.View())
+	} else {
+		b.WriteString("Press / to search")
+	}
+	b.WriteString("\n")
+	b.WriteString(m.table.View())
+	b.WriteString("\n")
+	fmt.Fprintf(&b, "%d of %d modules • ↑/↓ scroll • / search • esc clear • q quit", m.shown, len(m.modules))
+	return b.String()
+}
+
+// endSearch returns focus from the search box to the table.
+func (m *tuiModel) endSearch() {
+	m.searching = false
+	m.search.Blur()
+	m.table.Focus()
+}
+
+// applyFilter shows the modules matching the current search.
+func (m *tuiModel) applyFilter() {
+	matches := filterModules(m.modules, m.search.Value())
+
+	rows := make([]table.Row, len(matches))
+	for i, mod := range matches {
+		rows[i] = table.Row{mod.Config, mod.Name, mod.Source, mod.SourceType, mod.Version}
+	}
+	m.table.SetRows(rows)
+	m.table.GotoTop()
+	m.shown = len(matches)
+}
+
+// filterModules returns the modules whose name or source contains the query,
+// ignoring case. An empty query matches every module.
+func filterModules(modules []ModuleInfo, query string) []ModuleInfo {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return modules
+	}
+
+	var matches []ModuleInfo
+	for _, mod := range modules {
+		if strings.Contains(strings.ToLower(mod.Name), query) || strings.Contains(strings.ToLower(mod.Source), query) {
+			matches = append(matches, mod)
+		}
+	}
+	return matches
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// tuiTestSBOM returns an SBOM with a few modules to browse.
+func tuiTestSBOM() *SBOM {
+	return &SBOM{Modules: []ModuleInfo{
+		{Name: "vpc", Source: "terraform-aws-modules/vpc/aws", SourceType: sourceTypeRegistry, Version: "5.1.0", Config: "network"},
+		{Name: "labels", Source: "git::https://github.com/acme/labels.git?ref=v1.0.0", SourceType: sourceTypeGit, Version: "v1.0.0", Config: "app"},
+		{Name: "service", Source: "./modules/service", SourceType: sourceTypeLocal, Version: "local", Config: "app"},
+	}}
+}
+
+// TestFilterModules tests case-insensitive search by module name and source.
+func TestFilterModules(t *testing.T) {
+	modules := tuiTestSBOM().Modules
+
+	tests := []struct {
+		query    string
+		expected []string
+	}{
+		{"", []string{"vpc", "labels", "service"}},
+		{"VPC", []string{"vpc"}},
+		{"acme", []string{"labels"}},
+		{"modules", []string{"vpc", "service"}},
+		{"missing", nil},
+	}
+
+	for _, tt := range tests {
+		var names []string
+		for _, mod := range filterModules(modules, tt.query) {
+			names = append(names, mod.Name)
+		}
+		if strings.Join(names, ",") != strings.Join(tt.expected, ",") {
+			t.Errorf("filterModules(%q) = %v, expected %v", tt.query, names, tt.expected)
+		}
+	}
+}
+
+// TestTUISearch tests searching, clearing, and quitting through key presses.
+func TestTUISearch(t *testing.T) {
+	var model tea.Model = newTUIModel(tuiTestSBOM())
+	press := func(msg tea.KeyMsg) tea.Cmd {
+		var cmd tea.Cmd
+		model, cmd = model.Update(msg)
+		return cmd
+	}
+
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")})
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("acme")})
+	press(tea.KeyMsg{Type: tea.KeyEnter})
+
+	if view := model.View(); !strings.Contains(view, "1 of 3 modules") || !strings.Contains(view, "labels") {
+		t.Errorf("Expected only the labels module after searching, got:\n%s", view)
+	}
+
+	press(tea.KeyMsg{Type: tea.KeyEsc})
+	if view := model.View(); !strings.Contains(view, "3 of 3 modules") {
+		t.Errorf("Expected every module after clearing the search, got:\n%s", view)
+	}
+
+	if cmd := press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")}); cmd == nil {
+		t.Fatalf("Expected q to quit")
+	} else if _, ok := cmd().(tea.QuitMsg); !ok {
+		t.Errorf("Expected q to quit, got %T", cmd())
+	}
+}
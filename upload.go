@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// Object stores an SBOM can be written to instead of a local file. Each is compiled
+// in only with its build tag, such as go build -tags gcs,azure.
+const (
+	objectStoreGCS   = "gcs"
+	objectStoreAzure = "azure"
+)
+
+// azureBlobHostSuffix is the host suffix of Azure blob storage accounts.
+const azureBlobHostSuffix = ".blob.core.windows.net"
+
+// remoteLocation is an object in cloud storage that an SBOM is written to.
+type remoteLocation struct {
+	Store    string // objectStoreGCS or objectStoreAzure
+	Endpoint string // Account URL of an Azure blob, e.g. https://acme.blob.core.windows.net
+	Bucket   string // GCS bucket or Azure container
+	Object   string // Object or blob name within the bucket
+	URI      string // Location as given on the command line
+}
+
+// parseRemoteLocation recognizes output paths in cloud storage: gs://bucket/object for
+// Google Cloud Storage and https://account.blob.core.windows.net/container/blob for
+// Azure blob storage. Any other path is a local file and reports false. Query strings
+// are rejected, so that credentials such as SAS tokens are passed through the
+// environment rather than on the command line.
+func parseRemoteLocation(uri string) (remoteLocation, bool, error) {
+	if !strings.HasPrefix(uri, "gs://") && !strings.HasPrefix(uri, "https://") {
+		return remoteLocation{}, false, nil
+	}
+	u, err := url.Parse(uri)
+	if err != nil {
+		return remoteLocation{}, true, fmt.Errorf("invalid output location %s: %v", uri, err)
+	}
+
+	var loc remoteLocation
+	switch {
+	case u.Scheme == "gs":
+		loc.Store = objectStoreGCS
+	case strings.HasSuffix(strings.ToLower(u.Hostname()), azureBlobHostSuffix):
+		loc.Store = objectStoreAzure
+		loc.Endpoint = "https://" + u.Host
+	default:
+		return remoteLocation{}, false, nil
+	}
+
+	if u.RawQuery != "" || u.Fragment != "" {
+		return remoteLocation{}, true, fmt.Errorf("invalid output location %s: query strings are not supported; pass credentials through the environment", uri)
+	}
+
+	path := strings.TrimPrefix(u.Path, "/")
+	if loc.Store == objectStoreGCS {
+		loc.Bucket, loc.Object = u.Host, path
+	} else {
+		loc.Bucket, loc.Object, _ = strings.Cut(path, "/")
+	}
+	if loc.Bucket == "" || loc.Object == "" || strings.HasSuffix(loc.Object, "/") {
+		return remoteLocation{}, true, fmt.Errorf("invalid output location %s: expected a bucket or container followed by an object name", uri)
+	}
+
+	loc.URI = uri
+	return loc, true, nil
+}
+
+// objectStore opens writers for objects in one kind of cloud storage. The object is
+// only complete once the writer is closed without an error.
+type objectStore interface {
+	NewWriter(ctx context.Context, loc remoteLocation) (io.WriteCloser, error)
+}
+
+// objectStores creates a client for each object store compiled into the binary,
+// keyed by store name. Stores register themselves from files behind their build tag.
+var objectStores = map[string]func(client *apiClient) objectStore{}
+
+// newObjectStore returns a client for the named object store, or an error naming
+// the build tag to compile it in with.
+func newObjectStore(name string, client *apiClient) (objectStore, error) {
+	newStore, ok := objectStores[name]
+	if !ok {
+		return nil, fmt.Errorf("%s output is not supported by this build; rebuild with -tags %s", name, name)
+	}
+	return newStore(client), nil
+}
+
+// uploadFile copies a local file to an object in cloud storage.
+func uploadFile(ctx context.Context, store objectStore, localPath string, loc remoteLocation) error {
+	file, err := os.Open(localPath)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %v", localPath, err)
+	}
+	defer file.Close()
+
+	w, err := store.NewWriter(ctx, loc)
+	if err != nil {
+		return fmt.Errorf("failed to upload to %s: %v", loc.URI, err)
+	}
+	if _, err := io.Copy(w, file); err != nil {
+		w.Close()
+		return fmt.Errorf("failed to upload to %s: %v", loc.URI, err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to upload to %s: %v", loc.URI, err)
+	}
+
+	fmt.Printf("SBOM uploaded to %s\n", loc.URI)
+	return nil
+}
+
+// requestWriter collects an object in memory and sends it in a single upload request
+// when closed, since the storage APIs need the length of an object up front.
+type requestWriter struct {
+	bytes.Buffer
+	client     *apiClient
+	newRequest func(body []byte) (*http.Request, error)
+}
+
+// Close sends the upload request and reports a failed upload as an error.
+func (w *requestWriter) Close() error {
+	req, err := w.newRequest(w.Bytes())
+	if err != nil {
+		return err
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer closeResponse(resp)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("unexpected status %s: %s", resp.Status, strings.TrimSpace(string(message)))
+	}
+	return nil
+}
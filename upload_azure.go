@@ -0,0 +1,71 @@
+//go:build azure
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// azureSASTokenEnv names the environment variable holding the shared access signature
+// that authorizes blob uploads, such as sv=2022-11-02&ss=b&sig=...
+const azureSASTokenEnv = "AZURE_STORAGE_SAS_TOKEN"
+
+// azureStorageVersion is the Blob service REST API version requests are made against.
+const azureStorageVersion = "2021-08-06"
+
+func init() {
+	objectStores[objectStoreAzure] = func(client *apiClient) objectStore {
+		return &azureStore{client: client, sasToken: os.Getenv(azureSASTokenEnv)}
+	}
+}
+
+// azureStore uploads blobs to Azure blob storage.
+type azureStore struct {
+	client   *apiClient
+	sasToken string
+	endpoint string // Replaces the account URL of the location in tests
+}
+
+// NewWriter implements objectStore with a Put Blob request creating a block blob.
+func (s *azureStore) NewWriter(ctx context.Context, loc remoteLocation) (io.WriteCloser, error) {
+	if s.sasToken == "" {
+		return nil, fmt.Errorf("%s is not set", azureSASTokenEnv)
+	}
+
+	base := loc.Endpoint
+	if s.endpoint != "" {
+		base = s.endpoint
+	}
+	endpoint := fmt.Sprintf("%s/%s/%s?%s", base, url.PathEscape(loc.Bucket), escapeBlobName(loc.Object), strings.TrimPrefix(s.sasToken, "?"))
+
+	return &requestWriter{
+		client: s.client,
+		newRequest: func(body []byte) (*http.Request, error) {
+			req, err := http.NewRequestWithContext(ctx, http.MethodPut, endpoint, bytes.NewReader(body))
+			if err != nil {
+				return nil, err
+			}
+			req.Header.Set("x-ms-blob-type", "BlockBlob")
+			req.Header.Set("x-ms-version", azureStorageVersion)
+			req.Header.Set("Content-Type", "application/octet-stream")
+			return req, nil
+		},
+	}, nil
+}
+
+// escapeBlobName escapes each segment of a blob name, keeping the slashes that
+// separate virtual directories.
+func escapeBlobName(name string) string {
+	segments := strings.Split(name, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,53 @@
+//go:build azure
+
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestAzureStore tests the Put Blob request sent for an Azure blob.
+func TestAzureStore(t *testing.T) {
+	var req *http.Request
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req = r
+		content, _ := io.ReadAll(r.Body)
+		body = string(content)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	store := &azureStore{client: newAPIClient(nil, 0), sasToken: "?sv=2022-11-02&sig=abc", endpoint: server.URL}
+	loc := remoteLocation{Store: objectStoreAzure, Endpoint: "https://acme.blob.core.windows.net", Bucket: "sboms", Object: "platform/sbom 1.json"}
+	w, err := store.NewWriter(context.Background(), loc)
+	if err != nil {
+		t.Fatalf("Failed to create writer: %v", err)
+	}
+	io.WriteString(w, "{}")
+	if err := w.Close(); err != nil {
+		t.Fatalf("Failed to upload: %v", err)
+	}
+
+	if req.Method != http.MethodPut || req.URL.EscapedPath() != "/sboms/platform/sbom%201.json" {
+		t.Errorf("Expected PUT /sboms/platform/sbom%%201.json, got %s %s", req.Method, req.URL.EscapedPath())
+	}
+	if got := req.URL.Query().Get("sig"); got != "abc" {
+		t.Errorf("Expected the SAS token in the query, got %q", req.URL.RawQuery)
+	}
+	if got := req.Header.Get("x-ms-blob-type"); got != "BlockBlob" {
+		t.Errorf("Expected a block blob, got %q", got)
+	}
+	if body != "{}" {
+		t.Errorf("Expected body {}, got %q", body)
+	}
+
+	store.sasToken = ""
+	if _, err := store.NewWriter(context.Background(), loc); err == nil {
+		t.Errorf("Expected an error without a SAS token")
+	}
+}
@@ -0,0 +1,55 @@
+//go:build gcs
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+)
+
+// gcsUploadURL is the base URL of the Cloud Storage JSON API upload endpoint.
+const gcsUploadURL = "https://storage.googleapis.com/upload/storage/v1"
+
+// gcsTokenEnv names the environment variable holding the OAuth access token used for
+// Cloud Storage uploads, as printed by gcloud auth print-access-token. It is the same
+// variable the Terraform Google provider reads.
+const gcsTokenEnv = "GOOGLE_OAUTH_ACCESS_TOKEN"
+
+func init() {
+	objectStores[objectStoreGCS] = func(client *apiClient) objectStore {
+		return &gcsStore{client: client, baseURL: gcsUploadURL, token: os.Getenv(gcsTokenEnv)}
+	}
+}
+
+// gcsStore uploads objects to Google Cloud Storage.
+type gcsStore struct {
+	client  *apiClient
+	baseURL string
+	token   string
+}
+
+// NewWriter implements objectStore with a simple media upload of the whole object.
+func (s *gcsStore) NewWriter(ctx context.Context, loc remoteLocation) (io.WriteCloser, error) {
+	if s.token == "" {
+		return nil, fmt.Errorf("%s is not set", gcsTokenEnv)
+	}
+
+	endpoint := fmt.Sprintf("%s/b/%s/o?uploadType=media&name=%s", s.baseURL, url.PathEscape(loc.Bucket), url.QueryEscape(loc.Object))
+	return &requestWriter{
+		client: s.client,
+		newRequest: func(body []byte) (*http.Request, error) {
+			req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+			if err != nil {
+				return nil, err
+			}
+			req.Header.Set("Authorization", "Bearer "+s.token)
+			req.Header.Set("Content-Type", "application/octet-stream")
+			return req, nil
+		},
+	}, nil
+}
@@ -0,0 +1,52 @@
+//go:build gcs
+
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestGCSStore tests the media upload request sent for a Cloud Storage object.
+func TestGCSStore(t *testing.T) {
+	var req *http.Request
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req = r
+		content, _ := io.ReadAll(r.Body)
+		body = string(content)
+	}))
+	defer server.Close()
+
+	store := &gcsStore{client: newAPIClient(nil, 0), baseURL: server.URL, token: "ya29.token"}
+	loc := remoteLocation{Store: objectStoreGCS, Bucket: "acme-sboms", Object: "platform/sbom.json"}
+	w, err := store.NewWriter(context.Background(), loc)
+	if err != nil {
+		t.Fatalf("Failed to create writer: %v", err)
+	}
+	io.WriteString(w, "{}")
+	if err := w.Close(); err != nil {
+		t.Fatalf("Failed to upload: %v", err)
+	}
+
+	if req.Method != http.MethodPost || req.URL.Path != "/b/acme-sboms/o" {
+		t.Errorf("Expected POST /b/acme-sboms/o, got %s %s", req.Method, req.URL.Path)
+	}
+	if got := req.URL.Query().Get("name"); got != "platform/sbom.json" {
+		t.Errorf("Expected object name platform/sbom.json, got %q", got)
+	}
+	if got := req.Header.Get("Authorization"); got != "Bearer ya29.token" {
+		t.Errorf("Expected a bearer token, got %q", got)
+	}
+	if body != "{}" {
+		t.Errorf("Expected body {}, got %q", body)
+	}
+
+	store.token = ""
+	if _, err := store.NewWriter(context.Background(), loc); err == nil {
+		t.Errorf("Expected an error without an access token")
+	}
+}
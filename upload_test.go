@@ -0,0 +1,159 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestParseRemoteLocation tests recognizing cloud storage output locations.
+func TestParseRemoteLocation(t *testing.T) {
+	tests := []struct {
+		uri      string
+		remote   bool
+		expected remoteLocation
+		err      bool
+	}{
+		{uri: "sbom.json"},
+		{uri: "/tmp/sbom.json"},
+		{uri: "https://example.com/sbom.json"},
+		{
+			uri:      "gs://acme-sboms/platform/sbom.json",
+			remote:   true,
+			expected: remoteLocation{Store: objectStoreGCS, Bucket: "acme-sboms", Object: "platform/sbom.json", URI: "gs://acme-sboms/platform/sbom.json"},
+		},
+		{
+			uri:      "https://acme.blob.core.windows.net/sboms/platform/sbom.json",
+			remote:   true,
+			expected: remoteLocation{Store: objectStoreAzure, Endpoint: "https://acme.blob.core.windows.net", Bucket: "sboms", Object: "platform/sbom.json", URI: "https://acme.blob.core.windows.net/sboms/platform/sbom.json"},
+		},
+		{uri: "gs://acme-sboms", remote: true, err: true},
+		{uri: "gs://acme-sboms/platform/", remote: true, err: true},
+		{uri: "https://acme.blob.core.windows.net/sboms", remote: true, err: true},
+		{uri: "https://acme.blob.core.windows.net/sboms/sbom.json?sv=2022-11-02&sig=secret", remote: true, err: true},
+	}
+
+	for _, tt := range tests {
+		loc, remote, err := parseRemoteLocation(tt.uri)
+		if remote != tt.remote {
+			t.Errorf("parseRemoteLocation(%q) remote = %v, expected %v", tt.uri, remote, tt.remote)
+		}
+		if (err != nil) != tt.err {
+			t.Errorf("parseRemoteLocation(%q) error = %v, expected error %v", tt.uri, err, tt.err)
+		}
+		if !tt.err && loc != tt.expected {
+			t.Errorf("parseRemoteLocation(%q) = %+v, expected %+v", tt.uri, loc, tt.expected)
+		}
+	}
+}
+
+// mockObjectStore records the objects written to it in memory.
+type mockObjectStore struct {
+	objects  map[string]*bytes.Buffer
+	closeErr error
+}
+
+// mockObjectWriter is a writer returned by mockObjectStore.
+type mockObjectWriter struct {
+	*bytes.Buffer
+	closeErr error
+}
+
+// Close returns the configured error of the store.
+func (w mockObjectWriter) Close() error {
+	return w.closeErr
+}
+
+// NewWriter implements objectStore.
+func (s *mockObjectStore) NewWriter(ctx context.Context, loc remoteLocation) (io.WriteCloser, error) {
+	buf := &bytes.Buffer{}
+	s.objects[loc.Bucket+"/"+loc.Object] = buf
+	return mockObjectWriter{Buffer: buf, closeErr: s.closeErr}, nil
+}
+
+// TestUploadFile tests copying a written SBOM to an object store.
+func TestUploadFile(t *testing.T) {
+	localPath := filepath.Join(t.TempDir(), "sbom.json")
+	if err := writeSBOMToJSON(mockSBOM(), localPath); err != nil {
+		t.Fatalf("Failed to write SBOM: %v", err)
+	}
+	content, err := os.ReadFile(localPath)
+	if err != nil {
+		t.Fatalf("Failed to read SBOM: %v", err)
+	}
+
+	loc, _, err := parseRemoteLocation("gs://acme-sboms/platform/sbom.json")
+	if err != nil {
+		t.Fatalf("Failed to parse location: %v", err)
+	}
+
+	store := &mockObjectStore{objects: make(map[string]*bytes.Buffer)}
+	if err := uploadFile(context.Background(), store, localPath, loc); err != nil {
+		t.Fatalf("Failed to upload SBOM: %v", err)
+	}
+	if got := store.objects["acme-sboms/platform/sbom.json"]; got == nil || !bytes.Equal(got.Bytes(), content) {
+		t.Errorf("Expected the uploaded object to match the written SBOM, got %v", got)
+	}
+
+	store.closeErr = errors.New("precondition failed")
+	err = uploadFile(context.Background(), store, localPath, loc)
+	if err == nil || !strings.Contains(err.Error(), "precondition failed") {
+		t.Errorf("Expected the error closing the writer to be reported, got %v", err)
+	}
+}
+
+// TestNewObjectStoreNotBuilt tests the error for an object store that is not compiled in.
+func TestNewObjectStoreNotBuilt(t *testing.T) {
+	_, err := newObjectStore("mock", newAPIClient(nil, 0))
+	if err == nil || !strings.Contains(err.Error(), "-tags mock") {
+		t.Errorf("Expected an error naming the build tag, got %v", err)
+	}
+}
+
+// TestRequestWriter tests that the collected object is sent when the writer is
+// closed, and that a failed upload is reported.
+func TestRequestWriter(t *testing.T) {
+	var received string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = string(body)
+		if r.URL.Path == "/denied" {
+			http.Error(w, "AccessDenied", http.StatusForbidden)
+		}
+	}))
+	defer server.Close()
+
+	newWriter := func(path string) *requestWriter {
+		return &requestWriter{
+			client: newAPIClient(nil, 0),
+			newRequest: func(body []byte) (*http.Request, error) {
+				return http.NewRequest(http.MethodPut, server.URL+path, bytes.NewReader(body))
+			},
+		}
+	}
+
+	w := newWriter("/ok")
+	io.WriteString(w, "module records")
+	if received != "" {
+		t.Errorf("Expected nothing to be sent before the writer is closed, got %q", received)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Failed to close writer: %v", err)
+	}
+	if received != "module records" {
+		t.Errorf("Expected the object to be sent on close, got %q", received)
+	}
+
+	w = newWriter("/denied")
+	io.WriteString(w, "module records")
+	if err := w.Close(); err == nil || !strings.Contains(err.Error(), "AccessDenied") {
+		t.Errorf("Expected a failed upload to be reported, got %v", err)
+	}
+}
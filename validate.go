@@ -0,0 +1,36 @@
+package main
+
+import "fmt"
+
+// validateSBOM checks an SBOM for missing required fields, malformed identifiers, and duplicate module calls,
+// returning a description of each problem found.
+func validateSBOM(sbom *SBOM) []string {
+	var problems []string
+
+	if sbom.SerialNumber != "" && !isValidSerialNumber(sbom.SerialNumber) {
+		problems = append(problems, fmt.Sprintf("invalid serial number %q: expected a urn:uuid URN", sbom.SerialNumber))
+	}
+	if sbom.Version < 0 {
+		problems = append(problems, fmt.Sprintf("invalid version %d", sbom.Version))
+	}
+
+	seen := make(map[moduleKey]bool)
+	for i, mod := range sbom.Modules {
+		if mod.Name == "" {
+			problems = append(problems, fmt.Sprintf("module %d: missing name", i))
+		}
+		if mod.Source == "" {
+			problems = append(problems, fmt.Sprintf("module %d (%s): missing source", i, mod.Name))
+		}
+		if mod.Config == "" {
+			problems = append(problems, fmt.Sprintf("module %d (%s): missing config path", i, mod.Name))
+		}
+
+		if seen[keyOf(mod)] {
+			problems = append(problems, fmt.Sprintf("module %d (%s): duplicate module in config %s", i, mod.Name, mod.Config))
+		}
+		seen[keyOf(mod)] = true
+	}
+
+	return problems
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestValidateSBOM tests reporting of missing fields and duplicate modules.
+func TestValidateSBOM(t *testing.T) {
+	if problems := validateSBOM(mockSBOM()); len(problems) != 0 {
+		t.Errorf("Expected a valid SBOM, got %v", problems)
+	}
+
+	sbom := &SBOM{Modules: []ModuleInfo{
+		{Name: "vpc", Source: "terraform-aws-modules/vpc/aws", Config: "network"},
+		{Name: "", Source: "./modules/dns", Config: "network"},
+		{Name: "vpc", Source: "", Config: "network"},
+	}}
+
+	expected := []string{
+		"module 1: missing name",
+		"module 2 (vpc): missing source",
+		"module 2 (vpc): duplicate module in config network",
+	}
+	if problems := validateSBOM(sbom); !reflect.DeepEqual(problems, expected) {
+		t.Errorf("Validation problems mismatch:\nexpected %v\ngot      %v", expected, problems)
+	}
+}
+
+// TestValidateSBOMSerialNumber tests that a malformed serial number is reported.
+func TestValidateSBOMSerialNumber(t *testing.T) {
+	sbom := mockSBOM()
+	sbom.SerialNumber = "12345"
+
+	expected := []string{`invalid serial number "12345": expected a urn:uuid URN`}
+	if problems := validateSBOM(sbom); !reflect.DeepEqual(problems, expected) {
+		t.Errorf("Validation problems mismatch:\nexpected %v\ngot      %v", expected, problems)
+	}
+}
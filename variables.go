@@ -0,0 +1,216 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/hashicorp/terraform-config-inspect/tfconfig"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+)
+
+// variableBlockSchema describes the variable blocks whose defaults are used when
+// resolving module sources.
+var variableBlockSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: "variable", LabelNames: []string{"name"}},
+	},
+}
+
+// variableDefaultSchema describes the default argument of a variable block.
+var variableDefaultSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{Name: "default"},
+	},
+}
+
+// moduleSourceSchema describes the module call arguments that are resolved against
+// variable values.
+var moduleSourceSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{Name: "source"},
+		{Name: "version"},
+	},
+}
+
+// variableArg is a single -var or -var-file flag. They are kept in the order given
+// on the command line, since later values override earlier ones as in Terraform.
+type variableArg struct {
+	File       string // Path of a .tfvars or .tfvars.json file
+	Assignment string // NAME=VALUE given with -var
+}
+
+// variableArgsFlag collects -var or -var-file flags into a list shared by both.
+type variableArgsFlag struct {
+	args *[]variableArg
+	file bool
+}
+
+// String returns the collected values of this flag separated by commas.
+func (f variableArgsFlag) String() string {
+	if f.args == nil {
+		return ""
+	}
+	var values []string
+	for _, arg := range *f.args {
+		if f.file && arg.File != "" {
+			values = append(values, arg.File)
+		} else if !f.file && arg.Assignment != "" {
+			values = append(values, arg.Assignment)
+		}
+	}
+	return strings.Join(values, ",")
+}
+
+// Set appends a value to the shared list.
+func (f variableArgsFlag) Set(value string) error {
+	if f.file {
+		*f.args = append(*f.args, variableArg{File: value})
+	} else {
+		*f.args = append(*f.args, variableArg{Assignment: value})
+	}
+	return nil
+}
+
+// loadVariables reads the values set by -var-file and -var flags, in order, so a
+// later file or assignment overrides an earlier one. Values given with -var are
+// always strings.
+func loadVariables(args []variableArg) (map[string]cty.Value, error) {
+	values := make(map[string]cty.Value)
+	for _, arg := range args {
+		if arg.File == "" {
+			name, value, ok := strings.Cut(arg.Assignment, "=")
+			name = strings.TrimSpace(name)
+			if !ok || name == "" {
+				return nil, fmt.Errorf("invalid variable assignment %q: expected NAME=VALUE", arg.Assignment)
+			}
+			values[name] = cty.StringVal(value)
+			continue
+		}
+
+		fileValues, err := readVariableFile(expandPath(arg.File))
+		if err != nil {
+			return nil, err
+		}
+		for name, value := range fileValues {
+			values[name] = value
+		}
+	}
+	return values, nil
+}
+
+// readVariableFile reads the variable values assigned in a .tfvars file, or in a
+// .tfvars.json file in the JSON syntax.
+func readVariableFile(path string) (map[string]cty.Value, error) {
+	parser := hclparse.NewParser()
+	var file *hcl.File
+	var diags hcl.Diagnostics
+	if strings.HasSuffix(path, ".json") {
+		file, diags = parser.ParseJSONFile(path)
+	} else {
+		file, diags = parser.ParseHCLFile(path)
+	}
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("failed to read variable file %s: %v", path, diags.Error())
+	}
+
+	attrs, diags := file.Body.JustAttributes()
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("failed to read variable file %s: %v", path, diags.Error())
+	}
+
+	values := make(map[string]cty.Value, len(attrs))
+	for name, attr := range attrs {
+		value, diags := attr.Expr.Value(nil)
+		if diags.HasErrors() {
+			return nil, fmt.Errorf("failed to read variable file %s: %v", path, diags.Error())
+		}
+		values[name] = value
+	}
+	return values, nil
+}
+
+// resolveModuleCalls evaluates module sources and versions that reference input
+// variables, such as "git::https://github.com/acme/vpc.git?ref=${var.vpc_ref}",
+// against the given values and the defaults of the variable blocks, and records the
+// result on the module calls. tfconfig only reads literal strings, so such a source
+// is otherwise empty. Expressions that cannot be evaluated are left as they are.
+// The returned positions locate the resolved expressions, so that the diagnostics
+// tfconfig reported for them can be dropped.
+func resolveModuleCalls(module *tfconfig.Module, files []*hcl.File, values map[string]cty.Value) map[tfconfig.SourcePos]bool {
+	vars := variableDefaults(files)
+	for name, value := range values {
+		vars[name] = value
+	}
+	ctx := &hcl.EvalContext{
+		Variables: map[string]cty.Value{"var": cty.ObjectVal(vars)},
+	}
+
+	resolved := make(map[tfconfig.SourcePos]bool)
+	for _, file := range files {
+		content, _, _ := file.Body.PartialContent(moduleBlockSchema)
+		for _, block := range content.Blocks {
+			modCall, ok := module.ModuleCalls[block.Labels[0]]
+			if !ok {
+				continue
+			}
+
+			attrs, _, _ := block.Body.PartialContent(moduleSourceSchema)
+			for name, attr := range attrs.Attributes {
+				if len(attr.Expr.Variables()) == 0 {
+					continue
+				}
+				value, ok := evaluateString(attr.Expr, ctx)
+				if !ok {
+					continue
+				}
+
+				if name == "source" {
+					modCall.Source = value
+				} else {
+					modCall.Version = value
+				}
+				exprRange := attr.Expr.Range()
+				for line := exprRange.Start.Line; line <= exprRange.End.Line; line++ {
+					resolved[tfconfig.SourcePos{Filename: exprRange.Filename, Line: line}] = true
+				}
+			}
+		}
+	}
+	return resolved
+}
+
+// variableDefaults returns the default value of every variable block that has one.
+func variableDefaults(files []*hcl.File) map[string]cty.Value {
+	defaults := make(map[string]cty.Value)
+	for _, file := range files {
+		content, _, _ := file.Body.PartialContent(variableBlockSchema)
+		for _, block := range content.Blocks {
+			attrs, _, _ := block.Body.PartialContent(variableDefaultSchema)
+			attr, ok := attrs.Attributes["default"]
+			if !ok {
+				continue
+			}
+			if value, diags := attr.Expr.Value(nil); !diags.HasErrors() {
+				defaults[block.Labels[0]] = value
+			}
+		}
+	}
+	return defaults
+}
+
+// evaluateString evaluates an expression to a string, reporting false if it cannot
+// be evaluated or does not convert to a string.
+func evaluateString(expr hcl.Expression, ctx *hcl.EvalContext) (string, bool) {
+	value, diags := expr.Value(ctx)
+	if diags.HasErrors() || !value.IsWhollyKnown() || value.IsNull() {
+		return "", false
+	}
+	str, err := convert.Convert(value, cty.String)
+	if err != nil {
+		return "", false
+	}
+	return str.AsString(), true
+}
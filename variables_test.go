@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// TestGenerateSBOMWithVariables tests that module sources and versions referencing
+// variables resolve against variable files and assignments, with later ones overriding
+// earlier ones and variable defaults used for anything unset.
+func TestGenerateSBOMWithVariables(t *testing.T) {
+	tests := []struct {
+		name            string
+		args            []variableArg
+		expectedRef     string
+		expectedVersion string
+	}{
+		{"defaults", nil, "v1.0.0", "N/A"},
+		{"file", []variableArg{{File: "testdata/workspaces/staging.tfvars"}}, "v1.1.0", "5.1.0"},
+		{"later file overrides", []variableArg{{File: "testdata/workspaces/staging.tfvars"}, {File: "testdata/workspaces/prod.tfvars.json"}}, "v2.0.0", "5.1.0"},
+		{"assignment overrides file", []variableArg{{Assignment: "network_ref=v3.0.0"}, {File: "testdata/workspaces/staging.tfvars"}, {Assignment: "vpc_version=6.0.0"}}, "v1.1.0", "6.0.0"},
+	}
+
+	for _, tt := range tests {
+		variables, err := loadVariables(tt.args)
+		if err != nil {
+			t.Fatalf("%s: Failed to load variables: %v", tt.name, err)
+		}
+
+		sbom, err := generateSBOM(context.Background(), "testdata/workspaces", scanOptions{Variables: variables})
+		if err != nil {
+			t.Fatalf("%s: Failed to generate SBOM: %v", tt.name, err)
+		}
+
+		versions := make(map[string]string)
+		for _, mod := range sbom.Modules {
+			versions[mod.Name] = mod.Version
+		}
+		if versions["network"] != tt.expectedRef {
+			t.Errorf("%s: Expected network to be pinned to %s, got %s", tt.name, tt.expectedRef, versions["network"])
+		}
+		if versions["vpc"] != tt.expectedVersion {
+			t.Errorf("%s: Expected vpc version %s, got %s", tt.name, tt.expectedVersion, versions["vpc"])
+		}
+
+		for _, warning := range sbom.Warnings {
+			if strings.Contains(warning, "main.tf:11") {
+				t.Errorf("%s: Expected no warning for the resolved network source, got %q", tt.name, warning)
+			}
+		}
+	}
+}
+
+// TestGenerateSBOMWithoutVariables tests that sources referencing variables are left
+// unresolved, with a warning, when no variables are given.
+func TestGenerateSBOMWithoutVariables(t *testing.T) {
+	sbom, err := generateSBOM(context.Background(), "testdata/workspaces", scanOptions{})
+	if err != nil {
+		t.Fatalf("Failed to generate SBOM: %v", err)
+	}
+
+	for _, mod := range sbom.Modules {
+		if mod.Name == "network" && mod.Source != "" {
+			t.Errorf("Expected the network source to be unresolved, got %s", mod.Source)
+		}
+	}
+	if len(sbom.Warnings) == 0 {
+		t.Errorf("Expected warnings for the unresolved sources")
+	}
+}
+
+// TestLoadVariablesInvalid tests that malformed assignments and unreadable files are errors.
+func TestLoadVariablesInvalid(t *testing.T) {
+	for _, args := range [][]variableArg{
+		{{Assignment: "network_ref"}},
+		{{Assignment: "=v1.0.0"}},
+		{{File: "testdata/workspaces/missing.tfvars"}},
+	} {
+		if _, err := loadVariables(args); err == nil {
+			t.Errorf("Expected an error loading %+v", args)
+		}
+	}
+}
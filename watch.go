@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchDebounce is how long -watch waits after the last change before scanning
+// again, so that saving several files at once only triggers one scan.
+const watchDebounce = 500 * time.Millisecond
+
+// watchConfig calls run once and then again whenever a Terraform configuration file
+// in dir changes, or in any directory below it when recursive is set. Changes are
+// debounced: run is only called once no further change has been seen for debounce.
+// Directories created while watching are watched as well. It returns when ctx is
+// done.
+func watchConfig(ctx context.Context, dir string, recursive bool, debounce time.Duration, run func()) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to start watching: %v", err)
+	}
+	defer watcher.Close()
+
+	if err := watchDirs(watcher, dir, recursive); err != nil {
+		return err
+	}
+
+	run()
+
+	var pending <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if recursive && event.Has(fsnotify.Create) {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() && !strings.HasPrefix(info.Name(), ".") {
+					if err := watchDirs(watcher, event.Name, true); err != nil {
+						fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+					}
+					pending = time.After(debounce)
+					continue
+				}
+			}
+			if isConfigChange(event) {
+				pending = time.After(debounce)
+			}
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+
+		case <-pending:
+			pending = nil
+			run()
+		}
+	}
+}
+
+// watchDirs adds dir to the watcher and, when recursive is set, every directory
+// below it. Hidden directories such as .terraform are skipped, as in a recursive scan.
+func watchDirs(watcher *fsnotify.Watcher, dir string, recursive bool) error {
+	if !recursive {
+		if err := watcher.Add(dir); err != nil {
+			return fmt.Errorf("failed to watch %s: %v", dir, err)
+		}
+		return nil
+	}
+
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return fmt.Errorf("failed to walk %s: %v", path, err)
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != dir && strings.HasPrefix(d.Name(), ".") {
+			return filepath.SkipDir
+		}
+		if err := watcher.Add(path); err != nil {
+			return fmt.Errorf("failed to watch %s: %v", path, err)
+		}
+		return nil
+	})
+}
+
+// isConfigChange reports whether an event changes the content of a Terraform or OpenTofu
+// configuration file. Permission changes and editor swap files are ignored.
+func isConfigChange(event fsnotify.Event) bool {
+	name := filepath.Base(event.Name)
+	if isIgnoredFile(name) || !isConfigFile(name) {
+		return false
+	}
+	return event.Has(fsnotify.Create) || event.Has(fsnotify.Write) || event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename)
+}
+
+// runWatch implements -watch. Each scan runs this program again with the same
+// arguments and -watch turned off, so every scan starts from a clean state and one
+// that fails, for example because a file is half-written or a policy check fails,
+// does not end the watch. The first scan is run with args and later ones with
+// rescanArgs, which can replace the output the first scan wrote.
+func runWatch(ctx context.Context, configPath string, recursive bool, args []string, rescanArgs []string) error {
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to find the executable to run scans with: %v", err)
+	}
+
+	first := true
+	run := func() {
+		scanArgs := rescanArgs
+		if first {
+			scanArgs = args
+		}
+		first = false
+
+		cmd := exec.CommandContext(ctx, executable, append([]string{"scan"}, scanArgs...)...)
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil && ctx.Err() == nil {
+			fmt.Fprintf(os.Stderr, "Warning: scan failed: %v\n", err)
+		}
+		if ctx.Err() == nil {
+			fmt.Fprintf(os.Stderr, "Info: watching %s for changes; press Ctrl+C to stop\n", configPath)
+		}
+	}
+
+	return watchConfig(ctx, configPath, recursive, watchDebounce, run)
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestWatchConfig tests that a burst of changes to configuration files triggers a
+// single run, that other files are ignored, and that new subdirectories are watched
+// in recursive mode.
+func TestWatchConfig(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, content string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("main.tf", "module \"vpc\" {}\n")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	runs := make(chan struct{}, 10)
+	done := make(chan error)
+	go func() {
+		done <- watchConfig(ctx, dir, true, 100*time.Millisecond, func() { runs <- struct{}{} })
+	}()
+
+	expectRuns := func(step string, expected int) {
+		t.Helper()
+		count := 0
+		timeout := time.After(500 * time.Millisecond)
+		for {
+			select {
+			case <-runs:
+				count++
+				continue
+			case <-timeout:
+			}
+			break
+		}
+		if count != expected {
+			t.Fatalf("%s: expected %d run(s), got %d", step, expected, count)
+		}
+	}
+
+	expectRuns("initial scan", 1)
+
+	for i := 0; i < 5; i++ {
+		write("main.tf", "module \"vpc\" {}\n"+string(rune('a'+i))+" = 1\n")
+		time.Sleep(10 * time.Millisecond)
+	}
+	expectRuns("burst of writes", 1)
+
+	write("notes.txt", "not a configuration file\n")
+	write(".main.tf.swp", "swap file\n")
+	expectRuns("unrelated files", 0)
+
+	if err := os.Mkdir(filepath.Join(dir, "network"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	expectRuns("new directory", 1)
+	write("network/main.tf.json", "{}\n")
+	expectRuns("file in new directory", 1)
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Expected watching to stop cleanly, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected watching to stop when the context is cancelled")
+	}
+}